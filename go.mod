@@ -3,7 +3,10 @@ module github.com/jimyag/ripples
 go 1.25
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/sourcegraph/go-diff v0.7.0
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/mod v0.30.0
 	golang.org/x/tools v0.38.0
 	golang.org/x/tools/gopls v0.0.0
 )
@@ -18,7 +21,6 @@ require (
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/exp/typeparams v0.0.0-20251023183803-a4bb9ffd2546 // indirect
-	golang.org/x/mod v0.30.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/telemetry v0.0.0-20251111182119-bc8e575c7b54 // indirect
@@ -0,0 +1,165 @@
+package analyzer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+// buildFiles are the names of Bazel BUILD files, in the order Bazel prefers them
+var buildFiles = []string{"BUILD.bazel", "BUILD"}
+
+// bazelTarget returns the Bazel label building a path's binary: from mapping,
+// keyed by main package directory first, then by binary name, otherwise the
+// go_binary rule in the BUILD file of the main package directory. Empty when the
+// binary is not built by Bazel
+func (f *pathFilter) bazelTarget(p lsp.CallPath, mapping map[string]string) string {
+	dir := f.mainDir(p.MainURI)
+	for _, key := range []string{dir, p.BinaryName} {
+		if target, ok := mapping[key]; ok {
+			return target
+		}
+	}
+	for _, name := range buildFiles {
+		src, err := os.ReadFile(filepath.Join(f.root, filepath.FromSlash(dir), name))
+		if err != nil {
+			continue
+		}
+		if rule := goBinaryRule(string(src), path.Base(dir)); rule != "" {
+			return bazelLabel(dir, rule)
+		}
+		return ""
+	}
+	return ""
+}
+
+// bazelLabel returns the label of the rule name in the package at dir,
+// relative to the workspace root
+func bazelLabel(dir, name string) string {
+	if dir == "." {
+		dir = ""
+	}
+	return "//" + dir + ":" + name
+}
+
+// ruleName matches the name attribute of a rule
+var ruleName = regexp.MustCompile(`(?:^|[(,\s])name\s*=\s*"([^"]+)"`)
+
+// goBinaryRule returns the name of the go_binary rule in a BUILD file, the one
+// named after the directory (as Gazelle names them) when there are several.
+// Empty when the file declares none
+func goBinaryRule(src, dirName string) string {
+	var names []string
+	for _, call := range ruleCalls(src, "go_binary") {
+		if m := ruleName.FindStringSubmatch(call); m != nil {
+			names = append(names, m[1])
+		}
+	}
+	for _, name := range names {
+		if name == dirName {
+			return name
+		}
+	}
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// ruleCalls returns the arguments of each call of the rule kind in a BUILD
+// file without comments, skipping calls in comments and strings
+func ruleCalls(src, kind string) []string {
+	var res []string
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			i = skipString(src, i)
+		case strings.HasPrefix(src[i:], kind) && (i == 0 || !isIdentByte(src[i-1])):
+			j := i + len(kind)
+			for j < len(src) && (src[j] == ' ' || src[j] == '\t') {
+				j++
+			}
+			if j >= len(src) || src[j] != '(' {
+				continue
+			}
+			end := closingParen(src, j)
+			res = append(res, stripComments(src[j+1:end]))
+			i = end
+		}
+	}
+	return res
+}
+
+// closingParen returns the index of the parenthesis closing the one at open,
+// or the end of src when it is not closed
+func closingParen(src string, open int) int {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			i = skipString(src, i)
+		case '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(src)
+}
+
+// stripComments removes the comments of BUILD file source
+func stripComments(src string) string {
+	var b strings.Builder
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			end := min(skipString(src, i), len(src)-1)
+			b.WriteString(src[i : end+1])
+			i = end
+		case '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				b.WriteByte('\n')
+			}
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return b.String()
+}
+
+// skipString returns the index of the quote closing the string starting at i
+func skipString(src string, i int) int {
+	quote := src[i]
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(src)
+}
+
+// isIdentByte reports whether c may be part of an identifier
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
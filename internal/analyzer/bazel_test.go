@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+func TestGoBinaryRule(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"gazelle", `load("@io_bazel_rules_go//go:def.bzl", "go_binary", "go_library")
+
+go_library(
+    name = "api_lib",
+    srcs = ["main.go"],
+)
+
+go_binary(
+    name = "api",
+    embed = [":api_lib"],
+    visibility = ["//visibility:public"],
+)
+`, "api"},
+		{"one line", `go_binary(name = "server", srcs = ["main.go"])`, "server"},
+		{"named after the directory", `go_binary(name = "api_race", race = "on")
+go_binary(name = "api")`, "api"},
+		{"comments and strings", `# go_binary(name = "commented")
+genrule(name = "gen", cmd = "go_binary(name = \"quoted\")")
+go_binary(
+    # name = "ignored"
+    name = "real",
+)`, "real"},
+		{"no binary", `go_library(name = "api_lib")
+my_go_binary(name = "macro")`, ""},
+	}
+	for _, tt := range tests {
+		if got := goBinaryRule(tt.src, "api"); got != tt.want {
+			t.Errorf("%s: goBinaryRule = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBazelTarget(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"cmd/api/BUILD.bazel":    `go_binary(name = "api")`,
+		"cmd/api/BUILD":          `go_binary(name = "legacy")`,
+		"cmd/worker/BUILD":       `go_binary(name = "worker_bin")`,
+		"cmd/tool/BUILD.bazel":   `go_library(name = "tool_lib")`,
+		"BUILD.bazel":            `go_binary(name = "root")`,
+		"cmd/mapped/BUILD.bazel": `go_binary(name = "mapped")`,
+	}
+	for name, src := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := newPathFilter(root, Options{})
+	mapping := map[string]string{"cmd/mapped": "//deploy:mapped_image", "legacy-cli": "//tools:cli"}
+	tests := []struct {
+		binary, dir, want string
+	}{
+		{"api", "cmd/api", "//cmd/api:api"},
+		{"worker", "cmd/worker", "//cmd/worker:worker_bin"},
+		{"tool", "cmd/tool", ""},
+		{"plain", "cmd/plain", ""},
+		{"root", ".", "//:root"},
+		{"mapped", "cmd/mapped", "//deploy:mapped_image"},
+		{"legacy-cli", "cmd/legacy", "//tools:cli"},
+	}
+	for _, tt := range tests {
+		p := lsp.CallPath{BinaryName: tt.binary, MainURI: lsp.URIFromPath(filepath.Join(root, tt.dir, "main.go"))}
+		if got := f.bazelTarget(p, mapping); got != tt.want {
+			t.Errorf("bazelTarget(%s) = %q, want %q", tt.binary, got, tt.want)
+		}
+	}
+}
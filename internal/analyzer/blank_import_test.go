@@ -181,3 +181,20 @@ func TestIsSupportedSymbolKindImport(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeSkipsPlainImports(t *testing.T) {
+	change := ChangedSymbol{Symbol: &parser.Symbol{
+		Name:  "fmt",
+		Kind:  parser.SymbolKindImport,
+		Extra: parser.ImportExtra{Path: "fmt"},
+	}}
+
+	// 普通导入不会单独执行代码,不追踪也不报告为影响未知
+	report, err := (&LSPImpactAnalyzer{}).Analyze([]ChangedSymbol{change})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Unknown) != 0 || len(report.Affected) != 0 {
+		t.Errorf("Expected an empty report, got %+v", report)
+	}
+}
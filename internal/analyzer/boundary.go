@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+// Defaults used by the cross-service filter when only part of the rules is configured.
+// They mirror the heuristics built into the gopls tracer.
+var (
+	DefaultServices       = []string{"cmd/*", "internal/*"}
+	DefaultCommonPackages = []string{"pkg/", "common/", "shared/", "lib/"}
+)
+
+// InterfaceFilter controls how the cross-service filter prunes paths that enter
+// another service through dynamic dispatch
+type InterfaceFilter string
+
+const (
+	// InterfaceFilterStrict prunes paths entering another service through an
+	// interface call or function value, and through calls it cannot type-check
+	InterfaceFilterStrict InterfaceFilter = "strict"
+	// InterfaceFilterLoose only prunes paths whose dispatch is proven by type
+	// information, calls it cannot type-check are kept
+	InterfaceFilterLoose InterfaceFilter = "loose"
+	// InterfaceFilterOff keeps all paths. The heuristic built into the gopls
+	// tracer still applies
+	InterfaceFilterOff InterfaceFilter = "off"
+)
+
+// ParseInterfaceFilter parses an interface filter mode, "" is strict
+func ParseInterfaceFilter(s string) (InterfaceFilter, error) {
+	switch m := InterfaceFilter(s); m {
+	case "":
+		return InterfaceFilterStrict, nil
+	case InterfaceFilterStrict, InterfaceFilterLoose, InterfaceFilterOff:
+		return m, nil
+	}
+	return "", i18n.Errorf("不支持的接口过滤模式: %s", s)
+}
+
+// pathFilter drops traced paths that the repository configuration rules out.
+//
+// It runs on top of the gopls tracer's own heuristics and can only remove
+// paths. The tracer reports each binary once per run, so a binary whose only
+// reported path is filtered out is not reported even if another valid path exists.
+//
+// Service boundaries come from the configured rules; whether a path may cross
+// one is decided with type information, see crossesServices.
+type pathFilter struct {
+	root        string          // Absolute repository root
+	modules     []string        // Module paths used to relativize package paths
+	entrypoints []string        // Main package directory patterns, empty means all
+	targets     map[string]bool // Names of the only binaries to keep, nil means all
+	services    []string        // Service boundary patterns, empty disables the cross-service check
+	common      []string        // Shared package prefixes that belong to no service
+	loose       bool            // Keep paths entering another service through calls that cannot be type-checked
+	calls       *callResolver
+}
+
+func newPathFilter(root string, opts Options) *pathFilter {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	f := &pathFilter{
+		root:        root,
+		modules:     opts.Modules,
+		entrypoints: opts.Entrypoints,
+		calls:       newCallResolver(root, nil),
+	}
+	if len(opts.Targets) > 0 {
+		f.targets = make(map[string]bool, len(opts.Targets))
+		for _, name := range opts.Targets {
+			f.targets[name] = true
+		}
+	}
+
+	// Configuring either services or common packages enables the filter,
+	// the other half falls back to the defaults
+	mode := opts.InterfaceFilter
+	if opts.DisableCrossServiceFilter {
+		mode = InterfaceFilterOff
+	}
+	if mode != InterfaceFilterOff && (len(opts.Services) > 0 || len(opts.CommonPackages) > 0) {
+		f.loose = mode == InterfaceFilterLoose
+		f.services = opts.Services
+		if len(f.services) == 0 {
+			f.services = DefaultServices
+		}
+		f.common = opts.CommonPackages
+		if len(f.common) == 0 {
+			f.common = DefaultCommonPackages
+		}
+	}
+	return f
+}
+
+// mode returns the effective interface filter mode
+func (f *pathFilter) mode() InterfaceFilter {
+	switch {
+	case len(f.services) == 0:
+		return InterfaceFilterOff
+	case f.loose:
+		return InterfaceFilterLoose
+	}
+	return InterfaceFilterStrict
+}
+
+// filter returns the paths that pass the entrypoint and service boundary rules
+func (f *pathFilter) filter(paths []lsp.CallPath) []lsp.CallPath {
+	if len(f.entrypoints) == 0 && len(f.services) == 0 && f.targets == nil {
+		return paths
+	}
+
+	var res []lsp.CallPath
+	for _, p := range paths {
+		if f.targets != nil && !f.targets[p.BinaryName] {
+			continue
+		}
+		if len(f.entrypoints) > 0 && !config.MatchAny(f.entrypoints, f.mainDir(p.MainURI)) {
+			continue
+		}
+		if len(f.services) > 0 && f.crossesServices(p) {
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// owners looks up the owning teams of a path's binary: the configured mapping
+// by main package directory or binary name first, then CODEOWNERS on the main file
+func (f *pathFilter) owners(p lsp.CallPath, mapping map[string][]string, codeOwners interface{ Owners(string) []string }) []string {
+	dir := f.mainDir(p.MainURI)
+	for _, key := range []string{dir, p.BinaryName} {
+		if owners, ok := mapping[key]; ok {
+			return owners
+		}
+	}
+	if codeOwners == nil {
+		return nil
+	}
+	return codeOwners.Owners(path.Join(dir, filepath.Base(lsp.PathFromURI(p.MainURI))))
+}
+
+// team looks up the team of a path's binary: the first team in name order with
+// a pattern matching the main package directory or the binary name
+func (f *pathFilter) team(p lsp.CallPath, teams map[string][]string) string {
+	if len(teams) == 0 {
+		return ""
+	}
+	dir := f.mainDir(p.MainURI)
+	for _, team := range slices.Sorted(maps.Keys(teams)) {
+		for _, pattern := range teams[team] {
+			if pattern == p.BinaryName || config.Match(pattern, dir) {
+				return team
+			}
+		}
+	}
+	return ""
+}
+
+// mainDir returns the directory of a main file URI relative to the repository root
+func (f *pathFilter) mainDir(uri string) string {
+	filename := lsp.PathFromURI(uri)
+	rel, err := filepath.Rel(f.root, filepath.Dir(filename))
+	if err != nil {
+		return filepath.ToSlash(filepath.Dir(filename))
+	}
+	return filepath.ToSlash(rel)
+}
+
+// deployment looks up the deployment of a path's binary by main package
+// directory first, then by binary name
+func (f *pathFilter) deployment(p lsp.CallPath, deployments map[string]Deployment) *Deployment {
+	if len(deployments) == 0 {
+		return nil
+	}
+	for _, key := range []string{f.mainDir(p.MainURI), p.BinaryName} {
+		if d, ok := deployments[key]; ok {
+			return &d
+		}
+	}
+	return nil
+}
+
+// crossesServices reports whether a path enters another service through dynamic
+// dispatch. The path starts in the service of its binary; a static call into
+// another service's package is a real dependency and makes that service part of
+// the path, while an interface call or function value only reaches
+// implementations in services already on the path. Edges that cannot be
+// type-checked count as dispatch unless the filter is loose
+func (f *pathFilter) crossesServices(p lsp.CallPath) bool {
+	var onPath []string
+	for i, node := range p.Path {
+		svc := f.serviceOf(node.PackagePath)
+		if svc == "" || slices.Contains(onPath, svc) {
+			continue
+		}
+		if len(onPath) > 0 {
+			static, ok := f.calls.static(p.Path[i-1], node)
+			if ok && !static || !ok && !f.loose {
+				return true
+			}
+		}
+		onPath = append(onPath, svc)
+	}
+	return false
+}
+
+// serviceOf returns the service a package belongs to, or "" for shared and
+// external packages
+func (f *pathFilter) serviceOf(pkgPath string) string {
+	rel, ok := f.relativePackage(pkgPath)
+	if !ok || f.isCommon(rel) {
+		return ""
+	}
+
+	segs := strings.Split(rel, "/")
+	for _, pattern := range f.services {
+		pat := strings.Split(strings.Trim(pattern, "/"), "/")
+		if len(segs) < len(pat) {
+			continue
+		}
+		matched := true
+		for i, p := range pat {
+			if ok, _ := path.Match(p, segs[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return segs[len(pat)-1]
+		}
+	}
+	return ""
+}
+
+// relativePackage strips the longest matching module path from a package path
+func (f *pathFilter) relativePackage(pkgPath string) (string, bool) {
+	if len(f.modules) == 0 {
+		return pkgPath, true
+	}
+	best, found := "", false
+	for _, module := range f.modules {
+		if pkgPath == module {
+			return "", true
+		}
+		if rel, ok := strings.CutPrefix(pkgPath, module+"/"); ok && (!found || len(rel) < len(best)) {
+			best, found = rel, true
+		}
+	}
+	return best, found
+}
+
+// isCommon reports whether a module-relative package path is a shared package
+func (f *pathFilter) isCommon(rel string) bool {
+	for _, prefix := range f.common {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+func boundaryPath(binary, mainURI string, pkgs ...string) lsp.CallPath {
+	p := lsp.CallPath{BinaryName: binary, MainURI: mainURI}
+	for _, pkg := range pkgs {
+		p.Path = append(p.Path, lsp.CallNode{FunctionName: "F", PackagePath: pkg})
+	}
+	return p
+}
+
+func TestPathFilterEntrypoints(t *testing.T) {
+	f := newPathFilter("/repo", Options{Entrypoints: []string{"cmd/*"}})
+
+	paths := f.filter([]lsp.CallPath{
+		boundaryPath("api", "file:///repo/cmd/api/main.go", "m/cmd/api"),
+		boundaryPath("gen", "file:///repo/tools/gen/main.go", "m/tools/gen"),
+	})
+	if len(paths) != 1 || paths[0].BinaryName != "api" {
+		t.Errorf("Expected only cmd/api, got %+v", paths)
+	}
+}
+
+func TestPathFilterTargets(t *testing.T) {
+	f := newPathFilter("/repo", Options{Targets: []string{"api"}})
+
+	paths := f.filter([]lsp.CallPath{
+		boundaryPath("api", "file:///repo/cmd/api/main.go", "m/cmd/api"),
+		boundaryPath("worker", "file:///repo/cmd/worker/main.go", "m/cmd/worker"),
+	})
+	if len(paths) != 1 || paths[0].BinaryName != "api" {
+		t.Errorf("Expected only the api target, got %+v", paths)
+	}
+}
+
+func TestPathFilterServices(t *testing.T) {
+	f := newPathFilter("/repo", Options{
+		Modules:        []string{"m"},
+		Services:       []string{"cmd/*", "internal/*"},
+		CommonPackages: []string{"pkg/", "foundation"},
+	})
+
+	tests := []struct {
+		name string
+		path lsp.CallPath
+		keep bool
+	}{
+		{"same service", boundaryPath("bill", "", "m/cmd/bill", "m/internal/bill/server", "m/pkg/log"), true},
+		{"via shared package", boundaryPath("bill", "", "m/cmd/bill", "m/foundation/grace", "m/internal/bill/api"), true},
+		{"external package", boundaryPath("bill", "", "m/cmd/bill", "github.com/x/internal/rfs"), true},
+		{"cross service", boundaryPath("rfs", "", "m/cmd/rfs", "m/foundation/grace", "m/internal/bill/api"), false},
+	}
+	for _, tt := range tests {
+		got := len(f.filter([]lsp.CallPath{tt.path})) == 1
+		if got != tt.keep {
+			t.Errorf("%s: keep = %v, want %v", tt.name, got, tt.keep)
+		}
+	}
+}
+
+func TestPathFilterDeployment(t *testing.T) {
+	f := newPathFilter("/repo", Options{})
+	deployments := map[string]Deployment{
+		"cmd/api":  {Image: "registry/api"},
+		"worker":   {HelmRelease: "worker"},
+		"cmd/gone": {Image: "registry/gone"},
+	}
+
+	if d := f.deployment(boundaryPath("api", "file:///repo/cmd/api/main.go"), deployments); d == nil || d.Image != "registry/api" {
+		t.Errorf("Expected lookup by directory, got %+v", d)
+	}
+	if d := f.deployment(boundaryPath("worker", "file:///repo/cmd/worker/main.go"), deployments); d == nil || d.HelmRelease != "worker" {
+		t.Errorf("Expected lookup by binary name, got %+v", d)
+	}
+	if d := f.deployment(boundaryPath("other", "file:///repo/cmd/other/main.go"), deployments); d != nil {
+		t.Errorf("Expected no deployment, got %+v", d)
+	}
+}
+
+type staticOwners map[string][]string
+
+func (o staticOwners) Owners(file string) []string { return o[file] }
+
+func TestPathFilterOwners(t *testing.T) {
+	f := newPathFilter("/repo", Options{})
+	mapping := map[string][]string{"worker": {"@jobs"}}
+	codeOwners := staticOwners{"cmd/api/main.go": {"@api"}, "cmd/worker/main.go": {"@platform"}}
+
+	if got := f.owners(boundaryPath("api", "file:///repo/cmd/api/main.go"), mapping, codeOwners); len(got) != 1 || got[0] != "@api" {
+		t.Errorf("Expected CODEOWNERS lookup on main file, got %v", got)
+	}
+	if got := f.owners(boundaryPath("worker", "file:///repo/cmd/worker/main.go"), mapping, codeOwners); len(got) != 1 || got[0] != "@jobs" {
+		t.Errorf("Expected config mapping to take precedence, got %v", got)
+	}
+	if got := f.owners(boundaryPath("x", "file:///repo/cmd/x/main.go"), nil, nil); got != nil {
+		t.Errorf("Expected no owners, got %v", got)
+	}
+}
+
+func TestPathFilterTeam(t *testing.T) {
+	f := newPathFilter("/repo", Options{})
+	teams := map[string][]string{
+		"payments": {"cmd/billing", "cmd/pay-*"},
+		"platform": {"worker", "cmd/pay-gateway"},
+	}
+
+	if got := f.team(boundaryPath("pay-api", "file:///repo/cmd/pay-api/main.go"), teams); got != "payments" {
+		t.Errorf("Expected lookup by directory pattern, got %q", got)
+	}
+	if got := f.team(boundaryPath("worker", "file:///repo/cmd/worker/main.go"), teams); got != "platform" {
+		t.Errorf("Expected lookup by binary name, got %q", got)
+	}
+	// A binary matching several teams belongs to the first one by name
+	if got := f.team(boundaryPath("pay-gateway", "file:///repo/cmd/pay-gateway/main.go"), teams); got != "payments" {
+		t.Errorf("Expected first team by name, got %q", got)
+	}
+	if got := f.team(boundaryPath("other", "file:///repo/cmd/other/main.go"), teams); got != "" {
+		t.Errorf("Expected no team, got %q", got)
+	}
+}
+
+func TestPathFilterDisabled(t *testing.T) {
+	in := []lsp.CallPath{boundaryPath("rfs", "", "m/cmd/rfs", "m/internal/bill/api")}
+
+	if got := newPathFilter("/repo", Options{}).filter(in); len(got) != 1 {
+		t.Errorf("Expected no filtering without rules, got %+v", got)
+	}
+
+	f := newPathFilter("/repo", Options{Modules: []string{"m"}, Services: DefaultServices, DisableCrossServiceFilter: true})
+	if got := f.filter(in); len(got) != 1 {
+		t.Errorf("Expected no filtering when disabled, got %+v", got)
+	}
+}
+
+func TestPathFilterCommonOnly(t *testing.T) {
+	// Only common packages configured: services default to cmd/* and internal/*
+	f := newPathFilter("/repo", Options{Modules: []string{"m"}, CommonPackages: []string{"foundation/", "x/"}})
+
+	keep := boundaryPath("bill", "", "m/cmd/bill", "m/x/internal/retry", "m/internal/bill/api")
+	drop := boundaryPath("rfs", "", "m/cmd/rfs", "m/foundation/grace", "m/internal/bill/api")
+	got := f.filter([]lsp.CallPath{keep, drop})
+	if len(got) != 1 || got[0].BinaryName != "bill" {
+		t.Errorf("Expected only the same-service path, got %+v", got)
+	}
+}
+
+func TestPathFilterStaticCalls(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "service-boundary-test")
+	f := newPathFilter(root, Options{Modules: []string{"example.com/boundary"}, Services: DefaultServices})
+
+	node := func(pkg, fn string) lsp.CallNode {
+		return lsp.CallNode{FunctionName: fn, PackagePath: "example.com/boundary/" + pkg}
+	}
+	tests := []struct {
+		name string
+		path lsp.CallPath
+		keep bool
+	}{
+		// rfs calls api.Lookup directly, so bill's package is a real dependency
+		{"static call", lsp.CallPath{BinaryName: "rfs", Path: []lsp.CallNode{
+			node("cmd/rfs", "main"), node("internal/bill/api", "Lookup"),
+		}}, true},
+		// grace.Run calls Runner.Start; rfs never passes bill's Server
+		{"interface call", lsp.CallPath{BinaryName: "rfs", Path: []lsp.CallNode{
+			node("cmd/rfs", "main"), node("pkg/grace", "Run"), node("internal/bill/api", "Start"),
+		}}, false},
+		{"interface call in the same service", lsp.CallPath{BinaryName: "bill", Path: []lsp.CallNode{
+			node("cmd/bill", "main"), node("pkg/grace", "Run"), node("internal/bill/api", "Start"),
+		}}, true},
+	}
+	for _, tt := range tests {
+		got := len(f.filter([]lsp.CallPath{tt.path})) == 1
+		if got != tt.keep {
+			t.Errorf("%s: keep = %v, want %v", tt.name, got, tt.keep)
+		}
+	}
+}
+
+func TestPathFilterInterfaceFilter(t *testing.T) {
+	// The caller's package cannot be loaded under /repo, so the call cannot be type-checked
+	in := []lsp.CallPath{boundaryPath("rfs", "", "m/cmd/rfs", "m/internal/bill/api")}
+	opts := Options{Modules: []string{"m"}, Services: DefaultServices}
+
+	tests := []struct {
+		mode InterfaceFilter
+		keep bool
+	}{
+		{"", false},
+		{InterfaceFilterStrict, false},
+		{InterfaceFilterLoose, true},
+		{InterfaceFilterOff, true},
+	}
+	for _, tt := range tests {
+		opts.InterfaceFilter = tt.mode
+		f := newPathFilter("/repo", opts)
+		if got := len(f.filter(in)) == 1; got != tt.keep {
+			t.Errorf("%q: keep = %v, want %v", tt.mode, got, tt.keep)
+		}
+		want := tt.mode
+		if want == "" {
+			want = InterfaceFilterStrict
+		}
+		if f.mode() != want {
+			t.Errorf("%q: mode = %q, want %q", tt.mode, f.mode(), want)
+		}
+	}
+
+	if m := newPathFilter("/repo", Options{InterfaceFilter: InterfaceFilterLoose}).mode(); m != InterfaceFilterOff {
+		t.Errorf("Expected off without service rules, got %q", m)
+	}
+	if _, err := ParseInterfaceFilter("lenient"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
@@ -1,12 +1,13 @@
 package analyzer
 
 import (
-	"fmt"
 	"go/token"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
 	"github.com/jimyag/ripples/internal/parser"
 )
 
@@ -14,6 +15,7 @@ import (
 type ChangeDetector struct {
 	parser      *parser.Parser
 	projectPath string
+	embeddings  *parser.Embeddings // 按需加载
 }
 
 // NewChangeDetector 创建变更检测器
@@ -29,28 +31,76 @@ type ChangedSymbol struct {
 	Symbol      *parser.Symbol
 	ChangeType  ChangeType
 	PackagePath string
+	// Modification 修改的内容,只用于 ChangeTypeModify
+	Modification Modification
+	Lines        []int // 符号内变更的行号(新版本文件中)
+	// Hunks 包含 Lines 的 diff hunk 头,如 "@@ -28,6 +28,7 @@ func LogMessage(message string) {",
+	// 直接指定符号时为空
+	Hunks []string
+
+	// 常量变更前后的值(源码表达式),变更前不存在或由 iota 隐式重复时为空
+	OldValue string
+	NewValue string
+
+	// Family 值发生变化的枚举式常量所属类型的其他常量,比较它们的函数同样受影响
+	Family []*parser.Symbol
+
+	// Promoted 通过嵌入获得该方法的外层结构体(仅方法)
+	Promoted []*parser.Symbol
+
+	// Local 未导出的符号已在包内完成可达性分析,只需追踪 Exits
+	Local bool
+	// Exits 包内可达性分析得到的出口(导出的函数或方法),为空表示包内没有引用
+	Exits []parser.ExitPoint
+
+	// GoDefer 在 go 或 defer 语句中调用该函数的函数(仅函数)
+	GoDefer []*parser.Symbol
+	// Registrations 包初始化时把该函数登记到注册表的位置(仅函数)
+	Registrations []parser.Registration
 }
 
 // ChangeType 变更类型
 type ChangeType string
 
 const (
-	ChangeTypeAdd    ChangeType = "ADD"
-	ChangeTypeModify ChangeType = "MODIFY"
-	ChangeTypeDelete ChangeType = "DELETE" // 目前主要关注修改和新增
+	ChangeTypeAdd    ChangeType = "ADD"    // 旧版本中没有的符号,通常不影响已有的服务
+	ChangeTypeModify ChangeType = "MODIFY" // 无法比较新旧版本时也视为修改
+	ChangeTypeDelete ChangeType = "DELETE" // 新版本中已不存在的符号,不追踪: 引用它的代码也一定发生了变更
+)
+
+// Modification 修改的内容
+type Modification string
+
+const (
+	ModificationSignature Modification = "signature" // 函数签名或接收者、类型定义、常量和变量声明的类型变化
+	ModificationValue     Modification = "value"     // 常量或变量的值变化
+	ModificationBody      Modification = "body"      // 只有实现变化,如函数体
 )
 
-// DetectChanges 检测变更的符号
+// modificationOf 返回签名没有变化(或无法比较)时该种类符号的修改内容
+func modificationOf(kind parser.SymbolKind) Modification {
+	if kind == parser.SymbolKindConstant || kind == parser.SymbolKindVariable {
+		return ModificationValue
+	}
+	return ModificationBody
+}
+
+// DetectChanges 检测两个 commit 之间变更的符号
 func (cd *ChangeDetector) DetectChanges(oldCommit, newCommit string) ([]ChangedSymbol, error) {
 	// 1. 获取 git diff
 	diffContent, err := git.GetGitDiff(cd.projectPath, oldCommit, newCommit)
 	if err != nil {
-		return nil, fmt.Errorf("获取 git diff 失败: %w", err)
+		return nil, i18n.Errorf("获取 git diff 失败: %w", err)
 	}
+	return cd.DetectDiffChanges(diffContent, oldCommit, newCommit)
+}
 
+// DetectDiffChanges 检测 diff 中变更的符号,diff 的新版本应与已加载的代码一致。
+// 常量变更前后的值从 oldCommit 和 newCommit 中读取,未指定 commit 时不填充
+func (cd *ChangeDetector) DetectDiffChanges(diffContent []byte, oldCommit, newCommit string) ([]ChangedSymbol, error) {
 	fileDiffs, err := git.ParseDiff(diffContent)
 	if err != nil {
-		return nil, fmt.Errorf("解析 diff 失败: %w", err)
+		return nil, i18n.Errorf("解析 diff 失败: %w", err)
 	}
 
 	var changedSymbols []ChangedSymbol
@@ -78,35 +128,181 @@ func (cd *ChangeDetector) DetectChanges(oldCommit, newCommit string) ([]ChangedS
 
 		// 3. 映射变更行到符号
 		fileChangedSymbols := cd.mapLinesToSymbols(symbols, fileDiff.ChangedLines, fileDiff.Filename)
+		fileChangedSymbols = cd.mapDeletionsToSymbols(fileChangedSymbols, symbols, fileDiff.Deletions)
+		fillHunks(fileChangedSymbols, fileDiff.Hunks)
+		fileChangedSymbols = cd.expandDotImports(fileChangedSymbols, absFilename)
+		if oldCommit != "" && newCommit != "" {
+			cd.fillConstantValues(fileChangedSymbols, oldCommit, newCommit, fileDiff.Filename)
+		}
 		changedSymbols = append(changedSymbols, fileChangedSymbols...)
 	}
 
-	return changedSymbols, nil
+	deleted := cd.classifyChanges(changedSymbols, fileDiffs, oldCommit)
+	cd.fillConstantFamilies(changedSymbols)
+	cd.fillPromotedTypes(changedSymbols)
+	cd.fillPackageExits(changedSymbols)
+	cd.fillGoDeferCallers(changedSymbols)
+	cd.fillInitRegistrations(changedSymbols)
+	// 被删除的符号不再存在,不需要补充追踪信息
+	return append(changedSymbols, deleted...), nil
 }
 
 // mapLinesToSymbols 将变更行映射到符号
 func (cd *ChangeDetector) mapLinesToSymbols(symbols []*parser.Symbol, changedLines []int, filename string) []ChangedSymbol {
 	var res []ChangedSymbol
-	seen := make(map[*parser.Symbol]bool)
+	seen := make(map[*parser.Symbol]int)
 
 	fset := cd.parser.GetFileSet()
 
 	for _, line := range changedLines {
-		// 直接找到包含该行的顶层符号
+		// 找到包含该行的顶层符号,结构体中的字段行对应到字段
+		symbol := cd.findSymbolContainingLine(symbols, fset, line)
+		if symbol == nil {
+			continue
+		}
+		if i, ok := seen[symbol]; ok {
+			res[i].Lines = append(res[i].Lines, line)
+			continue
+		}
+		seen[symbol] = len(res)
+		res = append(res, ChangedSymbol{
+			Symbol:      symbol,
+			ChangeType:  ChangeTypeModify,
+			PackagePath: symbol.PackagePath,
+			Lines:       []int{line},
+		})
+	}
+
+	return res
+}
+
+// fillHunks 记录每个变更符号的变更行所在的 hunk
+func fillHunks(changes []ChangedSymbol, hunks []git.HunkDiff) {
+	for i := range changes {
+		for _, h := range hunks {
+			if slices.ContainsFunc(changes[i].Lines, h.Contains) {
+				changes[i].Hunks = append(changes[i].Hunks, h.Header)
+			}
+		}
+	}
+}
+
+// mapDeletionsToSymbols 将只删除代码的修改映射到符号: 删除位置前后的行(新版本文件中)
+// 属于同一个顶层符号时,删除发生在该符号内部,符号仍然存在,视为修改。删除位置记录为
+// 之后的一行。整个符号被删除时前后的行属于不同的符号,由 classifyChanges 报告为删除。
+// 结构体中前后的行属于同一个字段时视为该字段的修改,一条声明定义多个变量时同理
+func (cd *ChangeDetector) mapDeletionsToSymbols(changes []ChangedSymbol, symbols []*parser.Symbol, deletions []git.Deletion) []ChangedSymbol {
+	fset := cd.parser.GetFileSet()
+	for _, d := range deletions {
+		line := int(d.NewLine)
 		symbol := cd.findTopLevelSymbolContainingLine(symbols, fset, line)
-		if symbol != nil && !seen[symbol] {
-			res = append(res, ChangedSymbol{
+		if symbol == nil || cd.findTopLevelSymbolContainingLine(symbols, fset, line-1) != symbol {
+			continue
+		}
+		if inner := cd.findSymbolContainingLine(symbols, fset, line); inner != symbol && ownsLine(inner, fset, line-1) {
+			symbol = inner
+		}
+		i := slices.IndexFunc(changes, func(c ChangedSymbol) bool { return c.Symbol == symbol })
+		if i < 0 {
+			changes = append(changes, ChangedSymbol{
 				Symbol:      symbol,
 				ChangeType:  ChangeTypeModify,
 				PackagePath: symbol.PackagePath,
 			})
-			seen[symbol] = true
+			i = len(changes) - 1
+		}
+		if !slices.Contains(changes[i].Lines, line) {
+			changes[i].Lines = append(changes[i].Lines, line)
+		}
+	}
+	return changes
+}
+
+// expandDotImports 将变更的点导入替换为文件中使用了该包标识符的符号:
+// 点导入本身不会被追踪,它影响的是本文件中引用这些标识符的代码
+func (cd *ChangeDetector) expandDotImports(changes []ChangedSymbol, filename string) []ChangedSymbol {
+	var res []ChangedSymbol
+	seen := make(map[string]bool)
+	key := func(s *parser.Symbol) string {
+		return s.Name + "\x00" + s.Position.String()
+	}
+	for _, c := range changes {
+		if !isDotImport(c.Symbol) {
+			seen[key(c.Symbol)] = true
 		}
 	}
 
+	for _, c := range changes {
+		if !isDotImport(c.Symbol) {
+			res = append(res, c)
+			continue
+		}
+		users, err := cd.parser.DotImportUsers(filename, c.Symbol.Extra.(parser.ImportExtra).Path)
+		if err != nil {
+			continue
+		}
+		for _, s := range users {
+			if seen[key(s)] {
+				continue
+			}
+			seen[key(s)] = true
+			res = append(res, ChangedSymbol{
+				Symbol:      s,
+				ChangeType:  ChangeTypeModify,
+				PackagePath: s.PackagePath,
+				Hunks:       c.Hunks, // 由点导入的变更引起
+			})
+		}
+	}
 	return res
 }
 
+// isDotImport 判断符号是否是点导入 (import . "path")
+func isDotImport(s *parser.Symbol) bool {
+	extra, ok := s.Extra.(parser.ImportExtra)
+	return ok && s.Kind == parser.SymbolKindImport && extra.Alias == "."
+}
+
+// findSymbolContainingLine 找到包含指定行的符号: 结构体中字段所在的行对应到字段
+// (TypeExtra.Fields),一条声明定义多个变量时初始值所在的行对应到该变量
+// (VariableExtra.ValuePos),其他行对应到顶层符号
+func (cd *ChangeDetector) findSymbolContainingLine(symbols []*parser.Symbol, fset *token.FileSet, line int) *parser.Symbol {
+	symbol := cd.findTopLevelSymbolContainingLine(symbols, fset, line)
+	if symbol == nil {
+		return nil
+	}
+	switch extra := symbol.Extra.(type) {
+	case parser.TypeExtra:
+		if !extra.IsStruct {
+			break
+		}
+		for _, field := range extra.Fields {
+			if field.ContainsLine(fset, line) {
+				return field
+			}
+		}
+	case parser.VariableExtra:
+		if !extra.ValuePos.IsValid() || extra.ValueContainsLine(fset, line) {
+			break
+		}
+		for _, s := range symbols {
+			if other, ok := s.Extra.(parser.VariableExtra); ok && s.StartPos == symbol.StartPos && other.ValueContainsLine(fset, line) {
+				return s
+			}
+		}
+	}
+	return symbol
+}
+
+// ownsLine 判断 findSymbolContainingLine 细化出的符号是否包含指定行: 一条声明定义多个
+// 变量时只看变量自己的初始值,其他符号看自身的范围
+func ownsLine(s *parser.Symbol, fset *token.FileSet, line int) bool {
+	if extra, ok := s.Extra.(parser.VariableExtra); ok && extra.ValuePos.IsValid() {
+		return extra.ValueContainsLine(fset, line)
+	}
+	return s.ContainsLine(fset, line)
+}
+
 // findTopLevelSymbolContainingLine 找到包含指定行的顶层符号
 func (cd *ChangeDetector) findTopLevelSymbolContainingLine(symbols []*parser.Symbol, fset *token.FileSet, line int) *parser.Symbol {
 	for _, s := range symbols {
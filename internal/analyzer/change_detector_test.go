@@ -1,7 +1,104 @@
 package analyzer
 
-import "testing"
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
 
 func TestChangeDetector_Placeholder(t *testing.T) {
 	// Placeholder to avoid lint errors and unused file issues
 }
+
+func TestFindSymbolContainingLine(t *testing.T) {
+	src := `package p
+
+type Config struct {
+	Name string
+
+	// Timeout 超时时间
+	Timeout int
+}
+
+func Load() {}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "p.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+	config := &parser.Symbol{Name: "Config", Kind: parser.SymbolKindStruct, StartPos: spec.Pos(), EndPos: spec.End()}
+	var fields []*parser.Symbol
+	for _, f := range spec.Type.(*ast.StructType).Fields.List {
+		fields = append(fields, &parser.Symbol{Name: f.Names[0].Name, Kind: parser.SymbolKindStructField, StartPos: f.Pos(), EndPos: f.End(), Parent: config})
+	}
+	config.Extra = parser.TypeExtra{IsStruct: true, Fields: fields}
+	fn := file.Decls[1].(*ast.FuncDecl)
+	load := &parser.Symbol{Name: "Load", Kind: parser.SymbolKindFunction, StartPos: fn.Pos(), EndPos: fn.End()}
+
+	cd := &ChangeDetector{}
+	tests := map[int]*parser.Symbol{
+		3:  config,    // type Config struct {
+		4:  fields[0], // Name string
+		6:  config,    // 字段的文档注释
+		7:  fields[1], // Timeout int
+		8:  config,    // }
+		10: load,
+		9:  nil,
+	}
+	for line, want := range tests {
+		if got := cd.findSymbolContainingLine([]*parser.Symbol{config, load}, fset, line); got != want {
+			t.Errorf("line %d: got %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestFindVariableContainingLine(t *testing.T) {
+	src := `package p
+
+var Hosts, Ports = []string{
+	"localhost",
+}, map[string]int{
+	"http": 80,
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	var symbols []*parser.Symbol
+	for i, name := range spec.Names {
+		symbols = append(symbols, &parser.Symbol{
+			Name:     name.Name,
+			Kind:     parser.SymbolKindVariable,
+			StartPos: spec.Pos(),
+			EndPos:   spec.End(),
+			Extra:    parser.VariableExtra{ValuePos: spec.Values[i].Pos(), ValueEnd: spec.Values[i].End()},
+		})
+	}
+	hosts, ports := symbols[0], symbols[1]
+
+	cd := &ChangeDetector{}
+	tests := map[int]*parser.Symbol{
+		3: hosts, // var Hosts, Ports = []string{
+		4: hosts,
+		5: hosts, // }, map[string]int{
+		6: ports,
+		7: ports,
+	}
+	for line, want := range tests {
+		if got := cd.findSymbolContainingLine(symbols, fset, line); got != want {
+			t.Errorf("line %d: got %v, want %v", line, got, want)
+		}
+	}
+	// 两个初始值共用的一行也属于 Ports,删除 "http": 80 时前后两行都在 Ports 中
+	if !ownsLine(ports, fset, 5) || ownsLine(ports, fset, 4) {
+		t.Error("Expected the line shared by both values to belong to Ports")
+	}
+}
@@ -0,0 +1,369 @@
+package analyzer
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// declaration 顶层声明
+type declaration struct {
+	name      string
+	kind      parser.SymbolKind
+	file      string // 相对仓库根目录
+	line      int
+	signature string // 函数的接收者和签名、类型的定义、常量和变量声明的类型
+	parent    string // 方法的接收者类型(如 "*Client")、字段所属的结构体
+}
+
+// classifyChanges 比较变更文件在旧版本和新版本中的顶层声明: 旧版本中没有的变更符号标注为
+// ChangeTypeAdd,并返回旧版本中有、新版本中已经不存在的符号(ChangeTypeDelete)。
+// 按目录(包)比较,符号在同一个包的文件之间移动时仍是修改。旧版本从 oldCommit 读取,
+// 读取不到某个非新增文件的旧版本时(如没有 oldCommit 或文件被重命名),该目录的变更保持为修改。
+// 修改的符号同时标注修改内容: 声明的签名或类型变化时为 ModificationSignature,否则按种类为
+// ModificationValue 或 ModificationBody
+func (cd *ChangeDetector) classifyChanges(changes []ChangedSymbol, fileDiffs []git.FileDiff, oldCommit string) []ChangedSymbol {
+	byDir := make(map[string][]git.FileDiff)
+	var dirs []string
+	for _, fd := range fileDiffs {
+		if !strings.HasSuffix(fd.Filename, ".go") {
+			continue
+		}
+		dir := path.Dir(fd.Filename)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], fd)
+	}
+
+	var deleted []ChangedSymbol
+	for _, dir := range dirs {
+		old, ok := cd.oldDeclarations(byDir[dir], oldCommit)
+		if !ok {
+			continue
+		}
+
+		current := make(map[string]bool)
+		pkgPath := ""
+		for _, fd := range byDir[dir] {
+			if fd.IsDeletedFile {
+				continue
+			}
+			symbols, err := cd.parser.ParseFile(filepath.Join(cd.projectPath, fd.Filename))
+			if err != nil {
+				// 新版本无法解析时无法判断哪些符号被删除
+				current = nil
+				break
+			}
+			for _, s := range symbols {
+				current[symbolKey(s.Kind, receiverOf(s), s.Name, fd.Filename)] = true
+				pkgPath = s.PackagePath
+				if extra, ok := s.Extra.(parser.TypeExtra); ok {
+					for _, f := range extra.Fields {
+						current[symbolKey(f.Kind, receiverOf(f), f.Name, fd.Filename)] = true
+					}
+				}
+			}
+		}
+		if current == nil {
+			continue
+		}
+
+		newDecls := cd.newDeclarations(byDir[dir])
+		for i := range changes {
+			c := &changes[i]
+			rel, err := filepath.Rel(cd.projectPath, c.Symbol.Position.Filename)
+			if err != nil || path.Dir(filepath.ToSlash(rel)) != dir {
+				continue
+			}
+			key := symbolKey(c.Symbol.Kind, receiverOf(c.Symbol), c.Symbol.Name, filepath.ToSlash(rel))
+			prev, existed := old[key]
+			if !existed {
+				c.ChangeType = ChangeTypeAdd
+				continue
+			}
+			if next, ok := newDecls[key]; ok && next.signature != prev.signature {
+				c.Modification = ModificationSignature
+			}
+		}
+
+		if pkgPath == "" {
+			pkgPath = cd.packagePathOf(filepath.Join(cd.projectPath, dir))
+		}
+		// 推导不出所在的包时跳过
+		if pkgPath == "" {
+			continue
+		}
+		for key, decl := range old {
+			// 导入只属于所在的文件,删除导入的影响体现在使用它的代码上
+			if current[key] || decl.kind == parser.SymbolKindImport {
+				continue
+			}
+			symbol := &parser.Symbol{
+				Name:        decl.name,
+				Kind:        decl.kind,
+				Position:    token.Position{Filename: filepath.Join(cd.projectPath, decl.file), Line: decl.line},
+				PackagePath: pkgPath,
+			}
+			// 方法和字段带上接收者,与新版本中的符号一样命名
+			switch {
+			case decl.kind == parser.SymbolKindStructField:
+				symbol.Parent = &parser.Symbol{Name: decl.parent, Kind: parser.SymbolKindStruct, PackagePath: pkgPath}
+			case decl.parent != "":
+				symbol.Extra = parser.FunctionExtra{IsMethod: true, ReceiverType: decl.parent}
+			}
+			deleted = append(deleted, ChangedSymbol{
+				Symbol:      symbol,
+				ChangeType:  ChangeTypeDelete,
+				PackagePath: pkgPath,
+			})
+		}
+	}
+	for i := range changes {
+		if c := &changes[i]; c.ChangeType == ChangeTypeModify && c.Modification == "" {
+			c.Modification = modificationOf(c.Symbol.Kind)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		a, b := deleted[i].Symbol.Position, deleted[j].Symbol.Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Line < b.Line
+	})
+	return deleted
+}
+
+// packagePathOf 返回已加载的包中位于目录 dir(绝对路径)的包的导入路径。目录中已经没有包时
+// (整个包被删除),按与 dir 最近的已加载包的导入路径和两者的相对位置推导
+func (cd *ChangeDetector) packagePathOf(dir string) string {
+	res, depth := "", -1
+	for _, pkg := range cd.parser.GetPackages() {
+		if len(pkg.GoFiles) == 0 || strings.HasSuffix(pkg.PkgPath, "_test") {
+			continue
+		}
+		pkgDir := filepath.Dir(pkg.GoFiles[0])
+		if pkgDir == dir {
+			return pkg.PkgPath
+		}
+		common := commonDir(pkgDir, dir)
+		if d := strings.Count(common, string(filepath.Separator)); common != "" && d > depth {
+			if root, ok := importRoot(pkg.PkgPath, pkgDir, common); ok {
+				rel, _ := filepath.Rel(common, dir)
+				res, depth = path.Join(root, filepath.ToSlash(rel)), d
+			}
+		}
+	}
+	return res
+}
+
+// commonDir 返回两个绝对路径目录最近的共同上级目录
+func commonDir(a, b string) string {
+	for {
+		if rel, err := filepath.Rel(a, b); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return a
+		}
+		parent := filepath.Dir(a)
+		if parent == a {
+			return ""
+		}
+		a = parent
+	}
+}
+
+// importRoot 返回目录 dir 中导入路径为 pkgPath 的包的上级目录 root 对应的导入路径
+func importRoot(pkgPath, dir, root string) (string, bool) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return pkgPath, true
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasSuffix(pkgPath, "/"+rel) {
+		return "", false
+	}
+	return strings.TrimSuffix(pkgPath, "/"+rel), true
+}
+
+// oldDeclarations 返回目录中变更文件在旧版本中的顶层声明,键为 symbolKey。
+// 新增的文件在旧版本中没有声明;其他文件读取不到旧版本时返回 false
+func (cd *ChangeDetector) oldDeclarations(fileDiffs []git.FileDiff, oldCommit string) (map[string]declaration, bool) {
+	res := make(map[string]declaration)
+	for _, fd := range fileDiffs {
+		if fd.IsNewFile {
+			continue
+		}
+		if oldCommit == "" {
+			return nil, false
+		}
+		src, err := git.ShowFile(cd.projectPath, oldCommit, fd.Filename)
+		if err != nil || !parseDeclarations(res, src, fd.Filename) {
+			return nil, false
+		}
+	}
+	return res, true
+}
+
+// newDeclarations 返回目录中变更文件在新版本(工作区)中的顶层声明,键为 symbolKey,
+// 读取或解析失败的文件不贡献声明
+func (cd *ChangeDetector) newDeclarations(fileDiffs []git.FileDiff) map[string]declaration {
+	res := make(map[string]declaration)
+	for _, fd := range fileDiffs {
+		if fd.IsDeletedFile {
+			continue
+		}
+		if src, err := os.ReadFile(filepath.Join(cd.projectPath, fd.Filename)); err == nil {
+			parseDeclarations(res, src, fd.Filename)
+		}
+	}
+	return res
+}
+
+// parseDeclarations 解析文件 filename 的源码 src,把其中的顶层声明加入 decls,解析失败时返回 false
+func parseDeclarations(decls map[string]declaration, src []byte, filename string) bool {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range declarations(fset, file, filename) {
+		decls[symbolKey(decl.kind, decl.receiver, decl.name, filename)] = decl.declaration
+	}
+	return true
+}
+
+// receiverDeclaration 带接收者的声明
+type receiverDeclaration struct {
+	declaration
+	receiver string
+}
+
+// declarations 返回文件中的导入、顶层声明和结构体字段,种类与 parser 提取的符号一致。
+// 字段的接收者为所属的结构体,签名为类型和标签
+func declarations(fset *token.FileSet, file *ast.File, filename string) []receiverDeclaration {
+	var res []receiverDeclaration
+	add := func(name string, kind parser.SymbolKind, receiver, parent string, pos token.Pos, signature string) {
+		res = append(res, receiverDeclaration{
+			declaration: declaration{name: name, kind: kind, file: filename, line: fset.Position(pos).Line, signature: signature, parent: parent},
+			receiver:    receiver,
+		})
+	}
+	for _, imp := range file.Imports {
+		add(strings.Trim(imp.Path.Value, `"`), parser.SymbolKindImport, "", "", imp.Pos(), "")
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := parser.SymbolKindFunction
+			if d.Name.Name == "init" {
+				kind = parser.SymbolKindInit
+			}
+			receiver, parent, signature := "", "", types.ExprString(d.Type)
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				receiver = receiverName(d.Recv.List[0].Type)
+				parent = types.ExprString(d.Recv.List[0].Type)
+				signature = "(" + parent + ") " + signature
+			}
+			add(d.Name.Name, kind, receiver, parent, d.Pos(), typeParams(d.Type.TypeParams)+signature)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					kind := parser.SymbolKindVariable
+					if d.Tok == token.CONST {
+						kind = parser.SymbolKindConstant
+					}
+					signature := ""
+					if s.Type != nil {
+						signature = types.ExprString(s.Type)
+					}
+					for _, name := range s.Names {
+						add(name.Name, kind, "", "", name.Pos(), signature)
+					}
+				case *ast.TypeSpec:
+					kind := parser.SymbolKindTypeAlias
+					switch t := s.Type.(type) {
+					case *ast.StructType:
+						kind = parser.SymbolKindStruct
+						for _, f := range t.Fields.List {
+							signature := types.ExprString(f.Type)
+							if f.Tag != nil {
+								signature += " " + f.Tag.Value
+							}
+							if len(f.Names) == 0 {
+								add(types.ExprString(f.Type), parser.SymbolKindStructField, s.Name.Name, s.Name.Name, f.Pos(), signature)
+							}
+							for _, name := range f.Names {
+								add(name.Name, parser.SymbolKindStructField, s.Name.Name, s.Name.Name, name.Pos(), signature)
+							}
+						}
+					case *ast.InterfaceType:
+						kind = parser.SymbolKindInterface
+					}
+					add(s.Name.Name, kind, "", "", s.Pos(), typeParams(s.TypeParams)+types.ExprString(s.Type))
+				}
+			}
+		}
+	}
+	return res
+}
+
+// typeParams 返回类型参数列表的源码形式,如 "[K comparable, V any]",没有类型参数时为空
+func typeParams(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var params []string
+	for _, f := range fields.List {
+		var names []string
+		for _, name := range f.Names {
+			names = append(names, name.Name)
+		}
+		params = append(params, strings.Join(names, ", ")+" "+types.ExprString(f.Type))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// receiverName 返回接收者的类型名,不含 * 和类型参数
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// symbolKey 顶层声明在包内的标识: 方法为 "接收者.方法",导入只在所在文件中有效,
+// 带上文件名;init 函数可以有多个,按名称视为同一个
+func symbolKey(kind parser.SymbolKind, receiver, name, file string) string {
+	receiver, _, _ = strings.Cut(receiver, "[")
+	switch {
+	case kind == parser.SymbolKindImport:
+		return file + ":import " + name
+	case receiver != "":
+		return receiver + "." + name
+	}
+	return name
+}
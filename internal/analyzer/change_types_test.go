@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestDeclarations(t *testing.T) {
+	src := `package p
+
+import _ "embed"
+
+const A, B = 1, 2
+
+var v = 0
+
+type S struct {
+	Name string
+	*Base
+}
+
+type I interface{}
+
+type N int
+
+func init() {}
+
+func (s *S) Get() {}
+
+func (b Box[K, V]) Put() {}
+
+func Free() {}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]parser.SymbolKind)
+	for _, d := range declarations(fset, file, "p/p.go") {
+		got[symbolKey(d.kind, d.receiver, d.name, d.file)] = d.kind
+	}
+	want := map[string]parser.SymbolKind{
+		"p/p.go:import embed": parser.SymbolKindImport,
+		"A":                   parser.SymbolKindConstant,
+		"B":                   parser.SymbolKindConstant,
+		"v":                   parser.SymbolKindVariable,
+		"S":                   parser.SymbolKindStruct,
+		"S.Name":              parser.SymbolKindStructField,
+		"S.*Base":             parser.SymbolKindStructField,
+		"I":                   parser.SymbolKindInterface,
+		"N":                   parser.SymbolKindTypeAlias,
+		"init":                parser.SymbolKindInit,
+		"S.Get":               parser.SymbolKindFunction,
+		"Box.Put":             parser.SymbolKindFunction,
+		"Free":                parser.SymbolKindFunction,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("declarations = %v, want %v", got, want)
+	}
+
+	// 新版本中的方法接收者带有类型参数
+	if key := symbolKey(parser.SymbolKindFunction, "Box[K, V]", "Put", "p/p.go"); key != "Box.Put" {
+		t.Errorf("symbolKey = %q, want Box.Put", key)
+	}
+}
+
+func TestDeclarationSignatures(t *testing.T) {
+	src := `package p
+
+const C int = 1
+
+var v = 0
+
+type Box[K comparable, V any] struct {
+	m   map[K]V
+	Max int "max"
+}
+
+func (b *Box[K, V]) Put(k K, v V) error { return nil }
+
+func Free(n int) (string, error) { return "", nil }
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, d := range declarations(fset, file, "p/p.go") {
+		got[symbolKey(d.kind, d.receiver, d.name, d.file)] = d.signature
+	}
+	want := map[string]string{
+		"C":       "int",
+		"v":       "",
+		"Box":     "[K comparable, V any]struct{m map[K]V; Max int}",
+		"Box.m":   "map[K]V",
+		"Box.Max": `int "max"`,
+		"Box.Put": "(*Box[K, V]) func(k K, v V) error",
+		"Free":    "func(n int) (string, error)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("signatures = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+// binaryCollector aggregates call paths into affected binaries
+type binaryCollector struct {
+	limit     int // Max paths per binary, 0 means unlimited
+	order     []string
+	byName    map[string]*AffectedBinary
+	seenPaths map[string]bool
+}
+
+// newBinaryCollector creates a collector keeping at most limit paths per binary
+func newBinaryCollector(limit int) *binaryCollector {
+	return &binaryCollector{
+		limit:     limit,
+		byName:    make(map[string]*AffectedBinary),
+		seenPaths: make(map[string]bool),
+	}
+}
+
+// add records a call path, returns true if the binary was seen for the first time
+func (c *binaryCollector) add(path lsp.CallPath, confidence Confidence) bool {
+	pathStrs := formatTracePath(path)
+	key := pathKey(path.BinaryName, pathStrs)
+
+	binary, seen := c.byName[path.BinaryName]
+	if !seen {
+		c.seenPaths[key] = true
+		c.order = append(c.order, path.BinaryName)
+		c.byName[path.BinaryName] = &AffectedBinary{
+			Name:       path.BinaryName,
+			PkgPath:    rootPackage(path),
+			TracePath:  pathStrs,
+			Paths:      [][]string{pathStrs},
+			Confidence: confidence,
+
+			Approximate: path.Approximate,
+		}
+		return true
+	}
+	binary.Approximate = binary.Approximate && path.Approximate
+
+	// The strongest evidence determines the binary's confidence
+	if confidence.rank() > binary.Confidence.rank() {
+		binary.Confidence = confidence
+	}
+
+	// Keep additional distinct paths up to the configured limit
+	if c.limit > 0 && len(binary.Paths) >= c.limit {
+		return false
+	}
+	if c.seenPaths[key] {
+		return false
+	}
+	c.seenPaths[key] = true
+	binary.Paths = append(binary.Paths, pathStrs)
+	return false
+}
+
+// addRoute records a route of a binary already added, once per method and path
+func (c *binaryCollector) addRoute(name string, route Route) {
+	binary, ok := c.byName[name]
+	if !ok {
+		return
+	}
+	for _, r := range binary.Routes {
+		if r.Method == route.Method && r.Path == route.Path {
+			return
+		}
+	}
+	binary.Routes = append(binary.Routes, route)
+}
+
+// addCommand records a subcommand of a binary already added
+func (c *binaryCollector) addCommand(name, command string) {
+	binary, ok := c.byName[name]
+	if ok && !slices.Contains(binary.Commands, command) {
+		binary.Commands = append(binary.Commands, command)
+	}
+}
+
+// addJob records a configured job of a binary already added
+func (c *binaryCollector) addJob(name, job string) {
+	binary, ok := c.byName[name]
+	if ok && !slices.Contains(binary.Jobs, job) {
+		binary.Jobs = append(binary.Jobs, job)
+	}
+}
+
+// first returns a binary with only the path it was discovered by
+func (c *binaryCollector) first(name string) AffectedBinary {
+	binary := *c.byName[name]
+	binary.Paths = nil
+	return binary
+}
+
+// binaries returns the affected binaries in discovery order
+func (c *binaryCollector) binaries() []AffectedBinary {
+	var res []AffectedBinary
+	for _, name := range c.order {
+		binary := *c.byName[name]
+		slices.SortFunc(binary.Routes, func(a, b Route) int {
+			return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+		})
+		slices.Sort(binary.Commands)
+		slices.Sort(binary.Jobs)
+		// Paths is only reported when multiple paths were requested
+		if c.limit == 1 {
+			binary.Paths = nil
+		}
+		res = append(res, binary)
+	}
+	return res
+}
+
+// formatTracePath formats a call path as strings from main to the changed symbol
+func formatTracePath(path lsp.CallPath) []string {
+	var pathStrs []string
+	for i, node := range path.Path {
+		// Import paths have package nodes only
+		formatted := node.QualifiedName()
+		if i == 0 && path.Custom {
+			pathStrs = append(pathStrs, fmt.Sprintf("%s (entrypoint)", formatted))
+		} else if i == 0 {
+			pathStrs = append(pathStrs, fmt.Sprintf("%s (main)", formatted))
+		} else if i == len(path.Path)-1 {
+			pathStrs = append(pathStrs, fmt.Sprintf("%s (Changed)", formatted))
+		} else {
+			pathStrs = append(pathStrs, formatted)
+		}
+	}
+	return pathStrs
+}
+
+// rootPackage returns the import path of the package the path starts in: the
+// main package, or the package of a custom entrypoint
+func rootPackage(path lsp.CallPath) string {
+	if len(path.Path) == 0 {
+		return ""
+	}
+	return path.Path[0].PackagePath
+}
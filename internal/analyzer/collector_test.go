@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func makePath(binary string, funcs ...string) lsp.CallPath {
+	var nodes []lsp.CallNode
+	for _, fn := range funcs {
+		nodes = append(nodes, lsp.CallNode{FunctionName: fn, PackagePath: "example.com/p"})
+	}
+	return lsp.CallPath{BinaryName: binary, MainURI: "file:///p/cmd/" + binary + "/main.go", Path: nodes}
+}
+
+func TestBinaryCollectorFirstPathOnly(t *testing.T) {
+	c := newBinaryCollector(Options{}.pathLimit())
+	c.add(makePath("server", "main", "A", "Changed"), ConfidenceHigh)
+	c.add(makePath("server", "main", "B", "Changed"), ConfidenceHigh)
+	c.add(makePath("worker", "main", "Changed"), ConfidenceHigh)
+
+	bins := c.binaries()
+	if len(bins) != 2 {
+		t.Fatalf("Expected 2 binaries, got %d", len(bins))
+	}
+	if bins[0].Name != "server" || bins[1].Name != "worker" {
+		t.Errorf("Expected discovery order [server worker], got [%s %s]", bins[0].Name, bins[1].Name)
+	}
+	if bins[0].Paths != nil {
+		t.Errorf("Expected no Paths in default mode, got %v", bins[0].Paths)
+	}
+	if bins[0].TracePath[1] != "example.com/p.A" {
+		t.Errorf("Expected first discovered path to be kept, got %v", bins[0].TracePath)
+	}
+}
+
+func TestBinaryCollectorAllPaths(t *testing.T) {
+	c := newBinaryCollector(Options{AllPaths: true}.pathLimit())
+	c.add(makePath("server", "main", "A", "Changed"), ConfidenceHigh)
+	c.add(makePath("server", "main", "B", "Changed"), ConfidenceHigh)
+	c.add(makePath("server", "main", "A", "Changed"), ConfidenceHigh) // duplicate
+
+	bins := c.binaries()
+	if len(bins) != 1 {
+		t.Fatalf("Expected 1 binary, got %d", len(bins))
+	}
+	if len(bins[0].Paths) != 2 {
+		t.Errorf("Expected 2 distinct paths, got %d: %v", len(bins[0].Paths), bins[0].Paths)
+	}
+}
+
+func TestBinaryCollectorMaxPaths(t *testing.T) {
+	c := newBinaryCollector(Options{MaxPathsPerBinary: 2}.pathLimit())
+	c.add(makePath("server", "main", "A", "Changed"), ConfidenceHigh)
+	c.add(makePath("server", "main", "B", "Changed"), ConfidenceHigh)
+	c.add(makePath("server", "main", "C", "Changed"), ConfidenceHigh)
+
+	bins := c.binaries()
+	if len(bins[0].Paths) != 2 {
+		t.Errorf("Expected paths to be limited to 2, got %d", len(bins[0].Paths))
+	}
+}
+
+func TestFormatTracePath(t *testing.T) {
+	got := formatTracePath(makePath("server", "main", "Handle", "Changed"))
+	want := []string{"example.com/p.main (main)", "example.com/p.Handle", "example.com/p.Changed (Changed)"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Node %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestBinaryCollectorConfidenceUpgrade(t *testing.T) {
+	c := newBinaryCollector(Options{}.pathLimit())
+	c.add(makePath("server", "main"), ConfidenceLow)
+	c.add(makePath("server", "main", "Changed"), ConfidenceMedium)
+	c.add(makePath("server", "main", "Other"), ConfidenceLow)
+
+	bins := c.binaries()
+	if bins[0].Confidence != ConfidenceMedium {
+		t.Errorf("Expected strongest confidence medium, got %s", bins[0].Confidence)
+	}
+}
+
+func TestSymbolConfidence(t *testing.T) {
+	tests := []struct {
+		symbol *parser.Symbol
+		want   Confidence
+	}{
+		{&parser.Symbol{Kind: parser.SymbolKindFunction, Extra: parser.FunctionExtra{}}, ConfidenceHigh},
+		{&parser.Symbol{Kind: parser.SymbolKindFunction, Extra: parser.FunctionExtra{IsMethod: true, ImplementsInterface: true}}, ConfidenceMedium},
+		{&parser.Symbol{Kind: parser.SymbolKindConstant}, ConfidenceHigh},
+		{&parser.Symbol{Kind: parser.SymbolKindInit}, ConfidenceLow},
+		{&parser.Symbol{Kind: parser.SymbolKindImport}, ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		if got := symbolConfidence(tt.symbol); got != tt.want {
+			t.Errorf("symbolConfidence(%v) = %s, want %s", tt.symbol.Kind, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryCollectorApproximate(t *testing.T) {
+	c := newBinaryCollector(Options{}.pathLimit())
+	approx := makePath("server", "main", "Log")
+	approx.Approximate = true
+	c.add(approx, ConfidenceLow)
+	other := makePath("worker", "main", "Log")
+	other.Approximate = true
+	c.add(other, ConfidenceLow)
+	c.add(makePath("worker", "main", "Run", "Changed"), ConfidenceHigh)
+
+	bins := c.binaries()
+	if !bins[0].Approximate {
+		t.Error("Expected server, only found through imports, to be approximate")
+	}
+	if bins[1].Approximate {
+		t.Error("Expected worker to be exact once a call path reaches it")
+	}
+}
@@ -0,0 +1,22 @@
+package analyzer
+
+import (
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// fillConstantFamilies 为值发生变化的枚举式常量补充同一类型的其他常量。枚举值常被持久化,
+// 一个值变化(或新增的常量让 iota 后移)后,按其他常量比较存储值的 switch 和条件判断可能
+// 把旧数据当成另一个状态,这些比较的位置离定义往往很远,需要额外追踪。
+// 读取不到变更前后的值时(如从标准输入读取 diff)按值已变化处理
+func (cd *ChangeDetector) fillConstantFamilies(changes []ChangedSymbol) {
+	for i := range changes {
+		c := &changes[i]
+		if c.ChangeType == ChangeTypeDelete || c.ChangeType == ChangeTypeModify && c.OldValue == c.NewValue && c.OldValue != "" {
+			continue
+		}
+		c.Family = cd.parser.ConstantFamily(c.Symbol)
+		if len(c.Family) > 0 {
+			logger.Debug("枚举常量值变化", "symbol", c.Symbol.Name, "family", len(c.Family))
+		}
+	}
+}
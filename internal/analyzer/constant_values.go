@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// fillConstantValues 为变更的常量补充两个 commit 中的值,便于评审直接看到
+// "MaxRetries 从 3 改为 10"
+func (cd *ChangeDetector) fillConstantValues(changes []ChangedSymbol, oldCommit, newCommit, filename string) {
+	var oldSrc, newSrc []byte
+	loaded := false
+	for i := range changes {
+		if changes[i].Symbol.Kind != parser.SymbolKindConstant {
+			continue
+		}
+		if !loaded {
+			// 文件在旧 commit 中不存在时 oldSrc 为空,常量视为新增
+			oldSrc, _ = git.ShowFile(cd.projectPath, oldCommit, filename)
+			newSrc, _ = git.ShowFile(cd.projectPath, newCommit, filename)
+			loaded = true
+		}
+		changes[i].OldValue = constantValue(oldSrc, changes[i].Symbol.Name)
+		changes[i].NewValue = constantValue(newSrc, changes[i].Symbol.Name)
+	}
+}
+
+// constantValue 返回源码中顶层常量的值表达式,找不到或没有显式值时返回空字符串
+func constantValue(src []byte, name string) string {
+	if len(src) == 0 {
+		return ""
+	}
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", src, goparser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, ident := range valueSpec.Names {
+				if ident.Name != name {
+					continue
+				}
+				if i >= len(valueSpec.Values) {
+					return ""
+				}
+				value := valueSpec.Values[i]
+				return string(src[fset.Position(value.Pos()).Offset:fset.Position(value.End()).Offset])
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import "testing"
+
+func TestConstantValue(t *testing.T) {
+	src := []byte(`package config
+
+import "time"
+
+const MaxRetries = 3
+
+const (
+	Timeout = 5 * time.Second
+	Name, Version = "svc", "v1"
+)
+
+const (
+	A = iota
+	B
+)
+
+var NotConst = 1
+`)
+
+	tests := map[string]string{
+		"MaxRetries": "3",
+		"Timeout":    "5 * time.Second",
+		"Version":    `"v1"`,
+		"A":          "iota",
+		"B":          "", // iota 隐式重复,没有显式值
+		"NotConst":   "",
+		"Missing":    "",
+	}
+	for name, want := range tests {
+		if got := constantValue(src, name); got != want {
+			t.Errorf("constantValue(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := constantValue(nil, "MaxRetries"); got != "" {
+		t.Errorf("constantValue on empty source = %q, want empty", got)
+	}
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/cover"
+)
+
+// Coverage counts the statements of changed code and how many of them the tests
+// of a coverage profile ran
+type Coverage struct {
+	Statements int `json:"statements"`
+	Covered    int `json:"covered"`
+}
+
+// String formats the coverage as "40% (4/10)"
+func (c Coverage) String() string {
+	return fmt.Sprintf("%d%% (%d/%d)", c.percent(), c.Covered, c.Statements)
+}
+
+// percent returns the covered share of the statements, rounded down
+func (c Coverage) percent() int {
+	if c.Statements == 0 {
+		return 0
+	}
+	return c.Covered * 100 / c.Statements
+}
+
+// uncovered returns the share of the statements no test ran, from 0 to 1
+func (c Coverage) uncovered() float64 {
+	if c.Statements == 0 {
+		return 0
+	}
+	return float64(c.Statements-c.Covered) / float64(c.Statements)
+}
+
+// CoverProfile holds the blocks of a "go test -coverprofile" file, keyed by the
+// profile's file names: the import path of the package joined with the base name
+// of the file
+type CoverProfile map[string][]cover.ProfileBlock
+
+// LoadCoverProfile reads a coverage profile. Profiles of several test runs may
+// be concatenated, blocks listed more than once are merged
+func LoadCoverProfile(filename string) (CoverProfile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	// Only the first mode line is expected by the parser
+	lines := strings.SplitAfter(string(data), "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		if i == 0 || !strings.HasPrefix(line, "mode:") {
+			kept = append(kept, line)
+		}
+	}
+	profiles, err := cover.ParseProfilesFromReader(strings.NewReader(strings.Join(kept, "")))
+	if err != nil {
+		return nil, err
+	}
+	res := make(CoverProfile, len(profiles))
+	for _, p := range profiles {
+		res[p.FileName] = p.Blocks
+	}
+	return res, nil
+}
+
+// coverage returns the coverage of the changed lines of a symbol, or of its
+// declaration line when no lines are known. It is nil when the profile does not
+// describe the symbol's file or no statement is on those lines, such as for
+// constants, type declarations or deleted symbols
+func (p CoverProfile) coverage(change ChangedSymbol) *Coverage {
+	blocks, lines := p.blocks(change)
+	var res Coverage
+	for _, b := range blocks {
+		for _, line := range lines {
+			if line < b.StartLine || line > b.EndLine {
+				continue
+			}
+			res.Statements += b.NumStmt
+			if b.Count > 0 {
+				res.Covered += b.NumStmt
+			}
+			break
+		}
+	}
+	if res.Statements == 0 {
+		return nil
+	}
+	return &res
+}
+
+// blocks returns the blocks of the file declaring a changed symbol and the lines
+// of the symbol to look for in them: its changed lines, or its declaration line
+// when no lines are known. Deleted symbols have no lines in the profiled code
+func (p CoverProfile) blocks(change ChangedSymbol) ([]cover.ProfileBlock, []int) {
+	if p == nil || change.ChangeType == ChangeTypeDelete || change.Symbol.PackagePath == "" {
+		return nil, nil
+	}
+	blocks := p[path.Join(change.Symbol.PackagePath, filepath.Base(change.Symbol.Position.Filename))]
+	lines := change.Lines
+	if len(lines) == 0 {
+		lines = []int{change.Symbol.Position.Line}
+	}
+	return blocks, lines
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestCoverProfile(t *testing.T) {
+	// 两次测试运行拼接的覆盖率文件,重复的块合并
+	profile := `mode: set
+example.com/p/svc/process.go:10.30,12.10 2 1
+example.com/p/svc/process.go:12.10,14.3 1 0
+example.com/p/svc/process.go:16.2,16.12 1 0
+mode: set
+example.com/p/svc/process.go:16.2,16.12 1 1
+example.com/p/svc/other.go:3.20,5.2 2 0
+`
+	filename := filepath.Join(t.TempDir(), "cover.out")
+	if err := os.WriteFile(filename, []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadCoverProfile(filename)
+	if err != nil {
+		t.Fatalf("LoadCoverProfile failed: %v", err)
+	}
+
+	change := func(file string, line int, lines ...int) ChangedSymbol {
+		return ChangedSymbol{
+			Symbol: &parser.Symbol{
+				Name:        "Process",
+				Kind:        parser.SymbolKindFunction,
+				Position:    token.Position{Filename: "/repo/svc/" + file, Line: line},
+				PackagePath: "example.com/p/svc",
+			},
+			ChangeType: ChangeTypeModify,
+			Lines:      lines,
+		}
+	}
+	tests := []struct {
+		name   string
+		change ChangedSymbol
+		want   *Coverage
+	}{
+		{"changed lines", change("process.go", 9, 11, 13, 16), &Coverage{Statements: 4, Covered: 3}},
+		{"uncovered block", change("process.go", 9, 14), &Coverage{Statements: 1}},
+		{"declaration line", change("other.go", 3), &Coverage{Statements: 2}},
+		{"no statements", change("process.go", 9, 20), nil},
+		{"file not in profile", change("types.go", 3, 3), nil},
+	}
+	for _, tt := range tests {
+		got := p.coverage(tt.change)
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("%s: coverage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	deleted := change("process.go", 11, 11)
+	deleted.ChangeType = ChangeTypeDelete
+	if got := p.coverage(deleted); got != nil {
+		t.Errorf("deleted symbol: coverage = %v, want nil", got)
+	}
+	if got := CoverProfile(nil).coverage(change("process.go", 11, 11)); got != nil {
+		t.Errorf("nil profile: coverage = %v, want nil", got)
+	}
+	if got := (Coverage{Statements: 3, Covered: 2}).String(); got != "66% (2/3)" {
+		t.Errorf("String = %q, want 66%% (2/3)", got)
+	}
+}
@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// CoveringTest is a test run whose coverage profile executed changed lines
+type CoveringTest struct {
+	// Name is the path of the profile relative to the coverage directory,
+	// slash-separated and without extension, such as "pkg/store/TestSave"
+	Name string `json:"name"`
+	// Package and Test split a name ending in a test function into the test
+	// package directory ("./pkg/store") and the function ("TestSave"), so the
+	// test can be run on its own. Both are empty for other profiles
+	Package string `json:"package,omitempty"`
+	Test    string `json:"test,omitempty"`
+	// Changes are the changed symbols whose changed lines the run executed, sorted
+	Changes []string `json:"changes"`
+	// Lines counts the changed lines the run executed
+	Lines int `json:"lines"`
+}
+
+// LoadCoverDir reads every coverage profile under dir, keyed by its name (see
+// CoveringTest.Name). Files starting with a dot are skipped
+func LoadCoverDir(dir string) (map[string]CoverProfile, error) {
+	res := make(map[string]CoverProfile)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		profile, err := LoadCoverProfile(p)
+		if err != nil {
+			return i18n.Errorf("解析覆盖率文件 %s 失败: %w", p, err)
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		res[strings.TrimSuffix(rel, path.Ext(rel))] = profile
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CoveringTests returns the test runs whose profiles executed changed lines of
+// changes, the runs executing the most changed lines first. A changed line is
+// executed when a block spanning it ran at least once
+func CoveringTests(profiles map[string]CoverProfile, changes []ChangedSymbol) []CoveringTest {
+	var res []CoveringTest
+	for name, profile := range profiles {
+		test := CoveringTest{Name: name}
+		for _, change := range changes {
+			if lines := profile.executed(change); lines > 0 {
+				test.Changes = append(test.Changes, qualifiedSymbolName(change))
+				test.Lines += lines
+			}
+		}
+		if test.Lines == 0 {
+			continue
+		}
+		test.Package, test.Test = splitTestName(name)
+		sort.Strings(test.Changes)
+		test.Changes = slices.Compact(test.Changes)
+		res = append(res, test)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Lines != res[j].Lines {
+			return res[i].Lines > res[j].Lines
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// executed counts the lines of a changed symbol (see CoverProfile.blocks) that
+// a block run at least once spans
+func (p CoverProfile) executed(change ChangedSymbol) int {
+	blocks, lines := p.blocks(change)
+	n := 0
+	for _, line := range lines {
+		for _, b := range blocks {
+			if b.Count > 0 && line >= b.StartLine && line <= b.EndLine {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// testPrefixes are the prefixes of the functions go test -run selects
+var testPrefixes = []string{"Test", "Example", "Fuzz"}
+
+// splitTestName splits a profile name "dir/TestName" into the test package
+// directory "./dir" ("." for "TestName") and the test function, empty when the
+// last element is not a test function
+func splitTestName(name string) (pkg, test string) {
+	dir, base := path.Split(name)
+	for _, prefix := range testPrefixes {
+		if !strings.HasPrefix(base, prefix) || !isTestSuffix(strings.TrimPrefix(base, prefix)) {
+			continue
+		}
+		if dir == "" {
+			return ".", base
+		}
+		return "./" + strings.TrimSuffix(dir, "/"), base
+	}
+	return "", ""
+}
+
+// isTestSuffix reports whether s may follow a test prefix: go test ignores
+// functions such as Testify, whose prefix is followed by a lower-case letter
+func isTestSuffix(s string) bool {
+	return s == "" || s[0] < 'a' || s[0] > 'z'
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestCoveringTests(t *testing.T) {
+	dir := t.TempDir()
+	profiles := map[string]string{
+		"pkg/store/TestSave.out": "example.com/p/store/store.go:10.20,14.2 3 1\n",
+		"pkg/store/TestLoad.out": "example.com/p/store/store.go:10.20,14.2 3 0\nexample.com/p/store/store.go:20.20,22.2 1 2\n",
+		"integration.cov":        "example.com/p/store/store.go:10.20,22.2 4 1\n",
+		"pkg/store/TestNone.out": "example.com/p/store/store.go:10.20,22.2 4 0\n",
+		".cache/TestSkip.out":    "not a profile\n",
+	}
+	for name, blocks := range profiles {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("mode: count\n"+blocks), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded, err := LoadCoverDir(dir)
+	if err != nil {
+		t.Fatalf("LoadCoverDir failed: %v", err)
+	}
+
+	change := func(name string, lines ...int) ChangedSymbol {
+		return ChangedSymbol{
+			Symbol: &parser.Symbol{
+				Name:        name,
+				Kind:        parser.SymbolKindFunction,
+				Position:    token.Position{Filename: "/repo/pkg/store/store.go", Line: lines[0] - 1},
+				PackagePath: "example.com/p/store",
+			},
+			ChangeType: ChangeTypeModify,
+			Lines:      lines,
+		}
+	}
+	got := CoveringTests(loaded, []ChangedSymbol{change("Save", 11, 12), change("Load", 21)})
+	want := []CoveringTest{
+		{Name: "integration", Changes: []string{"example.com/p/store.Load", "example.com/p/store.Save"}, Lines: 3},
+		{Name: "pkg/store/TestSave", Package: "./pkg/store", Test: "TestSave", Changes: []string{"example.com/p/store.Save"}, Lines: 2},
+		{Name: "pkg/store/TestLoad", Package: "./pkg/store", Test: "TestLoad", Changes: []string{"example.com/p/store.Load"}, Lines: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CoveringTests = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitTestName(t *testing.T) {
+	tests := []struct {
+		name, pkg, test string
+	}{
+		{"pkg/store/TestSave", "./pkg/store", "TestSave"},
+		{"TestMain", ".", "TestMain"},
+		{"pkg/store/Example_save", "./pkg/store", "Example_save"},
+		{"pkg/store/Testify", "", ""},
+		{"pkg/store", "", ""},
+	}
+	for _, tt := range tests {
+		pkg, test := splitTestName(tt.name)
+		if pkg != tt.pkg || test != tt.test {
+			t.Errorf("splitTestName(%q) = %q, %q, want %q, %q", tt.name, pkg, test, tt.pkg, tt.test)
+		}
+	}
+}
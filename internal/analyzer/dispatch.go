@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/lsp"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// callResolver tells static calls from dynamic dispatch using type information.
+//
+// gopls reports a call through an interface as a call of every implementation,
+// so a path may run from one service into another service's implementation that
+// the binary never uses. A static call into another service, on the other hand,
+// is a real dependency. Packages come from the parser when it loaded them with
+// type information, otherwise they are loaded on demand, once per package. Only
+// the loaded packages themselves are used: the parser drops the function bodies
+// of their dependencies.
+type callResolver struct {
+	root string
+	pkgs map[string]*packages.Package // Import path -> type-checked package, nil if it failed to load
+}
+
+func newCallResolver(root string, loaded []*packages.Package) *callResolver {
+	r := &callResolver{root: root, pkgs: make(map[string]*packages.Package)}
+	for _, pkg := range loaded {
+		if pkg.TypesInfo != nil && pkg.ForTest == "" {
+			r.pkgs[pkg.PkgPath] = pkg
+		}
+	}
+	return r
+}
+
+// static reports whether caller calls callee directly: a function call or a
+// method call on a concrete receiver, as opposed to a call through an interface
+// or function value. ok is false when the caller's package cannot be
+// type-checked or declares no function named like the caller
+func (r *callResolver) static(caller, callee lsp.CallNode) (static, ok bool) {
+	pkg := r.load(caller.PackagePath)
+	if pkg == nil {
+		return false, false
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, isFunc := decl.(*ast.FuncDecl)
+			if !isFunc || fd.Name.Name != caller.FunctionName || fd.Body == nil {
+				continue
+			}
+			ok = true
+			if callsStatically(pkg.TypesInfo, fd.Body, callee) {
+				return true, true
+			}
+		}
+	}
+	return false, ok
+}
+
+// pathConfidence returns the confidence of a path ending at a changed symbol of
+// the given confidence. A method satisfying an interface is only medium because
+// the call reaching it may go through the interface, to any implementation; a
+// static call as the path's last edge settles that it runs
+func (r *callResolver) pathConfidence(path lsp.CallPath, confidence Confidence) Confidence {
+	n := len(path.Path)
+	if confidence != ConfidenceMedium || n < 2 {
+		return confidence
+	}
+	if static, ok := r.static(path.Path[n-2], path.Path[n-1]); ok && static {
+		return ConfidenceHigh
+	}
+	return confidence
+}
+
+// callsStatically reports whether body contains a static call of callee
+func callsStatically(info *types.Info, body *ast.BlockStmt, callee lsp.CallNode) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		fn, ok := typeutil.Callee(info, call).(*types.Func)
+		if !ok || fn.Name() != callee.FunctionName || fn.Pkg() == nil || fn.Pkg().Path() != callee.PackagePath {
+			return true
+		}
+		recv := fn.Type().(*types.Signature).Recv()
+		found = recv == nil || !types.IsInterface(recv.Type())
+		return !found
+	})
+	return found
+}
+
+// load returns the type-checked package with the given import path
+func (r *callResolver) load(pkgPath string) *packages.Package {
+	if pkg, ok := r.pkgs[pkgPath]; ok {
+		return pkg
+	}
+	r.pkgs[pkgPath] = nil
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  r.root,
+	}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil || len(pkgs) != 1 || pkgs[0].TypesInfo == nil || len(pkgs[0].Errors) > 0 {
+		logger.Debug("failed to type-check package for the cross-service filter", "package", pkgPath, "error", err)
+		return nil
+	}
+	r.pkgs[pkgPath] = pkgs[0]
+	return pkgs[0]
+}
@@ -0,0 +1,217 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/lsp"
+	"golang.org/x/tools/go/packages"
+)
+
+// Evidence explains one reported path of a binary: where each function on it
+// refers to the next one, and the diff hunks that changed the symbol it ends at
+type Evidence struct {
+	Symbol    string     `json:"symbol"`          // Changed symbol the path ends at
+	File      string     `json:"file,omitempty"`  // File of the changed symbol, relative to the repository root
+	Hunks     []string   `json:"hunks,omitempty"` // Headers of the diff hunks changing the symbol, empty for traced symbols
+	CallSites []CallSite `json:"call_sites"`      // One per edge of the path, from main down
+}
+
+// CallSite locates the references of a caller on a path to its callee
+type CallSite struct {
+	Caller string `json:"caller"`          // Qualified name of the caller
+	Callee string `json:"callee"`          // Qualified name of the callee
+	File   string `json:"file,omitempty"`  // File of the caller, relative to the repository root; empty if not located
+	Lines  []int  `json:"lines,omitempty"` // Lines referring to the callee, usually calls
+}
+
+// explainer remembers the change behind every recorded path, and resolves the
+// call sites of the reported paths once tracing is done
+type explainer struct {
+	root   string
+	paths  map[string]explainedPath // Path key -> recorded path
+	files  map[string][]string      // Import path -> Go files, filled lazily
+	fset   *token.FileSet
+	parsed map[string]*ast.File // Files parsed with function bodies, nil on error
+}
+
+// explainedPath is a recorded path and the change it was traced from
+type explainedPath struct {
+	path   lsp.CallPath
+	change ChangedSymbol
+}
+
+func newExplainer(root string, loaded []*packages.Package) *explainer {
+	e := &explainer{
+		root:   root,
+		paths:  make(map[string]explainedPath),
+		files:  make(map[string][]string),
+		fset:   token.NewFileSet(),
+		parsed: make(map[string]*ast.File),
+	}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if pkg.ForTest == "" && len(pkg.GoFiles) > 0 {
+			e.files[pkg.PkgPath] = pkg.GoFiles
+		}
+	})
+	return e
+}
+
+// record remembers the change a path of a binary was traced from. The first
+// change recorded for the same formatted path wins
+func (e *explainer) record(path lsp.CallPath, change ChangedSymbol) {
+	key := pathKey(path.BinaryName, formatTracePath(path))
+	if _, ok := e.paths[key]; !ok {
+		e.paths[key] = explainedPath{path, change}
+	}
+}
+
+// explain fills the Evidence of the binaries, one entry per reported path
+func (e *explainer) explain(binaries []AffectedBinary) {
+	metrics := newMetricsBuilder(e.root)
+	for i := range binaries {
+		reported := binaries[i].Paths
+		if len(reported) == 0 {
+			reported = [][]string{binaries[i].TracePath}
+		}
+		for _, strs := range reported {
+			recorded, ok := e.paths[pathKey(binaries[i].Name, strs)]
+			if !ok {
+				continue
+			}
+			path, change := recorded.path, recorded.change
+			ev := Evidence{
+				Symbol:    qualifiedSymbolName(change),
+				File:      metrics.relativePath(change.Symbol.Position.Filename),
+				Hunks:     change.Hunks,
+				CallSites: []CallSite{},
+			}
+			for j := 0; j+1 < len(path.Path); j++ {
+				ev.CallSites = append(ev.CallSites, e.callSite(path.Path[j], path.Path[j+1], metrics))
+			}
+			binaries[i].Evidence = append(binaries[i].Evidence, ev)
+		}
+	}
+}
+
+// callSite finds where caller refers to callee. Functions are matched by name
+// in the caller's package, so the lines are those of every identifier named like
+// the callee in the caller's declaration
+func (e *explainer) callSite(caller, callee lsp.CallNode, metrics *metricsBuilder) CallSite {
+	site := CallSite{Caller: caller.QualifiedName(), Callee: callee.QualifiedName()}
+	if caller.FunctionName == "" || callee.FunctionName == "" {
+		// Package nodes of import paths have no call sites
+		return site
+	}
+	name := callee.FunctionName[strings.LastIndex(callee.FunctionName, ".")+1:]
+	declared := caller.FunctionName
+	if caller.Receiver != "" {
+		declared = strings.TrimPrefix(caller.Receiver, "*") + "." + caller.FunctionName
+	}
+	for _, filename := range e.packageFiles(caller.PackagePath) {
+		file := e.parse(filename)
+		if file == nil {
+			continue
+		}
+		for _, decl := range namedDeclarations(file, declared) {
+			lines := e.references(decl, name)
+			if len(lines) > 0 {
+				site.File = metrics.relativePath(filename)
+				site.Lines = lines
+				return site
+			}
+		}
+	}
+	return site
+}
+
+// references returns the sorted lines of the identifiers named name in decl,
+// other than the name it declares
+func (e *explainer) references(decl ast.Decl, name string) []int {
+	var lines []int
+	ast.Inspect(decl, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id.Name != name {
+			return true
+		}
+		if fd, ok := decl.(*ast.FuncDecl); ok && fd.Name == id {
+			return true
+		}
+		if line := e.fset.Position(id.Pos()).Line; !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+		return true
+	})
+	slices.Sort(lines)
+	return lines
+}
+
+// packageFiles returns the Go files of a package, loading its file list when the
+// parser did not load it
+func (e *explainer) packageFiles(pkgPath string) []string {
+	if files, ok := e.files[pkgPath]; ok {
+		return files
+	}
+	e.files[pkgPath] = nil
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: e.root}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil || len(pkgs) != 1 {
+		logger.Debug("failed to list package files for explain", "package", pkgPath, "error", err)
+		return nil
+	}
+	e.files[pkgPath] = pkgs[0].GoFiles
+	return pkgs[0].GoFiles
+}
+
+// parse parses a file with function bodies, the loaded packages may skip them
+func (e *explainer) parse(filename string) *ast.File {
+	if file, ok := e.parsed[filename]; ok {
+		return file
+	}
+	file, err := parser.ParseFile(e.fset, filename, nil, parser.SkipObjectResolution)
+	if err != nil {
+		file = nil
+	}
+	e.parsed[filename] = file
+	return file
+}
+
+// namedDeclarations returns the top-level declarations of name in file: functions
+// and methods, "Type.Method" restricting the receiver type, or package-level
+// variables and constants
+func namedDeclarations(file *ast.File, name string) []ast.Decl {
+	recv, fn, qualified := strings.Cut(name, ".")
+	if !qualified {
+		fn = recv
+	}
+	var res []ast.Decl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name != fn {
+				continue
+			}
+			if qualified && (d.Recv == nil || len(d.Recv.List) == 0 || receiverName(d.Recv.List[0].Type) != recv) {
+				continue
+			}
+			res = append(res, d)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if ok && !qualified && slices.ContainsFunc(vs.Names, func(id *ast.Ident) bool { return id.Name == name }) {
+					res = append(res, d)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// pathKey identifies a formatted path of a binary
+func pathKey(binary string, path []string) string {
+	return binary + "\x00" + strings.Join(path, "\x00")
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestExplain(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "cmd", "api", "main.go")
+	worker := filepath.Join(root, "internal", "worker", "worker.go")
+	files := map[string]string{
+		api: `package main
+
+import "example.com/app/internal/worker"
+
+func main() {
+	w := &worker.Worker{}
+	w.Run()
+	w.Run()
+}
+`,
+		worker: `package worker
+
+type Worker struct{}
+
+type Other struct{}
+
+func (o *Other) Run() { process() }
+
+func (w *Worker) Run() {
+	handlers["a"]()
+}
+
+var handlers = map[string]func(){
+	"a": process,
+}
+
+func process() {}
+`,
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := newExplainer(root, nil)
+	e.files["example.com/app/cmd/api"] = []string{api}
+	e.files["example.com/app/internal/worker"] = []string{worker}
+
+	path := lsp.CallPath{
+		BinaryName: "api",
+		Path: []lsp.CallNode{
+			{FunctionName: "main", PackagePath: "example.com/app/cmd/api"},
+			{FunctionName: "Worker.Run", PackagePath: "example.com/app/internal/worker"},
+			{FunctionName: "handlers", PackagePath: "example.com/app/internal/worker"},
+			{FunctionName: "process", PackagePath: "example.com/app/internal/worker"},
+		},
+	}
+	change := ChangedSymbol{
+		Symbol: &parser.Symbol{
+			Name:        "process",
+			PackagePath: "example.com/app/internal/worker",
+			Position:    token.Position{Filename: worker, Line: 17},
+		},
+		Hunks: []string{"@@ -17,1 +17,1 @@"},
+	}
+	e.record(path, change)
+	binaries := []AffectedBinary{{Name: "api", TracePath: formatTracePath(path)}}
+	e.explain(binaries)
+
+	want := []Evidence{{
+		Symbol: "example.com/app/internal/worker.process",
+		File:   "internal/worker/worker.go",
+		Hunks:  []string{"@@ -17,1 +17,1 @@"},
+		CallSites: []CallSite{
+			{Caller: "example.com/app/cmd/api.main", Callee: "example.com/app/internal/worker.Worker.Run", File: "cmd/api/main.go", Lines: []int{7, 8}},
+			// Worker.Run, not Other.Run, refers to the variable
+			{Caller: "example.com/app/internal/worker.Worker.Run", Callee: "example.com/app/internal/worker.handlers", File: "internal/worker/worker.go", Lines: []int{10}},
+			{Caller: "example.com/app/internal/worker.handlers", Callee: "example.com/app/internal/worker.process", File: "internal/worker/worker.go", Lines: []int{14}},
+		},
+	}}
+	if !reflect.DeepEqual(binaries[0].Evidence, want) {
+		t.Errorf("Evidence = %+v, want %+v", binaries[0].Evidence, want)
+	}
+}
+
+func TestExplainUnlocated(t *testing.T) {
+	e := newExplainer(t.TempDir(), nil)
+	e.files["example.com/app/cmd/api"] = nil
+	site := e.callSite(lsp.CallNode{FunctionName: "main", PackagePath: "example.com/app/cmd/api"},
+		lsp.CallNode{PackagePath: "example.com/app/internal/db"}, newMetricsBuilder(e.root))
+	if site.File != "" || site.Callee != "example.com/app/internal/db" {
+		t.Errorf("Unexpected call site %+v", site)
+	}
+}
@@ -0,0 +1,323 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Strategy selects the direction in which changed symbols are connected to binaries
+type Strategy string
+
+const (
+	// StrategyReverse walks the incoming calls of each changed symbol up to main
+	// functions with gopls
+	StrategyReverse Strategy = "reverse"
+	// StrategyForward builds the call graph of the repository once and walks the
+	// outgoing calls of each main function down to the changed symbols. Its cost
+	// does not grow with how widely a changed symbol is used
+	StrategyForward Strategy = "forward"
+	// StrategyAuto goes forward when a changed symbol fans out widely, reverse otherwise
+	StrategyAuto Strategy = "auto"
+)
+
+// ParseStrategy parses a tracing strategy, the empty string is reverse
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(s) {
+	case "", StrategyReverse:
+		return StrategyReverse, nil
+	case StrategyForward, StrategyAuto:
+		return Strategy(s), nil
+	}
+	return "", i18n.Errorf("不支持的追踪策略: %s", s)
+}
+
+// autoForwardFanOut is the caller fan-out above which StrategyAuto goes forward
+const autoForwardFanOut = 50
+
+// maxForwardDepth bounds the calls followed from a main function
+const maxForwardDepth = 30
+
+// strategy resolves StrategyAuto: forward as soon as one changed symbol has
+// more than autoForwardFanOut callers at one of its first caller levels
+func (a *LSPImpactAnalyzer) strategy(changes []ChangedSymbol) Strategy {
+	if a.opts.Strategy != StrategyAuto {
+		if a.opts.Strategy == "" {
+			return StrategyReverse
+		}
+		return a.opts.Strategy
+	}
+	for _, ch := range changes {
+		symbol := ch.Symbol
+		if !tracesReferences(symbol) || ch.ChangeType == ChangeTypeDelete {
+			continue
+		}
+		if wide, err := a.tracer.FanOut(symbol, autoForwardFanOut); err == nil && wide {
+			logger.Info("symbol fans out widely, tracing forward from main functions",
+				"symbol", qualifiedSymbolName(ch))
+			return StrategyForward
+		}
+	}
+	return StrategyReverse
+}
+
+// forwardSearch is the call graph of the repository, built from SSA with class
+// hierarchy analysis: a call through an interface reaches every implementation,
+// like gopls' incoming calls do. Code outside the repository is not built, so
+// calls back into the repository from dependencies are not followed
+type forwardSearch struct {
+	fset  *token.FileSet
+	pkgs  []*packages.Package
+	prog  *ssa.Program
+	graph *callgraph.Graph
+}
+
+// loadForward type-checks every package of the repository and builds its call graph
+func loadForward(root string) (*forwardSearch, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  root,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			logger.Debug("package error while building the call graph", "package", pkg.PkgPath, "error", err)
+		}
+	}
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	return &forwardSearch{fset: prog.Fset, pkgs: pkgs, prog: prog, graph: cha.CallGraph(prog)}, nil
+}
+
+// forwardTarget is a function whose being reached from main means a changed
+// symbol is used: the changed function itself, or a function referring to a
+// changed constant or variable
+type forwardTarget struct {
+	index int           // Index of the change
+	leaf  *lsp.CallNode // Node appended after the function for constants and variables
+}
+
+// paths returns the paths from main functions to each change, keyed by change
+// index. Changes missing from the result could not be resolved in the call
+// graph, such as init functions and blank imports, and are traced in reverse
+func (s *forwardSearch) paths(changes []ChangedSymbol) map[int][]lsp.CallPath {
+	targets := make(map[types.Object][]forwardTarget)
+	res := make(map[int][]lsp.CallPath)
+	for i, ch := range changes {
+		if !tracesReferences(ch.Symbol) || ch.ChangeType == ChangeTypeDelete {
+			continue
+		}
+		obj := s.object(ch.Symbol)
+		if obj == nil {
+			continue
+		}
+		res[i] = nil
+		if _, ok := obj.(*types.Func); ok {
+			targets[obj] = append(targets[obj], forwardTarget{index: i})
+			continue
+		}
+		leaf := &lsp.CallNode{FunctionName: ch.Symbol.Name, PackagePath: ch.Symbol.PackagePath}
+		for _, fn := range s.referrers(obj) {
+			targets[fn] = append(targets[fn], forwardTarget{index: i, leaf: leaf})
+		}
+	}
+	if len(targets) == 0 {
+		return res
+	}
+
+	for _, main := range s.mains() {
+		parent, depth := s.walk(main)
+		// The shortest path per change, ties broken by the formatted path
+		best := make(map[int]lsp.CallPath)
+		bestDepth := make(map[int]int)
+		for fn, d := range depth {
+			if fn.Object() == nil {
+				continue
+			}
+			for _, t := range targets[fn.Object()] {
+				path := s.path(main, fn, parent)
+				if t.leaf != nil {
+					path.Path = append(path.Path, *t.leaf)
+				}
+				if prev, ok := best[t.index]; ok && (bestDepth[t.index] < d ||
+					bestDepth[t.index] == d && strings.Join(formatTracePath(prev), "\x00") <= strings.Join(formatTracePath(path), "\x00")) {
+					continue
+				}
+				best[t.index] = path
+				bestDepth[t.index] = d
+			}
+		}
+		for i, path := range best {
+			res[i] = append(res[i], path)
+		}
+	}
+	return res
+}
+
+// walk visits the functions reachable from main breadth-first, up to
+// maxForwardDepth calls, and returns each one's caller on a shortest path and
+// the number of calls on it
+func (s *forwardSearch) walk(main *ssa.Function) (parent map[*ssa.Function]*ssa.Function, depths map[*ssa.Function]int) {
+	parent = map[*ssa.Function]*ssa.Function{main: nil}
+	depths = map[*ssa.Function]int{main: 0}
+	level := []*ssa.Function{main}
+	for depth := 0; depth < maxForwardDepth && len(level) > 0; depth++ {
+		var next []*ssa.Function
+		for _, fn := range level {
+			node := s.graph.Nodes[fn]
+			if node == nil {
+				continue
+			}
+			for _, edge := range node.Out {
+				callee := edge.Callee.Func
+				if _, seen := parent[callee]; seen {
+					continue
+				}
+				parent[callee] = fn
+				depths[callee] = depth + 1
+				next = append(next, callee)
+			}
+		}
+		level = next
+	}
+	return parent, depths
+}
+
+// path converts the chain of callers from main to fn into a call path. Anonymous
+// functions and synthetic wrappers are left out, their calls are attributed to
+// the enclosing function or the wrapped method
+func (s *forwardSearch) path(main, fn *ssa.Function, parent map[*ssa.Function]*ssa.Function) lsp.CallPath {
+	var chain []*ssa.Function
+	for f := fn; f != nil; f = parent[f] {
+		chain = append(chain, f)
+	}
+	var nodes []lsp.CallNode
+	for i := len(chain) - 1; i >= 0; i-- {
+		// Generic instances are named after their origin
+		f, obj := chain[i], chain[i].Object()
+		if f.Parent() != nil || obj == nil || obj.Pkg() == nil || f.Synthetic != "" && f.Origin() == nil {
+			continue
+		}
+		nodes = append(nodes, lsp.CallNode{FunctionName: obj.Name(), PackagePath: obj.Pkg().Path(), Receiver: receiverType(obj)})
+	}
+	return lsp.CallPath{
+		BinaryName: parser.BinaryName(main.Pkg.Pkg.Path()),
+		MainURI:    lsp.URIFromPath(s.fset.Position(main.Pos()).Filename),
+		Path:       nodes,
+	}
+}
+
+// receiverType returns the receiver type of a method as declared, "*Handler" or
+// "Handler" without type arguments, "" for functions
+func receiverType(obj types.Object) string {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	typ, ptr := recv.Type(), ""
+	if p, ok := typ.(*types.Pointer); ok {
+		typ, ptr = p.Elem(), "*"
+	}
+	if named, ok := typ.(*types.Named); ok {
+		return ptr + named.Obj().Name()
+	}
+	return ""
+}
+
+// mains returns the main functions of the repository's main packages
+func (s *forwardSearch) mains() []*ssa.Function {
+	var res []*ssa.Function
+	for _, pkg := range s.pkgs {
+		if pkg.Name != "main" || pkg.Types == nil {
+			continue
+		}
+		if ssaPkg := s.prog.Package(pkg.Types); ssaPkg != nil {
+			if main := ssaPkg.Func("main"); main != nil {
+				res = append(res, main)
+			}
+		}
+	}
+	return res
+}
+
+// object returns the package-level function, method, constant or variable
+// declared by symbol, nil if it is not part of the loaded packages
+func (s *forwardSearch) object(symbol *parser.Symbol) types.Object {
+	for _, pkg := range s.pkgs {
+		if pkg.PkgPath != symbol.PackagePath || pkg.TypesInfo == nil {
+			continue
+		}
+		for id, obj := range pkg.TypesInfo.Defs {
+			if obj == nil || id.Name != symbol.Name {
+				continue
+			}
+			if _, isFunc := obj.(*types.Func); !isFunc && obj.Parent() != pkg.Types.Scope() {
+				continue
+			}
+			if s.declaredAt(id, obj, symbol.Position) {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// declaredAt reports whether the identifier id declaring obj is at pos. Functions
+// are positioned at their func keyword, which is on the line of their name
+func (s *forwardSearch) declaredAt(id *ast.Ident, obj types.Object, pos token.Position) bool {
+	p := s.fset.Position(id.Pos())
+	if filepath.Clean(p.Filename) != filepath.Clean(pos.Filename) || p.Line != pos.Line {
+		return false
+	}
+	_, isFunc := obj.(*types.Func)
+	return isFunc || p.Column == pos.Column
+}
+
+// referrers returns the functions referring to obj, including through closures
+// declared in them
+func (s *forwardSearch) referrers(obj types.Object) []types.Object {
+	var res []types.Object
+	for _, pkg := range s.pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				found := false
+				ast.Inspect(fd.Body, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok && pkg.TypesInfo.Uses[id] == obj {
+						found = true
+					}
+					return !found
+				})
+				if fn := pkg.TypesInfo.Defs[fd.Name]; found && fn != nil {
+					res = append(res, fn)
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range []string{"", "reverse", "forward", "auto"} {
+		if _, err := ParseStrategy(s); err != nil {
+			t.Errorf("ParseStrategy(%q): %v", s, err)
+		}
+	}
+	if _, err := ParseStrategy("sideways"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestForwardPaths(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", "testdata", "service-boundary-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := loadForward(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(root, "internal", "bill", "api", "api.go")
+	change := func(name string, kind parser.SymbolKind, line, column int) ChangedSymbol {
+		return ChangedSymbol{Symbol: &parser.Symbol{
+			Name:        name,
+			Kind:        kind,
+			PackagePath: "example.com/boundary/internal/bill/api",
+			Position:    token.Position{Filename: file, Line: line, Column: column},
+		}}
+	}
+	paths := search.paths([]ChangedSymbol{
+		change("Start", parser.SymbolKindFunction, 7, 1),
+		change("prefix", parser.SymbolKindConstant, 12, 7),
+		change("Lookup", parser.SymbolKindFunction, 15, 1),
+	})
+
+	got := make(map[int][]string)
+	for i, ps := range paths {
+		for _, p := range ps {
+			var nodes []string
+			for _, node := range p.Path {
+				nodes = append(nodes, path.Base(node.PackagePath)+"."+node.FunctionName)
+			}
+			got[i] = append(got[i], p.BinaryName+": "+strings.Join(nodes, " -> "))
+		}
+	}
+	want := map[int][]string{
+		// grace.Run calls Runner.Start, which reaches every implementation
+		0: {"bill: bill.main -> grace.Run -> api.Start", "rfs: rfs.main -> grace.Run -> api.Start"},
+		1: {"rfs: rfs.main -> api.Lookup -> api.prefix"},
+		2: {"rfs: rfs.main -> api.Lookup"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
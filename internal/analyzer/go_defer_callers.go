@@ -0,0 +1,21 @@
+package analyzer
+
+import (
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// fillGoDeferCallers 为变更的函数补充在 go 或 defer 语句中调用它的函数。gopls 对这些调用的
+// 报告并不总是与直接调用一致,调用链分析没有结果时由它们继续追踪。
+// 已完成包内可达性分析的符号不需要: 包内分析基于类型信息,包含这些调用
+func (cd *ChangeDetector) fillGoDeferCallers(changes []ChangedSymbol) {
+	for i := range changes {
+		c := &changes[i]
+		if c.Local || c.ChangeType == ChangeTypeDelete {
+			continue
+		}
+		c.GoDefer = cd.parser.GoDeferCallers(c.Symbol)
+		if len(c.GoDefer) > 0 {
+			logger.Debug("go/defer 调用", "symbol", c.Symbol.Name, "callers", len(c.GoDefer))
+		}
+	}
+}
@@ -1,8 +1,242 @@
 package analyzer
 
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
 // AffectedBinary represents a binary/service affected by code changes
 type AffectedBinary struct {
-	Name      string   // Binary name (e.g., "cmd/service1")
-	PkgPath   string   // Package path
-	TracePath []string // Call trace path from main to changed function
+	Name       string      `json:"name"`                 // Binary name (e.g., "cmd/service1")
+	PkgPath    string      `json:"package"`              // Package path
+	Dir        string      `json:"dir,omitempty"`        // Directory of the main package, relative to the repository root
+	TracePath  []string    `json:"trace_path"`           // Call trace path from main to changed function
+	Paths      [][]string  `json:"paths,omitempty"`      // All distinct call paths (only when multiple paths are requested)
+	Confidence Confidence  `json:"confidence"`           // How certain the binary is actually affected
+	Risk       int         `json:"risk,omitempty"`       // Risk score from 1 to 100, see riskScorer; unset when streamed
+	Coverage   *Coverage   `json:"coverage,omitempty"`   // Test coverage of the changed code reaching the binary, nil if unknown
+	Deployment *Deployment `json:"deployment,omitempty"` // Deployment identifiers from the repository config
+	Owners     []string    `json:"owners,omitempty"`     // Owning teams from the config or CODEOWNERS
+	Team       string      `json:"team,omitempty"`       // Team the binary is routed to, from the repository config
+	Routes     []Route     `json:"routes,omitempty"`     // Affected HTTP routes and gRPC methods, only when routes are requested
+	Commands   []string    `json:"commands,omitempty"`   // Affected subcommands such as "db migrate", only when commands are requested
+	Jobs       []string    `json:"jobs,omitempty"`       // Affected configured jobs such as "internal/jobs.RunNightlyReconcile"
+
+	// BazelTarget is the label of the Bazel rule building the binary, such as
+	// "//cmd/api:api". Only resolved with Options.Bazel
+	BazelTarget string `json:"bazel_target,omitempty"`
+
+	// Approximate means the binary was only found through the package import graph
+	// of a symbol with too many callers: it imports the package but may not call the symbol
+	Approximate bool `json:"approximate,omitempty"`
+
+	// Evidence explains each reported path, in the order of Paths (or TracePath
+	// alone), with call site locations. Only filled with Options.Explain
+	Evidence []Evidence `json:"evidence,omitempty"`
+}
+
+// Route is an HTTP endpoint or gRPC method of a binary that reaches a changed symbol
+type Route struct {
+	Method  string `json:"method,omitempty"` // HTTP method, empty if any method matches; "gRPC" for gRPC methods
+	Path    string `json:"path"`             // URL pattern, or "Service/Method" for gRPC
+	Handler string `json:"handler"`          // Registered function, e.g. "handler.(*Users).List"
+}
+
+// String formats the route as "METHOD /path"
+func (r Route) String() string {
+	if r.Method == "" {
+		return r.Path
+	}
+	return r.Method + " " + r.Path
+}
+
+// Deployment identifies where a binary is deployed
+type Deployment struct {
+	Image       string `json:"image,omitempty"`        // Container image, e.g. "registry/api-server"
+	HelmRelease string `json:"helm_release,omitempty"` // Helm release name
+	Kubernetes  string `json:"kubernetes,omitempty"`   // Kubernetes workload, e.g. "prod/deployment/api-server"
+}
+
+// String returns the non-empty identifiers joined by ", "
+func (d *Deployment) String() string {
+	if d == nil {
+		return ""
+	}
+	var parts []string
+	if d.Image != "" {
+		parts = append(parts, "image="+d.Image)
+	}
+	if d.HelmRelease != "" {
+		parts = append(parts, "helm="+d.HelmRelease)
+	}
+	if d.Kubernetes != "" {
+		parts = append(parts, "k8s="+d.Kubernetes)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ReportMetadata records the analysis settings a report depends on
+type ReportMetadata struct {
+	// InterfaceFilter is the effective cross-service pruning mode, off when no
+	// service boundary rules are configured
+	InterfaceFilter InterfaceFilter `json:"interface_filter"`
+	// Mode is the analysis mode, ModeCalls or ModeImports
+	Mode string `json:"mode"`
+	// Strategy is the tracing direction actually used in ModeCalls
+	Strategy Strategy `json:"strategy,omitempty"`
+}
+
+// Confidence describes how reliable an impact result is
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"   // Direct static calls or references
+	ConfidenceMedium Confidence = "medium" // Reached through interface dispatch
+	ConfidenceLow    Confidence = "low"    // Reached only through init/blank-import reachability
+)
+
+// rank returns an ordering value, higher means more confident
+func (c Confidence) rank() int {
+	switch c {
+	case ConfidenceHigh:
+		return 3
+	case ConfidenceMedium:
+		return 2
+	case ConfidenceLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// symbolConfidence returns the confidence of impact derived from a changed symbol
+func symbolConfidence(symbol *parser.Symbol) Confidence {
+	switch symbol.Kind {
+	case parser.SymbolKindInit, parser.SymbolKindImport:
+		return ConfidenceLow
+	case parser.SymbolKindFunction:
+		if extra, ok := symbol.Extra.(parser.FunctionExtra); ok && extra.ImplementsInterface {
+			return ConfidenceMedium
+		}
+	}
+	return ConfidenceHigh
+}
+
+// Report is the complete result of an impact analysis
+type Report struct {
+	Affected    []AffectedBinary `json:"affected"`     // Affected binaries
+	Changes     []ChangeMetrics  `json:"changes"`      // Per-change blast radius metrics
+	BlastRadius BlastRadius      `json:"blast_radius"` // Overall blast radius metrics
+	Metadata    ReportMetadata   `json:"metadata"`     // Settings that shaped the report
+
+	// InterfaceBreakage lists conversions to interfaces that a changed type no longer satisfies
+	InterfaceBreakage []InterfaceBreakage `json:"interface_breakage,omitempty"`
+	// FailedPackages lists the packages that failed to load. Changes in them were
+	// not analyzed, so the report is incomplete
+	FailedPackages []FailedPackage `json:"failed_packages,omitempty"`
+	// OutOfCone lists the directories, relative to the repository root, of changed
+	// packages outside the sparse checkout. They were not analyzed, so the report
+	// is incomplete
+	OutOfCone []string `json:"out_of_cone,omitempty"`
+	// Unknown lists the changed symbols whose trace failed, so their impact is
+	// unknown and the report may miss binaries
+	Unknown []UnknownImpact `json:"unknown,omitempty"`
+	// Packages lists the packages on the call paths with GranularityPackage
+	Packages []AffectedPackage `json:"packages,omitempty"`
+	// Tests lists the test runs whose coverage profiles executed changed lines
+	Tests []CoveringTest `json:"tests,omitempty"`
+	// Range describes the analyzed git range, nil when the changes did not come
+	// from a diff between two commits
+	Range *CommitRange `json:"range,omitempty"`
+}
+
+// CommitRange is the git range a diff was read from
+type CommitRange struct {
+	Old     string   `json:"old"`     // Full hash of the old commit
+	New     string   `json:"new"`     // Full hash of the new commit
+	Commits []Commit `json:"commits"` // Commits reachable from New but not Old, newest first
+}
+
+// Commit describes a git commit
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email,omitempty"`
+	Date    time.Time `json:"date"` // Author date
+	Subject string    `json:"subject"`
+}
+
+// ShortHash returns the first 7 characters of the hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// MarshalJSON encodes empty affected binaries, changes and commits as [] rather
+// than null, so consumers can iterate over them without a null check
+func (r Report) MarshalJSON() ([]byte, error) {
+	type report Report // Without the MarshalJSON method
+	res := report(r)
+	if res.Affected == nil {
+		res.Affected = []AffectedBinary{}
+	}
+	if res.Changes == nil {
+		res.Changes = []ChangeMetrics{}
+	}
+	if res.Range != nil && res.Range.Commits == nil {
+		rg := *res.Range
+		rg.Commits = []Commit{}
+		res.Range = &rg
+	}
+	return json.Marshal(res)
+}
+
+// Incomplete reports whether the report may miss affected binaries because
+// packages failed to load, were not checked out, traces failed or the time
+// budget ran out
+func (r *Report) Incomplete() bool {
+	return len(r.FailedPackages) > 0 || len(r.OutOfCone) > 0 || len(r.Unknown) > 0 || len(r.NotAnalyzed()) > 0
+}
+
+// NotAnalyzed returns the changes skipped because the time budget ran out
+func (r *Report) NotAnalyzed() []ChangeMetrics {
+	var res []ChangeMetrics
+	for _, c := range r.Changes {
+		if c.Skipped == SkipBudget {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// UnknownImpact is a changed symbol whose impact could not be determined
+type UnknownImpact struct {
+	Symbol string `json:"symbol"`         // Qualified symbol name (package.Name)
+	Kind   string `json:"kind"`           // Symbol kind
+	File   string `json:"file,omitempty"` // File containing the symbol, relative to the repository root
+	Line   int    `json:"line,omitempty"` // Line of the symbol
+	Error  string `json:"error"`          // Why the trace failed
+}
+
+// sortedUnknown sorts unknown changes by symbol, traces finish in any order
+func sortedUnknown(unknown []UnknownImpact) []UnknownImpact {
+	sort.Slice(unknown, func(i, j int) bool {
+		if unknown[i].Symbol != unknown[j].Symbol {
+			return unknown[i].Symbol < unknown[j].Symbol
+		}
+		return unknown[i].Line < unknown[j].Line
+	})
+	return unknown
+}
+
+// FailedPackage is a package excluded from the analysis because it has errors
+type FailedPackage struct {
+	Package string `json:"package"` // Import path
+	Error   string `json:"error"`   // First error reported for the package
 }
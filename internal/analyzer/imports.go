@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// Analysis modes recorded in ReportMetadata.Mode
+const (
+	// ModeCalls traces the call hierarchy of every changed symbol
+	ModeCalls = "calls"
+	// ModeImports reports every binary importing a changed package, see ImportImpact
+	ModeImports = "imports"
+)
+
+// PackageChange is a changed package and the import chains of the binaries
+// importing it, directly or indirectly
+type PackageChange struct {
+	PkgPath string
+	Chains  []parser.ImportChain
+}
+
+// ImportImpact builds a report without any call hierarchy: every binary whose
+// import graph includes a changed package is affected. It over-approximates
+// the call-based analysis, a binary importing a package may not run the changed
+// code, so binaries are reported with low confidence and marked Approximate.
+// Paths run through package nodes from the main package to the changed package.
+// The entrypoint, target, deployment and owner options apply; service boundary
+// rules do not, as paths carry no calls to check
+func ImportImpact(root string, changes []PackageChange, opts Options) *Report {
+	opts.DisableCrossServiceFilter = true
+	filter := newPathFilter(root, opts)
+	collector := newBinaryCollector(opts.pathLimit())
+	metrics := newMetricsBuilder(root)
+	pkgs := opts.packageCollector()
+	risk := newRiskScorer()
+
+	for i, change := range changes {
+		var paths []lsp.CallPath
+		for _, chain := range change.Chains {
+			path := lsp.CallPath{
+				BinaryName:  parser.BinaryName(chain.Main.PkgPath),
+				MainURI:     lsp.URIFromPath(chain.Main.File),
+				Approximate: true,
+			}
+			for _, pkgPath := range chain.Packages {
+				path.Path = append(path.Path, lsp.CallNode{PackagePath: pkgPath})
+			}
+			paths = append(paths, path)
+		}
+		paths = filter.filter(paths)
+		pkgs.add(paths)
+
+		symbol := ChangedSymbol{Symbol: &parser.Symbol{Kind: parser.SymbolKindPackage, PackagePath: change.PkgPath}}
+		metrics.add(i, symbol, paths, lsp.Reachable)
+		risk.add(i, symbol, paths, nil)
+		for _, path := range paths {
+			if !collector.add(path, ConfidenceLow) {
+				continue
+			}
+			binary := collector.byName[path.BinaryName]
+			binary.Dir = filter.mainDir(path.MainURI)
+			binary.Deployment = filter.deployment(path, opts.Deployments)
+			if opts.Bazel {
+				binary.BazelTarget = filter.bazelTarget(path, opts.BazelTargets)
+			}
+			binary.Owners = filter.owners(path, opts.Owners, opts.CodeOwners)
+			binary.Team = filter.team(path, opts.Teams)
+			if opts.OnAffected != nil {
+				opts.OnAffected(collector.first(path.BinaryName))
+			}
+		}
+	}
+
+	return &Report{
+		Affected:    risk.apply(collector.binaries(), opts.MinRisk),
+		Changes:     metrics.sortedChanges(),
+		BlastRadius: metrics.blastRadius(),
+		Metadata:    ReportMetadata{InterfaceFilter: filter.mode(), Mode: ModeImports},
+		Packages:    pkgs.packages(),
+	}
+}
@@ -0,0 +1,20 @@
+package analyzer
+
+import (
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// fillInitRegistrations 为变更的函数补充包初始化时登记它的位置(如 init 中的
+// handlers["foo"] = handleFoo)。登记后函数可能被导入登记包的任何程序通过注册表调用
+func (cd *ChangeDetector) fillInitRegistrations(changes []ChangedSymbol) {
+	for i := range changes {
+		c := &changes[i]
+		if c.Local || c.ChangeType == ChangeTypeDelete {
+			continue
+		}
+		c.Registrations = cd.parser.InitRegistrations(c.Symbol)
+		if len(c.Registrations) > 0 {
+			logger.Debug("包初始化时登记", "symbol", c.Symbol.Name, "registrations", len(c.Registrations))
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package analyzer
+
+import (
+	"context"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// InterfaceBreakage 旧代码中把类型赋值或转换为接口的位置。类型的方法被删除或签名变化后,
+// 或接口(包括它嵌入的接口)新增了类型没有的方法后,类型不再满足该接口,所在包将无法编译。
+// 这与调用链影响不同,调用链追踪无法发现
+type InterfaceBreakage struct {
+	Type      string   `json:"type"`               // 被转换的类型,如 *example.com/app/pkg.Server
+	Method    string   `json:"method"`             // 变化的方法,如 Base.Close(可能通过嵌入提升到 Type),或接口新增的方法,如 Reader.Keys
+	Removed   bool     `json:"removed"`            // 方法被删除(否则为签名变化)
+	Missing   bool     `json:"missing,omitempty"`  // 接口新增了 Method,类型没有实现(可能由 Interface 嵌入的接口新增)
+	Interface string   `json:"interface"`          // 目标接口类型
+	Package   string   `json:"package"`            // 赋值或转换所在的包
+	File      string   `json:"file,omitempty"`     // 所在文件(相对仓库根目录),包级变量初始化中的转换可能没有位置
+	Line      int      `json:"line,omitempty"`     // 所在行
+	Binaries  []string `json:"binaries,omitempty"` // 导入该包的服务,均无法编译
+}
+
+// FindInterfaceBreakage 加载 dir 下的所有包(应为旧 commit 的代码,此时能通过编译),
+// 查找把 changes 中的接收者类型隐式或显式转换为包含该方法的接口的位置,以及把类型转换为
+// 方法集变大的接口的位置: interfaces 中的接口,或直接、间接嵌入了它们的接口。类型在旧代码中
+// 没有新增的方法、也没有在 changes 中补上时不再满足接口。
+// root 为仓库根目录,用于匹配 MethodChange.Dir、InterfaceChange.Dir 和生成相对路径
+func FindInterfaceBreakage(ctx context.Context, root, dir string, changes []MethodChange, interfaces []InterfaceChange) ([]InterfaceBreakage, error) {
+	if len(changes) == 0 && len(interfaces) == 0 {
+		return nil, nil
+	}
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.LoadAllSyntax,
+		Dir:     dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+	})
+
+	// 只为仓库内的包构建函数体,依赖只需要类型信息
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	local := make(map[string]bool)
+	for _, pkg := range pkgs {
+		local[pkg.PkgPath] = true
+	}
+	binaries := importingBinaries(pkgs)
+
+	relative := func(file string) string {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return file
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	type key struct {
+		typ, method, iface, file string
+		line                     int
+	}
+	seen := make(map[key]bool)
+	var res []InterfaceBreakage
+	add := func(b InterfaceBreakage) {
+		k := key{b.Type, b.Method, b.Interface, b.File, b.Line}
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		b.Binaries = binaries[b.Package]
+		res = append(res, b)
+	}
+	byPath := make(map[string]*packages.Package)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		byPath[pkg.PkgPath] = pkg
+	})
+	additions := &interfaceAdditions{
+		changes:  interfaces,
+		methods:  changes,
+		pkgs:     byPath,
+		fset:     prog.Fset,
+		relative: relative,
+		cache:    make(map[*types.Named][]interfaceAddition),
+	}
+	for fn := range ssautil.AllFunctions(prog) {
+		if fn.Pkg == nil || !local[fn.Pkg.Pkg.Path()] {
+			continue
+		}
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				mi, ok := instr.(*ssa.MakeInterface)
+				if !ok {
+					continue
+				}
+				iface, ok := mi.Type().Underlying().(*types.Interface)
+				if !ok {
+					continue
+				}
+				_, pointer := mi.X.Type().(*types.Pointer)
+				methodSet := prog.MethodSets.MethodSet(mi.X.Type())
+				for i := 0; i < iface.NumMethods(); i++ {
+					// 方法可能通过嵌入提升而来,按实际声明方法的接收者匹配
+					sel := methodSet.Lookup(iface.Method(i).Pkg(), iface.Method(i).Name())
+					if sel == nil {
+						continue
+					}
+					recv, _ := namedType(sel.Obj().Type().(*types.Signature).Recv().Type())
+					if recv == nil {
+						continue
+					}
+					recvDir := filepath.ToSlash(filepath.Dir(relative(prog.Fset.Position(recv.Obj().Pos()).Filename)))
+					change, ok := findMethodChange(changes, recvDir, recv.Obj().Name(), sel.Obj().Name())
+					if !ok || change.Added() {
+						continue
+					}
+					// 只改为值接收者时方法集只增不减;改为指针接收者时只影响值类型的转换
+					if newPointer, ok := change.receiverOnly(); ok && (!newPointer || pointer) {
+						continue
+					}
+					pos := position(prog, mi, fn)
+					add(InterfaceBreakage{
+						Type:      types.TypeString(mi.X.Type(), nil),
+						Method:    change.Receiver + "." + change.Name,
+						Removed:   change.Removed(),
+						Interface: types.TypeString(mi.Type(), nil),
+						Package:   fn.Pkg.Pkg.Path(),
+						File:      relative(pos.Filename),
+						Line:      pos.Line,
+					})
+				}
+
+				for _, a := range additions.of(mi.Type()) {
+					if hasMethod(methodSet, a.method) || additions.gains(mi.X.Type(), a.method) {
+						continue
+					}
+					pos := position(prog, mi, fn)
+					add(InterfaceBreakage{
+						Type:      types.TypeString(mi.X.Type(), nil),
+						Method:    a.iface + "." + a.method,
+						Missing:   true,
+						Interface: types.TypeString(mi.Type(), nil),
+						Package:   fn.Pkg.Pkg.Path(),
+						File:      relative(pos.Filename),
+						Line:      pos.Line,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Method < b.Method
+	})
+	return res, nil
+}
+
+// interfaceAddition 接口新增的方法
+type interfaceAddition struct {
+	iface  string // 新增方法的接口名,可能是被转换的接口嵌入的接口
+	method string
+}
+
+// interfaceAdditions 按旧代码的类型信息重新计算接口的方法集: 接口新增的方法包括它自己
+// 新声明的方法、新嵌入的接口的方法,以及它嵌入的接口(逐层)新增的方法
+type interfaceAdditions struct {
+	changes  []InterfaceChange
+	methods  []MethodChange               // 用于判断类型是否在同一次变更中补上了新增的方法
+	pkgs     map[string]*packages.Package // 包路径 -> 包,用于按文件的导入声明解析嵌入的接口
+	fset     *token.FileSet
+	relative func(string) string
+	cache    map[*types.Named][]interfaceAddition
+}
+
+// of 返回接口类型 t 新增的方法,t 不是接口时返回 nil
+func (a *interfaceAdditions) of(t types.Type) []interfaceAddition {
+	if len(a.changes) == 0 {
+		return nil
+	}
+	iface, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	named, _ := namedType(t)
+	if named == nil {
+		return a.embedded(iface)
+	}
+	if res, ok := a.cache[named]; ok {
+		return res
+	}
+	// 旧代码能通过编译,接口的嵌入不会形成循环
+	res := append(a.declared(named), a.embedded(iface)...)
+	a.cache[named] = res
+	return res
+}
+
+// embedded 返回接口嵌入的接口新增的方法
+func (a *interfaceAdditions) embedded(iface *types.Interface) []interfaceAddition {
+	var res []interfaceAddition
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		res = append(res, a.of(iface.EmbeddedType(i))...)
+	}
+	return res
+}
+
+// declared 返回接口自己的声明中新增的方法
+func (a *interfaceAdditions) declared(named *types.Named) []interfaceAddition {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return nil
+	}
+	dir := a.dir(obj)
+	var res []interfaceAddition
+	for _, c := range a.changes {
+		if c.Dir != dir || c.Name != obj.Name() {
+			continue
+		}
+		for _, m := range c.Methods {
+			res = append(res, interfaceAddition{iface: c.Name, method: m})
+		}
+		for _, e := range c.Embeds {
+			// 旧代码中还没有的接口无法展开
+			embedded, ok := a.lookupType(obj, e).(*types.Interface)
+			if !ok {
+				continue
+			}
+			for i := 0; i < embedded.NumMethods(); i++ {
+				res = append(res, interfaceAddition{iface: c.Name, method: embedded.Method(i).Name()})
+			}
+		}
+	}
+	return res
+}
+
+// lookupType 在声明 obj 的文件中按源码中的写法("Closer" 或 "io.Closer")查找类型,返回其底层类型
+func (a *interfaceAdditions) lookupType(obj types.Object, expr string) types.Type {
+	scope := obj.Pkg().Scope()
+	if qualifier, name, ok := strings.Cut(expr, "."); ok {
+		imported := a.imported(obj, qualifier)
+		if imported == nil {
+			return nil
+		}
+		scope, expr = imported.Scope(), name
+	}
+	if obj, ok := scope.Lookup(expr).(*types.TypeName); ok {
+		return obj.Type().Underlying()
+	}
+	return nil
+}
+
+// imported 按声明 obj 的文件的导入声明查找以 name 引用的包,支持别名导入(stdio "io")
+func (a *interfaceAdditions) imported(obj types.Object, name string) *types.Package {
+	pkg := a.pkgs[obj.Pkg().Path()]
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil
+	}
+	for _, file := range pkg.Syntax {
+		if obj.Pos() < file.FileStart || obj.Pos() >= file.FileEnd {
+			continue
+		}
+		for _, spec := range file.Imports {
+			if pkgName := pkg.TypesInfo.PkgNameOf(spec); pkgName != nil && pkgName.Name() == name {
+				return pkgName.Imported()
+			}
+		}
+	}
+	return nil
+}
+
+// gains 判断被转换的类型 t 是否在同一次变更中补上了方法 name: 类型自己新增了该方法,
+// 或嵌入的字段新增了该方法并提升到 t。与方法集的规则一致,值类型不包含新增的指针接收者方法
+func (a *interfaceAdditions) gains(t types.Type, name string) bool {
+	named, pointer := namedType(t)
+	return named != nil && a.gainsNamed(named, name, pointer, make(map[*types.Named]bool))
+}
+
+// gainsNamed 判断命名类型是否补上了方法 name,addressable 表示指针接收者的方法是否可用
+func (a *interfaceAdditions) gainsNamed(named *types.Named, name string, addressable bool, seen map[*types.Named]bool) bool {
+	if seen[named] || named.Obj().Pkg() == nil {
+		return false
+	}
+	seen[named] = true
+	change, ok := findMethodChange(a.methods, a.dir(named.Obj()), named.Obj().Name(), name)
+	if ok && change.Added() && (addressable || !strings.HasPrefix(change.NewSignature, "(*")) {
+		return true
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		embedded, pointer := namedType(field.Type())
+		if embedded == nil {
+			continue
+		}
+		// 嵌入的接口新增了该方法,字段的值必然实现它
+		if _, ok := embedded.Underlying().(*types.Interface); ok {
+			for _, add := range a.of(embedded) {
+				if add.method == name {
+					return true
+				}
+			}
+			continue
+		}
+		if a.gainsNamed(embedded, name, addressable || pointer, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// dir 返回声明 obj 的包的目录(相对仓库根目录)
+func (a *interfaceAdditions) dir(obj types.Object) string {
+	return filepath.ToSlash(filepath.Dir(a.relative(a.fset.Position(obj.Pos()).Filename)))
+}
+
+// hasMethod 判断方法集中是否有名为 name 的方法
+func hasMethod(methodSet *types.MethodSet, name string) bool {
+	for i := 0; i < methodSet.Len(); i++ {
+		if methodSet.At(i).Obj().Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// position 返回转换的位置。隐式转换没有位置,依次退回到使用转换结果的指令
+// (调用、赋值、返回等)、被转换的值和所在函数
+func position(prog *ssa.Program, mi *ssa.MakeInterface, fn *ssa.Function) token.Position {
+	candidates := []token.Pos{mi.Pos()}
+	if refs := mi.Referrers(); refs != nil {
+		for _, ref := range *refs {
+			candidates = append(candidates, ref.Pos())
+		}
+	}
+	for _, pos := range append(candidates, mi.X.Pos(), fn.Pos()) {
+		if pos.IsValid() {
+			return prog.Fset.Position(pos)
+		}
+	}
+	return token.Position{}
+}
+
+// namedType 返回类型(或其指针所指)的命名类型,泛型实例化返回原始类型
+func namedType(t types.Type) (*types.Named, bool) {
+	pointer := false
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t, pointer = ptr.Elem(), true
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return nil, false
+	}
+	return named.Origin(), pointer
+}
+
+// findMethodChange 查找指定目录中接收者类型的方法变化
+func findMethodChange(changes []MethodChange, dir, receiver, name string) (MethodChange, bool) {
+	for _, c := range changes {
+		if c.Dir == dir && c.Receiver == receiver && c.Name == name {
+			return c, true
+		}
+	}
+	return MethodChange{}, false
+}
+
+// importingBinaries 返回每个包被哪些 main 包(直接或间接)导入,服务名见 parser.BinaryName
+func importingBinaries(pkgs []*packages.Package) map[string][]string {
+	res := make(map[string][]string)
+	for _, pkg := range pkgs {
+		if pkg.Name != "main" || len(pkg.GoFiles) == 0 {
+			continue
+		}
+		binary := parser.BinaryName(pkg.PkgPath)
+		seen := make(map[string]bool)
+		packages.Visit([]*packages.Package{pkg}, func(p *packages.Package) bool {
+			if seen[p.PkgPath] {
+				return false
+			}
+			seen[p.PkgPath] = true
+			res[p.PkgPath] = append(res[p.PkgPath], binary)
+			return true
+		}, nil)
+	}
+	for pkg := range res {
+		sort.Strings(res[pkg])
+	}
+	return res
+}
@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCollectMethods(t *testing.T) {
+	src := []byte(`package p
+
+type T struct{}
+
+func (t *T) Get(ctx context.Context, key, def string) (string, error) { return "", nil }
+
+func (T) Name() string { return "" }
+
+func (b *Box[K, V]) Put(k K, v V) {}
+
+func Free() {}
+`)
+	decls := newVersionDecls()
+	decls.add("p", src)
+	methods := decls.methods
+
+	want := map[string]string{
+		"T.Get":   "(*T) (context.Context, string, string) (string, error)",
+		"T.Name":  "(T) () (string)",
+		"Box.Put": "(*Box) (K, V) ()",
+	}
+	if !reflect.DeepEqual(methods["p"], want) {
+		t.Errorf("collectMethods = %v, want %v", methods["p"], want)
+	}
+}
+
+func TestMethodChangeReceiverOnly(t *testing.T) {
+	toValue := MethodChange{OldSignature: "(*T) () (error)", NewSignature: "(T) () (error)"}
+	if pointer, ok := toValue.receiverOnly(); !ok || pointer {
+		t.Errorf("Expected a receiver-only change to a value receiver, got %v %v", pointer, ok)
+	}
+	params := MethodChange{OldSignature: "(*T) () (error)", NewSignature: "(*T) (int) (error)"}
+	if _, ok := params.receiverOnly(); ok {
+		t.Error("Parameter changes are not receiver-only")
+	}
+	if _, ok := (MethodChange{OldSignature: "(*T) () (error)"}).receiverOnly(); ok {
+		t.Error("Removed methods are not receiver-only")
+	}
+}
+
+func TestFindInterfaceBreakage(t *testing.T) {
+	testProject, err := filepath.Abs(filepath.Join("..", "..", "testdata", "embed-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除 Base.Close 后,worker.Run 中 *Worker 到 io.Closer 的转换无法编译;
+	// app 直接调用 s.Close(),属于调用链影响,不在这里报告
+	changes := []MethodChange{{Dir: "pkg/base", Receiver: "Base", Name: "Close", OldSignature: "(*Base) () (error)"}}
+	got, err := FindInterfaceBreakage(context.Background(), testProject, testProject, changes, nil)
+	if err != nil {
+		t.Fatalf("FindInterfaceBreakage failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 breakage, got %+v", got)
+	}
+	want := InterfaceBreakage{
+		Type:      "*example.com/embed-test/internal/worker.Worker",
+		Method:    "Base.Close",
+		Removed:   true,
+		Interface: "io.Closer",
+		Package:   "example.com/embed-test/internal/worker",
+		File:      "internal/worker/worker.go",
+		Line:      22,
+		Binaries:  []string{"worker"},
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("FindInterfaceBreakage = %+v, want %+v", got[0], want)
+	}
+
+	// 改为值接收者不会让任何类型失去方法
+	changes[0].NewSignature = "(Base) () (error)"
+	got, err = FindInterfaceBreakage(context.Background(), testProject, testProject, changes, nil)
+	if err != nil {
+		t.Fatalf("FindInterfaceBreakage failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expected no breakage for a value receiver, got %+v", got)
+	}
+}
+
+func TestCollectInterfaces(t *testing.T) {
+	src := []byte(`package p
+
+type Store interface {
+	Reader
+	io.Closer
+	Put(key, value string)
+}
+
+type Number interface {
+	~int | ~float64
+}
+
+type T struct{}
+`)
+	decls := newVersionDecls()
+	decls.add("p", src)
+	ifaces := decls.ifaces
+
+	want := map[string]interfaceDecl{
+		"Store":  {methods: []string{"Put"}, embeds: []string{"Reader", "io.Closer"}},
+		"Number": {},
+	}
+	if !reflect.DeepEqual(ifaces["p"], want) {
+		t.Errorf("collectInterfaces = %+v, want %+v", ifaces["p"], want)
+	}
+}
+
+func TestFindInterfaceBreakageEmbedded(t *testing.T) {
+	testProject, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iface-embed-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reader 新增 Keys 后 Store 也随之新增;Cache 已经有 Keys,Memory 和 Layered 不再满足 Store
+	interfaces := []InterfaceChange{{Dir: "pkg/store", Name: "Reader", Methods: []string{"Keys"}}}
+	got, err := FindInterfaceBreakage(context.Background(), testProject, testProject, nil, interfaces)
+	if err != nil {
+		t.Fatalf("FindInterfaceBreakage failed: %v", err)
+	}
+	want := []InterfaceBreakage{
+		{
+			Type:      "example.com/iface-embed-test/internal/layered.Layered",
+			Method:    "Reader.Keys",
+			Missing:   true,
+			Interface: "example.com/iface-embed-test/pkg/store.Store",
+			Package:   "example.com/iface-embed-test/internal/layered",
+			File:      "internal/layered/layered.go",
+			Line:      28,
+			Binaries:  []string{"worker"},
+		},
+		{
+			Type:      "*example.com/iface-embed-test/internal/memory.Memory",
+			Method:    "Reader.Keys",
+			Missing:   true,
+			Interface: "example.com/iface-embed-test/pkg/store.Store",
+			Package:   "example.com/iface-embed-test/internal/memory",
+			File:      "internal/memory/memory.go",
+			Line:      12,
+			Binaries:  []string{"api"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindInterfaceBreakage = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		changes []MethodChange
+		want    []string // 仍不满足接口的类型所在的文件
+	}{
+		{
+			name:    "Memory 自己补上 Keys",
+			changes: []MethodChange{{Dir: "internal/memory", Receiver: "Memory", Name: "Keys", NewSignature: "(*Memory) () ([]string)"}},
+			want:    []string{"internal/layered/layered.go"},
+		},
+		{
+			name:    "嵌入的 *Base 补上 Keys 并提升到 Layered",
+			changes: []MethodChange{{Dir: "internal/layered", Receiver: "Base", Name: "Keys", NewSignature: "(*Base) () ([]string)"}},
+			want:    []string{"internal/memory/memory.go"},
+		},
+		{
+			name:    "指针接收者的 Keys 不属于被转换的 Layered 值",
+			changes: []MethodChange{{Dir: "internal/layered", Receiver: "Layered", Name: "Keys", NewSignature: "(*Layered) () ([]string)"}},
+			want:    []string{"internal/layered/layered.go", "internal/memory/memory.go"},
+		},
+		{
+			name:    "值接收者的 Keys",
+			changes: []MethodChange{{Dir: "internal/layered", Receiver: "Layered", Name: "Keys", NewSignature: "(Layered) () ([]string)"}},
+			want:    []string{"internal/memory/memory.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindInterfaceBreakage(context.Background(), testProject, testProject, tt.changes, interfaces)
+			if err != nil {
+				t.Fatalf("FindInterfaceBreakage failed: %v", err)
+			}
+			var files []string
+			for _, b := range got {
+				files = append(files, b.File)
+			}
+			if !reflect.DeepEqual(files, tt.want) {
+				t.Errorf("Expected breakage in %v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Store 新嵌入以别名导入的 stdio.Closer,按 store.go 的导入声明解析为 io.Closer
+	interfaces = []InterfaceChange{{Dir: "pkg/store", Name: "Store", Embeds: []string{"stdio.Closer"}}}
+	got, err = FindInterfaceBreakage(context.Background(), testProject, testProject, nil, interfaces)
+	if err != nil {
+		t.Fatalf("FindInterfaceBreakage failed: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected Cache, Layered and Memory to miss Close, got %+v", got)
+	}
+	for _, b := range got {
+		if b.Method != "Store.Close" || !b.Missing {
+			t.Errorf("Unexpected breakage: %+v", b)
+		}
+	}
+}
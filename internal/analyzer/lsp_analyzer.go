@@ -3,16 +3,160 @@ package analyzer
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/jimyag/ripples/internal/logger"
 	"github.com/jimyag/ripples/internal/lsp"
 	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/packages"
 )
 
 // LSPImpactAnalyzer uses LSP client to analyze impact
 type LSPImpactAnalyzer struct {
 	tracer   *lsp.DirectCallTracer
 	rootPath string
+	opts     Options
+	packages []*packages.Package // Loaded by the parser, see SetPackages
+}
+
+// Options controls how analysis results are aggregated
+type Options struct {
+	// AllPaths keeps every distinct call path per binary instead of only the first one
+	AllPaths bool
+	// MaxPathsPerBinary limits the number of paths kept per binary.
+	// A positive value implies AllPaths; 0 means unlimited when AllPaths is set.
+	MaxPathsPerBinary int
+	// OnAffected is called once per binary as soon as the trace that reaches it
+	// completes, before the remaining symbols are traced. Calls are serial.
+	OnAffected func(AffectedBinary)
+
+	// Modules are the module paths used to relativize package paths for Services
+	// and CommonPackages; a package is relative to the longest matching module
+	Modules []string
+	// Entrypoints limits reported binaries to main packages whose directory
+	// (relative to the repository root) matches one of these patterns
+	Entrypoints []string
+	// CustomEntrypoints are functions other than main that count as binaries,
+	// such as Lambda handlers or cron jobs marked with //ripples:entrypoint
+	CustomEntrypoints []parser.Entrypoint
+	// Jobs are functions within binaries, such as cron jobs, declared as roots.
+	// Each binary reports the jobs through which it reaches the changed symbols
+	Jobs []parser.Job
+	// Routes reports the HTTP routes and gRPC methods through which each binary
+	// reaches the changed symbols
+	Routes bool
+	// Commands reports the cobra or urfave/cli subcommands through which each
+	// binary reaches the changed symbols
+	Commands bool
+	// Targets are the names of the only binaries to report. With BinariesOnly,
+	// tracing stops once all of them are affected and the remaining symbols are
+	// marked as skipped
+	Targets []string
+	// Binaries are the names of all binaries that may be reported. With
+	// BinariesOnly, once all of them are affected the remaining symbols are
+	// skipped as saturated, unless every path is wanted (AllPaths, MaxPathsPerBinary)
+	Binaries []string
+	// BinariesOnly means only the list of affected binaries is used, so tracing
+	// may stop early as described for Targets and Binaries. Results reported per
+	// change (Routes, Commands, Jobs, Explain, Coverage, Snippets, MinRisk, risk
+	// scores and confidence, unreached symbols) would miss the skipped symbols;
+	// the options among them still trace every symbol
+	BinariesOnly bool
+	// MaxFanOut bounds the callers at each of the first levels above a function,
+	// constant or variable. A symbol exceeding it, such as a logging helper, is
+	// reported for every binary importing its package instead of being traced,
+	// marked Approximate. 0 means no limit
+	MaxFanOut int
+	// Strategy is the tracing direction, reverse when empty. Forward resolves the
+	// functions, constants and variables it finds in the call graph itself, other
+	// symbols are still traced in reverse
+	Strategy Strategy
+	// Services are service boundary patterns such as "cmd/*" or "internal/*";
+	// paths entering another service through an interface call or function
+	// value are dropped, static calls into another service are kept
+	Services []string
+	// CommonPackages are module-relative prefixes of shared packages that belong to no service
+	CommonPackages []string
+	// DisableCrossServiceFilter turns off the Services/CommonPackages check, like
+	// InterfaceFilterOff. The tracer's built-in heuristic still applies.
+	DisableCrossServiceFilter bool
+	// InterfaceFilter selects how the Services/CommonPackages check treats calls
+	// it cannot type-check, "" is strict
+	InterfaceFilter InterfaceFilter
+	// Deployments maps a binary name or its main package directory
+	// (relative to the repository root, e.g. "cmd/api-server") to deployment identifiers
+	Deployments map[string]Deployment
+	// Owners maps a binary name or its main package directory to owning teams,
+	// taking precedence over CodeOwners
+	Owners map[string][]string
+	// Teams maps a team to the binaries it is responsible for: main package
+	// directory patterns or binary names. A binary belongs to the first team, in
+	// name order, matching it
+	Teams map[string][]string
+	// CodeOwners resolves owners of the binary's main file (relative to the repository root)
+	CodeOwners interface{ Owners(file string) []string }
+	// Bazel resolves the Bazel target building each binary into AffectedBinary.BazelTarget
+	Bazel bool
+	// BazelTargets maps a binary name or its main package directory to a Bazel
+	// label, taking precedence over the go_binary rule of the directory's BUILD file
+	BazelTargets map[string]string
+	// MinRisk drops binaries whose risk score is below it from Report.Affected.
+	// OnAffected is called before scores are known and is not filtered
+	MinRisk int
+	// Coverage is the coverage profile of the repository's tests. The coverage of
+	// each change's lines is reported and uncovered changes weigh more in the risk
+	// score; nil means unknown
+	Coverage CoverProfile
+	// Explain fills AffectedBinary.Evidence with the call sites along every
+	// reported path and the diff hunks of the changed symbol it ends at
+	Explain bool
+	// Snippets fills ChangeMetrics.Snippet with the changed lines of every
+	// changed symbol and a few lines of context
+	Snippets bool
+	// Granularity GranularityPackage also reports the packages on the call paths
+	// in Report.Packages, and keeps tracing after every binary is affected
+	Granularity Granularity
+	// ReverseDeps are the changed packages and the packages importing them,
+	// directly or indirectly. Reported packages are restricted to them; nil
+	// means no restriction
+	ReverseDeps []string
+	// Deadline is when the time budget runs out. Symbols not traced by then are
+	// skipped with SkipBudget and the report holds what was found so far. Traces
+	// running at the deadline are not interrupted, as gopls caches a cancelled
+	// trace as if it were complete, but their results are dropped the same way.
+	// Zero means no budget
+	Deadline time.Time
+	// Done, when closed, stops tracing symbols that have not started yet. The
+	// caller is expected to discard the report. Nil means tracing runs to the end
+	Done <-chan struct{}
+}
+
+// outOfBudget reports whether the time budget has run out
+func (o Options) outOfBudget() bool {
+	return !o.Deadline.IsZero() && !time.Now().Before(o.Deadline)
+}
+
+// stopped reports whether Done is closed
+func (o Options) stopped() bool {
+	select {
+	case <-o.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// pathLimit returns the number of paths to keep per binary, 0 means unlimited
+func (o Options) pathLimit() int {
+	if o.MaxPathsPerBinary > 0 {
+		return o.MaxPathsPerBinary
+	}
+	if o.AllPaths {
+		return 0
+	}
+	return 1
 }
 
 // NewLSPImpactAnalyzer creates a new LSP-based impact analyzer
@@ -28,46 +172,118 @@ func NewLSPImpactAnalyzer(ctx context.Context, rootPath string) (*LSPImpactAnaly
 	}, nil
 }
 
+// SetOptions updates the analysis options
+func (a *LSPImpactAnalyzer) SetOptions(opts Options) {
+	a.opts = opts
+}
+
+// SetPackages passes the packages loaded by the parser to the tracer, so trace
+// nodes carry their real import paths, and to the cross-service filter, which
+// reuses their type information
+func (a *LSPImpactAnalyzer) SetPackages(pkgs []*packages.Package) {
+	a.packages = pkgs
+	a.tracer.SetPackages(pkgs)
+}
+
 // Close closes the analyzer
 func (a *LSPImpactAnalyzer) Close() error {
 	return a.tracer.Close()
 }
 
+// RequestStats returns the count and latency of the requests made to gopls so
+// far, by method
+func (a *LSPImpactAnalyzer) RequestStats() []lsp.RequestStats {
+	return a.tracer.Stats()
+}
+
 // Analyze analyzes the impact of changed symbols
-func (a *LSPImpactAnalyzer) Analyze(changes []ChangedSymbol) ([]AffectedBinary, error) {
+func (a *LSPImpactAnalyzer) Analyze(changes []ChangedSymbol) (*Report, error) {
 	// Filter out unsupported symbols first
 	var supportedChanges []ChangedSymbol
 	for _, change := range changes {
-		if !isSupportedSymbolKind(change.Symbol.Kind) {
+		// Only blank imports run code by themselves, other imports affect nothing
+		// until their package is used, which is a change of its own
+		if extra, ok := change.Symbol.Extra.(parser.ImportExtra); ok && change.Symbol.Kind == parser.SymbolKindImport && !extra.IsBlankImport() {
+			continue
+		}
+		// Deleted symbols are reported without tracing, whatever their kind
+		if !isSupportedSymbolKind(change.Symbol.Kind) && change.ChangeType != ChangeTypeDelete {
 			if change.Symbol.Kind != parser.SymbolKindStruct &&
+				change.Symbol.Kind != parser.SymbolKindStructField &&
 				change.Symbol.Kind != parser.SymbolKindInterface &&
 				change.Symbol.Kind != parser.SymbolKindType {
-				fmt.Printf("Info: symbol kind %v not yet supported, skipping %s\n",
-					change.Symbol.Kind, change.Symbol.Name)
+				logger.Info("symbol kind not yet supported, skipping",
+					"kind", change.Symbol.Kind, "symbol", change.Symbol.Name)
 			}
 			continue
 		}
 		supportedChanges = append(supportedChanges, change)
 	}
 
+	filter := newPathFilter(a.rootPath, a.opts)
 	if len(supportedChanges) == 0 {
-		return nil, nil
+		return &Report{Metadata: ReportMetadata{InterfaceFilter: filter.mode(), Mode: ModeCalls}}, nil
 	}
 
 	// Concurrent processing
 	type traceResult struct {
-		paths []lsp.CallPath
-		err   error
+		index      int
+		change     ChangedSymbol
+		paths      []lsp.CallPath
+		initPaths  []lsp.CallPath   // Binaries running the symbol at package initialization
+		promoted   []lsp.CallPath   // Binaries using an outer type that gets the method through embedding
+		custom     []lsp.CallPath   // Custom entrypoints using the symbol
+		scheduled  []lsp.CallPath   // Binaries running a configured job that uses the symbol
+		values     []lsp.CallPath   // Binaries running the function after it flows through package-level variables
+		spawned    []lsp.CallPath   // Binaries running the function in go and defer statements gopls found no callers for
+		enrolled   []lsp.CallPath   // Binaries importing a package that registers the function at initialization
+		registered []lsp.CallPath   // Paths through route and subcommand registrations
+		compared   []lsp.Comparison // Functions comparing against the other constants of a changed enum
+		unreached  lsp.Reachability
+		confidence Confidence
+		skipped    string // Why the symbol was not traced, see ChangeMetrics.Skipped
+		err        error
+	}
+
+	strategy := a.strategy(supportedChanges)
+	var forward map[int][]lsp.CallPath // Paths found by the forward search, by change index
+	if strategy == StrategyForward {
+		search, err := loadForward(a.rootPath)
+		if err != nil {
+			logger.Warn("failed to build the call graph, tracing in reverse", "error", err)
+			strategy = StrategyReverse
+		} else {
+			forward = search.paths(supportedChanges)
+			filter.calls = newCallResolver(filter.root, slices.Concat(a.packages, search.pkgs))
+		}
 	}
 
 	results := make(chan traceResult, len(supportedChanges))
 	var wg sync.WaitGroup
+	targets, skipReason := a.stopSet()
+	slots := tracingSlots(targets)
 
 	// Process symbols concurrently
-	for _, change := range supportedChanges {
+	for i, change := range supportedChanges {
 		wg.Add(1)
-		go func(ch ChangedSymbol) {
+		go func(index int, ch ChangedSymbol) {
 			defer wg.Done()
+			if slots != nil {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+			}
+			if ch.ChangeType == ChangeTypeDelete {
+				results <- traceResult{index: index, change: ch, skipped: SkipDeleted}
+				return
+			}
+			if targets.resolved() {
+				results <- traceResult{index: index, change: ch, skipped: skipReason}
+				return
+			}
+			if a.opts.outOfBudget() || a.opts.stopped() {
+				results <- traceResult{index: index, change: ch, skipped: SkipBudget}
+				return
+			}
 
 			// Convert ChangedSymbol to parser.Symbol
 			symbol := &parser.Symbol{
@@ -78,10 +294,196 @@ func (a *LSPImpactAnalyzer) Analyze(changes []ChangedSymbol) ([]AffectedBinary,
 				Extra:       ch.Symbol.Extra,
 			}
 
+			// A symbol used nearly everywhere is degraded to the binaries importing its
+			// package, tracing it would walk most of the repository's call graph
+			fwPaths, forwarded := forward[index]
+			if a.opts.MaxFanOut > 0 && tracesReferences(symbol) && !forwarded {
+				wide, err := a.tracer.FanOut(symbol, a.opts.MaxFanOut)
+				if err == nil && wide {
+					logger.Info("symbol fans out too widely, reporting importing binaries",
+						"symbol", qualifiedSymbolName(ch), "max_fanout", a.opts.MaxFanOut)
+					paths, err := a.tracer.TraceImporters(symbol)
+					results <- traceResult{index: index, change: ch, paths: paths, confidence: ConfidenceLow, err: err}
+					return
+				}
+				if err != nil {
+					logger.Warn("failed to check fan-out",
+						"symbol", qualifiedSymbolName(ch), "error", err)
+				}
+			}
+
 			// Trace to main functions
-			paths, err := a.tracer.TraceToMain(symbol)
-			results <- traceResult{paths: paths, err: err}
-		}(change)
+			paths := fwPaths
+			var err error
+			if !forwarded {
+				paths, err = a.trace(symbol, ch)
+			}
+
+			// A function only passed around as a value, e.g. stored in a registry map,
+			// may have no callers for gopls. A change inside a closure the function
+			// returns runs where the result is called, so the result is followed too.
+			// gopls attributes a method value, e.g. s.Status in a handler table, to the
+			// function creating it, so the tables holding method values are followed
+			var values []lsp.CallPath
+			if err == nil && symbol.Kind == parser.SymbolKindFunction && !ch.Local {
+				var valueErr error
+				switch {
+				case len(paths) == 0 || changesClosure(symbol, ch.Lines):
+					values, valueErr = a.tracer.TraceFunctionValues(symbol)
+				default:
+					values, valueErr = a.tracer.TraceMethodValues(symbol)
+				}
+				if valueErr != nil {
+					logger.Warn("failed to trace function values",
+						"symbol", qualifiedSymbolName(ch), "error", valueErr)
+				}
+			}
+
+			// A call in a go or defer statement runs the function like a direct call.
+			// When gopls reports no callers at all, the functions containing such
+			// statements, found with type information, are traced instead
+			var spawned []lsp.CallPath
+			for _, caller := range ch.GoDefer {
+				if err != nil || len(paths)+len(values) > 0 {
+					break
+				}
+				callerPaths, callerErr := a.tracer.TraceToMain(caller)
+				if callerErr != nil {
+					logger.Warn("failed to trace go/defer caller",
+						"symbol", qualifiedSymbolName(ch), "caller", caller.Name, "error", callerErr)
+					continue
+				}
+				for _, path := range callerPaths {
+					spawned = append(spawned, extendPath(path, lsp.SymbolNode(symbol)))
+				}
+			}
+
+			// A variable initializer that calls functions runs when the package is
+			// initialized, so every binary importing the package is affected too
+			var initPaths []lsp.CallPath
+			if err == nil && hasInitCall(symbol) {
+				initPaths, err = a.tracer.TraceToMain(&parser.Symbol{
+					Name:        symbol.Name,
+					Kind:        parser.SymbolKindInit,
+					Position:    symbol.Position,
+					PackagePath: symbol.PackagePath,
+				})
+			}
+
+			// A function registered into a registry at package initialization, e.g.
+			// handlers["foo"] = handleFoo in init, may be looked up and run by any binary
+			// importing the registering package
+			var enrolled []lsp.CallPath
+			for _, reg := range ch.Registrations {
+				if err != nil {
+					break
+				}
+				regPaths, regErr := a.tracer.TraceToMain(&parser.Symbol{
+					Name:        "init",
+					Kind:        parser.SymbolKindInit,
+					PackagePath: reg.PackagePath,
+				})
+				if regErr != nil {
+					logger.Warn("failed to trace init registration",
+						"symbol", qualifiedSymbolName(ch), "registry", reg.Registry, "error", regErr)
+					continue
+				}
+				for _, path := range regPaths {
+					enrolled = append(enrolled, extendPath(path,
+						lsp.CallNode{FunctionName: "init", PackagePath: reg.PackagePath},
+						lsp.CallNode{FunctionName: reg.Registry, PackagePath: reg.RegistryPackage},
+						lsp.SymbolNode(symbol)))
+				}
+			}
+
+			// A method promoted to outer types may be called through an interface the
+			// outer type satisfies, which gopls does not report as a call of the method
+			var promoted []lsp.CallPath
+			for _, outer := range ch.Promoted {
+				if err != nil {
+					break
+				}
+				outerPaths, outerErr := a.tracer.TraceToMain(outer)
+				if outerErr != nil {
+					logger.Warn("failed to trace promoted method",
+						"symbol", qualifiedSymbolName(ch), "type", outer.Name, "error", outerErr)
+					continue
+				}
+				// End the path with the promoted form of the method, e.g. "Worker.Close"
+				for _, path := range outerPaths {
+					promoted = append(promoted, extendPath(path, lsp.CallNode{
+						FunctionName: symbol.Name,
+						PackagePath:  outer.PackagePath,
+						Receiver:     outer.Name,
+					}))
+				}
+			}
+
+			// A changed enum value may make values stored with the other constants of its
+			// type read back differently, so switches and comparisons on them are affected
+			var compared []lsp.Comparison
+			for _, constant := range ch.Family {
+				if err != nil {
+					break
+				}
+				comparisons, cmpErr := a.tracer.TraceComparisons(constant)
+				if cmpErr != nil {
+					logger.Warn("failed to trace comparisons",
+						"symbol", qualifiedSymbolName(ch), "constant", constant.Name, "error", cmpErr)
+					continue
+				}
+				compared = append(compared, comparisons...)
+			}
+
+			var custom []lsp.CallPath
+			if err == nil && len(a.opts.CustomEntrypoints) > 0 && tracesReferences(symbol) {
+				var customErr error
+				custom, customErr = a.tracer.TraceToEntrypoints(symbol, a.opts.CustomEntrypoints)
+				if customErr != nil {
+					logger.Warn("failed to trace custom entrypoints",
+						"symbol", qualifiedSymbolName(ch), "error", customErr)
+				}
+			}
+
+			var scheduled []lsp.CallPath
+			if err == nil && len(a.opts.Jobs) > 0 && tracesReferences(symbol) {
+				var jobErr error
+				scheduled, jobErr = a.tracer.TraceToJobs(symbol, a.opts.Jobs)
+				if jobErr != nil {
+					logger.Warn("failed to trace jobs",
+						"symbol", qualifiedSymbolName(ch), "error", jobErr)
+				}
+			}
+
+			var registered []lsp.CallPath
+			kinds := lsp.Registrations{Routes: a.opts.Routes, Commands: a.opts.Commands}
+			if err == nil && (kinds.Routes || kinds.Commands) && tracesReferences(symbol) {
+				var regErr error
+				registered, regErr = a.tracer.TraceRegistrations(symbol, kinds)
+				if regErr != nil {
+					logger.Warn("failed to trace registrations",
+						"symbol", qualifiedSymbolName(ch), "error", regErr)
+				}
+			}
+
+			// No paths may also mean another symbol's trace already claimed the binaries,
+			// so check whether anything that runs uses the symbol at all
+			var unreached lsp.Reachability
+			if err == nil && len(paths)+len(initPaths)+len(promoted)+len(custom)+len(scheduled)+len(registered)+len(values)+len(spawned)+len(enrolled)+len(compared) == 0 && mayBeUnreached(symbol, ch) {
+				var reachErr error
+				unreached, reachErr = a.tracer.Reachability(symbol)
+				if reachErr != nil {
+					logger.Warn("failed to check reachability",
+						"symbol", qualifiedSymbolName(ch), "error", reachErr)
+				}
+			}
+			if a.opts.outOfBudget() {
+				// The budget ran out while the symbol was traced
+				results <- traceResult{index: index, change: ch, skipped: SkipBudget}
+				return
+			}
+			results <- traceResult{index: index, change: ch, paths: paths, initPaths: initPaths, promoted: promoted, custom: custom, scheduled: scheduled, values: values, spawned: spawned, enrolled: enrolled, registered: registered, compared: compared, unreached: unreached, confidence: symbolConfidence(symbol), err: err}
+		}(i, change)
 	}
 
 	// Close results channel when all goroutines complete
@@ -91,54 +493,217 @@ func (a *LSPImpactAnalyzer) Analyze(changes []ChangedSymbol) ([]AffectedBinary,
 	}()
 
 	// Collect results
-	var affectedBinaries []AffectedBinary
-	seenBinaries := make(map[string]bool)
+	collector := newBinaryCollector(a.opts.pathLimit())
+	metrics := newMetricsBuilder(a.rootPath)
+	pkgs := a.opts.packageCollector()
+	risk := newRiskScorer()
+	if forward == nil {
+		filter.calls = newCallResolver(filter.root, a.packages)
+	}
+	var unknown []UnknownImpact
+	var explain *explainer
+	if a.opts.Explain {
+		explain = newExplainer(a.rootPath, a.packages)
+	}
+	var snippets *snippetReader
+	if a.opts.Snippets {
+		snippets = newSnippetReader()
+	}
 
 	for res := range results {
+		if res.skipped != "" {
+			metrics.skip(res.index, res.change, res.skipped)
+			if snippets != nil {
+				metrics.snippet(res.index, snippets.read(res.change))
+			}
+			continue
+		}
 		if res.err != nil {
-			fmt.Printf("Warning: failed to trace symbol: %v\n", res.err)
+			logger.Warn("failed to trace symbol",
+				"symbol", qualifiedSymbolName(res.change), "error", res.err)
+			unknown = append(unknown, metrics.unknown(res.change, res.err))
 			continue
 		}
 
+		res.paths = filter.filter(res.paths)
+		res.initPaths = filter.filter(res.initPaths)
+		res.promoted = filter.filter(res.promoted)
+		res.custom = filter.filter(res.custom)
+		res.scheduled = filter.filter(res.scheduled)
+		res.values = filter.filter(res.values)
+		res.spawned = filter.filter(res.spawned)
+		res.enrolled = filter.filter(res.enrolled)
+		res.registered = filter.filter(res.registered)
+		var compared []lsp.CallPath
+		for _, cmp := range res.compared {
+			compared = append(compared, cmp.Paths...)
+		}
+		compared = filter.filter(compared)
+		all := slices.Concat(res.paths, res.initPaths, res.promoted, res.custom, res.values, res.spawned, res.registered, res.scheduled, compared, res.enrolled)
+		metrics.add(res.index, res.change, all, res.unreached)
+		metrics.compared(res.index, res.compared)
+		cov := a.opts.Coverage.coverage(res.change)
+		metrics.covered(res.index, cov)
+		if snippets != nil {
+			metrics.snippet(res.index, snippets.read(res.change))
+		}
+		risk.add(res.index, res.change, all, cov)
+		pkgs.add(all)
+		record := func(path lsp.CallPath, confidence Confidence) {
+			targets.reached(path.BinaryName)
+			if explain != nil {
+				explain.record(path, res.change)
+			}
+			if !collector.add(path, confidence) {
+				return
+			}
+			binary := collector.byName[path.BinaryName]
+			binary.Dir = filter.mainDir(path.MainURI)
+			binary.Deployment = filter.deployment(path, a.opts.Deployments)
+			if a.opts.Bazel {
+				binary.BazelTarget = filter.bazelTarget(path, a.opts.BazelTargets)
+			}
+			binary.Owners = filter.owners(path, a.opts.Owners, a.opts.CodeOwners)
+			binary.Team = filter.team(path, a.opts.Teams)
+			if a.opts.OnAffected != nil {
+				a.opts.OnAffected(collector.first(path.BinaryName))
+			}
+		}
 		for _, path := range res.paths {
-			if seenBinaries[path.BinaryName] {
+			record(path, filter.calls.pathConfidence(path, res.confidence))
+		}
+		for _, path := range res.initPaths {
+			record(path, ConfidenceLow)
+		}
+		for _, path := range res.promoted {
+			record(path, ConfidenceMedium)
+		}
+		for _, path := range res.custom {
+			record(path, filter.calls.pathConfidence(path, res.confidence))
+		}
+		for _, path := range compared {
+			// The function compares against a constant whose meaning may have shifted
+			record(path, ConfidenceMedium)
+		}
+		for _, path := range res.spawned {
+			record(path, filter.calls.pathConfidence(path, res.confidence))
+		}
+		for _, path := range res.values {
+			// The function is called through a variable holding it
+			record(path, ConfidenceMedium)
+		}
+		for _, path := range res.registered {
+			if path.Route == nil {
+				record(path, res.confidence)
+				collector.addCommand(path.BinaryName, path.Command)
 				continue
 			}
-			seenBinaries[path.BinaryName] = true
+			// gRPC servers call the registered type through the generated service interface
+			confidence := res.confidence
+			if path.Route.Method == lsp.GRPCMethod && confidence.rank() > ConfidenceMedium.rank() {
+				confidence = ConfidenceMedium
+			}
+			record(path, confidence)
+			collector.addRoute(path.BinaryName, Route(*path.Route))
+		}
+		for _, path := range res.scheduled {
+			record(path, res.confidence)
+			collector.addJob(path.BinaryName, path.Job)
+		}
+		for _, path := range res.enrolled {
+			// The binary may never look the function up in the registry
+			record(path, ConfidenceLow)
+		}
+	}
 
-			// Format path strings
-			var pathStrs []string
-			for i, node := range path.Path {
-				var formatted string
-				if node.PackagePath != "" {
-					formatted = fmt.Sprintf("%s.%s", node.PackagePath, node.FunctionName)
-				} else {
-					formatted = node.FunctionName
-				}
+	affected := risk.apply(collector.binaries(), a.opts.MinRisk)
+	if explain != nil {
+		explain.explain(affected)
+	}
+	return &Report{
+		Affected:    affected,
+		Changes:     metrics.sortedChanges(),
+		BlastRadius: metrics.blastRadius(),
+		Metadata:    ReportMetadata{InterfaceFilter: filter.mode(), Mode: ModeCalls, Strategy: strategy},
+		Unknown:     sortedUnknown(unknown),
+		Packages:    pkgs.packages(),
+	}, nil
+}
 
-				if i == 0 {
-					pathStrs = append(pathStrs, fmt.Sprintf("%s (main)", formatted))
-				} else if i == len(path.Path)-1 {
-					pathStrs = append(pathStrs, fmt.Sprintf("%s (Changed)", formatted))
-				} else {
-					pathStrs = append(pathStrs, formatted)
-				}
-			}
+// hasInitCall reports whether symbol is a package-level variable whose
+// initializer calls a function
+func hasInitCall(symbol *parser.Symbol) bool {
+	extra, ok := symbol.Extra.(parser.VariableExtra)
+	return ok && symbol.Kind == parser.SymbolKindVariable && extra.InitCall
+}
 
-			affectedBinaries = append(affectedBinaries, AffectedBinary{
-				Name:      path.BinaryName,
-				PkgPath:   extractPkgPath(path.MainURI),
-				TracePath: pathStrs,
-			})
+// changesClosure reports whether any changed line of a function is inside a
+// function literal it returns
+func changesClosure(symbol *parser.Symbol, lines []int) bool {
+	extra, ok := symbol.Extra.(parser.FunctionExtra)
+	if !ok {
+		return false
+	}
+	for _, closure := range extra.Closures {
+		if slices.ContainsFunc(lines, closure.Contains) {
+			return true
 		}
 	}
+	return false
+}
+
+// tracesReferences reports whether a symbol is traced through its references,
+// which custom entrypoints can be found by
+func tracesReferences(symbol *parser.Symbol) bool {
+	switch symbol.Kind {
+	case parser.SymbolKindFunction, parser.SymbolKindConstant, parser.SymbolKindVariable:
+		return true
+	}
+	return false
+}
 
-	return affectedBinaries, nil
+// mayBeUnreached reports whether a symbol that no trace reached can be checked for
+// dead code. Init functions and blank imports have no callers by design, and methods
+// may be called through interfaces or promoted to outer types
+func mayBeUnreached(symbol *parser.Symbol, ch ChangedSymbol) bool {
+	return tracesReferences(symbol) && symbolConfidence(symbol) == ConfidenceHigh && len(ch.Promoted) == 0
+}
+
+// trace traces a changed symbol to main functions. An unexported symbol already
+// analyzed within its package only needs its package's exit points traced;
+// the in-package part of each path comes from the local analysis
+func (a *LSPImpactAnalyzer) trace(symbol *parser.Symbol, ch ChangedSymbol) ([]lsp.CallPath, error) {
+	if !ch.Local {
+		return a.tracer.TraceToMain(symbol)
+	}
+
+	var paths []lsp.CallPath
+	for _, exit := range ch.Exits {
+		exitPaths, err := a.tracer.TraceToMain(exit.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		local := []lsp.CallNode{lsp.SymbolNode(exit.Symbol)}
+		for _, s := range exit.Via {
+			local = append(local, lsp.SymbolNode(s))
+		}
+		local = append(local, lsp.SymbolNode(symbol))
+		// The exit itself ends the paths traced from it
+		local = a.tracer.NoteLazyInit(local)[1:]
+		for _, path := range exitPaths {
+			paths = append(paths, extendPath(path, local...))
+		}
+	}
+	return paths, nil
 }
 
-// extractPkgPath extracts package path from URI
-func extractPkgPath(uri string) string {
-	return uri // TODO: implement proper extraction
+// extendPath returns a copy of path with nodes appended after its last node.
+// Paths returned by the tracer are cached, so they must not be modified in place
+func extendPath(path lsp.CallPath, nodes ...lsp.CallNode) lsp.CallPath {
+	extended := make([]lsp.CallNode, len(path.Path), len(path.Path)+len(nodes))
+	copy(extended, path.Path)
+	path.Path = append(extended, nodes...)
+	return path
 }
 
 // isSupportedSymbolKind checks if a symbol kind is supported for tracing
@@ -0,0 +1,280 @@
+package analyzer
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// MethodChange 被删除、签名发生变化或新增的方法。前两种情况下接收者类型可能不再满足
+// 之前实现的接口;新增的方法用于判断类型是否随接口一起补上了接口新增的方法
+type MethodChange struct {
+	Dir          string // 接收者所在包的目录(相对仓库根目录)
+	Receiver     string // 接收者类型名(不含 *)
+	Name         string // 方法名
+	OldSignature string // 旧签名,如 "(*T) (context.Context, string) (error)",新增的方法为空
+	NewSignature string // 新签名,方法被删除时为空
+}
+
+// Removed 方法是否被删除
+func (m MethodChange) Removed() bool {
+	return m.NewSignature == ""
+}
+
+// Added 方法是否是新增的
+func (m MethodChange) Added() bool {
+	return m.OldSignature == ""
+}
+
+// InterfaceChange 方法集变大的接口: 新版本中直接声明了新的方法或嵌入了新的接口。
+// 实现该接口(或嵌入了它的接口)的类型没有同时补上这些方法时不再满足接口
+type InterfaceChange struct {
+	Dir     string   // 接口所在包的目录(相对仓库根目录)
+	Name    string   // 接口名
+	Methods []string // 新声明的方法
+	Embeds  []string // 新嵌入的接口,如 "Closer" 或 "io.Closer"
+}
+
+// receiverOnly 签名只有接收者是否为指针发生了变化,返回新接收者是否为指针
+func (m MethodChange) receiverOnly() (pointer, ok bool) {
+	_, oldRest, _ := strings.Cut(m.OldSignature, " ")
+	newRecv, newRest, _ := strings.Cut(m.NewSignature, " ")
+	if m.Removed() || oldRest != newRest {
+		return false, false
+	}
+	return strings.HasPrefix(newRecv, "(*"), true
+}
+
+// DetectMethodChanges 比较两个 commit 中每个变更目录的方法和接口声明,返回被删除、签名变化或
+// 新增的方法,以及新声明了方法或嵌入了新接口的接口(新增的接口没有旧的实现,不会返回)。
+// 按目录比较,方法移动到同一个包的其他文件不算变化;只比较语法,不解析类型,嵌入的接口带来的
+// 方法在 FindInterfaceBreakage 中按旧代码的类型信息展开
+func (cd *ChangeDetector) DetectMethodChanges(oldCommit, newCommit string) ([]MethodChange, []InterfaceChange, error) {
+	files, err := git.ChangedGoFiles(cd.projectPath, oldCommit, newCommit)
+	if err != nil {
+		return nil, nil, i18n.Errorf("获取变更文件失败: %w", err)
+	}
+
+	oldDecls, newDecls := newVersionDecls(), newVersionDecls()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		dir := path.Dir(file)
+		// 文件在某个 commit 中不存在时内容为空,不贡献声明
+		oldSrc, _ := git.ShowFile(cd.projectPath, oldCommit, file)
+		newSrc, _ := git.ShowFile(cd.projectPath, newCommit, file)
+		oldDecls.add(dir, oldSrc)
+		newDecls.add(dir, newSrc)
+	}
+	return methodChanges(oldDecls.methods, newDecls.methods), interfaceChanges(oldDecls.ifaces, newDecls.ifaces), nil
+}
+
+// versionDecls 一个版本中各目录声明的方法和接口
+type versionDecls struct {
+	methods map[string]map[string]string        // 目录 -> 接收者.方法 -> 签名
+	ifaces  map[string]map[string]interfaceDecl // 目录 -> 接口名 -> 声明
+}
+
+func newVersionDecls() *versionDecls {
+	return &versionDecls{
+		methods: make(map[string]map[string]string),
+		ifaces:  make(map[string]map[string]interfaceDecl),
+	}
+}
+
+// add 将源码中声明的方法和接口加入目录 dir
+func (d *versionDecls) add(dir string, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	file, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.SkipObjectResolution)
+	if err != nil {
+		return
+	}
+	collectMethods(d.methods, dir, file)
+	collectInterfaces(d.ifaces, dir, file)
+}
+
+// methodChanges 返回被删除、签名变化或新增的方法
+func methodChanges(oldMethods, newMethods map[string]map[string]string) []MethodChange {
+	var res []MethodChange
+	for dir, methods := range oldMethods {
+		for key, oldSig := range methods {
+			newSig := newMethods[dir][key]
+			if newSig == oldSig {
+				continue
+			}
+			recv, name, _ := strings.Cut(key, ".")
+			res = append(res, MethodChange{
+				Dir:          dir,
+				Receiver:     recv,
+				Name:         name,
+				OldSignature: oldSig,
+				NewSignature: newSig,
+			})
+		}
+	}
+	for dir, methods := range newMethods {
+		for key, newSig := range methods {
+			if _, ok := oldMethods[dir][key]; ok {
+				continue
+			}
+			recv, name, _ := strings.Cut(key, ".")
+			res = append(res, MethodChange{Dir: dir, Receiver: recv, Name: name, NewSignature: newSig})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if a.Dir != b.Dir {
+			return a.Dir < b.Dir
+		}
+		if a.Receiver != b.Receiver {
+			return a.Receiver < b.Receiver
+		}
+		return a.Name < b.Name
+	})
+	return res
+}
+
+// interfaceChanges 返回两个版本中都有、新版本中新声明了方法或嵌入了新接口的接口
+func interfaceChanges(oldIfaces, newIfaces map[string]map[string]interfaceDecl) []InterfaceChange {
+	var res []InterfaceChange
+	for dir, ifaces := range newIfaces {
+		for name, decl := range ifaces {
+			old, ok := oldIfaces[dir][name]
+			if !ok {
+				continue
+			}
+			c := InterfaceChange{
+				Dir:     dir,
+				Name:    name,
+				Methods: missingFrom(decl.methods, old.methods),
+				Embeds:  missingFrom(decl.embeds, old.embeds),
+			}
+			if len(c.Methods) > 0 || len(c.Embeds) > 0 {
+				res = append(res, c)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Dir != res[j].Dir {
+			return res[i].Dir < res[j].Dir
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// interfaceDecl 接口直接声明的方法和嵌入的接口
+type interfaceDecl struct {
+	methods []string
+	embeds  []string
+}
+
+// collectInterfaces 将文件中声明的接口加入 ifaces[dir]。类型约束中的联合类型(~int | string)不算嵌入
+func collectInterfaces(ifaces map[string]map[string]interfaceDecl, dir string, file *ast.File) {
+	for _, d := range file.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gen.Specs {
+			spec := s.(*ast.TypeSpec)
+			iface, ok := spec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			var decl interfaceDecl
+			for _, field := range iface.Methods.List {
+				for _, name := range field.Names {
+					decl.methods = append(decl.methods, name.Name)
+				}
+				switch field.Type.(type) {
+				case *ast.Ident, *ast.SelectorExpr:
+					if len(field.Names) == 0 {
+						decl.embeds = append(decl.embeds, types.ExprString(field.Type))
+					}
+				}
+			}
+			if ifaces[dir] == nil {
+				ifaces[dir] = make(map[string]interfaceDecl)
+			}
+			ifaces[dir][spec.Name.Name] = decl
+		}
+	}
+}
+
+// missingFrom 返回 names 中不在 old 里的名字
+func missingFrom(names, old []string) []string {
+	var res []string
+	for _, name := range names {
+		if !slices.Contains(old, name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// collectMethods 将文件中声明的方法及其签名加入 methods[dir]
+func collectMethods(methods map[string]map[string]string, dir string, file *ast.File) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+		recv, pointer := receiverTypeName(fn.Recv.List[0].Type)
+		if recv == "" {
+			continue
+		}
+		if methods[dir] == nil {
+			methods[dir] = make(map[string]string)
+		}
+		sig := "(" + recv + ")"
+		if pointer {
+			sig = "(*" + recv + ")"
+		}
+		methods[dir][recv+"."+fn.Name.Name] = sig + " " + fieldTypes(fn.Type.Params) + " " + fieldTypes(fn.Type.Results)
+	}
+}
+
+// receiverTypeName 返回接收者的类型名和是否是指针接收者
+func receiverTypeName(expr ast.Expr) (string, bool) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, pointer = star.X, true
+	}
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr = t.X
+	case *ast.IndexListExpr:
+		expr = t.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name, pointer
+	}
+	return "", false
+}
+
+// fieldTypes 返回参数或返回值的类型列表,忽略参数名
+func fieldTypes(fields *ast.FieldList) string {
+	if fields == nil {
+		return "()"
+	}
+	var list []string
+	for _, field := range fields.List {
+		typ := types.ExprString(field.Type)
+		for range max(len(field.Names), 1) {
+			list = append(list, typ)
+		}
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
@@ -0,0 +1,318 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// ChangeMetrics describes the blast radius of a single changed symbol
+type ChangeMetrics struct {
+	Symbol           string `json:"symbol"`                 // Qualified symbol name (package.Name)
+	Kind             string `json:"kind"`                   // Symbol kind
+	ChangeType       string `json:"change_type,omitempty"`  // ADD, MODIFY or DELETE
+	Modification     string `json:"modification,omitempty"` // signature, value or body for MODIFY
+	AffectedBinaries int    `json:"affected_binaries"`      // Number of binaries reached
+	AffectedPackages int    `json:"affected_packages"`      // Number of distinct packages on the paths
+	CallSites        int    `json:"call_sites"`             // Number of distinct call edges on the paths
+	ShortestPath     int    `json:"shortest_path"`          // Fewest call edges from main to the symbol (0 if unreached)
+
+	File      string   `json:"file,omitempty"`       // File containing the symbol, relative to the repository root
+	StartLine int      `json:"start_line,omitempty"` // First changed line (symbol line if unknown)
+	EndLine   int      `json:"end_line,omitempty"`   // Last changed line
+	Binaries  []string `json:"binaries,omitempty"`   // Names of the binaries reached, sorted
+
+	OldValue string `json:"old_value,omitempty"` // Constant value before the change (source expression)
+	NewValue string `json:"new_value,omitempty"` // Constant value after the change (source expression)
+
+	// Coverage counts the statements on the changed lines and how many of them
+	// the tests of the coverage profile ran, nil without a profile or statements
+	Coverage *Coverage `json:"coverage,omitempty"`
+
+	// ComparedIn lists the functions comparing against the other constants of a
+	// changed enum-like constant's type, in switch cases or with comparison
+	// operators, as "package.Function file:line". A changed value may make stored
+	// values read back as another constant there, so they are traced too
+	ComparedIn []string `json:"compared_in,omitempty"`
+
+	// Unreached explains why no binary runs the symbol: "no_references", "tests_only"
+	// or "dead_callers" (only referenced by functions that are themselves unreached).
+	// Such a change has no runtime impact and may be dead code
+	Unreached string `json:"unreached,omitempty"`
+
+	// Skipped explains why the symbol was not traced: "targets_resolved" when
+	// every target binary had already been reached, "saturated" when every
+	// binary in the repository had, "budget_exceeded" when the time budget ran
+	// out. Its other metrics are zero
+	Skipped string `json:"skipped,omitempty"`
+
+	// Approximate means the symbol's callers fanned out beyond the configured
+	// limit, so its binaries come from the package import graph instead of calls.
+	// Such binaries only count towards AffectedBinaries
+	Approximate bool `json:"approximate,omitempty"`
+
+	// Snippet is the source around the changed lines, only filled with
+	// Options.Snippets
+	Snippet *Snippet `json:"snippet,omitempty"`
+
+	// Blame lists the commits that last changed the symbol's changed lines in
+	// the new commit, most lines first. Only filled when blame is requested
+	Blame []Commit `json:"blame,omitempty"`
+}
+
+// BlastRadius aggregates metrics over all changed symbols
+type BlastRadius struct {
+	ChangedSymbols   int `json:"changed_symbols"`
+	AffectedBinaries int `json:"affected_binaries"`
+	AffectedPackages int `json:"affected_packages"`
+	CallSites        int `json:"call_sites"`
+	ShortestPath     int `json:"shortest_path"`
+}
+
+// metricsBuilder accumulates per-change and overall blast radius metrics
+type metricsBuilder struct {
+	root     string // Repository root used to relativize file names
+	changes  []ChangeMetrics
+	order    []int // Input index of each entry in changes
+	binaries map[string]bool
+	packages map[string]bool
+	edges    map[string]bool
+	shortest int
+}
+
+func newMetricsBuilder(root string) *metricsBuilder {
+	return &metricsBuilder{
+		root:     root,
+		binaries: make(map[string]bool),
+		packages: make(map[string]bool),
+		edges:    make(map[string]bool),
+	}
+}
+
+// add records the paths traced for one changed symbol and, when it has none,
+// why no binary runs it.
+// Note: the gopls tracer skips binaries already found by earlier traces in the
+// same run, so per-change binary counts are a lower bound when symbols overlap.
+func (b *metricsBuilder) add(index int, change ChangedSymbol, paths []lsp.CallPath, unreached lsp.Reachability) {
+	binaries := make(map[string]bool)
+	packages := make(map[string]bool)
+	edges := make(map[string]bool)
+	shortest := 0
+	approximate := false
+	exact := 0 // Paths through calls
+
+	for _, path := range paths {
+		binaries[path.BinaryName] = true
+		b.binaries[path.BinaryName] = true
+		if path.Approximate {
+			approximate = true
+			continue
+		}
+		exact++
+
+		for i, node := range path.Path {
+			if node.PackagePath != "" {
+				packages[node.PackagePath] = true
+				b.packages[node.PackagePath] = true
+			}
+			if i > 0 {
+				prev := path.Path[i-1]
+				edge := prev.QualifiedName() + "->" + node.QualifiedName()
+				edges[edge] = true
+				b.edges[edge] = true
+			}
+		}
+
+		length := len(path.Path) - 1
+		if length < 0 {
+			length = 0
+		}
+		if shortest == 0 || length < shortest {
+			shortest = length
+		}
+	}
+
+	if exact > 0 && (b.shortest == 0 || shortest < b.shortest) {
+		b.shortest = shortest
+	}
+
+	names := make([]string, 0, len(binaries))
+	for name := range binaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	startLine, endLine := changedLineRange(change)
+
+	b.order = append(b.order, index)
+	b.changes = append(b.changes, ChangeMetrics{
+		Symbol:           qualifiedSymbolName(change),
+		Kind:             string(change.Symbol.Kind),
+		ChangeType:       string(change.ChangeType),
+		Modification:     string(change.Modification),
+		AffectedBinaries: len(binaries),
+		AffectedPackages: len(packages),
+		CallSites:        len(edges),
+		ShortestPath:     shortest,
+		File:             b.relativePath(change.Symbol.Position.Filename),
+		StartLine:        startLine,
+		EndLine:          endLine,
+		Binaries:         names,
+		OldValue:         change.OldValue,
+		NewValue:         change.NewValue,
+		Unreached:        string(unreached),
+		Approximate:      approximate,
+	})
+}
+
+// compared records the functions comparing against the other constants of the
+// type of the changed constant at index
+func (b *metricsBuilder) compared(index int, comparisons []lsp.Comparison) {
+	if len(comparisons) == 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	var sites []string
+	for _, cmp := range comparisons {
+		site := fmt.Sprintf("%s %s:%d", cmp.Function.QualifiedName(), b.relativePath(cmp.File), cmp.Line)
+		if !seen[site] {
+			seen[site] = true
+			sites = append(sites, site)
+		}
+	}
+	sort.Strings(sites)
+	for i := len(b.changes) - 1; i >= 0; i-- {
+		if b.order[i] == index {
+			b.changes[i].ComparedIn = sites
+			return
+		}
+	}
+}
+
+// covered records the coverage of the changed symbol at index
+func (b *metricsBuilder) covered(index int, cov *Coverage) {
+	if cov == nil {
+		return
+	}
+	for i := len(b.changes) - 1; i >= 0; i-- {
+		if b.order[i] == index {
+			b.changes[i].Coverage = cov
+			return
+		}
+	}
+}
+
+// snippet records the source snippet of the change at index
+func (b *metricsBuilder) snippet(index int, s *Snippet) {
+	if s == nil {
+		return
+	}
+	for i := len(b.changes) - 1; i >= 0; i-- {
+		if b.order[i] == index {
+			b.changes[i].Snippet = s
+			return
+		}
+	}
+}
+
+// skip records a changed symbol that was not traced
+func (b *metricsBuilder) skip(index int, change ChangedSymbol, reason string) {
+	startLine, endLine := changedLineRange(change)
+	b.order = append(b.order, index)
+	b.changes = append(b.changes, ChangeMetrics{
+		Symbol:       qualifiedSymbolName(change),
+		Kind:         string(change.Symbol.Kind),
+		ChangeType:   string(change.ChangeType),
+		Modification: string(change.Modification),
+		File:         b.relativePath(change.Symbol.Position.Filename),
+		StartLine:    startLine,
+		EndLine:      endLine,
+		Skipped:      reason,
+	})
+}
+
+// unknown describes a change whose trace failed
+func (b *metricsBuilder) unknown(change ChangedSymbol, err error) UnknownImpact {
+	return UnknownImpact{
+		Symbol: qualifiedSymbolName(change),
+		Kind:   string(change.Symbol.Kind),
+		File:   b.relativePath(change.Symbol.Position.Filename),
+		Line:   change.Symbol.Position.Line,
+		Error:  err.Error(),
+	}
+}
+
+// relativePath returns filename relative to the repository root when possible
+func (b *metricsBuilder) relativePath(filename string) string {
+	if filename == "" || b.root == "" || !filepath.IsAbs(filename) {
+		return filepath.ToSlash(filename)
+	}
+	root, err := filepath.Abs(b.root)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	rel, err := filepath.Rel(root, filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// changedLineRange returns the first and last changed line of a symbol,
+// falling back to the symbol's own line when no diff lines are known
+func changedLineRange(change ChangedSymbol) (int, int) {
+	if len(change.Lines) == 0 {
+		line := change.Symbol.Position.Line
+		return line, line
+	}
+	start, end := change.Lines[0], change.Lines[0]
+	for _, line := range change.Lines[1:] {
+		start = min(start, line)
+		end = max(end, line)
+	}
+	return start, end
+}
+
+// sortedChanges returns per-change metrics in input order
+func (b *metricsBuilder) sortedChanges() []ChangeMetrics {
+	idx := make([]int, len(b.changes))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return b.order[idx[i]] < b.order[idx[j]] })
+
+	res := make([]ChangeMetrics, 0, len(b.changes))
+	for _, i := range idx {
+		res = append(res, b.changes[i])
+	}
+	return res
+}
+
+// blastRadius returns the overall metrics
+func (b *metricsBuilder) blastRadius() BlastRadius {
+	return BlastRadius{
+		ChangedSymbols:   len(b.changes),
+		AffectedBinaries: len(b.binaries),
+		AffectedPackages: len(b.packages),
+		CallSites:        len(b.edges),
+		ShortestPath:     b.shortest,
+	}
+}
+
+// qualifiedSymbolName returns "package.Name" for a changed symbol, with methods
+// named like path nodes, e.g. "package.(*Handler).ProcessRequest"
+func qualifiedSymbolName(change ChangedSymbol) string {
+	if change.Symbol.PackagePath == "" {
+		return change.Symbol.Name
+	}
+	if change.Symbol.Kind == parser.SymbolKindPackage {
+		return change.Symbol.PackagePath
+	}
+	if recv := receiverOf(change.Symbol); change.Symbol.Kind == parser.SymbolKindStructField && recv != "" {
+		return fmt.Sprintf("%s.%s.%s", change.Symbol.PackagePath, recv, change.Symbol.Name)
+	}
+	return lsp.SymbolNode(change.Symbol).QualifiedName()
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"errors"
+	"go/token"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestMetricsBuilder(t *testing.T) {
+	b := newMetricsBuilder("/repo")
+
+	change := ChangedSymbol{Symbol: &parser.Symbol{
+		Name:        "Changed",
+		Kind:        parser.SymbolKindFunction,
+		PackagePath: "example.com/p",
+		Position:    token.Position{Filename: "/repo/p/changed.go", Line: 10},
+	}, Lines: []int{14, 12}}
+	b.add(1, change, []lsp.CallPath{
+		makePath("server", "main", "A", "Changed"),
+		makePath("worker", "main", "Changed"),
+	}, lsp.Reachable)
+
+	other := ChangedSymbol{Symbol: &parser.Symbol{
+		Name: "Unused",
+		Kind: parser.SymbolKindConstant,
+	}}
+	b.add(0, other, nil, lsp.TestsOnly)
+
+	changes := b.sortedChanges()
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 change metrics, got %d", len(changes))
+	}
+
+	if changes[0].Symbol != "Unused" {
+		t.Errorf("Expected changes in input order, got %s first", changes[0].Symbol)
+	}
+
+	got := changes[1]
+	if got.Symbol != "example.com/p.Changed" {
+		t.Errorf("Expected qualified symbol name, got %s", got.Symbol)
+	}
+	if got.AffectedBinaries != 2 {
+		t.Errorf("Expected 2 affected binaries, got %d", got.AffectedBinaries)
+	}
+	if got.AffectedPackages != 1 {
+		t.Errorf("Expected 1 affected package, got %d", got.AffectedPackages)
+	}
+	// main->A, A->Changed, main->Changed
+	if got.CallSites != 3 {
+		t.Errorf("Expected 3 call sites, got %d", got.CallSites)
+	}
+	if got.ShortestPath != 1 {
+		t.Errorf("Expected shortest path 1, got %d", got.ShortestPath)
+	}
+
+	if got.File != "p/changed.go" || got.StartLine != 12 || got.EndLine != 14 {
+		t.Errorf("Unexpected location %s:%d-%d", got.File, got.StartLine, got.EndLine)
+	}
+	if len(got.Binaries) != 2 || got.Binaries[0] != "server" || got.Binaries[1] != "worker" {
+		t.Errorf("Expected sorted binaries [server worker], got %v", got.Binaries)
+	}
+
+	if changes[0].AffectedBinaries != 0 || changes[0].ShortestPath != 0 {
+		t.Errorf("Expected empty metrics for unreached symbol, got %+v", changes[0])
+	}
+	if changes[0].Unreached != "tests_only" || got.Unreached != "" {
+		t.Errorf("Expected only the unused constant to be unreached, got %q and %q", changes[0].Unreached, got.Unreached)
+	}
+
+	br := b.blastRadius()
+	if br.ChangedSymbols != 2 || br.AffectedBinaries != 2 || br.CallSites != 3 || br.ShortestPath != 1 {
+		t.Errorf("Unexpected blast radius: %+v", br)
+	}
+}
+
+func TestMetricsBuilderApproximate(t *testing.T) {
+	b := newMetricsBuilder("/repo")
+	change := ChangedSymbol{Symbol: &parser.Symbol{Name: "Log", Kind: parser.SymbolKindFunction, PackagePath: "example.com/p"}}
+	path := makePath("server", "main", "Log")
+	path.Approximate = true
+	b.add(0, change, []lsp.CallPath{path}, lsp.Reachable)
+
+	got := b.sortedChanges()[0]
+	if !got.Approximate || got.AffectedBinaries != 1 || got.CallSites != 0 || got.ShortestPath != 0 {
+		t.Errorf("Expected only the binary to be counted for an approximate path, got %+v", got)
+	}
+}
+
+func TestUnknownImpact(t *testing.T) {
+	b := newMetricsBuilder("/repo")
+	change := func(name string, line int) ChangedSymbol {
+		return ChangedSymbol{Symbol: &parser.Symbol{
+			Name:        name,
+			Kind:        parser.SymbolKindFunction,
+			PackagePath: "example.com/p",
+			Position:    token.Position{Filename: "/repo/p/p.go", Line: line},
+		}}
+	}
+	unknown := sortedUnknown([]UnknownImpact{
+		b.unknown(change("Later", 20), errors.New("gopls: context deadline exceeded")),
+		b.unknown(change("Earlier", 10), errors.New("no package for file")),
+	})
+	want := UnknownImpact{Symbol: "example.com/p.Earlier", Kind: "Function", File: "p/p.go", Line: 10, Error: "no package for file"}
+	if len(unknown) != 2 || unknown[0] != want {
+		t.Errorf("unknown = %+v, want %+v first", unknown, want)
+	}
+
+	if (&Report{}).Incomplete() {
+		t.Error("Expected an empty report to be complete")
+	}
+	if !(&Report{Unknown: unknown}).Incomplete() || !(&Report{FailedPackages: []FailedPackage{{Package: "example.com/x"}}}).Incomplete() {
+		t.Error("Expected unknown changes and failed packages to make the report incomplete")
+	}
+}
@@ -0,0 +1,19 @@
+package analyzer
+
+import (
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// fillPackageExits 为未导出的变更符号做包内可达性分析。成功时只需追踪包的出口,
+// 不必让 gopls 逐层查找包内的调用方
+func (cd *ChangeDetector) fillPackageExits(changes []ChangedSymbol) {
+	for i := range changes {
+		exits, ok := cd.parser.PackageExits(changes[i].Symbol)
+		if !ok {
+			continue
+		}
+		changes[i].Local = true
+		changes[i].Exits = exits
+		logger.Debug("包内可达性分析", "symbol", changes[i].Symbol.Name, "exits", len(exits))
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+// Granularity selects what a report lists besides the affected binaries
+type Granularity string
+
+const (
+	// GranularityBinary reports affected binaries only
+	GranularityBinary Granularity = "binary"
+	// GranularityPackage also reports the affected packages in Report.Packages
+	GranularityPackage Granularity = "package"
+)
+
+// ParseGranularity parses a report granularity, the empty string is binary
+func ParseGranularity(s string) (Granularity, error) {
+	switch Granularity(s) {
+	case "", GranularityBinary:
+		return GranularityBinary, nil
+	case GranularityPackage:
+		return GranularityPackage, nil
+	}
+	return "", i18n.Errorf("不支持的报告粒度: %s", s)
+}
+
+// AffectedPackage is a package on the call paths from a binary to a changed symbol
+type AffectedPackage struct {
+	Package  string   `json:"package"`  // Import path
+	Binaries []string `json:"binaries"` // Binaries whose paths run through the package, sorted
+}
+
+// packageCollector returns the collector of affected packages, nil unless
+// packages are reported
+func (o Options) packageCollector() *packageCollector {
+	if o.Granularity != GranularityPackage {
+		return nil
+	}
+	return newPackageCollector(o.ReverseDeps)
+}
+
+// packageCollector aggregates the packages on call paths, restricted to the
+// reverse dependency closure of the changed packages when it is known: a
+// package calling into a changed one only through an interface does not depend
+// on it and is left out
+type packageCollector struct {
+	closure  map[string]bool // nil means no restriction
+	binaries map[string]map[string]bool
+}
+
+func newPackageCollector(closure []string) *packageCollector {
+	c := &packageCollector{binaries: make(map[string]map[string]bool)}
+	if closure != nil {
+		c.closure = make(map[string]bool, len(closure))
+		for _, pkgPath := range closure {
+			c.closure[pkgPath] = true
+		}
+	}
+	return c
+}
+
+// add records the packages on paths. A nil collector records nothing
+func (c *packageCollector) add(paths []lsp.CallPath) {
+	if c == nil {
+		return
+	}
+	for _, path := range paths {
+		for _, node := range path.Path {
+			if node.PackagePath == "" || c.closure != nil && !c.closure[node.PackagePath] {
+				continue
+			}
+			if c.binaries[node.PackagePath] == nil {
+				c.binaries[node.PackagePath] = make(map[string]bool)
+			}
+			c.binaries[node.PackagePath][path.BinaryName] = true
+		}
+	}
+}
+
+// packages returns the affected packages sorted by import path, nil for a nil collector
+func (c *packageCollector) packages() []AffectedPackage {
+	if c == nil {
+		return nil
+	}
+	res := make([]AffectedPackage, 0, len(c.binaries))
+	for pkgPath, binaries := range c.binaries {
+		res = append(res, AffectedPackage{Package: pkgPath, Binaries: slices.Sorted(maps.Keys(binaries))})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Package < res[j].Package })
+	return res
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+)
+
+func TestPackageCollector(t *testing.T) {
+	paths := []lsp.CallPath{
+		{BinaryName: "worker", Path: []lsp.CallNode{
+			{FunctionName: "main", PackagePath: "example.com/cmd/worker"},
+			{FunctionName: "Run", PackagePath: "example.com/jobs"},
+			{FunctionName: "Changed", PackagePath: "example.com/lib"},
+		}},
+		{BinaryName: "api", Path: []lsp.CallNode{
+			{FunctionName: "main", PackagePath: "example.com/cmd/api"},
+			{FunctionName: "Serve", PackagePath: "example.com/handler"},
+			{FunctionName: "Changed", PackagePath: "example.com/lib"},
+		}},
+	}
+
+	c := newPackageCollector(nil)
+	c.add(paths)
+	want := []AffectedPackage{
+		{Package: "example.com/cmd/api", Binaries: []string{"api"}},
+		{Package: "example.com/cmd/worker", Binaries: []string{"worker"}},
+		{Package: "example.com/handler", Binaries: []string{"api"}},
+		{Package: "example.com/jobs", Binaries: []string{"worker"}},
+		{Package: "example.com/lib", Binaries: []string{"api", "worker"}},
+	}
+	if got := c.packages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// example.com/handler reaches the change through an interface and does not import it
+	c = newPackageCollector([]string{"example.com/lib", "example.com/jobs", "example.com/cmd/worker", "example.com/cmd/api"})
+	c.add(paths)
+	for _, p := range c.packages() {
+		if p.Package == "example.com/handler" {
+			t.Errorf("Expected packages outside the reverse dependencies to be left out, got %v", p)
+		}
+	}
+
+	var off *packageCollector
+	off.add(paths)
+	if off.packages() != nil {
+		t.Error("Expected no packages from a nil collector")
+	}
+	if (Options{}).packageCollector() != nil {
+		t.Error("Expected no collector for the binary granularity")
+	}
+}
@@ -0,0 +1,29 @@
+package analyzer
+
+import (
+	"context"
+
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// fillPromotedTypes 为变更的方法补充通过嵌入获得该方法的外层结构体。
+// 通过外层类型直接调用提升方法(outer.Close())时 gopls 能找到调用方,
+// 但外层类型赋值给接口后的动态调用找不到,需要额外追踪外层类型的引用
+func (cd *ChangeDetector) fillPromotedTypes(changes []ChangedSymbol) {
+	for i := range changes {
+		recv := receiverOf(changes[i].Symbol)
+		if recv == "" {
+			continue
+		}
+		if cd.embeddings == nil {
+			embeddings, err := parser.LoadEmbeddings(context.Background(), cd.projectPath)
+			if err != nil {
+				logger.Warn("加载嵌入关系失败", "error", err)
+				return
+			}
+			cd.embeddings = embeddings
+		}
+		changes[i].Promoted = cd.embeddings.PromotingTypes(changes[i].PackagePath, recv, changes[i].Symbol.Name)
+	}
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// maxRisk is the highest risk score of a binary
+const maxRisk = 100
+
+// riskScorer accumulates the risk of each binary from the changes reaching it.
+// A change contributes once per binary, weighted by what was modified, the kind
+// of the symbol, how close to main its shortest path is and how much of it no test
+// ran; the score is the sum of the contributions times ten, capped at maxRisk.
+// Like the per-change metrics, it only sees the binaries each change's trace reported
+type riskScorer struct {
+	contributions map[string]map[int]float64 // Binary -> change index -> contribution
+	coverage      map[int]*Coverage          // Change index -> coverage, if known
+}
+
+func newRiskScorer() *riskScorer {
+	return &riskScorer{
+		contributions: make(map[string]map[int]float64),
+		coverage:      make(map[int]*Coverage),
+	}
+}
+
+// add records the paths traced for the change at index and the coverage of its
+// changed lines, nil if unknown
+func (s *riskScorer) add(index int, change ChangedSymbol, paths []lsp.CallPath, cov *Coverage) {
+	weight := modificationWeight(change) * kindWeight(change.Symbol.Kind) * coverageWeight(cov)
+	if cov != nil {
+		s.coverage[index] = cov
+	}
+	for _, path := range paths {
+		c := weight * distanceWeight(path)
+		byChange := s.contributions[path.BinaryName]
+		if byChange == nil {
+			byChange = make(map[int]float64)
+			s.contributions[path.BinaryName] = byChange
+		}
+		byChange[index] = max(byChange[index], c)
+	}
+}
+
+// score returns the risk of a binary, from 0 to maxRisk
+func (s *riskScorer) score(binary string) int {
+	sum := 0.0
+	for _, c := range s.contributions[binary] {
+		sum += c
+	}
+	return min(maxRisk, int(math.Round(sum*10)))
+}
+
+// binaryCoverage sums the coverage of the changes reaching a binary, nil if none is known
+func (s *riskScorer) binaryCoverage(binary string) *Coverage {
+	var res *Coverage
+	for index := range s.contributions[binary] {
+		cov := s.coverage[index]
+		if cov == nil {
+			continue
+		}
+		if res == nil {
+			res = &Coverage{}
+		}
+		res.Statements += cov.Statements
+		res.Covered += cov.Covered
+	}
+	return res
+}
+
+// apply sets the risk and coverage of binaries and drops those below minRisk
+func (s *riskScorer) apply(binaries []AffectedBinary, minRisk int) []AffectedBinary {
+	res := binaries[:0]
+	for _, b := range binaries {
+		b.Risk = s.score(b.Name)
+		b.Coverage = s.binaryCoverage(b.Name)
+		if b.Risk >= minRisk {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// modificationWeight rates what a change modified: a new signature or value
+// changes what callers see, a new body only what the symbol does, and an added
+// symbol only matters through callers that changed too
+func modificationWeight(change ChangedSymbol) float64 {
+	if change.ChangeType == ChangeTypeAdd {
+		return 0.5
+	}
+	switch change.Modification {
+	case ModificationSignature:
+		return 3
+	case ModificationValue:
+		return 2
+	}
+	return 1
+}
+
+// kindWeight rates the kind of a changed symbol: init functions and blank imports
+// run at startup of every binary importing their package, variables are shared
+// state, and package changes of the imports mode may not be used at all
+func kindWeight(kind parser.SymbolKind) float64 {
+	switch kind {
+	case parser.SymbolKindInit, parser.SymbolKindImport:
+		return 1.5
+	case parser.SymbolKindVariable:
+		return 1.2
+	case parser.SymbolKindPackage:
+		return 0.5
+	}
+	return 1
+}
+
+// coverageWeight rates how much of a change no test ran: fully uncovered code
+// weighs half again as much as covered code. Unknown coverage is neutral
+func coverageWeight(cov *Coverage) float64 {
+	if cov == nil {
+		return 1
+	}
+	return 1 + 0.5*cov.uncovered()
+}
+
+// distanceWeight rates how close to main a path reaches the change: code called
+// directly from main runs on every request or invocation, deep code often only
+// on some. Approximate paths say nothing about calls and get the lowest weight
+func distanceWeight(path lsp.CallPath) float64 {
+	if path.Approximate {
+		return 0.5
+	}
+	edges := max(1, len(path.Path)-1)
+	return max(0.5, 1/(1+0.25*float64(edges-1)))
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestRiskScorer(t *testing.T) {
+	path := func(binary string, nodes int, approximate bool) lsp.CallPath {
+		return lsp.CallPath{BinaryName: binary, Path: make([]lsp.CallNode, nodes), Approximate: approximate}
+	}
+	function := &parser.Symbol{Name: "F", Kind: parser.SymbolKindFunction}
+	constant := &parser.Symbol{Name: "C", Kind: parser.SymbolKindConstant}
+
+	s := newRiskScorer()
+	// 签名变化,main 直接调用: 3 * 1 * 1
+	s.add(0, ChangedSymbol{Symbol: function, ChangeType: ChangeTypeModify, Modification: ModificationSignature},
+		[]lsp.CallPath{path("api", 2, false), path("worker", 5, false)}, nil)
+	// 同一个变更的更长路径不降低分数
+	s.add(0, ChangedSymbol{Symbol: function, ChangeType: ChangeTypeModify, Modification: ModificationSignature},
+		[]lsp.CallPath{path("api", 9, false)}, nil)
+	// 常量值变化,近似路径: 2 * 1 * 0.5
+	s.add(1, ChangedSymbol{Symbol: constant, ChangeType: ChangeTypeModify, Modification: ModificationValue},
+		[]lsp.CallPath{path("api", 3, true)}, nil)
+
+	if got := s.score("api"); got != 40 {
+		t.Errorf("score(api) = %d, want 40", got)
+	}
+	// 4 条边: 3 / (1 + 0.25*3)
+	if got := s.score("worker"); got != 17 {
+		t.Errorf("score(worker) = %d, want 17", got)
+	}
+	if got := s.score("unknown"); got != 0 {
+		t.Errorf("score(unknown) = %d, want 0", got)
+	}
+
+	binaries := s.apply([]AffectedBinary{{Name: "api"}, {Name: "worker"}}, 20)
+	if len(binaries) != 1 || binaries[0].Name != "api" || binaries[0].Risk != 40 {
+		t.Errorf("apply = %+v, want api with risk 40", binaries)
+	}
+
+	for i := 2; i < 10; i++ {
+		s.add(i, ChangedSymbol{Symbol: function, ChangeType: ChangeTypeModify, Modification: ModificationSignature},
+			[]lsp.CallPath{path("api", 2, false)}, nil)
+	}
+	if got := s.score("api"); got != maxRisk {
+		t.Errorf("score(api) = %d, want %d", got, maxRisk)
+	}
+}
+
+func TestRiskScorerCoverage(t *testing.T) {
+	path := lsp.CallPath{BinaryName: "api", Path: make([]lsp.CallNode, 2)}
+	function := &parser.Symbol{Name: "F", Kind: parser.SymbolKindFunction}
+	change := ChangedSymbol{Symbol: function, ChangeType: ChangeTypeModify, Modification: ModificationBody}
+
+	s := newRiskScorer()
+	// 完全没有被覆盖: 1 * 1.5
+	s.add(0, change, []lsp.CallPath{path}, &Coverage{Statements: 4})
+	// 完全被覆盖: 1
+	s.add(1, change, []lsp.CallPath{path}, &Coverage{Statements: 2, Covered: 2})
+	// 覆盖率未知: 1
+	s.add(2, change, []lsp.CallPath{path}, nil)
+
+	binaries := s.apply([]AffectedBinary{{Name: "api"}, {Name: "worker"}}, 0)
+	if binaries[0].Risk != 35 {
+		t.Errorf("risk = %d, want 35", binaries[0].Risk)
+	}
+	if cov := binaries[0].Coverage; cov == nil || *cov != (Coverage{Statements: 6, Covered: 2}) {
+		t.Errorf("coverage = %v, want 2/6", cov)
+	}
+	if binaries[1].Coverage != nil {
+		t.Errorf("coverage of an unreached binary = %v, want nil", binaries[1].Coverage)
+	}
+}
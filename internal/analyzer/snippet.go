@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// snippetContext is the number of unchanged lines shown before and after the
+// changed lines of a snippet
+const snippetContext = 3
+
+// maxSnippetLines caps the snippet of a large change
+const maxSnippetLines = 40
+
+// Snippet is the source around the changed lines of a changed symbol, read from
+// the new version of its file
+type Snippet struct {
+	StartLine int      `json:"start_line"`          // Line number of the first line
+	Lines     []string `json:"lines"`               // Source lines, without line endings
+	Changed   []int    `json:"changed,omitempty"`   // Line numbers of the changed lines
+	Truncated bool     `json:"truncated,omitempty"` // Lines after maxSnippetLines were dropped
+}
+
+// snippetReader reads snippets of changed symbols, caching the lines of each file
+type snippetReader struct {
+	files map[string][]string
+}
+
+func newSnippetReader() *snippetReader {
+	return &snippetReader{files: make(map[string][]string)}
+}
+
+// read returns the snippet of a changed symbol, nil for deleted symbols and
+// unreadable files
+func (r *snippetReader) read(change ChangedSymbol) *Snippet {
+	if change.ChangeType == ChangeTypeDelete || change.Symbol == nil {
+		return nil
+	}
+	filename := change.Symbol.Position.Filename
+	lines, ok := r.files[filename]
+	if !ok {
+		data, err := os.ReadFile(filename)
+		if err == nil {
+			lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+		r.files[filename] = lines
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	startLine, endLine := changedLineRange(change)
+	first := max(startLine-snippetContext, 1)
+	last := min(endLine+snippetContext, len(lines))
+	if first > last {
+		return nil
+	}
+	s := &Snippet{StartLine: first}
+	if last-first+1 > maxSnippetLines {
+		last = first + maxSnippetLines - 1
+		s.Truncated = true
+	}
+	for i := first; i <= last; i++ {
+		s.Lines = append(s.Lines, strings.TrimSuffix(lines[i-1], "\r"))
+	}
+	for _, line := range change.Lines {
+		if line >= first && line <= last {
+			s.Changed = append(s.Changed, line)
+		}
+	}
+	slices.Sort(s.Changed)
+	s.Changed = slices.Compact(s.Changed)
+	return s
+}
+
+// IsChanged reports whether the line with the given number is a changed line
+func (s *Snippet) IsChanged(line int) bool {
+	_, found := slices.BinarySearch(s.Changed, line)
+	return found
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestSnippetReader(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	filename := filepath.Join(t.TempDir(), "process.go")
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	change := func(changeType ChangeType, line int, changed ...int) ChangedSymbol {
+		return ChangedSymbol{
+			Symbol:     &parser.Symbol{Name: "Process", Position: token.Position{Filename: filename, Line: line}},
+			ChangeType: changeType,
+			Lines:      changed,
+		}
+	}
+	r := newSnippetReader()
+
+	s := r.read(change(ChangeTypeModify, 10, 14, 12))
+	if s == nil || s.StartLine != 9 || len(s.Lines) != 9 || s.Lines[0] != "line 9" || s.Truncated {
+		t.Fatalf("Unexpected snippet %+v", s)
+	}
+	if !s.IsChanged(12) || !s.IsChanged(14) || s.IsChanged(13) {
+		t.Errorf("Changed = %v, want [12 14]", s.Changed)
+	}
+
+	// Context is clipped at the start and end of the file
+	if s := r.read(change(ChangeTypeModify, 1, 1, 100)); s == nil || s.StartLine != 1 || len(s.Lines) != maxSnippetLines || !s.Truncated {
+		t.Errorf("Expected a truncated snippet from line 1, got %+v", s)
+	}
+	if s := r.read(change(ChangeTypeModify, 99, 100)); s == nil || len(s.Lines) != 4 || s.Lines[3] != "line 100" {
+		t.Errorf("Expected a snippet ending at the last line, got %+v", s)
+	}
+
+	if s := r.read(change(ChangeTypeDelete, 10, 10)); s != nil {
+		t.Errorf("Expected no snippet for a deleted symbol, got %+v", s)
+	}
+}
@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// SymbolSpec 直接指定的追踪起点,两种写法:
+//   - 包.符号: "internal/foo.Bar"、"internal/foo.Server.Start"、"internal/foo.(*Server).Start"
+//   - 文件:行号: "internal/foo/bar.go:42",取包含该行的顶层符号
+//
+// 由 FileSpec 创建、Line 为 0 的说明表示整个文件都发生了变更
+type SymbolSpec struct {
+	Raw string // 原始写法
+
+	Package  string // 包的导入路径或相对仓库根目录的目录,根目录下的包写作 "."
+	Receiver string // 方法的接收者类型名(不含 *)
+	Name     string // 符号名
+
+	File string // 文件路径(相对仓库根目录或绝对路径)
+	Line int    // 行号,0 表示整个文件
+}
+
+// FileSpec 将整个文件视为变更,等同于新增该文件的 diff
+func FileSpec(file string) SymbolSpec {
+	return SymbolSpec{Raw: file, File: file}
+}
+
+var fileLineRe = regexp.MustCompile(`^(.+\.go):(\d+)$`)
+
+// ParseSymbolSpec 解析符号说明
+func ParseSymbolSpec(spec string) (SymbolSpec, error) {
+	s := SymbolSpec{Raw: spec}
+	if m := fileLineRe.FindStringSubmatch(spec); m != nil {
+		line, err := strconv.Atoi(m[2])
+		if err != nil || line <= 0 {
+			return s, i18n.Errorf("无效的符号: %s", spec)
+		}
+		s.File, s.Line = m[1], line
+		return s, nil
+	}
+
+	// 包路径中的 "." 只可能出现在最后一个 "/" 之前(如域名),符号从其后的第一个 "." 开始
+	slash := strings.LastIndex(spec, "/")
+	dot := strings.Index(spec[slash+1:], ".")
+	if dot < 0 {
+		return s, i18n.Errorf("无效的符号: %s", spec)
+	}
+	s.Package = strings.TrimSuffix(spec[:slash+1+dot], "/")
+	if s.Package == "" {
+		s.Package = "."
+	}
+
+	member := spec[slash+1+dot+1:]
+	if strings.HasPrefix(member, "(") {
+		end := strings.Index(member, ").")
+		if end < 0 {
+			return s, i18n.Errorf("无效的符号: %s", spec)
+		}
+		s.Receiver = strings.TrimPrefix(member[1:end], "*")
+		member = member[end+2:]
+	} else if i := strings.Index(member, "."); i >= 0 {
+		s.Receiver, member = member[:i], member[i+1:]
+	}
+	s.Name = member
+	if s.Name == "" || strings.ContainsAny(s.Name, ".()") {
+		return s, i18n.Errorf("无效的符号: %s", spec)
+	}
+	return s, nil
+}
+
+// IsFile 是否以文件:行号指定
+func (s SymbolSpec) IsFile() bool {
+	return s.File != ""
+}
+
+// ResolveSymbols 在已加载的包中查找指定的符号,作为变更符号返回。
+// 包.符号写法中的 Package 必须是导入路径。整个文件变更时文件中可以没有符号
+func (cd *ChangeDetector) ResolveSymbols(specs []SymbolSpec) ([]ChangedSymbol, error) {
+	var res []ChangedSymbol
+	for _, spec := range specs {
+		var found []ChangedSymbol
+		switch {
+		case spec.IsFile() && spec.Line == 0:
+			res = append(res, cd.resolveFile(spec)...)
+			continue
+		case spec.IsFile():
+			found = cd.resolveLine(spec)
+		default:
+			found = cd.resolveName(spec)
+		}
+		if len(found) == 0 {
+			return nil, i18n.Errorf("未找到符号: %s", spec.Raw)
+		}
+		res = append(res, found...)
+	}
+	cd.fillPromotedTypes(res)
+	cd.fillPackageExits(res)
+	cd.fillGoDeferCallers(res)
+	cd.fillInitRegistrations(res)
+	return res, nil
+}
+
+// resolveLine 查找包含指定行的顶层符号
+func (cd *ChangeDetector) resolveLine(spec SymbolSpec) []ChangedSymbol {
+	file := cd.specFile(spec)
+	symbols, err := cd.parser.ParseFile(file)
+	if err != nil {
+		return nil
+	}
+	return cd.expandDotImports(cd.mapLinesToSymbols(symbols, []int{spec.Line}, file), file)
+}
+
+// resolveFile 返回文件中的所有顶层符号,每一行都视为变更
+func (cd *ChangeDetector) resolveFile(spec SymbolSpec) []ChangedSymbol {
+	file := cd.specFile(spec)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	symbols, err := cd.parser.ParseFile(file)
+	if err != nil {
+		return nil
+	}
+	lines := make([]int, bytes.Count(content, []byte("\n"))+1)
+	for i := range lines {
+		lines[i] = i + 1
+	}
+	return cd.expandDotImports(cd.mapLinesToSymbols(symbols, lines, file), file)
+}
+
+// specFile 返回说明中文件的绝对路径
+func (cd *ChangeDetector) specFile(spec SymbolSpec) string {
+	if filepath.IsAbs(spec.File) {
+		return spec.File
+	}
+	return filepath.Join(cd.projectPath, spec.File)
+}
+
+// resolveName 按包路径、接收者和名称查找符号,同名的 init 函数会全部返回
+func (cd *ChangeDetector) resolveName(spec SymbolSpec) []ChangedSymbol {
+	var res []ChangedSymbol
+	for _, pkg := range cd.parser.GetPackages() {
+		if pkg.PkgPath != spec.Package {
+			continue
+		}
+		for _, file := range pkg.GoFiles {
+			symbols, err := cd.parser.ParseFile(file)
+			if err != nil {
+				continue
+			}
+			for _, s := range symbols {
+				if s.Kind == parser.SymbolKindImport || s.Name != spec.Name || receiverOf(s) != spec.Receiver {
+					continue
+				}
+				res = append(res, ChangedSymbol{
+					Symbol:      s,
+					ChangeType:  ChangeTypeModify,
+					PackagePath: s.PackagePath,
+					Lines:       []int{s.Position.Line},
+				})
+			}
+		}
+	}
+	return res
+}
+
+// receiverOf 返回方法接收者的类型名(不含 * 和类型参数),结构体字段返回所属的结构体,
+// 其他符号返回空字符串
+func receiverOf(s *parser.Symbol) string {
+	if s.Kind == parser.SymbolKindStructField && s.Parent != nil {
+		return s.Parent.Name
+	}
+	extra, ok := s.Extra.(parser.FunctionExtra)
+	if !ok || !extra.IsMethod {
+		return ""
+	}
+	recv, _, _ := strings.Cut(strings.TrimPrefix(extra.ReceiverType, "*"), "[")
+	return recv
+}
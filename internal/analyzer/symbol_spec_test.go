@@ -0,0 +1,37 @@
+package analyzer
+
+import "testing"
+
+func TestParseSymbolSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want SymbolSpec
+	}{
+		{"internal/foo.Bar", SymbolSpec{Package: "internal/foo", Name: "Bar"}},
+		{"internal/foo.Server.Start", SymbolSpec{Package: "internal/foo", Receiver: "Server", Name: "Start"}},
+		{"internal/foo.(*Server).Start", SymbolSpec{Package: "internal/foo", Receiver: "Server", Name: "Start"}},
+		{"example.com/app/pkg/common.LogMessage", SymbolSpec{Package: "example.com/app/pkg/common", Name: "LogMessage"}},
+		{"./.Version", SymbolSpec{Package: ".", Name: "Version"}},
+		{"internal/foo/bar.go:42", SymbolSpec{File: "internal/foo/bar.go", Line: 42}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSymbolSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSymbolSpec(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		tt.want.Raw = tt.spec
+		if got != tt.want {
+			t.Errorf("ParseSymbolSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseSymbolSpecInvalid(t *testing.T) {
+	for _, spec := range []string{"internal/foo", "internal/foo.", "internal/foo.(*Server.Start", "main.go:0", "foo.A.B.C"} {
+		if _, err := ParseSymbolSpec(spec); err == nil {
+			t.Errorf("ParseSymbolSpec(%q) should fail", spec)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Skip reasons recorded in ChangeMetrics.Skipped
+const (
+	// SkipTargetsResolved means every target binary was already affected when
+	// the symbol's turn came, so it was not traced
+	SkipTargetsResolved = "targets_resolved"
+	// SkipSaturated means every binary in the repository was already affected
+	SkipSaturated = "saturated"
+	// SkipDeleted means the symbol no longer exists. The code that used it
+	// changed too and is traced on its own
+	SkipDeleted = "deleted"
+	// SkipBudget means the time budget ran out before the symbol was traced, or
+	// while it was
+	SkipBudget = "budget_exceeded"
+)
+
+// stopSet returns the binaries whose being affected ends tracing early and the
+// skip reason recorded for the symbols left, nil if tracing runs to the end.
+// Skipped symbols have no results of their own, so tracing only stops early when
+// nothing but the list of affected binaries is wanted
+func (a *LSPImpactAnalyzer) stopSet() (*targetSet, string) {
+	o := a.opts
+	if !o.BinariesOnly || o.Routes || o.Commands || len(o.Jobs) > 0 || o.Explain || o.Coverage != nil || o.Snippets || o.MinRisk > 0 {
+		return nil, ""
+	}
+	if len(a.opts.Targets) > 0 {
+		return newTargetSet(a.opts.Targets), SkipTargetsResolved
+	}
+	if a.opts.AllPaths || a.opts.MaxPathsPerBinary > 0 || a.opts.Granularity == GranularityPackage {
+		return nil, ""
+	}
+	return newTargetSet(a.opts.Binaries), SkipSaturated
+}
+
+// targetSet tracks which of the requested target binaries have been reached.
+// Once all are, the remaining symbols are skipped: tracing them could only
+// report binaries that are filtered out anyway.
+type targetSet struct {
+	mu      sync.Mutex
+	pending map[string]bool // Target binaries not reached yet
+}
+
+// newTargetSet returns nil when there are no targets, which never resolves
+func newTargetSet(targets []string) *targetSet {
+	if len(targets) == 0 {
+		return nil
+	}
+	s := &targetSet{pending: make(map[string]bool, len(targets))}
+	for _, name := range targets {
+		s.pending[name] = true
+	}
+	return s
+}
+
+// reached marks a binary as affected
+func (s *targetSet) reached(binary string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, binary)
+}
+
+// resolved reports whether every target has been reached
+func (s *targetSet) resolved() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending) == 0
+}
+
+// tracingSlots bounds the symbols traced at once when tracing can stop early, so
+// symbols still waiting for a slot can be skipped. nil means no bound
+func tracingSlots(targets *targetSet) chan struct{} {
+	if targets == nil {
+		return nil
+	}
+	return make(chan struct{}, runtime.GOMAXPROCS(0))
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTargetSet(t *testing.T) {
+	var none *targetSet
+	none.reached("api")
+	if none.resolved() || tracingSlots(none) != nil {
+		t.Error("Expected no targets to never resolve and leave tracing unbounded")
+	}
+
+	s := newTargetSet([]string{"api", "worker"})
+	s.reached("api")
+	s.reached("gen")
+	if s.resolved() {
+		t.Error("Expected worker to be pending")
+	}
+	s.reached("worker")
+	if !s.resolved() {
+		t.Error("Expected all targets to be reached")
+	}
+}
+
+func TestStopSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		stops  bool
+		reason string
+	}{
+		{"targets", Options{Targets: []string{"api"}, Binaries: []string{"api", "worker"}, BinariesOnly: true}, true, SkipTargetsResolved},
+		{"saturation", Options{Binaries: []string{"api", "worker"}, BinariesOnly: true}, true, SkipSaturated},
+		{"per change", Options{Targets: []string{"api"}, Binaries: []string{"api", "worker"}}, false, ""},
+		{"all paths", Options{Binaries: []string{"api"}, AllPaths: true, BinariesOnly: true}, false, ""},
+		{"max paths", Options{Binaries: []string{"api"}, MaxPathsPerBinary: 2, BinariesOnly: true}, false, ""},
+		{"packages", Options{Binaries: []string{"api"}, Granularity: GranularityPackage, BinariesOnly: true}, false, ""},
+		{"packages with targets", Options{Targets: []string{"api"}, Binaries: []string{"api"}, Granularity: GranularityPackage, BinariesOnly: true}, true, SkipTargetsResolved},
+		{"routes", Options{Binaries: []string{"api"}, Routes: true, BinariesOnly: true}, false, ""},
+		{"explain", Options{Targets: []string{"api"}, Binaries: []string{"api"}, Explain: true, BinariesOnly: true}, false, ""},
+		{"coverage", Options{Binaries: []string{"api"}, Coverage: CoverProfile{}, BinariesOnly: true}, false, ""},
+		{"no binaries", Options{BinariesOnly: true}, false, SkipSaturated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &LSPImpactAnalyzer{opts: tt.opts}
+			set, reason := a.stopSet()
+			if (set != nil) != tt.stops || reason != tt.reason {
+				t.Errorf("Expected stops=%v reason=%q, got %v %q", tt.stops, tt.reason, set != nil, reason)
+			}
+		})
+	}
+}
+
+func TestOptionsStopped(t *testing.T) {
+	if (Options{}).stopped() || (Options{}).outOfBudget() {
+		t.Error("Expected tracing without a budget or Done channel to run to the end")
+	}
+	done := make(chan struct{})
+	opts := Options{Deadline: time.Now().Add(time.Hour), Done: done}
+	if opts.stopped() || opts.outOfBudget() {
+		t.Error("Expected tracing to go on before the deadline")
+	}
+	close(done)
+	if !opts.stopped() {
+		t.Error("Expected a closed Done channel to stop tracing")
+	}
+	if !(Options{Deadline: time.Now()}).outOfBudget() {
+		t.Error("Expected the budget to be exhausted at the deadline")
+	}
+}
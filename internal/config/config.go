@@ -0,0 +1,179 @@
+// Package config 读取仓库级配置文件 ripples.yaml
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// FileName 仓库根目录下的默认配置文件名
+const FileName = "ripples.yaml"
+
+// Config 仓库级配置,命令行参数优先于配置文件
+type Config struct {
+	// Services 服务边界规则: 相对模块根目录的包路径模式,"*" 匹配的最后一段为服务名,
+	// 例如 "cmd/*"、"internal/*"。调用链通过接口调用或函数值进入另一个服务时视为跨服务调用并被过滤
+	Services []string `yaml:"services"`
+	// CommonPackages 公共包前缀(相对模块根目录),如 "pkg/"、"lib/",不属于任何服务
+	CommonPackages []string `yaml:"common_packages"`
+	// CrossServiceFilter 是否启用基于 Services/CommonPackages 的跨服务过滤,默认启用
+	CrossServiceFilter *bool `yaml:"cross_service_filter"`
+	// InterfaceFilter 跨服务过滤的严格程度: strict、loose 或 off
+	InterfaceFilter string `yaml:"interface_filter"`
+	// Exclude 排除的文件路径模式(相对仓库根目录,支持 "**")
+	Exclude []string `yaml:"exclude"`
+	// Only 只分析匹配的文件路径模式(相对仓库根目录,支持 "**"),为空时不限制
+	Only []string `yaml:"only"`
+	// Entrypoints 作为服务入口的 main 包目录模式(相对仓库根目录),为空时不限制
+	Entrypoints []string `yaml:"entrypoints"`
+	// Targets 只分析的服务: main 包目录模式或服务名,所有目标都受影响后提前结束追踪
+	Targets []string `yaml:"targets"`
+	// EntrypointFunctions main 之外作为服务入口的函数,键为服务名,值为 "包目录.函数"
+	EntrypointFunctions map[string]string `yaml:"entrypoint_functions"`
+	// Jobs 服务内作为调用链根的函数(如定时任务),键为 main 包目录或服务名,值为 "包目录.函数" 列表
+	Jobs map[string][]string `yaml:"jobs"`
+	// Deployments 服务到部署标识的映射,键为 main 包目录(如 "cmd/api-server")或服务名
+	Deployments map[string]Deployment `yaml:"deployments"`
+	// BazelTargets 服务到 Bazel 目标(如 "//cmd/api:api")的映射,键为 main 包目录或服务名,
+	// 未配置的服务读取 main 包目录下 BUILD 文件中的 go_binary 规则
+	BazelTargets map[string]string `yaml:"bazel_targets"`
+	// Owners 服务到负责团队的映射,键为 main 包目录或服务名,优先于 CODEOWNERS
+	Owners map[string][]string `yaml:"owners"`
+	// Teams 团队到服务的映射,值为 main 包目录模式或服务名,服务属于按名称排序后第一个匹配的团队
+	Teams map[string][]string `yaml:"teams"`
+	// Mode 分析模式: calls(默认)或 imports
+	Mode string `yaml:"mode"`
+	// Strategy 追踪方向: reverse(默认)、forward 或 auto
+	Strategy string `yaml:"strategy"`
+	// Granularity 报告粒度: binary(默认)或 package
+	Granularity string `yaml:"granularity"`
+	// MaxFanOut 调用者扇出上限,超过时按导入包的服务近似报告,0 表示不限制
+	MaxFanOut int `yaml:"max_fanout"`
+	// MinRisk 只报告风险分数不低于该值的服务,0 表示不过滤
+	MinRisk int `yaml:"min_risk"`
+	// FailOnUnknown 有包加载失败或变更符号追踪失败时以非零状态退出,CI 中据此拒绝不完整的结果
+	FailOnUnknown bool `yaml:"fail_on_unknown"`
+	// BuildFlags 加载包时使用的构建参数,如 ["-mod=vendor", "-tags=integration"],应与 CI 的构建一致
+	BuildFlags []string `yaml:"build_flags"`
+	// Env 分析期间设置的环境变量,写法为 "KEY=VALUE"
+	Env []string `yaml:"env"`
+	// Timeout 分析超时时间,如 "5m"
+	Timeout Duration `yaml:"timeout"`
+	// Budget 时间预算,如 "2m",耗尽时返回已找到的结果
+	Budget Duration `yaml:"budget"`
+	// Output 输出相关的默认值
+	Output Output `yaml:"output"`
+	// CI ci 子命令生成 CI 流水线使用的模板
+	CI CI `yaml:"ci"`
+	// Hook ripples hook 安装的 git 钩子的行为
+	Hook Hook `yaml:"hook"`
+
+	// Hash 配置文件内容的 SHA-256 哈希("sha256:" 加十六进制),没有配置文件时为空
+	Hash string `yaml:"-"`
+}
+
+// CI 生成 CI 流水线的模板
+type CI struct {
+	// GitLab ripples ci gitlab 生成的子流水线,jobs 是作业名到作业的映射
+	GitLab Pipeline `yaml:"gitlab"`
+	// Buildkite ripples ci buildkite 生成的流水线,jobs 是步骤列表
+	Buildkite Pipeline `yaml:"buildkite"`
+	// CircleCI ripples ci circleci 生成的后续配置,jobs 是工作流中的作业调用列表
+	CircleCI Pipeline `yaml:"circleci"`
+}
+
+// Pipeline 动态流水线模板
+type Pipeline struct {
+	// Header 流水线的顶层内容,如 stages、variables、default,原样输出
+	Header yaml.Node `yaml:"header"`
+	// Jobs 每个受影响服务的作业。作业名和字符串值是 Go 模板,如 "build-{{.Name}}"
+	Jobs yaml.Node `yaml:"jobs"`
+	// Empty 没有受影响的服务时生成的作业,为空时生成一个只输出提示的作业
+	Empty yaml.Node `yaml:"empty"`
+	// Workflow CircleCI 中列出作业的工作流名,默认为 ripples
+	Workflow string `yaml:"workflow"`
+}
+
+// Hook git 钩子的配置
+type Hook struct {
+	// MaxAffected pre-push 钩子在受影响的服务数超过该值时拒绝推送,0 表示只输出不拒绝
+	MaxAffected int `yaml:"max_affected"`
+}
+
+// Deployment 服务的部署标识
+type Deployment struct {
+	Image       string `yaml:"image"`        // 镜像名
+	HelmRelease string `yaml:"helm_release"` // Helm release
+	Kubernetes  string `yaml:"kubernetes"`   // Kubernetes 工作负载,如 "prod/deployment/api-server"
+}
+
+// Output 输出相关的默认参数
+type Output struct {
+	Format            string `yaml:"format"`
+	AllPaths          bool   `yaml:"all_paths"`
+	MaxPathsPerBinary int    `yaml:"max_paths_per_binary"`
+	Lang              string `yaml:"lang"`
+	LogLevel          string `yaml:"log_level"`
+	LogFormat         string `yaml:"log_format"`
+}
+
+// Duration 支持 "30s"、"5m" 写法的时长
+type Duration time.Duration
+
+// UnmarshalYAML 解析时长字符串
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return i18n.Errorf("无效的时长 %q: %w", s, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Load 读取配置文件,未知字段视为错误
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("读取配置文件失败: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	cfg := &Config{Hash: "sha256:" + hex.EncodeToString(sum[:])}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, i18n.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Find 读取指定的配置文件;path 为空时查找仓库根目录下的 ripples.yaml,
+// 不存在时返回空配置
+func Find(path, repoPath string) (*Config, error) {
+	if path != "" {
+		return Load(path)
+	}
+
+	path = filepath.Join(repoPath, FileName)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, i18n.Errorf("读取配置文件失败: %w", err)
+	}
+	return Load(path)
+}
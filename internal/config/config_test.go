@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+services: ["cmd/*", "internal/*"]
+common_packages: ["pkg/", "foundation/"]
+exclude: ["gen/**", "*_mock.go"]
+only: ["internal/billing/**"]
+entrypoints: ["cmd/*"]
+cross_service_filter: false
+interface_filter: loose
+deployments:
+  cmd/api-server:
+    image: registry.example.com/api-server
+    helm_release: api
+timeout: 5m
+hook:
+  max_affected: 5
+output:
+  format: json
+  max_paths_per_binary: 3
+  lang: en
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Find("", dir)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	if len(cfg.Services) != 2 || cfg.CommonPackages[1] != "foundation/" {
+		t.Errorf("Unexpected boundaries: %+v", cfg)
+	}
+	if len(cfg.Exclude) != 2 || len(cfg.Only) != 1 || cfg.Only[0] != "internal/billing/**" {
+		t.Errorf("Unexpected file filters: exclude=%v only=%v", cfg.Exclude, cfg.Only)
+	}
+	if cfg.CrossServiceFilter == nil || *cfg.CrossServiceFilter {
+		t.Errorf("Expected cross_service_filter false, got %v", cfg.CrossServiceFilter)
+	}
+	if cfg.InterfaceFilter != "loose" {
+		t.Errorf("Expected interface_filter loose, got %q", cfg.InterfaceFilter)
+	}
+	if d := cfg.Deployments["cmd/api-server"]; d.Image != "registry.example.com/api-server" || d.HelmRelease != "api" {
+		t.Errorf("Unexpected deployment: %+v", d)
+	}
+	if cfg.Hook.MaxAffected != 5 {
+		t.Errorf("Expected hook.max_affected 5, got %d", cfg.Hook.MaxAffected)
+	}
+	if time.Duration(cfg.Timeout) != 5*time.Minute {
+		t.Errorf("Expected 5m timeout, got %v", time.Duration(cfg.Timeout))
+	}
+	if cfg.Output.Format != "json" || cfg.Output.MaxPathsPerBinary != 3 || cfg.Output.Lang != "en" {
+		t.Errorf("Unexpected output defaults: %+v", cfg.Output)
+	}
+	if !strings.HasPrefix(cfg.Hash, "sha256:") || len(cfg.Hash) != len("sha256:")+64 {
+		t.Errorf("Unexpected config hash %q", cfg.Hash)
+	}
+}
+
+func TestFindMissing(t *testing.T) {
+	cfg, err := Find("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(cfg.Services) != 0 || cfg.Output.Format != "" || cfg.Hash != "" {
+		t.Errorf("Expected empty config, got %+v", cfg)
+	}
+
+	if _, err := Find(filepath.Join(t.TempDir(), "missing.yaml"), "."); err == nil {
+		t.Error("Expected error for explicit missing file")
+	}
+}
+
+func TestLoadUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("exclud: [gen/**]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"gen/**", "gen/a/b.go", true},
+		{"gen/**", "pkg/gen/b.go", false},
+		{"**/gen/**", "pkg/gen/b.go", true},
+		{"*_mock.go", "internal/db/store_mock.go", true},
+		{"*_mock.go", "internal/db/store.go", false},
+		{"cmd/*", "cmd/api-server", true},
+		{"cmd/*", "cmd/api-server/sub", false},
+		{"internal/**/*.go", "internal/x.go", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
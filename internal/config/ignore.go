@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// IgnoreFileName 仓库根目录下的忽略文件名
+const IgnoreFileName = ".ripplesignore"
+
+// symbolPrefix 忽略文件中符号模式的前缀
+const symbolPrefix = "symbol:"
+
+// Ignore .ripplesignore 中的规则。文件和目录使用 gitignore 语法:
+//   - "#" 开头的行是注释,"!" 开头的规则重新包含之前被忽略的路径,后面的规则优先
+//   - 以 "/" 结尾的规则只匹配目录,目录下的所有文件都被忽略
+//   - 开头或中间含 "/" 的规则相对仓库根目录,否则匹配任意层级的文件名或目录名
+//   - "**" 匹配零个或多个目录
+//
+// 符号以 "symbol:" 开头,写法为 "包目录.符号",如 "symbol:internal/legacy.*"、
+// "symbol:internal/api.Server.Debug*";包目录相对仓库根目录,支持 "**",
+// 符号部分使用 path.Match 语法,方法写作 "接收者.方法"
+type Ignore struct {
+	rules   []ignoreRule
+	symbols []symbolRule
+}
+
+// ignoreRule 一条文件或目录规则
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// symbolRule 一条符号规则
+type symbolRule struct {
+	dir    string
+	name   string
+	negate bool
+}
+
+// LoadIgnore 读取仓库根目录下的 .ripplesignore,不存在时返回空规则
+func LoadIgnore(repoPath string) (*Ignore, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, IgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Ignore{}, nil
+		}
+		return nil, i18n.Errorf("读取忽略文件失败: %w", err)
+	}
+	return ParseIgnore(data)
+}
+
+// ParseIgnore 解析忽略规则
+func ParseIgnore(data []byte) (*Ignore, error) {
+	ig := &Ignore{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		negate := strings.HasPrefix(text, "!")
+		text = strings.TrimPrefix(text, "!")
+
+		if rest, ok := strings.CutPrefix(text, symbolPrefix); ok {
+			rule, ok := parseSymbolRule(rest)
+			if !ok {
+				return nil, i18n.Errorf("%s 第 %d 行: 无效的符号模式 %q", IgnoreFileName, line, text)
+			}
+			rule.negate = negate
+			ig.symbols = append(ig.symbols, rule)
+			continue
+		}
+
+		text = strings.TrimPrefix(text, `\`) // "\#"、"\!" 转义
+		rule := ignoreRule{negate: negate}
+		if strings.HasSuffix(text, "/") {
+			rule.dirOnly = true
+			text = strings.TrimRight(text, "/")
+		}
+		rule.anchored = strings.Contains(text, "/")
+		text = strings.TrimPrefix(text, "/")
+		if text == "" {
+			continue
+		}
+		rule.segments = strings.Split(text, "/")
+		ig.rules = append(ig.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取忽略文件失败: %w", err)
+	}
+	return ig, nil
+}
+
+// parseSymbolRule 解析 "包目录.符号",包目录中的 "." 只可能出现在最后一个 "/" 之前
+func parseSymbolRule(s string) (symbolRule, bool) {
+	slash := strings.LastIndex(s, "/")
+	dot := strings.Index(s[slash+1:], ".")
+	if dot < 0 {
+		return symbolRule{}, false
+	}
+	dir, name := s[:slash+1+dot], s[slash+1+dot+1:]
+	if dir == "" || name == "" {
+		return symbolRule{}, false
+	}
+	return symbolRule{dir: dir, name: name}, true
+}
+
+// File 判断文件(相对仓库根目录,"/" 分隔)是否被忽略
+func (ig *Ignore) File(name string) bool {
+	if ig == nil || len(ig.rules) == 0 {
+		return false
+	}
+	name = strings.TrimPrefix(name, "./")
+	segments := strings.Split(name, "/")
+
+	ignored := false
+	for _, rule := range ig.rules {
+		// 规则匹配文件本身或其所在的任一目录
+		for n := 1; n <= len(segments); n++ {
+			if rule.dirOnly && n == len(segments) {
+				break
+			}
+			if rule.match(segments[:n]) {
+				ignored = !rule.negate
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// match 判断规则是否匹配路径(按段)
+func (r ignoreRule) match(segments []string) bool {
+	if r.anchored {
+		return matchSegments(r.segments, segments)
+	}
+	ok, _ := path.Match(r.segments[0], segments[len(segments)-1])
+	return ok
+}
+
+// Symbol 判断包目录(相对仓库根目录,根目录为 ".")中的符号是否被忽略,
+// 方法的 name 写作 "接收者.方法"
+func (ig *Ignore) Symbol(dir, name string) bool {
+	if ig == nil {
+		return false
+	}
+	ignored := false
+	for _, rule := range ig.symbols {
+		if !matchSegments(strings.Split(rule.dir, "/"), strings.Split(dir, "/")) {
+			continue
+		}
+		if ok, _ := path.Match(rule.name, name); ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreFile(t *testing.T) {
+	ig, err := ParseIgnore([]byte(`
+# 工具和实验代码
+tools/
+/experiments
+*_mock.go
+gen/**/*.go
+!gen/keep/*.go
+\#weird.go
+`))
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"tools/lint/main.go", true},
+		{"internal/tools/x.go", true}, // 不含 "/" 的目录规则匹配任意层级
+		{"tools.go", false},           // 只匹配目录
+		{"experiments/a/b.go", true},
+		{"internal/experiments/b.go", false}, // 以 "/" 开头,相对仓库根目录
+		{"internal/store/store_mock.go", true},
+		{"gen/pb/api.pb.go", true},
+		{"gen/keep/keep.go", false},
+		{"#weird.go", true},
+		{"internal/store/store.go", false},
+	}
+	for _, tt := range tests {
+		if got := ig.File(tt.file); got != tt.want {
+			t.Errorf("File(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreSymbol(t *testing.T) {
+	ig, err := ParseIgnore([]byte(`
+symbol:internal/legacy.*
+symbol:internal/api.Server.Debug*
+symbol:**/metrics.init
+!symbol:internal/legacy.Keep
+`))
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	tests := []struct {
+		dir, name string
+		want      bool
+	}{
+		{"internal/legacy", "Old", true},
+		{"internal/legacy", "Keep", false},
+		{"internal/api", "Server.DebugDump", true},
+		{"internal/api", "Server.Start", false},
+		{"pkg/metrics", "init", true},
+		{"metrics", "init", true},
+		{"internal/legacy/sub", "Old", false},
+	}
+	for _, tt := range tests {
+		if got := ig.Symbol(tt.dir, tt.name); got != tt.want {
+			t.Errorf("Symbol(%q, %q) = %v, want %v", tt.dir, tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := ParseIgnore([]byte("symbol:nodot\n")); err == nil {
+		t.Error("Expected error for symbol pattern without a name")
+	}
+}
+
+func TestLoadIgnoreMissing(t *testing.T) {
+	ig, err := LoadIgnore(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadIgnore failed: %v", err)
+	}
+	if ig.File("tools/main.go") || ig.Symbol("internal/x", "Foo") {
+		t.Error("Expected empty rules to ignore nothing")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("tools/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ig, err = LoadIgnore(dir); err != nil || !ig.File("tools/main.go") {
+		t.Errorf("Expected tools/ to be ignored, err=%v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Scaffold ripples init 从仓库结构中检测到的内容,用于生成初始的配置文件
+type Scaffold struct {
+	Modules        []ScaffoldModule // 仓库中的模块,单模块仓库只有根目录一个
+	Services       []string         // 服务边界规则,相对模块根目录
+	CommonPackages []string         // 公共包前缀,相对模块根目录
+	Entrypoints    []string         // main 包目录模式,相对仓库根目录
+	Binaries       []ScaffoldBinary // 可执行程序,用于生成部署和负责人映射的示例
+	ProtoDirs      []string         // 含有 .proto 文件的目录,相对仓库根目录
+	Bazel          bool             // 仓库使用 Bazel 构建
+}
+
+// ScaffoldModule 仓库中的一个模块
+type ScaffoldModule struct {
+	Dir  string // 模块根目录,相对仓库根目录
+	Path string // 模块路径
+}
+
+// ScaffoldBinary 仓库中的一个可执行程序
+type ScaffoldBinary struct {
+	Name string // 服务名
+	Dir  string // main 包目录,相对仓库根目录
+}
+
+// Render 生成带注释的配置文件。检测到的字段直接写出,映射只以注释的形式给出示例,
+// 注释使用当前的输出语言
+func (s *Scaffold) Render() []byte {
+	var b bytes.Buffer
+	comment := func(msg string) { fmt.Fprintf(&b, "# %s\n", i18n.T(msg)) }
+
+	comment("ripples 配置文件，由 ripples init 根据仓库结构生成，请按需调整")
+	comment("命令行参数优先于配置文件，完整的字段说明见 README")
+	if len(s.Modules) > 1 {
+		b.WriteString("#\n")
+		comment("仓库中的模块:")
+		for _, m := range s.Modules {
+			fmt.Fprintf(&b, "#   %s (%s)\n", m.Dir, m.Path)
+		}
+	}
+
+	if len(s.Services) > 0 {
+		b.WriteString("\n")
+		comment("服务边界: 相对模块根目录的包路径模式，\"*\" 匹配的最后一段为服务名")
+		fmt.Fprintf(&b, "services: %s\n", flowList(s.Services))
+	}
+	if len(s.CommonPackages) > 0 {
+		comment("公共包前缀（相对模块根目录），不属于任何服务")
+		fmt.Fprintf(&b, "common_packages: %s\n", flowList(s.CommonPackages))
+	}
+	if len(s.Entrypoints) > 0 {
+		comment("作为服务入口的 main 包目录（相对仓库根目录）")
+		fmt.Fprintf(&b, "entrypoints: %s\n", flowList(s.Entrypoints))
+	}
+
+	if len(s.ProtoDirs) > 0 {
+		b.WriteString("\n")
+		comment(".proto 文件不参与分析，生成的 Go 代码默认跳过（-include-generated 可以分析）:")
+		for _, dir := range s.ProtoDirs {
+			fmt.Fprintf(&b, "#   %s\n", dir)
+		}
+	}
+
+	if len(s.Binaries) > 0 {
+		b.WriteString("\n")
+		comment("服务到部署标识的映射，键为 main 包目录或服务名")
+		b.WriteString("# deployments:\n")
+		for _, bin := range s.Binaries {
+			fmt.Fprintf(&b, "#   %s:\n#     image: registry.example.com/%s\n#     helm_release: %s\n", bin.Dir, bin.Name, bin.Name)
+		}
+		comment("服务到负责团队的映射，优先于 CODEOWNERS")
+		b.WriteString("# owners:\n")
+		for _, bin := range s.Binaries {
+			fmt.Fprintf(&b, "#   %s: [\"@org/team\"]\n", bin.Dir)
+		}
+		if s.Bazel {
+			comment("服务到 Bazel 目标的映射，未配置的服务读取 main 包目录下 BUILD 文件中的 go_binary 规则")
+			b.WriteString("# bazel_targets:\n")
+			for _, bin := range s.Binaries {
+				fmt.Fprintf(&b, "#   %s: \"//%s:%s\"\n", bin.Dir, strings.TrimPrefix(bin.Dir, "."), bin.Name)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// flowList 以 YAML 流式序列写出字符串列表
+func flowList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScaffoldRender(t *testing.T) {
+	s := &Scaffold{
+		Modules:        []ScaffoldModule{{Dir: ".", Path: "example.com/app"}, {Dir: "tools", Path: "example.com/app/tools"}},
+		Services:       []string{"cmd/*", "internal/*"},
+		CommonPackages: []string{"pkg/"},
+		Entrypoints:    []string{".", "cmd/*"},
+		Binaries:       []ScaffoldBinary{{Name: "app", Dir: "."}, {Name: "api", Dir: "cmd/api"}},
+		ProtoDirs:      []string{"api/proto"},
+		Bazel:          true,
+	}
+	data := s.Render()
+	for _, want := range []string{
+		"#   tools (example.com/app/tools)\n",
+		"#   api/proto\n",
+		"#   cmd/api:\n#     image: registry.example.com/api\n",
+		`#   .: "//:app"`,
+		`#   cmd/api: "//cmd/api:api"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in:\n%s", want, data)
+		}
+	}
+
+	// 生成的文件可以被读取,映射只是注释
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v\n%s", err, data)
+	}
+	if !slices.Equal(cfg.Services, s.Services) || !slices.Equal(cfg.CommonPackages, s.CommonPackages) ||
+		!slices.Equal(cfg.Entrypoints, s.Entrypoints) || len(cfg.Deployments) != 0 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
@@ -7,26 +7,36 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/jimyag/ripples/internal/i18n"
 	"github.com/sourcegraph/go-diff/diff"
 )
 
 // FileDiff 文件diff信息
 type FileDiff struct {
-	Filename      string
+	Filename      string // 新版本中的文件名,被删除的文件为旧版本中的文件名
 	Hunks         []HunkDiff
-	ChangedLines  []int // 所有变更的行号
-	IsNewFile     bool  // 是否是新文件
-	IsDeletedFile bool  // 是否是删除的文件
+	ChangedLines  []int      // 所有变更的行号
+	Deletions     []Deletion // 删除的连续行,只删除代码的修改没有新增行
+	IsNewFile     bool       // 是否是新文件
+	IsDeletedFile bool       // 是否是删除的文件
 }
 
 // HunkDiff 代码块diff信息
 type HunkDiff struct {
+	Header        string // hunk 头,如 "@@ -28,6 +28,7 @@ func LogMessage(message string) {"
 	NewStartLine  int32
 	NewLines      int32
 	AddedLines    []LineDiff
 	ModifiedLines []LineDiff // 修改的行
 }
 
+// Deletion 一段连续删除的行
+type Deletion struct {
+	OldLine int32 // 第一行在旧版本文件中的行号
+	Lines   int32 // 删除的行数
+	NewLine int32 // 删除位置之后的第一行在新版本文件中的行号
+}
+
 // LineDiff 行diff信息
 type LineDiff struct {
 	LineNumber  int32
@@ -39,7 +49,36 @@ func GetGitDiff(repoPath, oldCommit, newCommit string) ([]byte, error) {
 	cmd.Dir = repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("git diff 失败: %w\n输出: %s", err, string(output))
+		return nil, i18n.Errorf("git diff 失败: %w\n输出: %s", err, string(output))
+	}
+	return output, nil
+}
+
+// ChangedGoFiles 列出两个 commit 之间变更的 Go 文件(含新增和删除的文件,
+// 重命名视为删除加新增),路径相对仓库根目录
+func ChangedGoFiles(repoPath, oldCommit, newCommit string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--no-renames", oldCommit, newCommit, "--", "*.go")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, i18n.Errorf("git diff 失败: %w\n输出: %s", err, string(output))
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ShowFile 获取文件在指定 commit 中的内容
+func ShowFile(repoPath, commit, path string) ([]byte, error) {
+	cmd := exec.Command("git", "show", commit+":"+path)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, i18n.Errorf("git show 失败: %w", err)
 	}
 	return output, nil
 }
@@ -53,11 +92,6 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 
 	var res []FileDiff
 	for _, d := range diffs {
-		// 如果文件被删除了,则跳过
-		if d.NewName == "/dev/null" {
-			continue
-		}
-
 		// 去掉前缀 a/ 或 b/
 		newName := strings.TrimPrefix(d.NewName, "b/")
 		oldName := strings.TrimPrefix(d.OrigName, "a/")
@@ -69,6 +103,12 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 			IsNewFile:     oldName == "/dev/null",
 			IsDeletedFile: newName == "/dev/null",
 		}
+		// 被删除的文件使用旧版本中的文件名,没有新版本中的变更行
+		if fd.IsDeletedFile {
+			fd.Filename = oldName
+			res = append(res, fd)
+			continue
+		}
 
 		for _, h := range d.Hunks {
 			// 如果新文件的行数为0,则跳过
@@ -83,6 +123,12 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 			scanner := bufio.NewScanner(reader)
 
 			currentNewLineNum := h.NewStartLine
+			if h.NewLines == 0 {
+				// 只有删除的 hunk(如 -U0)中 NewStartLine 是删除位置之前的一行
+				currentNewLineNum++
+			}
+			currentOldLineNum := h.OrigStartLine
+			var deletion *Deletion
 			for scanner.Scan() {
 				line := scanner.Text()
 
@@ -91,6 +137,9 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 					continue
 				}
 
+				if !strings.HasPrefix(line, "-") {
+					deletion = nil
+				}
 				if strings.HasPrefix(line, "+") {
 					// 新增行
 					addedLines = append(addedLines, LineDiff{
@@ -100,12 +149,18 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 					fd.ChangedLines = append(fd.ChangedLines, int(currentNewLineNum))
 					currentNewLineNum++
 				} else if strings.HasPrefix(line, "-") {
-					// 删除行: 不影响新文件的行号,但记录为修改
-					// 注意: 这里我们主要关注新文件中的变更
-					continue
+					// 删除行: 不影响新文件的行号,记录删除的位置,
+					// 由变更检测根据前后的行判断删除是否发生在某个符号内部
+					if deletion == nil {
+						fd.Deletions = append(fd.Deletions, Deletion{OldLine: currentOldLineNum, NewLine: currentNewLineNum})
+						deletion = &fd.Deletions[len(fd.Deletions)-1]
+					}
+					deletion.Lines++
+					currentOldLineNum++
 				} else if strings.HasPrefix(line, " ") || line == "" {
 					// 上下文行(空格开头)或空行: 在新文件中存在
 					currentNewLineNum++
+					currentOldLineNum++
 				}
 			}
 
@@ -114,6 +169,7 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 			modifiedLines = addedLines
 
 			fd.Hunks = append(fd.Hunks, HunkDiff{
+				Header:        hunkHeader(h),
 				NewStartLine:  h.NewStartLine,
 				NewLines:      h.NewLines,
 				AddedLines:    addedLines,
@@ -127,6 +183,25 @@ func ParseDiff(diffContent []byte) ([]FileDiff, error) {
 	return res, nil
 }
 
+// Contains 判断新版本文件中的第 line 行是否在 hunk 内。只有删除的 hunk 包含删除位置之后的一行,
+// 与 Deletion.NewLine 一致
+func (h HunkDiff) Contains(line int) bool {
+	start := int(h.NewStartLine)
+	if h.NewLines == 0 {
+		return line == start+1
+	}
+	return line >= start && line < start+int(h.NewLines)
+}
+
+// hunkHeader 返回 hunk 头,与 git diff 的输出一致
+func hunkHeader(h *diff.Hunk) string {
+	header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OrigStartLine, h.OrigLines, h.NewStartLine, h.NewLines)
+	if h.Section != "" {
+		header += " " + h.Section
+	}
+	return header
+}
+
 // GetChangedFiles 获取变更的文件列表
 func GetChangedFiles(repoPath, oldCommit, newCommit string) ([]string, error) {
 	diffContent, err := GetGitDiff(repoPath, oldCommit, newCommit)
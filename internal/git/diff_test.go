@@ -0,0 +1,70 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiffDeletions(t *testing.T) {
+	diff := `diff --git a/pkg/a.go b/pkg/a.go
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -10,6 +10,4 @@ func A() {
+ 	a := 1
+-	b := 2
+-	c := 3
+ 	d := 4
++	e := 5
+-	f := 6
+ }
+@@ -30,1 +27,0 @@
+-	g := 7
+`
+	fileDiffs, err := ParseDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	if len(fileDiffs) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(fileDiffs))
+	}
+	want := []Deletion{
+		{OldLine: 11, Lines: 2, NewLine: 11},
+		{OldLine: 14, Lines: 1, NewLine: 13},
+		{OldLine: 30, Lines: 1, NewLine: 28},
+	}
+	if got := fileDiffs[0].Deletions; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected deletions %v, got %v", want, got)
+	}
+	if got := fileDiffs[0].ChangedLines; !reflect.DeepEqual(got, []int{12}) {
+		t.Errorf("Expected changed lines [12], got %v", got)
+	}
+	hunks := fileDiffs[0].Hunks
+	if len(hunks) != 2 || hunks[0].Header != "@@ -10,6 +10,4 @@ func A() {" || hunks[1].Header != "@@ -30,1 +27,0 @@" {
+		t.Fatalf("Unexpected hunks %+v", hunks)
+	}
+	if !hunks[0].Contains(13) || hunks[0].Contains(14) || !hunks[1].Contains(28) || hunks[1].Contains(27) {
+		t.Errorf("Unexpected hunk ranges %+v", hunks)
+	}
+}
+
+func TestParseDiffDeletedFile(t *testing.T) {
+	diff := `diff --git a/pkg/old.go b/pkg/old.go
+deleted file mode 100644
+--- a/pkg/old.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package pkg
+-
+-func Old() {}
+`
+	fileDiffs, err := ParseDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("ParseDiff failed: %v", err)
+	}
+	if len(fileDiffs) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(fileDiffs))
+	}
+	if fd := fileDiffs[0]; fd.Filename != "pkg/old.go" || !fd.IsDeletedFile || len(fd.ChangedLines) != 0 || len(fd.Hunks) != 0 {
+		t.Errorf("Expected pkg/old.go to be deleted without changed lines, got %+v", fd)
+	}
+}
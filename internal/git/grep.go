@@ -0,0 +1,33 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// GrepFiles 列出工作区中包含固定字符串 text 的文件(含未跟踪、未被忽略的文件),路径相对 repoPath。
+// pathspecs 限定搜索范围,如 "*.go"
+func GrepFiles(repoPath, text string, pathspecs ...string) ([]string, error) {
+	args := append([]string{"grep", "-l", "--untracked", "--fixed-strings", "-e", text, "--"}, pathspecs...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// 没有匹配时退出码为 1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, i18n.Errorf("git grep 失败: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Commit 历史中的一个提交
+type Commit struct {
+	Hash    string
+	Parent  string // 第一个父提交,根提交为空
+	Subject string
+	Author  string
+	Email   string
+	Date    time.Time // 作者时间
+}
+
+// logFormat git log 的输出格式,字段以 NUL 分隔,与 parseLog 对应
+const logFormat = "--format=%H %P%x00%an%x00%ae%x00%aI%x00%s"
+
+var relativeSinceRe = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// SinceArg 将 "90d"、"12w"、"6m"、"1y" 这类简写转换为 git --since 可识别的形式,
+// 其他写法(如 "2024-01-01"、"3 months ago")原样返回
+func SinceArg(since string) string {
+	m := relativeSinceRe.FindStringSubmatch(since)
+	if m == nil {
+		return since
+	}
+	n, _ := strconv.Atoi(m[1])
+	unit := map[string]string{"d": "days", "w": "weeks", "m": "months", "y": "years"}[m[2]]
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
+
+// ListCommits 列出 ref 可达的、since 之后的非合并提交,从新到旧
+func ListCommits(repoPath, ref, since string) ([]Commit, error) {
+	args := []string{"log", "--no-merges", logFormat}
+	if since != "" {
+		args = append(args, "--since="+SinceArg(since))
+	}
+	return gitLog(repoPath, append(args, ref, "--")...)
+}
+
+// RangeCommits 列出 newCommit 可达而 oldCommit 不可达的提交(含合并提交),从新到旧
+func RangeCommits(repoPath, oldCommit, newCommit string) ([]Commit, error) {
+	return gitLog(repoPath, "log", logFormat, "--end-of-options", oldCommit+".."+newCommit, "--")
+}
+
+// gitLog 运行 git log 并解析 logFormat 格式的输出
+func gitLog(repoPath string, args ...string) ([]Commit, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, i18n.Errorf("git log 失败: %w", err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		hashes := strings.Fields(fields[0])
+		c := Commit{Hash: hashes[0], Author: fields[1], Email: fields[2], Subject: fields[4]}
+		if len(hashes) > 1 {
+			c.Parent = hashes[1]
+		}
+		if date, err := time.Parse(time.RFC3339, fields[3]); err == nil {
+			c.Date = date.UTC()
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// Blame 返回 commit 中 file 的第 start 到 end 行最后一次修改所在的提交,按修改的行数从多到少排列,
+// 行数相同时较新的在前。Parent 不填写
+func Blame(repoPath, commit, file string, start, end int) ([]Commit, error) {
+	cmd := exec.Command("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", start, end), commit, "--", file)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, i18n.Errorf("git blame %s 失败: %w", file, err)
+	}
+	return parseBlame(string(output)), nil
+}
+
+// parseBlame 解析 git blame --porcelain 的输出。每行代码以 "<hash> <原行号> <行号> [<行数>]"
+// 开头,提交第一次出现时后面跟着 author、author-mail、author-time、summary 等头部
+func parseBlame(output string) []Commit {
+	var commits []Commit
+	lines := make(map[string]int)
+	index := make(map[string]int)
+	current := -1
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			// 代码行
+		case len(key) == 40 && strings.Trim(key, "0123456789abcdef") == "":
+			i, ok := index[key]
+			if !ok {
+				i = len(commits)
+				index[key] = i
+				commits = append(commits, Commit{Hash: key})
+			}
+			lines[key]++
+			current = i
+		case current < 0:
+		case key == "author":
+			commits[current].Author = value
+		case key == "author-mail":
+			commits[current].Email = strings.Trim(value, "<>")
+		case key == "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				commits[current].Date = time.Unix(sec, 0).UTC()
+			}
+		case key == "summary":
+			commits[current].Subject = value
+		}
+	}
+	slices.SortStableFunc(commits, func(a, b Commit) int {
+		if lines[a.Hash] != lines[b.Hash] {
+			return lines[b.Hash] - lines[a.Hash]
+		}
+		return b.Date.Compare(a.Date)
+	})
+	return commits
+}
+
+// ResolveCommit 将分支名、标签或缩写的 commit ID 解析为完整的 commit hash
+func ResolveCommit(repoPath, ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", i18n.Errorf("解析 commit %s 失败: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HooksDir 返回仓库的 git 钩子目录,遵循 core.hooksPath 设置,工作区中为主仓库的钩子目录
+func HooksDir(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", i18n.Errorf("查找 git 钩子目录失败: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	return dir, nil
+}
+
+// AddWorktree 在 dir 检出 commit 的独立工作区(分离 HEAD)
+func AddWorktree(repoPath, dir, commit string) error {
+	cmd := exec.Command("git", "worktree", "add", "--detach", "--force", dir, commit)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return i18n.Errorf("创建 git worktree 失败: %w\n输出: %s", err, string(output))
+	}
+	return nil
+}
+
+// RemoveWorktree 删除 AddWorktree 创建的工作区
+func RemoveWorktree(repoPath, dir string) error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", dir)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return i18n.Errorf("删除 git worktree 失败: %w\n输出: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSinceArg(t *testing.T) {
+	tests := map[string]string{
+		"90d":         "90 days ago",
+		"12w":         "12 weeks ago",
+		"6m":          "6 months ago",
+		"1y":          "1 years ago",
+		"2024-01-01":  "2024-01-01",
+		"3 weeks ago": "3 weeks ago",
+	}
+	for in, want := range tests {
+		if got := SinceArg(in); got != want {
+			t.Errorf("SinceArg(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	a := strings.Repeat("a", 40)
+	b := strings.Repeat("b", 40)
+	output := a + " 1 1 1\nauthor Ann\nauthor-mail <ann@example.com>\nauthor-time 1700000000\nsummary Add retries\n\tline one\n" +
+		b + " 2 2 2\nauthor Bob\nauthor-mail <bob@example.com>\nauthor-time 1600000000\nsummary Initial\n\tline two\n" +
+		b + " 3 3\n\tline three\n"
+	commits := parseBlame(output)
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	// 修改行数多的提交在前
+	if commits[0].Hash != b || commits[0].Author != "Bob" || commits[0].Subject != "Initial" {
+		t.Errorf("Unexpected first commit %+v", commits[0])
+	}
+	if commits[1].Email != "ann@example.com" || commits[1].Date.Unix() != 1700000000 {
+		t.Errorf("Unexpected second commit %+v", commits[1])
+	}
+}
+
+func TestListCommitsAndWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	for _, content := range []string{"one", "two"} {
+		if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "-q", "-m", "commit "+content)
+	}
+
+	commits, err := ListCommits(repo, "HEAD", "1y")
+	if err != nil {
+		t.Fatalf("ListCommits failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %+v", commits)
+	}
+	if commits[0].Subject != "commit two" || commits[0].Parent != commits[1].Hash {
+		t.Errorf("Unexpected newest commit: %+v", commits[0])
+	}
+	if commits[1].Parent != "" {
+		t.Errorf("Root commit should have no parent: %+v", commits[1])
+	}
+
+	if commits[0].Author != "t" || commits[0].Email != "t@example.com" || commits[0].Date.IsZero() {
+		t.Errorf("Missing author of %+v", commits[0])
+	}
+
+	inRange, err := RangeCommits(repo, commits[1].Hash, "HEAD")
+	if err != nil {
+		t.Fatalf("RangeCommits failed: %v", err)
+	}
+	if len(inRange) != 1 || inRange[0].Hash != commits[0].Hash {
+		t.Errorf("RangeCommits = %+v, want only %s", inRange, commits[0].Hash)
+	}
+	blamed, err := Blame(repo, "HEAD", "file.txt", 1, 1)
+	if err != nil {
+		t.Fatalf("Blame failed: %v", err)
+	}
+	if len(blamed) != 1 || blamed[0].Hash != commits[0].Hash || blamed[0].Subject != "commit two" || blamed[0].Email != "t@example.com" {
+		t.Errorf("Blame = %+v, want %s", blamed, commits[0].Hash)
+	}
+
+	if hash, err := ResolveCommit(repo, "HEAD~1"); err != nil || hash != commits[1].Hash {
+		t.Errorf("ResolveCommit(HEAD~1) = %q, %v; want %s", hash, err, commits[1].Hash)
+	}
+	if _, err := ResolveCommit(repo, "missing"); err == nil {
+		t.Error("Expected error for unknown ref")
+	}
+
+	dir := filepath.Join(t.TempDir(), "wt")
+	if err := AddWorktree(repo, dir, commits[1].Hash); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	if err != nil || string(data) != "one" {
+		t.Errorf("Worktree content = %q, %v; want \"one\"", data, err)
+	}
+	if err := RemoveWorktree(repo, dir); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Worktree %s still exists", dir)
+	}
+}
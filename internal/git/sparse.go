@@ -0,0 +1,30 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// SkippedFiles 返回 files 中不在稀疏检出范围内(索引中带有 skip-worktree 标记)的文件,
+// 路径相对 repoPath。这些文件被跟踪但不在工作区中,与已删除的文件不同
+func SkippedFiles(repoPath string, files []string) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	args := append([]string{"--literal-pathspecs", "ls-files", "-t", "--"}, files...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, i18n.Errorf("git ls-files 失败: %w", err)
+	}
+	var skipped []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if file, ok := strings.CutPrefix(line, "S "); ok {
+			skipped = append(skipped, file)
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,89 @@
+// Package github 提供 GitHub Actions 集成
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Actions GitHub Actions 运行环境
+type Actions struct {
+	SummaryPath string // $GITHUB_STEP_SUMMARY
+	OutputPath  string // $GITHUB_OUTPUT
+}
+
+// FromEnv 从环境变量读取 GitHub Actions 配置
+func FromEnv() (*Actions, error) {
+	a := &Actions{
+		SummaryPath: os.Getenv("GITHUB_STEP_SUMMARY"),
+		OutputPath:  os.Getenv("GITHUB_OUTPUT"),
+	}
+	if a.SummaryPath == "" && a.OutputPath == "" {
+		return nil, i18n.Errorf("未检测到 GitHub Actions 环境: GITHUB_STEP_SUMMARY 和 GITHUB_OUTPUT 均未设置")
+	}
+	return a, nil
+}
+
+// WriteSummary 将 Markdown 追加到 job summary
+func (a *Actions) WriteSummary(markdown string) error {
+	if a.SummaryPath == "" {
+		return nil
+	}
+	return appendFile(a.SummaryPath, markdown)
+}
+
+// SetOutput 设置 step output,值中包含换行时使用 heredoc 语法
+func (a *Actions) SetOutput(name, value string) error {
+	if a.OutputPath == "" {
+		return nil
+	}
+	if strings.Contains(value, "\n") {
+		delimiter := "ripples_EOF"
+		for strings.Contains(value, delimiter) {
+			delimiter += "_"
+		}
+		return appendFile(a.OutputPath, fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter))
+	}
+	return appendFile(a.OutputPath, fmt.Sprintf("%s=%s\n", name, value))
+}
+
+// SetAffectedServices 以 JSON 数组输出受影响服务,可直接用于 build matrix:
+//
+//	matrix:
+//	  service: ${{ fromJSON(needs.impact.outputs.affected-services) }}
+func (a *Actions) SetAffectedServices(services []string) error {
+	return a.setList("affected-services", services)
+}
+
+// SetAffectedImages 以 JSON 数组输出受影响服务的镜像(来自配置中的部署映射)
+func (a *Actions) SetAffectedImages(images []string) error {
+	return a.setList("affected-images", images)
+}
+
+// setList 以 JSON 数组设置 step output,空列表输出 []
+func (a *Actions) setList(name string, values []string) error {
+	if values == nil {
+		values = []string{}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return a.SetOutput(name, string(data))
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
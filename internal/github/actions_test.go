@@ -0,0 +1,61 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestActionsOutputs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(dir, "summary.md"))
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+
+	a, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv failed: %v", err)
+	}
+
+	if err := a.WriteSummary("## report\n"); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	if err := a.SetAffectedServices([]string{"api-server", "worker"}); err != nil {
+		t.Fatalf("SetAffectedServices failed: %v", err)
+	}
+	if err := a.SetAffectedImages([]string{"registry/api-server"}); err != nil {
+		t.Fatalf("SetAffectedImages failed: %v", err)
+	}
+	if err := a.SetOutput("details", "line1\nline2"); err != nil {
+		t.Fatalf("SetOutput failed: %v", err)
+	}
+
+	summary, _ := os.ReadFile(a.SummaryPath)
+	if string(summary) != "## report\n" {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+
+	output, _ := os.ReadFile(a.OutputPath)
+	want := "affected-services=[\"api-server\",\"worker\"]\naffected-images=[\"registry/api-server\"]\ndetails<<ripples_EOF\nline1\nline2\nripples_EOF\n"
+	if string(output) != want {
+		t.Errorf("Unexpected output:\n%q\nwant:\n%q", output, want)
+	}
+}
+
+func TestSetAffectedServicesEmpty(t *testing.T) {
+	a := &Actions{OutputPath: filepath.Join(t.TempDir(), "output")}
+	if err := a.SetAffectedServices(nil); err != nil {
+		t.Fatalf("SetAffectedServices failed: %v", err)
+	}
+	output, _ := os.ReadFile(a.OutputPath)
+	if string(output) != "affected-services=[]\n" {
+		t.Errorf("Expected empty JSON array, got %q", output)
+	}
+}
+
+func TestFromEnvOutsideActions(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	t.Setenv("GITHUB_OUTPUT", "")
+	if _, err := FromEnv(); err == nil {
+		t.Error("Expected error outside GitHub Actions")
+	}
+}
@@ -0,0 +1,153 @@
+// Package gitlab 将分析结果发布到 GitLab 合并请求
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Client GitLab 合并请求客户端
+type Client struct {
+	BaseURL    string // 例如 https://gitlab.example.com
+	Token      string // Personal/Project Access Token
+	ProjectID  string // 项目 ID 或 URL 编码前的 namespace/project
+	MRIID      string // 合并请求 IID
+	HTTPClient *http.Client
+}
+
+// NewClient 创建客户端
+func NewClient(baseURL, token, projectID, mrIID string) (*Client, error) {
+	if baseURL == "" || token == "" || projectID == "" || mrIID == "" {
+		return nil, i18n.Errorf("GitLab 配置不完整: 需要 URL、token、项目 ID 和 MR IID")
+	}
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		ProjectID:  projectID,
+		MRIID:      mrIID,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// mrURL 返回合并请求 API 地址
+func (c *Client) mrURL(suffix string) string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%s%s",
+		c.BaseURL, url.PathEscape(c.ProjectID), url.PathEscape(c.MRIID), suffix)
+}
+
+// noteMarker 标记 ripples 发布的评论,再次发布时更新这条评论而不是每次流水线都新增一条
+const noteMarker = "<!-- ripples-report -->"
+
+// note 合并请求评论
+type note struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+}
+
+// PostNote 在合并请求上发表评论
+func (c *Client) PostNote(ctx context.Context, body string) error {
+	return c.do(ctx, http.MethodPost, c.mrURL("/notes"), map[string]string{"body": body}, nil)
+}
+
+// UpsertNote 更新之前发布的报告评论,没有时发表新评论
+func (c *Client) UpsertNote(ctx context.Context, body string) error {
+	body = noteMarker + "\n" + body
+	id, err := c.findNote(ctx)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return c.PostNote(ctx, body)
+	}
+	return c.do(ctx, http.MethodPut, c.mrURL(fmt.Sprintf("/notes/%d", id)), map[string]string{"body": body}, nil)
+}
+
+// findNote 返回最近一条带有 noteMarker 的评论 ID,没有时返回 0
+func (c *Client) findNote(ctx context.Context) (int, error) {
+	const perPage = 100
+	for page := 1; ; page++ {
+		var notes []note
+		endpoint := c.mrURL(fmt.Sprintf("/notes?sort=desc&order_by=created_at&per_page=%d&page=%d", perPage, page))
+		if err := c.do(ctx, http.MethodGet, endpoint, nil, &notes); err != nil {
+			return 0, err
+		}
+		for _, n := range notes {
+			if strings.HasPrefix(n.Body, noteMarker) {
+				return n.ID, nil
+			}
+		}
+		if len(notes) < perPage {
+			return 0, nil
+		}
+	}
+}
+
+// SetLabel 添加或移除合并请求标签
+func (c *Client) SetLabel(ctx context.Context, label string, present bool) error {
+	payload := map[string]string{}
+	if present {
+		payload["add_labels"] = label
+	} else {
+		payload["remove_labels"] = label
+	}
+	return c.do(ctx, http.MethodPut, c.mrURL(""), payload, nil)
+}
+
+// do 发送 JSON 请求,payload 为 nil 时不带请求体;out 不为 nil 时将响应解析到 out
+func (c *Client) do(ctx context.Context, method, endpoint string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return i18n.Errorf("GitLab 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return i18n.Errorf("GitLab 返回错误 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return i18n.Errorf("解析 GitLab 响应失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Publish 发布报告评论,并根据受影响服务数量切换评审标签。同一合并请求上已有报告评论时
+// 更新该评论,每次流水线运行不会新增评论。threshold <= 0 或 label 为空时不修改标签。
+func (c *Client) Publish(ctx context.Context, markdown string, affected int, label string, threshold int) error {
+	if err := c.UpsertNote(ctx, markdown); err != nil {
+		return err
+	}
+	if label == "" || threshold <= 0 {
+		return nil
+	}
+	return c.SetLabel(ctx, label, affected > threshold)
+}
@@ -0,0 +1,140 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordedRequest struct {
+	method  string
+	path    string
+	token   string
+	payload map[string]string
+}
+
+func newTestServer(t *testing.T, status int, notes ...note) (*httptest.Server, *[]recordedRequest) {
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{
+			method: r.Method,
+			path:   r.URL.EscapedPath(),
+			token:  r.Header.Get("PRIVATE-TOKEN"),
+		}
+		if r.Method != http.MethodGet {
+			if err := json.NewDecoder(r.Body).Decode(&req.payload); err != nil {
+				t.Errorf("Invalid JSON payload: %v", err)
+			}
+		}
+		requests = append(requests, req)
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet && status < 300 {
+			if notes == nil {
+				notes = []note{}
+			}
+			_ = json.NewEncoder(w).Encode(notes)
+		}
+	}))
+	return srv, &requests
+}
+
+func TestPublishAddsLabelAboveThreshold(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusCreated)
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "secret", "group/project", "42")
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if err := c.Publish(context.Background(), "report", 5, "needs-extra-review", 3); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(*requests))
+	}
+
+	if list := (*requests)[0]; list.method != http.MethodGet || list.token != "secret" {
+		t.Errorf("Expected the existing notes to be listed first, got %+v", list)
+	}
+	post := (*requests)[1]
+	if post.method != http.MethodPost || post.path != "/api/v4/projects/group%2Fproject/merge_requests/42/notes" {
+		t.Errorf("Unexpected note request: %s %s", post.method, post.path)
+	}
+	if post.token != "secret" || post.payload["body"] != noteMarker+"\nreport" {
+		t.Errorf("Unexpected note content: %+v", post)
+	}
+
+	label := (*requests)[2]
+	if label.method != http.MethodPut || label.payload["add_labels"] != "needs-extra-review" {
+		t.Errorf("Expected label to be added, got %s %+v", label.method, label.payload)
+	}
+}
+
+func TestPublishRemovesLabelBelowThreshold(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK)
+	defer srv.Close()
+
+	c, _ := NewClient(srv.URL, "secret", "7", "1")
+	if err := c.Publish(context.Background(), "report", 1, "needs-extra-review", 3); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if got := (*requests)[2].payload["remove_labels"]; got != "needs-extra-review" {
+		t.Errorf("Expected label to be removed, got %+v", (*requests)[2].payload)
+	}
+}
+
+func TestPublishWithoutThresholdOnlyPostsNote(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusCreated)
+	defer srv.Close()
+
+	c, _ := NewClient(srv.URL, "secret", "7", "1")
+	if err := c.Publish(context.Background(), "report", 10, "needs-extra-review", 0); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("Expected only the note requests, got %d", len(*requests))
+	}
+}
+
+func TestPublishUpdatesPreviousNote(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK,
+		note{ID: 12, Body: "LGTM"},
+		note{ID: 7, Body: noteMarker + "\nold report"})
+	defer srv.Close()
+
+	c, _ := NewClient(srv.URL, "secret", "7", "1")
+	if err := c.Publish(context.Background(), "report", 1, "", 0); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected a list and an update request, got %+v", *requests)
+	}
+	update := (*requests)[1]
+	if update.method != http.MethodPut || update.path != "/api/v4/projects/7/merge_requests/1/notes/7" {
+		t.Errorf("Expected the previous report to be updated, got %s %s", update.method, update.path)
+	}
+	if update.payload["body"] != noteMarker+"\nreport" {
+		t.Errorf("Unexpected note content: %+v", update.payload)
+	}
+}
+
+func TestPublishReturnsAPIError(t *testing.T) {
+	srv, _ := newTestServer(t, http.StatusForbidden)
+	defer srv.Close()
+
+	c, _ := NewClient(srv.URL, "bad", "7", "1")
+	if err := c.Publish(context.Background(), "report", 0, "", 0); err == nil {
+		t.Error("Expected error for 403 response")
+	}
+}
+
+func TestNewClientValidatesConfig(t *testing.T) {
+	if _, err := NewClient("https://gitlab.example.com", "", "7", "1"); err == nil {
+		t.Error("Expected error when token is missing")
+	}
+}
@@ -0,0 +1,31 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDOT 以 Graphviz DOT 格式输出调用图,服务的 main 函数显示为方框
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph ripples {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	for _, r := range g.Roots {
+		fmt.Fprintf(bw, "\t%s [shape=box, label=%s];\n", strconv.Quote(r.Function), strconv.Quote(r.Binary))
+	}
+
+	// 同一对函数之间的多个调用点只画一条边
+	seen := make(map[[2]string]bool)
+	for _, e := range g.Edges {
+		key := [2]string{e.Caller, e.Callee}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		fmt.Fprintf(bw, "\t%s -> %s;\n", strconv.Quote(e.Caller), strconv.Quote(e.Callee))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,230 @@
+// Package graph 构建仓库级的调用图,以所有 main 函数为根,
+// 记录每个函数能被哪些服务调用到,与具体的 diff 无关。
+package graph
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"sort"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Graph 调用图,只包含仓库内且能从某个 main 函数到达的函数
+type Graph struct {
+	Roots []Root `json:"roots"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Root 调用图的根: 服务的 main 函数
+type Root struct {
+	Binary   string `json:"binary"`   // 服务名(见 parser.BinaryName)
+	Function string `json:"function"` // main 函数的节点 ID
+}
+
+// Node 调用图中的函数
+type Node struct {
+	ID       string   `json:"id"`             // 函数全名,如 (*example.com/app/pkg.Server).Start
+	Package  string   `json:"package"`        // 所属包的导入路径
+	File     string   `json:"file,omitempty"` // 声明所在文件(相对仓库根目录),合成的包初始化函数没有
+	Line     int      `json:"line,omitempty"` // 声明所在行
+	Binaries []string `json:"binaries"`
+}
+
+// Edge 调用关系
+type Edge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	File   string `json:"file,omitempty"` // 调用点所在文件(相对仓库根目录)
+	Line   int    `json:"line,omitempty"` // 调用点所在行
+}
+
+// Build 加载 dir 下匹配 patterns 的包(默认 "./..."),用 CHA 构建调用图。
+// 接口方法调用会连接到所有实现,因此结果是实际调用关系的超集
+func Build(ctx context.Context, dir string, patterns ...string) (*Graph, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.LoadAllSyntax,
+		Dir:     root,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+	var hasErrors bool
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			hasErrors = true
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+	})
+	if hasErrors {
+		return nil, i18n.Errorf("部分包加载失败")
+	}
+
+	// 只为仓库内的包构建函数体,依赖只需要类型信息
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	b := &builder{
+		root:  root,
+		fset:  prog.Fset,
+		cg:    cha.CallGraph(prog),
+		local: make(map[string]bool),
+		nodes: make(map[*ssa.Function]*Node),
+		edges: make(map[Edge]bool),
+	}
+	for _, pkg := range pkgs {
+		b.local[pkg.PkgPath] = true
+	}
+
+	g := &Graph{}
+	for _, pkg := range ssaPkgs {
+		if pkg == nil || pkg.Pkg.Name() != "main" {
+			continue
+		}
+		main := pkg.Func("main")
+		if main == nil {
+			continue
+		}
+		binary := parser.BinaryName(pkg.Pkg.Path())
+		g.Roots = append(g.Roots, Root{Binary: binary, Function: main.String()})
+		// 包初始化函数同样在服务启动时执行
+		b.walk(binary, main, pkg.Func("init"))
+	}
+
+	for _, n := range b.nodes {
+		sort.Strings(n.Binaries)
+		g.Nodes = append(g.Nodes, *n)
+	}
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Roots, func(i, j int) bool { return g.Roots[i].Function < g.Roots[j].Function })
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Caller != b.Caller {
+			return a.Caller < b.Caller
+		}
+		if a.Callee != b.Callee {
+			return a.Callee < b.Callee
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return g, nil
+}
+
+// builder 从每个 main 函数出发遍历调用图
+type builder struct {
+	root  string
+	fset  *token.FileSet
+	cg    *callgraph.Graph
+	local map[string]bool // 仓库内的包
+
+	nodes map[*ssa.Function]*Node
+	edges map[Edge]bool
+}
+
+// walk 标记从 starts 可达的仓库内函数属于 binary,只经过仓库内的函数
+func (b *builder) walk(binary string, starts ...*ssa.Function) {
+	seen := make(map[*ssa.Function]bool)
+	var queue []*ssa.Function
+	for _, fn := range starts {
+		if fn != nil && b.isLocal(fn) {
+			seen[fn] = true
+			queue = append(queue, fn)
+		}
+	}
+
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		b.node(fn).Binaries = append(b.node(fn).Binaries, binary)
+
+		cgNode := b.cg.Nodes[fn]
+		if cgNode == nil {
+			continue
+		}
+		for _, out := range cgNode.Out {
+			callee := out.Callee.Func
+			if !b.isLocal(callee) {
+				continue
+			}
+			pos := b.fset.Position(out.Pos())
+			b.edges[Edge{Caller: fn.String(), Callee: callee.String(), File: b.relative(pos.Filename), Line: pos.Line}] = true
+			if !seen[callee] {
+				seen[callee] = true
+				queue = append(queue, callee)
+			}
+		}
+	}
+}
+
+// node 返回函数对应的节点,不存在时创建
+func (b *builder) node(fn *ssa.Function) *Node {
+	if n, ok := b.nodes[fn]; ok {
+		return n
+	}
+	pos := b.fset.Position(fn.Pos())
+	n := &Node{
+		ID:      fn.String(),
+		Package: packagePath(fn),
+		File:    b.relative(pos.Filename),
+		Line:    pos.Line,
+	}
+	b.nodes[fn] = n
+	return n
+}
+
+// isLocal 判断函数是否属于仓库内的包
+func (b *builder) isLocal(fn *ssa.Function) bool {
+	return b.local[packagePath(fn)]
+}
+
+// relative 返回相对仓库根目录的路径
+func (b *builder) relative(file string) string {
+	if file == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(b.root, file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// packagePath 返回函数所属包的导入路径。泛型实例化使用原始函数的包,
+// 方法值等合成的包装函数使用被包装方法的包
+func packagePath(fn *ssa.Function) string {
+	if fn.Pkg != nil {
+		return fn.Pkg.Pkg.Path()
+	}
+	if origin := fn.Origin(); origin != nil && origin.Pkg != nil {
+		return origin.Pkg.Pkg.Path()
+	}
+	if obj := fn.Object(); obj != nil && obj.Pkg() != nil {
+		return obj.Pkg().Path()
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "shared-package-test")
+
+	g, err := Build(context.Background(), testProject)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(g.Roots) != 2 || g.Roots[0].Binary != "service-a" || g.Roots[1].Binary != "service-b" {
+		t.Errorf("Unexpected roots: %+v", g.Roots)
+	}
+
+	binaries := make(map[string]string)
+	for _, n := range g.Nodes {
+		binaries[n.ID] = strings.Join(n.Binaries, ",")
+	}
+	expected := map[string]string{
+		"example.com/shared-package-test/pkg/common.LogMessage":         "service-a,service-b",
+		"example.com/shared-package-test/internal/service-a.NewHandler": "service-a",
+		"example.com/shared-package-test/internal/service-b.NewHandler": "service-b",
+		// common.RunServer 通过 Runner 接口调用,CHA 会连接到所有实现
+		"(*example.com/shared-package-test/internal/service-a.Server).Run": "service-a,service-b",
+	}
+	for id, want := range expected {
+		if got, ok := binaries[id]; !ok {
+			t.Errorf("Node %s not found", id)
+		} else if got != want {
+			t.Errorf("Node %s binaries = %s, want %s", id, got, want)
+		}
+	}
+
+	for _, e := range g.Edges {
+		if _, ok := binaries[e.Caller]; !ok {
+			t.Errorf("Edge caller %s is not a node", e.Caller)
+		}
+		if _, ok := binaries[e.Callee]; !ok {
+			t.Errorf("Edge callee %s is not a node", e.Callee)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := &Graph{
+		Roots: []Root{{Binary: "api", Function: "example.com/app/cmd/api.main"}},
+		Edges: []Edge{
+			{Caller: "example.com/app/cmd/api.main", Callee: "example.com/app/pkg.Run", Line: 3},
+			{Caller: "example.com/app/cmd/api.main", Callee: "example.com/app/pkg.Run", Line: 4},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+	want := `digraph ripples {
+	rankdir=LR;
+	"example.com/app/cmd/api.main" [shape=box, label="api"];
+	"example.com/app/cmd/api.main" -> "example.com/app/pkg.Run";
+}
+`
+	if buf.String() != want {
+		t.Errorf("Unexpected DOT output:\n%s", buf.String())
+	}
+}
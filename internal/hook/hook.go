@@ -0,0 +1,121 @@
+// Package hook 安装和解析 ripples 使用的 git 钩子
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// PrePush 推送前运行的钩子
+const PrePush = "pre-push"
+
+// Names 支持安装的钩子
+var Names = []string{PrePush}
+
+// marker 标记由 ripples 安装的钩子,只有带有该标记的钩子可以被覆盖
+const marker = "# Installed by ripples hook install"
+
+// Install 在仓库的钩子目录中安装钩子 name,钩子调用 executable(不存在时使用 PATH 中的
+// ripples)的 "hook run"。已存在不是 ripples 安装的钩子时返回错误,force 为 true 时覆盖。
+// 返回钩子文件的路径
+func Install(repoPath, name, executable string, force bool) (string, error) {
+	if name != PrePush {
+		return "", i18n.Errorf("不支持的 git 钩子 %q，可选 %s", name, strings.Join(Names, "/"))
+	}
+	dir, err := git.HooksDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if data, err := os.ReadFile(path); err == nil && !force && !strings.Contains(string(data), marker) {
+		return "", i18n.Errorf("%s 已存在且不是 ripples 安装的钩子，使用 -force 覆盖", path)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", i18n.Errorf("写入钩子失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(script(name, executable)), 0o755); err != nil {
+		return "", i18n.Errorf("写入钩子失败: %w", err)
+	}
+	// 覆盖已有文件时 WriteFile 不修改权限
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", i18n.Errorf("写入钩子失败: %w", err)
+	}
+	return path, nil
+}
+
+// script 返回钩子 name 的脚本,git 传入的参数和标准输入原样交给 ripples
+func script(name, executable string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s %s
+# 跳过检查: git push --no-verify
+ripples=%s
+[ -x "$ripples" ] || ripples=ripples
+exec "$ripples" hook run %s "$@"
+`, marker, name, shellQuote(executable), name)
+}
+
+// shellQuote 用单引号包裹 s
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Update pre-push 钩子标准输入中的一行: 一个将被推送的引用
+type Update struct {
+	LocalRef  string
+	LocalSHA  string // 删除远程引用时为全 0
+	RemoteRef string
+	RemoteSHA string // 远程还没有该引用时为全 0
+}
+
+// Deletes 是否是删除远程引用
+func (u Update) Deletes() bool { return isZero(u.LocalSHA) }
+
+// Creates 是否是新建远程引用
+func (u Update) Creates() bool { return isZero(u.RemoteSHA) }
+
+// ParsePrePush 解析 pre-push 钩子的标准输入:
+// 每行为 "<local ref> <local sha> <remote ref> <remote sha>"
+func ParsePrePush(r io.Reader) ([]Update, error) {
+	var updates []Update
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, i18n.Errorf("无法解析 pre-push 输入: %q", scanner.Text())
+		}
+		updates = append(updates, Update{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取 pre-push 输入失败: %w", err)
+	}
+	return updates, nil
+}
+
+// Base 返回推送 u 时分析的旧 commit: 远程引用当前指向的 commit,远程还没有该引用
+// 或本地没有该 commit 时为当前分支的上游分支。都不可用时返回空字符串
+func Base(repoPath string, u Update) string {
+	if !u.Creates() {
+		if hash, err := git.ResolveCommit(repoPath, u.RemoteSHA); err == nil {
+			return hash
+		}
+	}
+	if hash, err := git.ResolveCommit(repoPath, "@{upstream}"); err == nil {
+		return hash
+	}
+	return ""
+}
+
+// isZero 是否是 git 表示不存在的对象的全 0 hash
+func isZero(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
@@ -0,0 +1,101 @@
+package hook
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const zero = "0000000000000000000000000000000000000000"
+
+func TestParsePrePush(t *testing.T) {
+	input := "refs/heads/main 1111111111111111111111111111111111111111 refs/heads/main 2222222222222222222222222222222222222222\n" +
+		"(delete) " + zero + " refs/heads/old 3333333333333333333333333333333333333333\n\n" +
+		"refs/heads/feat 4444444444444444444444444444444444444444 refs/heads/feat " + zero + "\n"
+	updates, err := ParsePrePush(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParsePrePush failed: %v", err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("Expected 3 updates, got %+v", updates)
+	}
+	if updates[0].RemoteRef != "refs/heads/main" || updates[0].Deletes() || updates[0].Creates() {
+		t.Errorf("Unexpected update %+v", updates[0])
+	}
+	if !updates[1].Deletes() || !updates[2].Creates() {
+		t.Errorf("Expected a deletion and a creation, got %+v", updates[1:])
+	}
+
+	if _, err := ParsePrePush(strings.NewReader("refs/heads/main 1111\n")); err == nil {
+		t.Error("Expected error for a malformed line")
+	}
+}
+
+func TestInstallAndBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "one")
+	first := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "two")
+	second := git("rev-parse", "HEAD")
+
+	path, err := Install(repo, PrePush, "/opt/it's/ripples", false)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0o111 == 0 {
+		t.Fatalf("Expected an executable hook at %s: %v %v", path, info, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `ripples='/opt/it'\''s/ripples'`) || !strings.Contains(string(data), "hook run pre-push") {
+		t.Errorf("Unexpected hook script:\n%s", data)
+	}
+	// 重新安装覆盖自己的钩子
+	if _, err := Install(repo, PrePush, "ripples", false); err != nil {
+		t.Errorf("Reinstall failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(repo, PrePush, "ripples", false); err == nil {
+		t.Error("Expected error when overwriting a foreign hook")
+	}
+	if _, err := Install(repo, PrePush, "ripples", true); err != nil {
+		t.Errorf("Install with force failed: %v", err)
+	}
+	if _, err := Install(repo, "pre-commit", "ripples", false); err == nil {
+		t.Error("Expected error for an unsupported hook")
+	}
+
+	// 远程引用已存在: 与远程的 commit 比较
+	if base := Base(repo, Update{LocalSHA: second, RemoteSHA: first}); base != first {
+		t.Errorf("Base = %q, want remote sha %s", base, first)
+	}
+	// 新分支且没有上游分支
+	if base := Base(repo, Update{LocalSHA: second, RemoteSHA: zero}); base != "" {
+		t.Errorf("Base = %q, want empty without upstream", base)
+	}
+	git("branch", "base", first)
+	git("branch", "--set-upstream-to", "base")
+	if base := Base(repo, Update{LocalSHA: second, RemoteSHA: zero}); base != first {
+		t.Errorf("Base = %q, want upstream %s", base, first)
+	}
+}
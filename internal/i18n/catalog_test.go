@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// TestCatalogComplete 确保源码中传给 i18n.* 和 logger.* 的中文信息以及命令行参数的
+// 说明都有英文翻译,否则 -lang en 时会输出中文
+func TestCatalogComplete(t *testing.T) {
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			var arg ast.Expr
+			switch pkg.Name {
+			case "i18n", "logger":
+				arg = call.Args[0]
+				// Fprintf 的第一个参数是输出目标
+				if sel.Sel.Name == "Fprintf" && len(call.Args) > 1 {
+					arg = call.Args[1]
+				}
+			case "flag":
+				// 参数说明在 usage 中经 T 翻译
+				if strings.HasSuffix(sel.Sel.Name, "Var") {
+					arg = call.Args[len(call.Args)-1]
+				}
+			}
+			lit, ok := arg.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil || !hasHan(msg) {
+				return true
+			}
+			if _, ok := en[msg]; !ok {
+				t.Errorf("%s: no English translation for %q", fset.Position(lit.Pos()), msg)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hasHan 判断字符串是否包含汉字
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
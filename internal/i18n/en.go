@@ -0,0 +1,474 @@
+package i18n
+
+// en 英文信息表
+var en = map[string]string{
+	// 通用
+	"不支持的语言: %s": "unsupported language: %s",
+	"错误: %v\n":   "Error: %v\n",
+
+	// 命令行参数
+	"Git 仓库路径":         "Git repository path",
+	"旧 commit ID (必填)": "Old commit ID (required)",
+	"新 commit ID (必填)": "New commit ID (required)",
+	"输出格式: simple, text, json, ndjson, summary, markdown, rdjson, bazel, cypher, workloads, workloads-json": "Output format: simple, text, json, ndjson, summary, markdown, rdjson, bazel, cypher, workloads, workloads-json",
+	"详细输出": "Verbose output",
+	"报告每个服务的所有调用链（默认只报告第一条）":        "Report every call path per service (default: first path only)",
+	"每个服务最多报告的调用链数量（隐含 -all-paths）": "Max call paths reported per service (implies -all-paths)",
+	"只输出错误日志": "Only log errors",
+	"日志级别: debug, info, warn, error (默认 warn，-verbose 时为 info)": "Log level: debug, info, warn, error (default warn, info with -verbose)",
+	"日志格式: text, json":            "Log format: text, json",
+	"输出语言: zh, en (默认根据 LANG 检测)": "Output language: zh, en (default: detected from LANG)",
+	"用法: %s [参数]\n":               "Usage: %s [flags]\n",
+
+	// 主流程
+	"错误: 必须指定 -old 和 -new 参数":     "Error: -old and -new are required",
+	"开始分析项目":                      "Starting analysis",
+	"步骤 1/6: 检测变更文件":              "Step 1/6: detecting changed files",
+	"获取 git diff 失败":              "Failed to get git diff",
+	"检测到变更文件":                     "Changed files detected",
+	"步骤 2/6: 初始化 Parser (只加载变更包)": "Step 2/6: initializing parser (changed packages only)",
+	"加载变更包失败，回退到加载整个项目":           "Failed to load changed packages, falling back to the whole project",
+	"加载项目失败":                      "Failed to load project",
+	"Parser 初始化完成":                "Parser initialized",
+	"当前模块":                        "Current module",
+	"步骤 3/6: 初始化 LSP 分析器 (gopls)": "Step 3/6: initializing LSP analyzer (gopls)",
+	"初始化 LSP 分析器失败":               "Failed to initialize LSP analyzer",
+	"LSP 分析器初始化完成":                "LSP analyzer initialized",
+	"步骤 4/6: 检测变更符号":              "Step 4/6: detecting changed symbols",
+	"检测变更失败":                      "Failed to detect changes",
+	"检测到变更符号":                     "Changed symbols detected",
+	"步骤 5/6: 追踪调用链到 main 函数":      "Step 5/6: tracing call chains to main",
+	"分析失败":                        "Analysis failed",
+	"调用链追踪完成":                     "Call chain tracing finished",
+	"步骤 6/6: 输出结果":                "Step 6/6: writing results",
+	"输出JSON失败":                    "Failed to write JSON",
+	"分析完成":                        "Analysis finished",
+
+	// 日志
+	"不支持的日志格式: %s": "unsupported log format: %s",
+	"不支持的日志级别: %s": "unsupported log level: %s",
+	"包加载错误":        "Package load error",
+
+	// 错误
+	"获取 git diff 失败: %w":      "failed to get git diff: %w",
+	"解析 diff 失败: %w":          "failed to parse diff: %w",
+	"git diff 失败: %w\n输出: %s": "git diff failed: %w\noutput: %s",
+	"加载项目失败: %w":              "failed to load project: %w",
+	"加载变更包失败: %w":             "failed to load changed packages: %w",
+	"部分包加载失败":                 "some packages failed to load",
+	"获取绝对路径失败: %w":            "failed to get absolute path: %w",
+	"未找到文件: %s":               "file not found: %s",
+	"未找到包: %s":                "package not found: %s",
+	"生成JSON失败: %w":            "failed to generate JSON: %w",
+	"生成YAML失败: %w":            "failed to generate YAML: %w",
+
+	// 报告
+	"✅ 未检测到受影响的服务。":                                         "✅ No affected services detected.",
+	"🔍 检测到 %d 个受影响的服务:\n":                                   "🔍 Detected %d affected service(s):\n",
+	"   变更符号: %d, 受影响服务: %d, 受影响包: %d, 调用点: %d, 最短路径: %d\n": "   Changed symbols: %d, affected services: %d, affected packages: %d, call sites: %d, shortest path: %d\n",
+	"   - %s [%s]: 服务 %d, 包 %d, 调用点 %d, 最短路径 %d\n":          "   - %s [%s]: services %d, packages %d, call sites %d, shortest path %d\n",
+	"受影响的服务: %d 个\n":                                        "Affected services: %d\n",
+	"影响范围: %d 个变更符号, %d 个包, %d 个调用点, 最短路径 %d\n":             "Blast radius: %d changed symbols, %d packages, %d call sites, shortest path %d\n",
+
+	// GitLab 集成与 Markdown 报告
+	"将报告发布为 GitLab 合并请求评论":                       "Post the report as a GitLab merge request note",
+	"GitLab 地址 (默认 $CI_SERVER_URL)":              "GitLab URL (default $CI_SERVER_URL)",
+	"GitLab 访问令牌 (默认 $GITLAB_TOKEN)":             "GitLab access token (default $GITLAB_TOKEN)",
+	"GitLab 项目 ID (默认 $CI_PROJECT_ID)":           "GitLab project ID (default $CI_PROJECT_ID)",
+	"GitLab 合并请求 IID (默认 $CI_MERGE_REQUEST_IID)": "GitLab merge request IID (default $CI_MERGE_REQUEST_IID)",
+	"受影响服务过多时添加的 MR 标签":                          "Merge request label added when too many services are affected",
+	"受影响服务数超过该值时添加评审标签，否则移除 (0 表示不修改标签)":         "Add the review label when affected services exceed this value, remove it otherwise (0 leaves labels untouched)",
+	"发布 GitLab 评论失败":                             "Failed to publish GitLab note",
+	"已发布 GitLab 评论":                              "Published GitLab note",
+	"GitLab 配置不完整: 需要 URL、token、项目 ID 和 MR IID":  "incomplete GitLab configuration: URL, token, project ID and MR IID are required",
+	"GitLab 请求失败: %w":                            "GitLab request failed: %w",
+	"GitLab 返回错误 %d: %s":                         "GitLab returned error %d: %s",
+	"解析 GitLab 响应失败: %w":                         "failed to parse GitLab response: %w",
+	"## ripples 影响分析":                            "## ripples impact analysis",
+	"检测到 **%d** 个受影响的服务。\n\n":                    "Detected **%d** affected service(s).\n\n",
+	"| 服务 | Main 包 | 可信度 | 风险 |":                 "| Service | Main package | Confidence | Risk |",
+	"调用链": "Call chains",
+
+	// GitHub Actions 集成
+	"写入 GitHub Actions job summary 并输出 affected-services":              "Write the GitHub Actions job summary and the affected-services output",
+	"写入 GitHub Actions 输出失败":                                           "Failed to write GitHub Actions outputs",
+	"未检测到 GitHub Actions 环境: GITHUB_STEP_SUMMARY 和 GITHUB_OUTPUT 均未设置": "GitHub Actions environment not detected: neither GITHUB_STEP_SUMMARY nor GITHUB_OUTPUT is set",
+
+	// reviewdog 输出
+	"%s 的变更影响 %d 个服务: %s": "Change to %s affects %d service(s): %s",
+
+	// Prometheus 指标
+	"Prometheus Pushgateway 地址，设置后推送分析指标": "Prometheus Pushgateway URL; pushes analysis metrics when set",
+	"推送指标使用的 Pushgateway job 名称":          "Pushgateway job name used when pushing metrics",
+	"推送 Prometheus 指标失败":                  "Failed to push Prometheus metrics",
+	"Pushgateway job 名称不能为空":              "Pushgateway job name must not be empty",
+	"推送指标失败: %w":                          "failed to push metrics: %w",
+	"Pushgateway 返回 %d: %s":               "Pushgateway returned %d: %s",
+
+	// 公共 API
+	"必须指定旧 commit 和新 commit": "both the old and the new commit must be specified",
+	"初始化 LSP 分析器失败: %w":      "failed to initialize the LSP analyzer: %w",
+	"检测变更失败: %w":             "failed to detect changes: %w",
+	"追踪调用链失败: %w":            "failed to trace call chains: %w",
+
+	// 流式输出
+	"发现受影响服务时立即以 NDJSON 逐行输出（忽略 -output）": "Emit each affected service as an NDJSON line as soon as it is found (ignores -output)",
+
+	// 配置文件
+	"无效的时长 %q: %w":     "invalid duration %q: %w",
+	"读取配置文件失败: %w":     "failed to read config file: %w",
+	"解析配置文件 %s 失败: %w": "failed to parse config file %s: %w",
+	"分析超时 (%s): %w":    "analysis timed out (%s): %w",
+	"排除变更文件":           "Excluding changed file",
+	"配置文件路径 (默认读取仓库根目录下的 ripples.yaml)": "Config file path (defaults to ripples.yaml at the repository root)",
+	"分析超时时间，如 5m (0 表示不限制)":             "Analysis timeout, e.g. 5m (0 means no limit)",
+
+	// 跨服务过滤
+	"服务边界模式，如 cmd/* 或 internal/* (可重复，覆盖配置文件)": "Service boundary pattern such as cmd/* or internal/* (repeatable, overrides the config file)",
+	"公共包前缀，如 foundation/ (可重复，覆盖配置文件)":         "Shared package prefix such as foundation/ (repeatable, overrides the config file)",
+	"根据服务边界和公共包过滤跨服务调用链":                       "Filter call paths that cross service boundaries using the service and common package rules",
+
+	// 部署映射
+	"| 服务 | Main 包 | 可信度 | 风险 | 部署 |": "| Service | Main package | Confidence | Risk | Deployment |",
+
+	// 负责人
+	"不支持的分组方式: %s":          "unsupported grouping: %s",
+	"(无负责人)":                "(no owner)",
+	"👥 负责人: %s (%d 个服务)\n":  "👥 Owner: %s (%d services)\n",
+	"报告分组方式: owner, team":   "Group the report by: owner, team",
+	"根据 CODEOWNERS 标注服务负责人": "Annotate services with owners from CODEOWNERS",
+	"读取 CODEOWNERS 失败: %w":  "failed to read CODEOWNERS: %w",
+	"输出结果失败":                "Failed to write the report",
+
+	// 多模块仓库
+	"跳过无法解析的 go.mod": "Skipping unparsable go.mod",
+	"查找模块失败: %w":     "failed to discover modules: %w",
+	"创建临时工作区失败: %w":  "failed to create a temporary workspace: %w",
+	"多模块仓库，使用临时工作区":  "Multi-module repository, using a temporary workspace",
+	"变更文件不属于任何模块，跳过": "Changed file belongs to no module, skipping",
+
+	// trace 子命令
+	"无效的符号: %s":      "invalid symbol: %s",
+	"未找到符号: %s":      "symbol not found: %s",
+	"步骤 1/6: 解析指定符号": "Step 1/6: resolving the given symbols",
+	"trace 子命令追踪的符号，如 internal/foo.Bar 或 internal/foo/bar.go:42 (可重复)": "symbol to trace with the trace subcommand, e.g. internal/foo.Bar or internal/foo/bar.go:42 (repeatable)",
+	"      %s trace -symbol <符号> [参数]\n":                               "      %s trace -symbol <symbol> [flags]\n",
+	"错误: -symbol 只能用于 trace 子命令":                                       "Error: -symbol can only be used with the trace subcommand",
+	"错误: trace 子命令必须指定 -symbol 参数":                                     "Error: the trace subcommand requires -symbol",
+	"错误: 未知子命令 %s\n":                                                   "Error: unknown subcommand %s\n",
+	"开始追踪符号":                                                           "Tracing symbols",
+
+	// binaries 子命令
+	"      %s binaries [参数]\n": "      %s binaries [flags]\n",
+	"列出可执行程序失败":                "Failed to list binaries",
+	"名称\t目录\t模块\t可追踪":          "NAME\tDIR\tMODULE\tTRACED",
+
+	// graph 子命令
+	"graph 子命令的输出格式: dot, json":                "output format of the graph subcommand: dot, json",
+	"      %s graph [-format dot|json] [参数]\n": "      %s graph [-format dot|json] [flags]\n",
+	"构建调用图失败":                                  "Failed to build the call graph",
+	"输出调用图失败":                                  "Failed to write the call graph",
+	"不支持的格式: %s":                               "unsupported format: %s",
+
+	// diff-report 子命令
+	"      %s diff-report [参数] <旧报告.json> <新报告.json>\n": "      %s diff-report [flags] <old-report.json> <new-report.json>\n",
+	"错误: diff-report 需要两个报告文件":                          "Error: diff-report requires two report files",
+	"比较报告失败":             "Failed to compare reports",
+	"读取报告失败: %w":         "failed to read report: %w",
+	"解析报告 %s 失败: %w":     "failed to parse report %s: %w",
+	"受影响的服务: %d -> %d\n": "Affected services: %d -> %d\n",
+	"✅ 受影响的服务没有变化。":      "✅ Affected services are unchanged.",
+	"新增受影响 (%d):\n":      "Newly affected (%d):\n",
+	"不再受影响 (%d):\n":      "No longer affected (%d):\n",
+	"可信度变化 (%d):\n":      "Confidence changed (%d):\n",
+
+	// stats 子命令
+	"stats 子命令统计的时间范围，如 90d、12w 或 2024-01-01": "time range of the stats subcommand, e.g. 90d, 12w or 2024-01-01",
+	"stats 子命令缓存每个提交分析报告的目录 (为空时不缓存)":         "directory where the stats subcommand caches per-commit reports (empty disables caching)",
+	"      %s stats [-since 90d] [参数]\n":      "      %s stats [-since 90d] [flags]\n",
+	"开始统计历史提交":                                "Collecting history statistics",
+	"统计失败":                                    "Failed to collect statistics",
+	"分析了 %d 个提交，其中 %d 个影响多个服务\n":              "Analyzed %d commits, %d of them affected multiple services\n",
+	"⚠️  %d 个提交分析失败\n":                        "⚠️  %d commits failed to analyze\n",
+	"包\t提交数\t最多服务数\t服务":                       "PACKAGE\tCOMMITS\tMAX SERVICES\tSERVICES",
+	"分析历史提交":                                  "Analyzing commit",
+	"分析历史提交失败":                                "Failed to analyze commit",
+	"写入报告缓存失败":                                "Failed to write the report cache",
+	"git log 失败: %w":                          "git log failed: %w",
+	"创建 git worktree 失败: %w\n输出: %s":          "failed to create git worktree: %w\noutput: %s",
+	"删除 git worktree 失败: %w\n输出: %s":          "failed to remove git worktree: %w\noutput: %s",
+	"删除 git worktree 失败":                      "Failed to remove git worktree",
+
+	// 常量值
+	"git show 失败: %w":    "git show failed: %w",
+	"     值: %s -> %s\n": "     value: %s -> %s\n",
+	"(无)":                "(none)",
+	"常量变更:":              "Constant changes:",
+	" (值: %s -> %s)":     " (value: %s -> %s)",
+
+	// 提升方法
+	"加载嵌入关系失败": "Failed to load struct embeddings",
+
+	// 接口实现破坏
+	"检查接口实现是否被破坏":             "Checking for broken interface implementations",
+	"检查接口实现失败":                "Failed to check interface implementations",
+	"获取变更文件失败: %w":            "failed to list changed files: %w",
+	"⚠️ 接口实现被破坏 (%d):":        "⚠️ Broken interface implementations (%d):",
+	"%s 不再满足 %s (%s 被删除)":     "%s no longer implements %s (%s removed)",
+	"%s 不再满足 %s (%s 签名变化)":    "%s no longer implements %s (%s signature changed)",
+	"%s 不再满足 %s (缺少接口新增的 %s)": "%s no longer implements %s (missing %s added to the interface)",
+	"     位置: %s:%d\n":        "     Location: %s:%d\n",
+	"     无法编译的服务: %s\n":      "     Services failing to compile: %s\n",
+	", 无法编译的服务: %s":           ", services failing to compile: %s",
+
+	// 包内快速路径
+	"包内可达性分析": "Package-local reachability",
+
+	// 无运行时影响的变更
+	"💤 无运行时影响 / 可能是死代码 (%d):": "💤 No runtime impact / possibly dead code (%d):",
+	"没有被引用":         "not referenced",
+	"只在测试中被引用":      "only referenced from tests",
+	"引用它的函数同样不会被执行": "only referenced by functions that never run either",
+
+	// 文件过滤参数
+	"排除的文件路径模式，如 gen/** 或 **/*_mock.go (可重复，覆盖配置文件)":        "File path pattern to exclude such as gen/** or **/*_mock.go (repeatable, overrides the config file)",
+	"只分析匹配的文件路径模式，如 internal/billing/** (可重复，覆盖配置文件)":       "Only analyze files matching this path pattern such as internal/billing/** (repeatable, overrides the config file)",
+	"分析带有 \"Code generated ... DO NOT EDIT.\" 头的生成文件（默认跳过）": "Analyze generated files with a \"Code generated ... DO NOT EDIT.\" header (skipped by default)",
+
+	// .ripplesignore
+	"读取忽略文件失败: %w":          "failed to read ignore file: %w",
+	"%s 第 %d 行: 无效的符号模式 %q": "%s line %d: invalid symbol pattern %q",
+	"排除变更符号":                "Excluding changed symbol",
+
+	// 自定义入口
+	"查找自定义入口失败":       "Failed to find custom entrypoints",
+	"未找到配置的入口函数":      "Configured entrypoint function not found",
+	"git grep 失败: %w": "git grep failed: %w",
+
+	// 定时任务
+	"查找任务函数失败":   "Failed to find job functions",
+	"未找到配置的任务函数": "Configured job function not found",
+	"任务配置的服务不存在": "Service configured for a job not found",
+	"受影响的定时任务:":  "Affected jobs:",
+
+	// 受影响的路由
+	"报告每个服务受影响的 HTTP 路由和 gRPC 方法": "report the affected HTTP routes and gRPC methods of each service",
+	"受影响的接口:":            "Affected routes:",
+	"| 服务 | 接口 | 处理函数 |": "| Service | Route | Handler |",
+
+	// 受影响的子命令
+	"报告每个服务受影响的 cobra/urfave-cli 子命令": "report the affected cobra/urfave-cli subcommands of each service",
+	"受影响的子命令:":                        "Affected subcommands:",
+
+	// cmd/ 之外的 main 包
+	"查找 main 包失败": "Failed to find main packages",
+
+	// 接口过滤模式
+	"跨服务过滤模式: strict (无法类型检查的调用视为接口调用)、loose (只过滤确定的接口调用) 或 off": "cross-service filter mode: strict (calls that cannot be type-checked count as interface calls), loose (only prune proven interface calls) or off",
+	"不支持的接口过滤模式: %s": "unsupported interface filter mode: %s",
+
+	// 目标服务
+	"只分析的服务，如 cmd/api,cmd/worker 或服务名 (逗号分隔或重复，覆盖配置文件)": "only analyze these services, e.g. cmd/api,cmd/worker or service names (comma-separated or repeated, overrides the config file)",
+	"目标 %s 没有匹配的服务": "target %s matches no service",
+	"目标服务":          "Target services",
+
+	// 未追踪的符号
+	"   - %s [%s]: 未追踪, %s\n": "   - %s [%s]: not traced, %s\n",
+	"所有目标服务均已受影响":             "all target services already affected",
+	"所有服务均已受影响":               "all services already affected",
+
+	// 扇出上限
+	"调用者扇出上限，超过时按导入包的服务近似报告 (0 表示不限制)": "caller fan-out limit; symbols exceeding it are reported for every service importing their package, marked approximate (0 means no limit)",
+
+	// 导入图分析
+	"分析模式: calls (追踪调用层级) 或 imports (按导入图快速近似，不启动 gopls)": "analysis mode: calls (trace the call hierarchy) or imports (fast approximation from the import graph, without gopls)",
+	"不支持的分析模式: %s": "unsupported analysis mode: %s",
+	"导入图模式: 加载导入图": "Imports mode: loading the import graph",
+	"导入图加载完成":      "Import graph loaded",
+	"导入图分析完成":      "Import graph analysis done",
+
+	// 追踪策略
+	"追踪方向: reverse (从变更符号向上)、forward (从 main 函数向下) 或 auto (按扇出自动选择)": "tracing direction: reverse (up from changed symbols), forward (down from main functions) or auto (chosen by fan-out)",
+	"不支持的追踪策略: %s": "unsupported tracing strategy: %s",
+
+	// 加载失败的包
+	"⚠️ 分析不完整: 包 %s 加载失败": "⚠️ Analysis incomplete: package %s failed to load",
+	"     错误: %s\n":       "     Error: %s\n",
+
+	// 稀疏检出
+	"⚠️ 分析不完整: %s 不在稀疏检出范围内,其中的变更未分析": "⚠️ Analysis incomplete: %s is outside the sparse checkout, its changes were not analyzed",
+	"变更的包不在稀疏检出范围内,未分析":               "Changed packages outside the sparse checkout were not analyzed",
+	"go.work 中的模块不在工作区中,只分析已检出的模块":    "Modules in go.work are not checked out, analyzing the checked-out modules only",
+	"检查稀疏检出失败":                        "Failed to check the sparse checkout",
+	"git ls-files 失败: %w":             "git ls-files failed: %w",
+
+	// 影响未知
+	"❓ 影响未知 (%d):": "❓ Unknown impact (%d):",
+
+	// 时间预算
+	"⏱️ 时间预算耗尽,未分析 (%d):": "⏱️ Time budget exhausted, not analyzed (%d):",
+	"时间预算耗尽":              "time budget exhausted",
+	"时间预算耗尽,跳过接口实现检查":     "Time budget exhausted, skipping the interface implementation check",
+	"时间预算，如 2m，耗尽时返回已找到的结果并列出未分析的符号 (0 表示不限制)": "time budget, e.g. 2m; when it runs out, report what was found and list the symbols not analyzed (0 means no limit)",
+	"分析结果不完整": "Analysis result is incomplete",
+	"有包加载失败、变更包不在稀疏检出范围内或变更符号追踪失败(影响未知)时以非零状态退出": "exit with a non-zero status when packages fail to load, changed packages are outside the sparse checkout or changed symbols fail to trace (unknown impact)",
+
+	// 构建配置
+	"加载包时使用的构建参数，如 \"-mod=vendor -tags=integration\" (覆盖配置文件)": "build flags used to load packages, e.g. \"-mod=vendor -tags=integration\" (overrides the config file)",
+	"分析期间设置的环境变量，如 GOFLAGS=-mod=vendor (可重复，覆盖配置文件)":           "environment variable set during analysis, e.g. GOFLAGS=-mod=vendor (repeatable, overrides the config file)",
+	"无效的环境变量: %s (需要 KEY=VALUE 形式)":                            "invalid environment variable: %s (expected KEY=VALUE)",
+	"无效的构建参数: %s (需要 -flag=value 形式)":                          "invalid build flag: %s (expected -flag=value)",
+
+	// 指定变更文件和符号
+	"变更文件列表 (每行一个路径)，设置后不读取 git diff":                         "file listing the changed files, one path per line; git diff is not read when set",
+	"JSON 格式的变更符号列表，如 [\"internal/foo.Bar\"]，设置后不读取 git diff": "JSON file listing the changed symbols, e.g. [\"internal/foo.Bar\"]; git diff is not read when set",
+	"读取变更文件列表失败: %w":                                          "failed to read the changed file list: %w",
+	"读取符号列表失败: %w":                                            "failed to read the symbol list: %w",
+	"跳过不存在的变更文件":                                              "Skipping changed file that does not exist",
+
+	// 标准输入 diff
+	"从标准输入读取 diff (如 git diff A B 的输出)，不调用 git diff": "read the diff from standard input (e.g. the output of git diff A B) instead of running git diff",
+	"读取标准输入失败": "Failed to read standard input",
+
+	// 变更类型
+	"符号已删除": "symbol deleted",
+	"新增":    "added",
+	"删除":    "deleted",
+
+	// report granularity
+	"报告粒度: binary (只报告服务) 或 package (同时报告调用链经过的包)": "report granularity: binary (binaries only) or package (also the packages on the call paths)",
+	"不支持的报告粒度: %s":  "unsupported report granularity: %s",
+	"📦 受影响的包 (%d):": "📦 Affected packages (%d):",
+
+	// enum comparisons
+	"     比较同类型常量: %s\n":       "     compares a constant of the same type: %s\n",
+	"`%s` 的值变化可能影响比较同类型常量的代码:": "A value change of `%s` may affect code comparing constants of the same type:",
+
+	// risk
+	"只报告风险分数不低于该值的服务 (0-100)": "only report binaries whose risk score is at least this (0-100)",
+	" (风险 %d)": " (risk %d)",
+
+	// coverage
+	"读取覆盖率文件失败: %w": "failed to read the coverage profile: %w",
+	"go test -coverprofile 生成的覆盖率文件，用于标注变更代码的测试覆盖率": "coverage profile written by go test -coverprofile, used to report the test coverage of changed code",
+	"     测试覆盖率: %s\n":  "     test coverage: %s\n",
+	"未被测试覆盖":            "not covered by tests",
+	" (测试覆盖率: %s)":      " (test coverage: %s)",
+	"变更代码的测试覆盖率:":       "Test coverage of the changed code:",
+	"未被测试覆盖的变更:":        "Changes not covered by tests:",
+	"解析覆盖率文件 %s 失败: %w": "failed to parse the coverage profile %s: %w",
+	"读取覆盖率目录失败: %w":     "failed to read the coverage directory: %w",
+	"每个测试一个覆盖率文件的目录，用于列出执行过变更行的测试":                 "directory of coverage profiles, one per test, used to list the tests executing changed lines",
+	"🧪 执行变更代码的测试 (%d):":                            "🧪 Tests executing the changed code (%d):",
+	"   - %s: %d 行 (%s)\n":                         "   - %s: %d lines (%s)\n",
+	"   重新运行:":                                     "   Re-run:",
+	"- `%s`: %d 行 (%s)":                            "- `%s`: %d lines (%s)",
+	"⚠️ 服务 %s 没有对应的 Bazel 目标\n":                    "⚠️ binary %s has no Bazel target\n",
+	"错误: 不支持的 CI 平台 %q，可选 %s\n":                    "Error: unsupported CI platform %q, choose one of %s\n",
+	"错误: 不支持的输出格式 %q，可选 %s\n":                      "Error: unsupported output format %q, choose one of %s\n",
+	"不支持的输出格式 %q，可选 %s":                            "unsupported output format %q, choose one of %s",
+	"      %s ci gitlab|buildkite|circleci [参数]\n": "      %s ci gitlab|buildkite|circleci [flags]\n",
+	"生成 CI 流水线失败":                                  "failed to generate the CI pipeline",
+	"配置文件中缺少流水线作业模板 ci.%s.jobs":                    "the config has no pipeline job template ci.%s.jobs",
+	"ci.%s.header 必须是映射":                           "ci.%s.header must be a mapping",
+	"ci.%s.jobs 必须是映射":                             "ci.%s.jobs must be a mapping",
+	"ci.%s.jobs 必须是列表":                             "ci.%s.jobs must be a list",
+	"ci.circleci.header 中已有工作流 %s":                 "ci.circleci.header already defines the workflow %s",
+	"渲染服务 %s 的作业失败: %w":                            "failed to render the jobs of %s: %w",
+	"流水线中的 %s 重复,作业名应包含 {{.Name}} 等服务相关的内容":        "duplicate %s in the pipeline, job names should contain service fields such as {{.Name}}",
+	"解析索引文件 %s 失败: %w":                             "failed to parse the index file %s: %w",
+	"索引文件 %s 的版本为 %d,需要重新生成":                       "the index file %s has version %d and must be regenerated",
+	"计算文件哈希失败: %w":                                 "failed to hash files: %w",
+	"生成索引失败: %w":                                   "failed to build the index: %w",
+	"写入索引失败: %w":                                   "failed to write the index: %w",
+	"生成索引失败":                                       "failed to build the index",
+	"已写入 %s: %d 个包，%d 个符号，重新解析了 %d 个包\n":           "Wrote %s: %d packages, %d symbols, %d packages reparsed\n",
+	"      %s index [-index 文件] [参数]\n":            "      %s index [-index file] [flags]\n",
+	"符号索引文件 (默认为仓库根目录下的 .ripples-index，存在时自动使用)": "symbol index file (default .ripples-index in the repository root, used when present)",
+	"使用符号索引":                    "Using the symbol index",
+	"读取索引失败,不使用索引":              "Failed to read the index, not using it",
+	"更新索引失败,不使用索引":              "Failed to update the index, not using it",
+	"构建环境或 go.mod 已变化,重新生成整个索引": "Build environment or go.mod changed, rebuilding the whole index",
+	"索引包时出错":                    "Error indexing package",
+	"开始生成符号索引":                  "Building the symbol index",
+	"输出各阶段耗时、gopls 请求等性能数据的格式: json (与分析报告分开输出)": "format of performance data such as phase durations and gopls requests: json (written separately from the report)",
+	"性能数据的输出文件 (默认输出到 stderr)":                   "file to write performance data to (default stderr)",
+	"错误: 不支持的性能数据格式 %q，可选 json\n":                "Error: unsupported timings format %q, expected json\n",
+	"写入性能数据失败": "Failed to write performance data",
+	"说明每个服务受影响的原因: 调用链上每一步调用的文件和行号，以及变更符号所在的 diff hunk":            "explain why each service is affected: the file and line of every call on its paths and the diff hunks of the changed symbol",
+	"写入运行清单的文件，如 ripples.lock (记录解析后的 commit、工具版本、配置哈希、分析后端和结果哈希)": "File to write the run manifest to, e.g. ripples.lock (resolved commits, tool version, config hash, analysis backend and result digest)",
+	"写入运行清单失败":            "Failed to write run manifest",
+	"解析 commit %s 失败: %w": "failed to resolve commit %s: %w",
+	"init 覆盖已存在的配置文件，hook install 覆盖已存在的、不是 ripples 安装的 git 钩子": "Make init overwrite an existing config file and hook install overwrite an existing git hook not installed by ripples",
+	"      %s hook install pre-push [-force] [参数]\n":            "      %s hook install pre-push [-force] [flags]\n",
+	"错误: hook 子命令的用法为 hook install|run <钩子名>":                   "Error: usage of the hook subcommand is hook install|run <hook name>",
+	"已安装 %s 钩子: %s\n":      "Installed %s hook: %s\n",
+	"安装钩子失败":               "Failed to install hook",
+	"运行钩子失败":               "Failed to run hook",
+	"不支持的 git 钩子 %q，可选 %s": "unsupported git hook %q, expected %s",
+	"%s 已存在且不是 ripples 安装的钩子，使用 -force 覆盖": "%s exists and was not installed by ripples, use -force to overwrite it",
+	"写入钩子失败: %w":                   "failed to write hook: %w",
+	"无法解析 pre-push 输入: %q":         "cannot parse pre-push input: %q",
+	"读取 pre-push 输入失败: %w":         "failed to read pre-push input: %w",
+	"查找 git 钩子目录失败: %w":            "failed to find the git hooks directory: %w",
+	"无法确定推送的比较基准，跳过":               "Cannot determine the base of the push, skipping",
+	"分析推送的影响失败":                    "Failed to analyze the impact of the push",
+	"ripples: 此次推送不影响任何服务":         "ripples: this push affects no services",
+	"ripples: 此次推送影响 %d 个服务: %s\n": "ripples: this push affects %d services: %s\n",
+	"ripples: 受影响的服务数超过上限 %d，已拒绝推送 (git push --no-verify 可跳过检查)\n": "ripples: more than %d services affected, push rejected (git push --no-verify skips the check)\n",
+	"      %s init [-force] [参数]\n": "      %s init [-force] [flags]\n",
+	"生成配置文件失败":                      "Failed to generate config file",
+	"%s 已存在，使用 -force 覆盖":           "%s exists, use -force to overwrite it",
+	"已写入 %s: %d 个服务，%d 个模块\n":       "Wrote %s: %d services, %d modules\n",
+	"%s 中没有 go.mod":                 "no go.mod in %s",
+	"扫描仓库失败: %w":                    "failed to scan repository: %w",
+	"ripples 配置文件，由 ripples init 根据仓库结构生成，请按需调整": "ripples config file, generated by ripples init from the repository layout; adjust as needed",
+	"命令行参数优先于配置文件，完整的字段说明见 README":               "Command-line flags take precedence over this file, see the README for all fields",
+	"仓库中的模块:": "Modules in the repository:",
+	"服务边界: 相对模块根目录的包路径模式，\"*\" 匹配的最后一段为服务名":                      "Service boundaries: package path patterns relative to the module root, the segment matched by \"*\" is the service name",
+	"公共包前缀（相对模块根目录），不属于任何服务":                                     "Common package prefixes (relative to the module root), not part of any service",
+	"作为服务入口的 main 包目录（相对仓库根目录）":                                  "main package directories used as service entrypoints (relative to the repository root)",
+	".proto 文件不参与分析，生成的 Go 代码默认跳过（-include-generated 可以分析）:":     ".proto files are not analyzed, and generated Go code is skipped by default (-include-generated analyzes it):",
+	"服务到部署标识的映射，键为 main 包目录或服务名":                                 "Service to deployment mapping, keyed by main package directory or service name",
+	"服务到负责团队的映射，优先于 CODEOWNERS":                                  "Service to owning team mapping, takes precedence over CODEOWNERS",
+	"服务到 Bazel 目标的映射，未配置的服务读取 main 包目录下 BUILD 文件中的 go_binary 规则": "Service to Bazel target mapping, services not listed use the go_binary rule in the BUILD file of the main package directory",
+	"👥 团队: %s (%d 个服务)\n":                                        "👥 Team: %s (%d services)\n",
+	"(无团队)":                                                      "(no team)",
+	"按团队拆分报告，每个团队一个文件写入该目录 (格式由 -output 决定)":                     "Split the report by team and write one file per team into this directory (format set by -output)",
+	"流式输出和 CI 流水线不支持按团队拆分报告":                                     "Splitting reports by team is not supported for streaming output or CI pipelines",
+	"写入团队报告失败":                                                   "Failed to write team report",
+	"已写入团队报告":                                                    "Wrote team report",
+	"分析范围: `%s..%s`，%d 个提交":                                      "Range: `%s..%s`, %d commits",
+	"提交":                                                         "Commits",
+	"变更符号最后修改于:":                                                 "Changed symbols last modified in:",
+	"读取提交信息失败":                                                   "Failed to read commit information",
+	"git blame 失败":                                               "git blame failed",
+	"用 git blame 标注最后修改每个变更符号的提交 (json/markdown)":                "Annotate each changed symbol with the commits that last modified it via git blame (json/markdown)",
+	"变更代码": "Changed code",
+	"在报告中附上每个变更符号的变更行及其前后几行代码 (json/markdown)":                 "Include the changed lines of each changed symbol with a few lines of context in the report (json/markdown)",
+	"stats 子命令跳过的 Conventional Commits 提交类型，如 docs,chore,test": "Conventional Commits types skipped by the stats subcommand, e.g. docs,chore,test",
+	"按提交类型跳过了 %d 个提交\n":                                        "Skipped %d commits by commit type\n",
+	"跳过提交": "Skipping commit",
+	"计算变更包的反向依赖失败: %w":                    "failed to compute the reverse dependencies of the changed packages: %w",
+	"列出包时出错,反向依赖可能不完整":                    "Error listing packages, reverse dependencies may be incomplete",
+	"变更包及其反向依赖":                           "Changed packages and their reverse dependencies",
+	"GOPATH 模式项目":                         "GOPATH mode project",
+	"枚举常量值变化":                             "Enum constant value changed",
+	"git blame %s 失败: %w":                 "git blame %s failed: %w",
+	"go/defer 调用":                         "go/defer calls",
+	"包初始化时登记":                             "Registered during package initialization",
+	"写入分析历史失败":                            "Failed to write analysis history",
+	"写入分析历史失败: %w":                        "failed to write analysis history: %w",
+	"打开分析历史失败: %w":                        "failed to open analysis history: %w",
+	"查询分析历史失败":                            "Failed to query analysis history",
+	"查询分析历史失败: %w":                        "failed to query analysis history: %w",
+	"commit 前缀 %s 有歧义，匹配 %d 个 commit: %v": "commit prefix %s is ambiguous, it matches %d commits: %v",
+	"记录分析历史的 SQLite 数据库，如 results.db (记录 commit、受影响的服务和耗时)，query 子命令从中读取":          "SQLite database recording the analysis history, e.g. results.db (records the commits, affected services and durations); read by the query subcommand",
+	"      %s query runs|services|diff -store <文件> [参数] [<旧 commit> <新 commit>]\n": "      %s query runs|services|diff -store <file> [flags] [<old commit> <new commit>]\n",
+	"必须指定 -store 参数":                  "the -store flag is required",
+	"query diff 需要两个 commit":          "query diff needs two commits",
+	"时间\t旧 commit\t新 commit\t耗时\t服务":  "TIME\tOLD COMMIT\tNEW COMMIT\tDURATION\tSERVICES",
+	"服务\t受影响次数\t最高风险\t最近的 commit":     "SERVICE\tRUNS AFFECTED\tMAX RISK\tLAST COMMIT",
+	"没有 commit %s 的分析记录":              "no analysis recorded for commit %s",
+	"不支持的查询 %q，可选 runs/services/diff": "unsupported query %q, choose runs/services/diff",
+}
@@ -0,0 +1,90 @@
+// Package i18n 提供用户可见信息的多语言支持。
+//
+// 源码中的中文信息即为消息 ID,T 在英文模式下返回对应的英文翻译,
+// 未收录的信息原样返回。
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+var current atomic.Value
+
+func init() {
+	current.Store(Detect())
+}
+
+// Detect 根据 LC_ALL、LC_MESSAGES、LANG 环境变量检测语言,默认中文
+func Detect() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := strings.ToLower(os.Getenv(key))
+		if v == "" {
+			continue
+		}
+		if strings.HasPrefix(v, "en") {
+			return LangEN
+		}
+		if strings.HasPrefix(v, "zh") {
+			return LangZH
+		}
+	}
+	return LangZH
+}
+
+// SetLang 设置当前语言
+func SetLang(lang string) error {
+	switch strings.ToLower(lang) {
+	case LangZH, "zh_cn", "zh-cn":
+		current.Store(LangZH)
+	case LangEN, "en_us", "en-us":
+		current.Store(LangEN)
+	default:
+		return fmt.Errorf(T("不支持的语言: %s"), lang)
+	}
+	return nil
+}
+
+// Lang 返回当前语言
+func Lang() string {
+	return current.Load().(string)
+}
+
+// T 翻译信息
+func T(msg string) string {
+	if Lang() == LangEN {
+		if translated, ok := en[msg]; ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// Sprintf 翻译格式串后格式化
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Printf 翻译格式串后输出到 stdout
+func Printf(format string, args ...any) {
+	fmt.Printf(T(format), args...)
+}
+
+// Fprintf 翻译格式串后输出到 w
+func Fprintf(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, T(format), args...)
+}
+
+// Errorf 翻译格式串后创建错误,支持 %w
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalogVerbsMatch 确保译文与原文的格式化动词一致
+func TestCatalogVerbsMatch(t *testing.T) {
+	for zh, translated := range en {
+		want := verbPattern.FindAllString(zh, -1)
+		got := verbPattern.FindAllString(translated, -1)
+		if len(want) != len(got) {
+			t.Errorf("verb count mismatch for %q: %v vs %v", zh, want, got)
+			continue
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				t.Errorf("verb mismatch for %q: %v vs %v", zh, want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLang(LangZH)
+
+	if err := SetLang(LangEN); err != nil {
+		t.Fatalf("SetLang failed: %v", err)
+	}
+	if got := T("分析失败"); got != "Analysis failed" {
+		t.Errorf("T() = %q, want English translation", got)
+	}
+	if got := T("untranslated"); got != "untranslated" {
+		t.Errorf("T() should return unknown messages unchanged, got %q", got)
+	}
+
+	if err := SetLang(LangZH); err != nil {
+		t.Fatalf("SetLang failed: %v", err)
+	}
+	if got := T("分析失败"); got != "分析失败" {
+		t.Errorf("T() = %q, want original message", got)
+	}
+
+	if err := SetLang("fr"); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Detect(); got != LangEN {
+		t.Errorf("Detect() = %s, want en", got)
+	}
+
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	if got := Detect(); got != LangZH {
+		t.Errorf("Detect() = %s, want zh", got)
+	}
+
+	t.Setenv("LANG", "C.UTF-8")
+	if got := Detect(); got != LangZH {
+		t.Errorf("Detect() = %s, want default zh", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Package logger 提供输出到 stderr 的分级日志,保证 stdout 只包含分析结果
+package logger
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Config 日志配置
+type Config struct {
+	Level  string // debug, info, warn, error
+	Format string // text, json
+	Quiet  bool   // 只输出错误
+}
+
+var (
+	mu     sync.RWMutex
+	level  = new(slog.LevelVar)
+	logger = newLogger(os.Stderr, "text")
+)
+
+func init() {
+	level.Set(slog.LevelWarn)
+}
+
+func newLogger(w io.Writer, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Setup 根据配置初始化日志
+func Setup(cfg Config) error {
+	lvl, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	if cfg.Quiet {
+		lvl = slog.LevelError
+	}
+
+	switch cfg.Format {
+	case "", "text", "json":
+	default:
+		return i18n.Errorf("不支持的日志格式: %s", cfg.Format)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	level.Set(lvl)
+	logger = newLogger(os.Stderr, cfg.Format)
+	return nil
+}
+
+// ParseLevel 解析日志级别
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "", "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelWarn, i18n.Errorf("不支持的日志级别: %s", s)
+	}
+}
+
+func get() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// Enabled 判断指定级别是否会输出
+func Enabled(lvl slog.Level) bool {
+	return lvl >= level.Level()
+}
+
+// Debug 输出调试日志
+func Debug(msg string, args ...any) { get().Debug(i18n.T(msg), args...) }
+
+// Info 输出信息日志
+func Info(msg string, args ...any) { get().Info(i18n.T(msg), args...) }
+
+// Warn 输出警告日志
+func Warn(msg string, args ...any) { get().Warn(i18n.T(msg), args...) }
+
+// Error 输出错误日志
+func Error(msg string, args ...any) { get().Error(i18n.T(msg), args...) }
+
+// RedirectStdout 将 os.Stdout 的输出转为警告日志,返回恢复函数。
+// 用于屏蔽依赖库(如 gopls 追踪器)直接打印到 stdout 的诊断信息。
+func RedirectStdout() (restore func()) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+
+	orig := os.Stdout
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			Warn(strings.TrimPrefix(line, "Warning: "), "source", "stdout")
+		}
+	}()
+
+	return func() {
+		os.Stdout = orig
+		w.Close()
+		<-done
+		r.Close()
+	}
+}
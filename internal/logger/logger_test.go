@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelWarn, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSetupQuiet(t *testing.T) {
+	defer Setup(Config{})
+
+	if err := Setup(Config{Level: "debug", Quiet: true}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if Enabled(slog.LevelWarn) {
+		t.Error("Quiet mode should suppress warnings")
+	}
+	if !Enabled(slog.LevelError) {
+		t.Error("Quiet mode should keep errors")
+	}
+
+	if err := Setup(Config{Format: "xml"}); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestRedirectStdout(t *testing.T) {
+	orig := os.Stdout
+	restore := RedirectStdout()
+	if os.Stdout == orig {
+		t.Fatal("Expected os.Stdout to be replaced")
+	}
+	fmt.Println("Warning: something happened")
+	restore()
+
+	if os.Stdout != orig {
+		t.Error("Expected os.Stdout to be restored")
+	}
+}
@@ -0,0 +1,255 @@
+package lsp
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// commandFuncFields are the fields of cobra.Command and urfave/cli Command holding
+// the functions a command runs
+var commandFuncFields = map[string]bool{
+	"Run": true, "RunE": true, "PreRun": true, "PreRunE": true, "PostRun": true, "PostRunE": true,
+	"PersistentPreRun": true, "PersistentPreRunE": true, "PersistentPostRun": true, "PersistentPostRunE": true,
+	"Action": true, "Before": true, "After": true,
+}
+
+// maxCommandDepth bounds the parent commands resolved for a subcommand
+const maxCommandDepth = 8
+
+// command recognizes ref as part of the function a cobra or urfave/cli command
+// runs, e.g. RunE: runMigrate or Action: func(c *cli.Context) error { ... }, and
+// returns the full name of the subcommand such as "db migrate". The top-level
+// command is the binary itself and is left out unless ref belongs to it
+func (w *registrationWalk) command(ref reference) (string, bool) {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for i, n := range path {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok || !contains(kv.Value, ref.pos) || i+1 >= len(path) {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || !commandFuncFields[key.Name] {
+			continue
+		}
+		lit, ok := path[i+1].(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		if _, ok := commandName(lit); !ok {
+			continue
+		}
+		names := w.commandPath(ref.filename, ref.file, lit, 0)
+		if len(names) > 1 {
+			names = names[1:]
+		}
+		return strings.Join(names, " "), true
+	}
+	return "", false
+}
+
+// commandPath returns the names of the command declared by lit and its parents,
+// outermost first. urfave/cli nests subcommands in the literal of their parent,
+// cobra adds them with parent.AddCommand(child)
+func (w *registrationWalk) commandPath(filename string, file *ast.File, lit *ast.CompositeLit, depth int) []string {
+	name, _ := commandName(lit)
+	if depth >= maxCommandDepth {
+		return []string{name}
+	}
+	path, _ := astutil.PathEnclosingInterval(file, lit.Pos(), lit.End())
+	for _, n := range path[1:] {
+		if outer, ok := n.(*ast.CompositeLit); ok {
+			if _, ok := commandName(outer); ok {
+				return append(w.commandPath(filename, file, outer, depth+1), name)
+			}
+		}
+	}
+	if parentFile, parent, ok := w.parentCommand(filename, path); ok {
+		return append(w.commandPath(parentFile, w.files[parentFile], parent, depth+1), name)
+	}
+	return []string{name}
+}
+
+// parentCommand finds the cobra command the command literal at the head of path is
+// added to, following the variable it is assigned to or the function returning it.
+// Parents that cannot be resolved syntactically are ignored
+func (w *registrationWalk) parentCommand(filename string, path []ast.Node) (string, *ast.CompositeLit, bool) {
+	id := commandIdent(path)
+	if id == nil {
+		return "", nil, false
+	}
+	p := w.fset.Position(id.Pos())
+	refs, err := w.references(ripplesapi.Position{Filename: filename, Line: p.Line, Column: p.Column}, id.Name)
+	if err != nil {
+		return "", nil, false
+	}
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.filename, "_test.go") {
+			continue
+		}
+		refPath, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+		for _, n := range refPath {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || ref.pos < call.Args[0].Pos() {
+				continue
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "AddCommand" {
+				continue
+			}
+			if parentFile, parent, ok := w.commandLit(ref, sel.X, call.Pos()); ok {
+				return parentFile, parent, true
+			}
+			break
+		}
+	}
+	return "", nil, false
+}
+
+// commandIdent returns the identifier naming the command literal at the head of
+// path: the variable it is assigned to or the function returning it
+func commandIdent(path []ast.Node) *ast.Ident {
+	lit := path[0]
+	for i, n := range path[1:] {
+		switch n := n.(type) {
+		case *ast.UnaryExpr, *ast.ParenExpr:
+			continue
+		case *ast.ValueSpec:
+			for j, v := range n.Values {
+				if contains(v, lit.Pos()) && j < len(n.Names) {
+					return n.Names[j]
+				}
+			}
+		case *ast.AssignStmt:
+			for j, v := range n.Rhs {
+				if contains(v, lit.Pos()) && j < len(n.Lhs) {
+					id, _ := n.Lhs[j].(*ast.Ident)
+					return id
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, outer := range path[i+2:] {
+				switch outer := outer.(type) {
+				case *ast.FuncLit:
+					return nil
+				case *ast.FuncDecl:
+					return outer.Name
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// commandLit resolves x, the receiver of an AddCommand call at pos, to the command
+// literal it holds: a variable assigned in the enclosing function or at package
+// level, or the result of a constructor in the same package
+func (w *registrationWalk) commandLit(ref reference, x ast.Expr, pos token.Pos) (string, *ast.CompositeLit, bool) {
+	filename := ref.filename
+	if id, ok := x.(*ast.Ident); ok {
+		x = nil
+		if ref.fn != nil {
+			x = assignedValue(ref.fn.Body, id.Name, pos)
+		}
+		if x == nil {
+			filename, x = w.packageValue(filepath.Dir(ref.filename), id.Name)
+		}
+	}
+	for {
+		switch e := x.(type) {
+		case *ast.UnaryExpr:
+			x = e.X
+			continue
+		case *ast.ParenExpr:
+			x = e.X
+			continue
+		case *ast.CompositeLit:
+			if _, ok := commandName(e); ok {
+				return filename, e, true
+			}
+		case *ast.CallExpr:
+			if id, ok := e.Fun.(*ast.Ident); ok {
+				if filename, x = w.constructorResult(filepath.Dir(filename), id.Name); x != nil {
+					continue
+				}
+			}
+		}
+		return "", nil, false
+	}
+}
+
+// packageValue finds the value of the package-level variable name declared in dir
+func (w *registrationWalk) packageValue(dir, name string) (string, ast.Expr) {
+	for filename, file := range w.packageFiles(dir) {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, id := range vs.Names {
+					if id.Name == name && i < len(vs.Values) {
+						return filename, vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// constructorResult finds the first returned value of the function name declared in dir
+func (w *registrationWalk) constructorResult(dir, name string) (string, ast.Expr) {
+	for filename, file := range w.packageFiles(dir) {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Name.Name != name || fd.Body == nil {
+				continue
+			}
+			var res ast.Expr
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.ReturnStmt:
+					if res == nil && len(n.Results) == 1 {
+						res = n.Results[0]
+					}
+				}
+				return res == nil
+			})
+			return filename, res
+		}
+	}
+	return "", nil
+}
+
+// commandName returns the name of the command declared by a cobra (Use) or
+// urfave/cli (Name) literal
+func commandName(lit *ast.CompositeLit) (string, bool) {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != "Use" && key.Name != "Name" {
+			continue
+		}
+		s, ok := stringLit(kv.Value)
+		if !ok {
+			return "", false
+		}
+		// cobra's Use is a one-line usage such as "migrate [flags]"
+		if fields := strings.Fields(s); len(fields) > 0 {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package lsp
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+func TestCommandIdent(t *testing.T) {
+	src := `package commands
+
+var migrateCmd = &cobra.Command{Use: "migrate [flags]"}
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{Use: "version"}
+}
+
+func setup() {
+	serve := &cobra.Command{Use: "serve"}
+	root.AddCommand(serve, &cobra.Command{Use: "inline"})
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "commands.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		name, ok := commandName(lit)
+		if !ok {
+			t.Errorf("No command name in literal at %v", fset.Position(lit.Pos()))
+			return true
+		}
+		path, _ := astutil.PathEnclosingInterval(file, lit.Pos(), lit.End())
+		if id := commandIdent(path); id != nil {
+			got[name] = id.Name
+		} else {
+			got[name] = ""
+		}
+		return true
+	})
+
+	want := map[string]string{
+		"migrate": "migrateCmd",
+		"version": "newVersionCmd",
+		"serve":   "serve",
+		"inline":  "",
+	}
+	for name, w := range want {
+		if g, ok := got[name]; !ok || g != w {
+			t.Errorf("%s: got %q, want %q", name, g, w)
+		}
+	}
+}
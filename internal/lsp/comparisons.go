@@ -0,0 +1,101 @@
+package lsp
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// Comparison is a function comparing a value against a constant, in a switch
+// case or with a comparison operator
+type Comparison struct {
+	Function CallNode
+	File     string
+	Line     int
+	// Paths run from the main functions reaching Function down to it and the
+	// constant, one per binary
+	Paths []CallPath
+}
+
+// TraceComparisons returns the functions comparing against constant. When the
+// value of another constant of the same type changes, values stored with this one
+// may be read back as something else, so these functions are affected too
+func (t *DirectCallTracer) TraceComparisons(constant *parser.Symbol) ([]Comparison, error) {
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(constant)
+	if err != nil {
+		return nil, err
+	}
+	w := &registrationWalk{
+		referenceWalk: c,
+		mains:         make(map[ripplesapi.Position][]CallPath),
+		importers:     make(map[string][]CallPath),
+		seen:          make(map[string]bool),
+	}
+	refs, err := w.references(pos, constant.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := SymbolNode(constant)
+	seen := make(map[ripplesapi.Position]bool)
+	var res []Comparison
+	for _, ref := range refs {
+		if ref.fn == nil || strings.HasSuffix(ref.filename, "_test.go") || !isCompared(ref) {
+			continue
+		}
+		fnPos := w.funcPosition(ref)
+		if seen[fnPos] {
+			continue
+		}
+		seen[fnPos] = true
+
+		mains, err := w.mainPaths(ref)
+		if err != nil {
+			return nil, err
+		}
+		cmp := Comparison{
+			Function: funcNode(ref.fn, w.packagePath(ref.filename)),
+			File:     ref.filename,
+			Line:     w.fset.Position(ref.pos).Line,
+		}
+		for _, main := range mains {
+			main.Path = append(append([]CallNode(nil), main.Path...), leaf)
+			cmp.Paths = append(cmp.Paths, main)
+		}
+		res = append(res, cmp)
+	}
+	return res, nil
+}
+
+// isCompared reports whether the identifier at ref is an operand of a comparison
+// or a case of a switch statement, possibly qualified by its package or parenthesized
+func isCompared(ref reference) bool {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.Ident, *ast.SelectorExpr, *ast.ParenExpr:
+			continue
+		case *ast.BinaryExpr:
+			switch n.Op {
+			case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ:
+				return true
+			}
+			return false
+		case *ast.CaseClause:
+			for _, expr := range n.List {
+				if contains(expr, ref.pos) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestIsCompared(t *testing.T) {
+	src := `package shipping
+
+func f(s order.Status) {
+	switch s {
+	case order.StatusPaid, (StatusShipped):
+	}
+	_ = s != order.StatusPaid
+	_ = StatusPaid <= s && s > 0
+	_ = []order.Status{order.StatusPaid}
+	_ = s == StatusPaid+1
+	use(StatusPaid)
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "shipping.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && (id.Name == "StatusPaid" || id.Name == "StatusShipped") {
+			got = append(got, isCompared(reference{file: file, pos: id.Pos()}))
+		}
+		return true
+	})
+	want := []bool{true, true, true, true, false, false, false}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d references, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Reference %d: isCompared = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
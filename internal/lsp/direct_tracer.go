@@ -3,15 +3,21 @@ package lsp
 import (
 	"context"
 	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/gopls/pkg/ripplesapi"
 )
 
 // DirectCallTracer uses gopls internal packages via API for call hierarchy analysis
 type DirectCallTracer struct {
-	tracer   *ripplesapi.DirectTracer
+	tracer   *countingTracer
 	rootPath string
+	packages map[string]string // Package directory -> import path, set by SetPackages
 }
 
 // NewDirectCallTracer creates a new DirectCallTracer
@@ -22,11 +28,26 @@ func NewDirectCallTracer(ctx context.Context, rootPath string) (*DirectCallTrace
 	}
 
 	return &DirectCallTracer{
-		tracer:   tracer,
+		tracer:   newCountingTracer(tracer),
 		rootPath: rootPath,
 	}, nil
 }
 
+// SetPackages indexes the import paths of loaded packages and their dependencies
+// by directory. Reference walks name the packages of the functions they pass
+// through with it, and only fall back to deriving the import path from the
+// nearest go.mod for directories outside the index. Must be called before tracing
+func (t *DirectCallTracer) SetPackages(pkgs []*packages.Package) {
+	t.packages = make(map[string]string)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		// Test variants share the directory of the package under test
+		if pkg.ForTest != "" || strings.HasSuffix(pkg.PkgPath, "_test") || len(pkg.GoFiles) == 0 {
+			return
+		}
+		t.packages[filepath.Dir(pkg.GoFiles[0])] = pkg.PkgPath
+	})
+}
+
 // Close releases resources
 func (t *DirectCallTracer) Close() error {
 	return t.tracer.Close()
@@ -47,13 +68,33 @@ func (t *DirectCallTracer) TraceToMain(symbol *parser.Symbol) ([]CallPath, error
 	// Handle different symbol kinds
 	switch symbol.Kind {
 	case parser.SymbolKindFunction:
-		// Function: use existing TraceToMain
-		apiPaths, err = t.tracer.TraceToMain(pos, symbol.Name)
+		// Function: use existing TraceToMain, positioned at the name token.
+		// The tracer moves the position to the first occurrence of the name it
+		// is given following "func" on the line, which is the receiver type when
+		// the name is a prefix of it, as in func (l *Loader) Load(). An empty
+		// name never matches, so the name token found in the AST is kept.
+		// TODO: have findFunctionNamePosition in the gopls fork
+		// (internal/ripplesapi) keep positions already on a function name, then
+		// pass symbol.Name again
+		name := symbol.Name
+		c := t.newReferenceWalk()
+		if namePos, nameErr := c.namePosition(symbol); nameErr == nil {
+			pos, name = namePos, ""
+		}
+		apiPaths, err = t.tracer.TraceToMain(pos, name)
+		if err == nil {
+			err = c.checkResolved(symbol, pos, name, apiPaths)
+		}
 
 	case parser.SymbolKindConstant, parser.SymbolKindVariable:
 		// Constant/Variable: find references and trace containing functions
 		apiPaths, err = t.tracer.TraceReferencesToMain(pos, symbol.Name)
 
+	case parser.SymbolKindStruct:
+		// Struct type: find functions referencing the type (e.g. constructing it) and trace them.
+		// Used for outer types that get a changed method through embedding
+		apiPaths, err = t.tracer.TraceReferencesToMain(pos, symbol.Name)
+
 	case parser.SymbolKindInit:
 		// Init function: find all main packages that import this package
 		// Init functions are automatically executed when a package is imported
@@ -84,6 +125,11 @@ func (t *DirectCallTracer) TraceToMain(symbol *parser.Symbol) ([]CallPath, error
 	}
 
 	// Convert results
+	c := t.newReferenceWalk()
+	var dirs map[string]string
+	if len(apiPaths) > 0 {
+		dirs = c.packageDirs()
+	}
 	var paths []CallPath
 	for _, ap := range apiPaths {
 		var nodes []CallNode
@@ -93,13 +139,88 @@ func (t *DirectCallTracer) TraceToMain(symbol *parser.Symbol) ([]CallPath, error
 				PackagePath:  an.PackagePath,
 			})
 		}
+		c.resolveReceivers(nodes, symbol, dirs)
 
 		paths = append(paths, CallPath{
-			BinaryName: ap.BinaryName,
+			BinaryName: c.mainBinaryName(ap),
 			MainURI:    ap.MainURI,
-			Path:       nodes,
+			Path:       c.noteLazyInit(nodes, dirs),
 		})
 	}
 
 	return paths, nil
 }
+
+// checkResolved verifies that the call hierarchy item the tracer resolved the
+// position to is the function symbol. The tracer names the item without its
+// receiver, so the declaration it lands on when looking for name from pos is
+// worked out from the source and must be the symbol's, receiver included: Get of
+// UserStore would otherwise pass for Get of OrderStore. Every path must then end
+// with a function of the same name in the same package. A position drifting onto
+// another function would otherwise report that function's callers
+func (c *referenceWalk) checkResolved(symbol *parser.Symbol, pos ripplesapi.Position, name string, apiPaths []ripplesapi.CallPath) error {
+	if fd, err := c.resolvedDecl(pos, name); err == nil {
+		if fd == nil {
+			return fmt.Errorf("call hierarchy position of %s is not a function name", SymbolNode(symbol).Name())
+		}
+		if fd.Name.Name != symbol.Name || !sameReceiver(symbol, fd) {
+			return fmt.Errorf("call hierarchy resolved %s to %s", SymbolNode(symbol).Name(), funcNode(fd, symbol.PackagePath).Name())
+		}
+	}
+	for _, ap := range apiPaths {
+		if len(ap.Path) == 0 {
+			continue
+		}
+		last := ap.Path[len(ap.Path)-1]
+		if last.FunctionName != symbol.Name || last.PackagePath != "" && symbol.PackagePath != "" && last.PackagePath != symbol.PackagePath {
+			return fmt.Errorf("call hierarchy resolved %s to %s.%s", symbol.Name, last.PackagePath, last.FunctionName)
+		}
+	}
+	return nil
+}
+
+// resolvedDecl returns the function whose name the gopls tracer moves pos to when
+// looking for name, or nil if that is not a function name. Like
+// findFunctionNamePosition in ripplesapi, the first line from pos.Line on (at most
+// three lines) where name occurs after "func" is taken, and pos is kept when there
+// is none, as for an empty name
+func (c *referenceWalk) resolvedDecl(pos ripplesapi.Position, name string) (*ast.FuncDecl, error) {
+	data, err := os.ReadFile(pos.Filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.parse(pos.Filename)
+	if err != nil {
+		return nil, err
+	}
+	line, column := pos.Line, pos.Column
+	lines := strings.Split(string(data), "\n")
+	for i := pos.Line - 1; i >= 0 && i < min(pos.Line+2, len(lines)); i++ {
+		if idx := strings.Index(lines[i], name); idx != -1 && strings.Contains(lines[i][:idx], "func") {
+			line, column = i+1, idx+1
+			break
+		}
+	}
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		start := c.fset.Position(fd.Name.Pos())
+		if start.Line == line && column >= start.Column && column < start.Column+len(fd.Name.Name) {
+			return fd, nil
+		}
+	}
+	return nil, nil
+}
+
+// mainBinaryName renames a binary found by the gopls tracer after its main
+// package directory. The tracer names binaries found through imports after the
+// element following cmd/ in the import path, so cmd/tools/gen would otherwise be
+// "tools" there and "gen" when reached through calls
+func (c *referenceWalk) mainBinaryName(ap ripplesapi.CallPath) string {
+	if !strings.HasPrefix(ap.MainURI, "file:") {
+		return ap.BinaryName
+	}
+	return c.binaryName(PathFromURI(ap.MainURI))
+}
@@ -0,0 +1,194 @@
+package lsp
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+func TestNamePositionReceiver(t *testing.T) {
+	src := `package store
+
+type UserStore struct{}
+
+type OrderStore struct{}
+
+func (s *UserStore) Get(id int) string { return "user" }
+
+func (s *OrderStore) Get(id int) string { return "order" }
+`
+	filename := filepath.Join(t.TempDir(), "store.go")
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	symbol := func(line int, recv string) *parser.Symbol {
+		return &parser.Symbol{
+			Name:     "Get",
+			Kind:     parser.SymbolKindFunction,
+			Position: token.Position{Filename: filename, Line: line, Column: 1},
+			Extra:    parser.FunctionExtra{IsMethod: true, ReceiverType: recv},
+		}
+	}
+	c := (&DirectCallTracer{}).newReferenceWalk()
+
+	tests := []struct {
+		name   string
+		symbol *parser.Symbol
+		line   int
+		column int
+	}{
+		{"declaration line", symbol(9, "*OrderStore"), 9, 22},
+		// The line holds Get of UserStore, the only Get of OrderStore is used
+		{"drifted onto another receiver", symbol(7, "*OrderStore"), 9, 22},
+		{"drifted off any declaration", symbol(8, "UserStore"), 7, 21},
+	}
+	for _, tt := range tests {
+		pos, err := c.namePosition(tt.symbol)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("%s: got %d:%d, want %d:%d", tt.name, pos.Line, pos.Column, tt.line, tt.column)
+		}
+	}
+	if _, err := c.namePosition(symbol(9, "*ItemStore")); err == nil {
+		t.Error("Expected an error for a receiver without the method")
+	}
+}
+
+func TestCheckResolved(t *testing.T) {
+	symbol := &parser.Symbol{Name: "Get", Kind: parser.SymbolKindFunction, PackagePath: "example.com/app/store"}
+	path := func(name, pkg string) []ripplesapi.CallPath {
+		return []ripplesapi.CallPath{{Path: []ripplesapi.CallNode{
+			{FunctionName: "main", PackagePath: "example.com/app/cmd/api"},
+			{FunctionName: name, PackagePath: pkg},
+		}}}
+	}
+	c := (&DirectCallTracer{}).newReferenceWalk()
+	// Without the source only the paths are checked
+	var pos ripplesapi.Position
+	if err := c.checkResolved(symbol, pos, "Get", path("Get", "example.com/app/store")); err != nil {
+		t.Errorf("Expected the symbol to match, got %v", err)
+	}
+	if err := c.checkResolved(symbol, pos, "Get", path("List", "example.com/app/store")); err == nil {
+		t.Error("Expected an error for another function")
+	}
+	if err := c.checkResolved(symbol, pos, "Get", path("Get", "example.com/app/cache")); err == nil {
+		t.Error("Expected an error for another package")
+	}
+}
+
+func TestCheckResolvedReceiver(t *testing.T) {
+	src := `package store
+
+type UserStore struct{}
+
+type OrderStore struct{}
+
+func (s *UserStore) Get(id int) string { return "user" }
+
+func (s *OrderStore) Get(id int) string { return "order" }
+`
+	filename := filepath.Join(t.TempDir(), "store.go")
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	symbol := func(recv string) *parser.Symbol {
+		return &parser.Symbol{
+			Name:        "Get",
+			Kind:        parser.SymbolKindFunction,
+			PackagePath: "example.com/app/store",
+			Extra:       parser.FunctionExtra{IsMethod: true, ReceiverType: recv},
+		}
+	}
+	paths := []ripplesapi.CallPath{{Path: []ripplesapi.CallNode{
+		{FunctionName: "main", PackagePath: "example.com/app/cmd/api"},
+		{FunctionName: "Get", PackagePath: "example.com/app/store"},
+	}}}
+	c := (&DirectCallTracer{}).newReferenceWalk()
+
+	tests := []struct {
+		name    string
+		recv    string
+		search  string
+		line    int
+		column  int
+		wantErr bool
+	}{
+		{"declaration of the receiver", "*UserStore", "", 7, 21, false},
+		{"another receiver's method", "*OrderStore", "", 7, 21, true},
+		// The tracer moves the position to the first search name after "func"
+		{"moved onto the receiver's method", "*OrderStore", "Get", 8, 1, false},
+		{"moved onto another receiver's method", "*OrderStore", "Get", 6, 1, true},
+		{"not on a function", "*UserStore", "", 3, 1, true},
+	}
+	for _, tt := range tests {
+		pos := ripplesapi.Position{Filename: filename, Line: tt.line, Column: tt.column}
+		err := c.checkResolved(symbol(tt.recv), pos, tt.search, paths)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolveReceivers(t *testing.T) {
+	src := `package store
+
+type UserStore struct{}
+
+type OrderStore struct{}
+
+type Cache[K comparable, V any] struct{}
+
+func (s *UserStore) Get() string { return load() }
+
+func (s OrderStore) Get() string { return "order" }
+
+func (s *UserStore) List() []string { return []string{s.Get()} }
+
+func (c *Cache[K, V]) Fill() { load() }
+
+func load() string { return "" }
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "store.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const pkg = "example.com/app/store"
+	nodes := []CallNode{
+		{FunctionName: "main", PackagePath: "example.com/app/cmd/api"},
+		{FunctionName: "List", PackagePath: pkg},
+		// Both stores declare Get, only UserStore.Get calls load
+		{FunctionName: "Get", PackagePath: pkg},
+		{FunctionName: "load", PackagePath: pkg},
+	}
+	symbol := &parser.Symbol{Name: "load", Kind: parser.SymbolKindFunction, PackagePath: pkg}
+	(&DirectCallTracer{}).newReferenceWalk().resolveReceivers(nodes, symbol, map[string]string{pkg: dir})
+
+	want := []string{
+		"example.com/app/cmd/api.main",
+		"example.com/app/store.(*UserStore).List",
+		"example.com/app/store.(*UserStore).Get",
+		"example.com/app/store.load",
+	}
+	for i, node := range nodes {
+		if got := node.QualifiedName(); got != want[i] {
+			t.Errorf("node %d: got %s, want %s", i, got, want[i])
+		}
+	}
+
+	method := func(recv string) *parser.Symbol {
+		return &parser.Symbol{Name: "Fill", Kind: parser.SymbolKindFunction, Extra: parser.FunctionExtra{IsMethod: true, ReceiverType: recv}}
+	}
+	if got := SymbolNode(method("*Cache[K, V]")).Name(); got != "(*Cache).Fill" {
+		t.Errorf("Expected the type parameters to be dropped, got %s", got)
+	}
+	if got := SymbolNode(method("OrderStore")).Name(); got != "OrderStore.Fill" {
+		t.Errorf("Expected a value receiver without parentheses, got %s", got)
+	}
+}
@@ -0,0 +1,208 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// TraceToEntrypoints walks the references of symbol upwards and returns a path
+// for every custom entrypoint (a function annotated with //ripples:entrypoint or
+// configured as one) that uses it. Each entrypoint is reported as a binary named
+// after it. The gopls tracer only stops at main functions, so this complements
+// TraceToMain for roots such as Lambda handlers or cron jobs registered by reference
+func (t *DirectCallTracer) TraceToEntrypoints(symbol *parser.Symbol, entrypoints []parser.Entrypoint) ([]CallPath, error) {
+	if len(entrypoints) == 0 {
+		return nil, nil
+	}
+	roots := make(map[ripplesapi.Position]parser.Entrypoint, len(entrypoints))
+	for _, ep := range entrypoints {
+		roots[ripplesapi.Position{
+			Filename: ep.Symbol.Position.Filename,
+			Line:     ep.Symbol.Position.Line,
+			Column:   ep.Symbol.Position.Column,
+		}] = ep
+	}
+
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+	w := &entrypointWalk{referenceWalk: c, roots: roots}
+	start := SymbolNode(symbol)
+	if err := w.walk(pos, symbol.Name, []CallNode{start}); err != nil {
+		return nil, err
+	}
+	return w.paths, nil
+}
+
+// TraceToJobs returns a path for every binary running a job that uses symbol. Jobs
+// are functions within a binary, such as cron jobs, that a user declared as roots:
+// their paths start at the binary's entrypoint, followed by the job function
+func (t *DirectCallTracer) TraceToJobs(symbol *parser.Symbol, jobs []parser.Job) ([]CallPath, error) {
+	roots := make([]parser.Entrypoint, 0, len(jobs))
+	for _, job := range jobs {
+		roots = append(roots, parser.Entrypoint{Name: job.Name, Symbol: job.Symbol})
+	}
+	paths, err := t.TraceToEntrypoints(symbol, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []CallPath
+	for _, path := range paths {
+		for _, job := range jobs {
+			if job.Name != path.BinaryName {
+				continue
+			}
+			for _, ep := range job.Binaries {
+				root := SymbolNode(ep.Symbol)
+				res = append(res, CallPath{
+					BinaryName: ep.Name,
+					MainURI:    URIFromPath(ep.Symbol.Position.Filename),
+					Path:       append([]CallNode{root}, path.Path...),
+					Custom:     !ep.Main,
+					Job:        job.Name,
+				})
+			}
+		}
+	}
+	return res, nil
+}
+
+// entrypointWalk collects paths to custom entrypoints
+type entrypointWalk struct {
+	*referenceWalk
+	roots map[ripplesapi.Position]parser.Entrypoint
+	paths []CallPath
+}
+
+// walk visits the functions using the symbol declared at pos. chain holds the
+// nodes from the symbol up to and including the current one
+func (w *entrypointWalk) walk(pos ripplesapi.Position, name string, chain []CallNode) error {
+	if w.visited[pos] {
+		return nil
+	}
+	w.visited[pos] = true
+	if ep, ok := w.roots[pos]; ok {
+		w.paths = append(w.paths, entrypointPath(ep, chain))
+		return nil
+	}
+	if len(chain) > maxReachabilityDepth {
+		return nil
+	}
+
+	refs, err := w.references(pos, name)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.fn == nil {
+			continue
+		}
+		node := funcNode(ref.fn, w.packagePath(ref.filename))
+		// chain is shared by sibling calls, so every extension needs its own copy
+		next := append(append([]CallNode(nil), chain...), node)
+		if err := w.walk(w.funcPosition(ref), ref.fn.Name.Name, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entrypointPath builds the path from the entrypoint down to the symbol
+func entrypointPath(ep parser.Entrypoint, chain []CallNode) CallPath {
+	nodes := make([]CallNode, 0, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		nodes = append(nodes, chain[i])
+	}
+	return CallPath{
+		BinaryName: ep.Name,
+		MainURI:    URIFromPath(ep.Symbol.Position.Filename),
+		Path:       nodes,
+		Custom:     !ep.Main,
+	}
+}
+
+// binaryName names the binary built from the main package containing filename
+// after the package directory relative to its module root (parser.BinaryName).
+// This matches the gopls tracer for binaries under cmd/ and also covers mains
+// outside it, which the tracer does not recognize
+func (c *referenceWalk) binaryName(filename string) string {
+	if pkgPath := c.packagePath(filename); pkgPath != "" {
+		return parser.BinaryName(pkgPath)
+	}
+	return filepath.Base(filepath.Dir(filename))
+}
+
+// packagePath returns the import path of the package containing filename, as
+// loaded by the parser or else derived from the module declared by the nearest go.mod
+func (c *referenceWalk) packagePath(filename string) string {
+	dir := filepath.Dir(filename)
+	if pkgPath, ok := c.pkgs[dir]; ok {
+		return pkgPath
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		module, ok := c.modules[d]
+		if !ok {
+			module = readModulePath(filepath.Join(d, "go.mod"))
+			c.modules[d] = module
+		}
+		if module != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil || rel == "." {
+				return module
+			}
+			return module + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// packageDir returns the directory of the package imported as pkgPath by the file
+// filename: the directory of the loaded package, or else a directory of the module
+// containing filename. Returns "" for packages of other modules that are not loaded
+func (c *referenceWalk) packageDir(pkgPath, filename string) string {
+	if dir, ok := c.packageDirs()[pkgPath]; ok {
+		return dir
+	}
+	for d := filepath.Dir(filename); ; d = filepath.Dir(d) {
+		module, ok := c.modules[d]
+		if !ok {
+			module = readModulePath(filepath.Join(d, "go.mod"))
+			c.modules[d] = module
+		}
+		if module != "" {
+			if rel, ok := strings.CutPrefix(pkgPath, module+"/"); ok {
+				return filepath.Join(d, filepath.FromSlash(rel))
+			}
+			if pkgPath == module {
+				return d
+			}
+			return ""
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// readModulePath returns the module path declared in a go.mod file, "" if there is none
+func readModulePath(gomod string) string {
+	content, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
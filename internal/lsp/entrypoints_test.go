@@ -0,0 +1,33 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackagePath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Loaded packages take precedence over import paths derived from go.mod, e.g. for replaced directories
+	c := &referenceWalk{
+		modules: make(map[string]string),
+		pkgs:    map[string]string{filepath.Join(root, "third_party", "lib"): "example.com/lib"},
+	}
+
+	tests := map[string]string{
+		filepath.Join(root, "main.go"):                      "example.com/app",
+		filepath.Join(root, "internal", "a", "b", "b.go"):   "example.com/app/internal/a/b",
+		filepath.Join(root, "third_party", "lib", "lib.go"): "example.com/lib",
+	}
+	for filename, want := range tests {
+		if got := c.packagePath(filename); got != want {
+			t.Errorf("packagePath(%s) = %q, want %q", filename, got, want)
+		}
+	}
+	if got := c.binaryName(filepath.Join(root, "main.go")); got != "app" {
+		t.Errorf("binaryName of the module root = %q, want app", got)
+	}
+}
@@ -0,0 +1,84 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// fanOutLevels is the number of caller levels FanOut inspects
+const fanOutLevels = 3
+
+// FanOut reports whether one of the first fanOutLevels levels of callers of
+// symbol has more than limit distinct functions, such as a logging helper used
+// throughout the repository. Tracing such a symbol walks a call hierarchy that
+// grows with every level, so callers fall back to TraceImporters. The check
+// stops as soon as a level exceeds limit, so it costs at most
+// fanOutLevels*limit reference lookups
+func (t *DirectCallTracer) FanOut(symbol *parser.Symbol, limit int) (bool, error) {
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(symbol)
+	if err != nil {
+		return false, err
+	}
+	type caller struct {
+		pos  ripplesapi.Position
+		name string
+	}
+	level := []caller{{pos, symbol.Name}}
+	c.visited[pos] = true
+	for range fanOutLevels {
+		var next []caller
+		for _, fn := range level {
+			refs, err := c.references(fn.pos, fn.name)
+			if err != nil {
+				return false, err
+			}
+			for _, ref := range refs {
+				if ref.fn == nil || strings.HasSuffix(ref.filename, "_test.go") {
+					continue
+				}
+				p := c.funcPosition(ref)
+				if c.visited[p] {
+					continue
+				}
+				c.visited[p] = true
+				if next = append(next, caller{p, ref.fn.Name.Name}); len(next) > limit {
+					return true, nil
+				}
+			}
+		}
+		if len(next) == 0 {
+			return false, nil
+		}
+		level = next
+	}
+	return false, nil
+}
+
+// TraceImporters returns a path for every main package importing the package of
+// symbol, directly or indirectly, from its main function straight to the symbol.
+// It is the package-level approximation of TraceToMain for symbols whose callers
+// fan out too widely: a binary importing the package may not call the symbol.
+// Paths are marked Approximate
+func (t *DirectCallTracer) TraceImporters(symbol *parser.Symbol) ([]CallPath, error) {
+	apiPaths, err := t.tracer.FindMainPackagesImporting(symbol.PackagePath)
+	if err != nil {
+		return nil, err
+	}
+	c := t.newReferenceWalk()
+	paths := make([]CallPath, 0, len(apiPaths))
+	for _, ap := range apiPaths {
+		paths = append(paths, CallPath{
+			BinaryName: c.mainBinaryName(ap),
+			MainURI:    ap.MainURI,
+			Path: []CallNode{
+				{FunctionName: "main", PackagePath: ap.Path[0].PackagePath},
+				SymbolNode(symbol),
+			},
+			Approximate: true,
+		})
+	}
+	return paths, nil
+}
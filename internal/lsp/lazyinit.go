@@ -0,0 +1,173 @@
+package lsp
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// onceWrappers are the sync functions returning a function that runs the wrapped
+// function on its first call
+var onceWrappers = map[string]bool{"OnceFunc": true, "OnceValue": true, "OnceValues": true}
+
+// NoteLazyInit marks lazy initialization in a path segment built outside the
+// tracer, see noteLazyInit. TraceToMain already marks the paths it returns
+func (t *DirectCallTracer) NoteLazyInit(nodes []CallNode) []CallNode {
+	c := t.newReferenceWalk()
+	return c.noteLazyInit(nodes, c.packageDirs())
+}
+
+// noteLazyInit marks lazy initialization in a path running from main to the changed
+// symbol. gopls reports a function run by once.Do in a getter as called by the
+// getter, so a "sync.(*Once).Do" node is inserted between them. A function wrapped by
+// sync.OnceFunc, sync.OnceValue or sync.OnceValues in a package-level variable is
+// reported as called by the package initialization, although it runs when the
+// variable is first called: the init node is replaced with the variable followed by
+// the sync function. dirs maps import paths to package directories
+func (c *referenceWalk) noteLazyInit(nodes []CallNode, dirs map[string]string) []CallNode {
+	var res []CallNode
+	for i, caller := range nodes {
+		if i+1 == len(nodes) {
+			res = append(res, caller)
+			break
+		}
+		callee := nodes[i+1].FunctionName
+		dir, ok := dirs[caller.PackagePath]
+		if !ok {
+			res = append(res, caller)
+			continue
+		}
+		files := c.packageFiles(dir)
+		if caller.FunctionName == "init" {
+			if name, wrapper := onceVar(files, callee); name != "" {
+				res = append(res, CallNode{FunctionName: name, PackagePath: caller.PackagePath},
+					CallNode{FunctionName: wrapper, PackagePath: "sync"})
+				continue
+			}
+		} else if callsOnceDo(files, caller.FunctionName, callee) {
+			res = append(res, caller, CallNode{FunctionName: "Do", PackagePath: "sync", Receiver: "*Once"})
+			continue
+		}
+		res = append(res, caller)
+	}
+	return res
+}
+
+// onceVar returns the package-level variable initialized with a sync.OnceFunc,
+// sync.OnceValue or sync.OnceValues call using the function named callee, and the
+// name of the sync function
+func onceVar(files map[string]*ast.File, callee string) (string, string) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, v := range vs.Values {
+					call, ok := v.(*ast.CallExpr)
+					if !ok || i >= len(vs.Names) {
+						continue
+					}
+					sel, ok := call.Fun.(*ast.SelectorExpr)
+					if !ok || !isIdent(sel.X, "sync") || !onceWrappers[sel.Sel.Name] {
+						continue
+					}
+					if uses(call, callee) {
+						return vs.Names[i].Name, sel.Sel.Name
+					}
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// callsOnceDo reports whether the function named fn passes a function using callee
+// to the Do method of a sync.Once, such as once.Do(load) or
+// c.once.Do(func() { c.dial() })
+func callsOnceDo(files map[string]*ast.File, fn, callee string) bool {
+	onces := onceNames(files)
+	if len(onces) == 0 {
+		return false
+	}
+	found := false
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Name.Name != fn || fd.Body == nil {
+				continue
+			}
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || found || len(call.Args) != 1 {
+					return !found
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "Do" {
+					return true
+				}
+				var once string
+				switch x := sel.X.(type) {
+				case *ast.Ident:
+					once = x.Name
+				case *ast.SelectorExpr:
+					once = x.Sel.Name
+				}
+				found = onces[once] && uses(call.Args[0], callee)
+				return !found
+			})
+		}
+	}
+	return found
+}
+
+// onceNames returns the names of the package-level variables and struct fields of
+// type sync.Once or *sync.Once declared in files
+func onceNames(files map[string]*ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			var typ ast.Expr
+			var ids []*ast.Ident
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				return false
+			case *ast.ValueSpec:
+				typ, ids = n.Type, n.Names
+			case *ast.Field:
+				typ, ids = n.Type, n.Names
+			default:
+				return true
+			}
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if sel, ok := typ.(*ast.SelectorExpr); ok && isIdent(sel.X, "sync") && sel.Sel.Name == "Once" {
+				for _, id := range ids {
+					names[id.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// uses reports whether node mentions the identifier name
+func uses(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isIdent reports whether expr is the identifier name
+func isIdent(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
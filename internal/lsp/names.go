@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"go/ast"
+	goparser "go/parser"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// Name returns the function name of the node, with the receiver of a method in
+// method expression form: "(*Handler).ProcessRequest" or "Handler.ProcessRequest"
+func (n CallNode) Name() string {
+	switch {
+	case n.Receiver == "":
+		return n.FunctionName
+	case n.Receiver[0] == '*':
+		return "(" + n.Receiver + ")." + n.FunctionName
+	}
+	return n.Receiver + "." + n.FunctionName
+}
+
+// QualifiedName returns the name of the node qualified with its package path, such
+// as "example.com/api/handler.(*Handler).ProcessRequest". Package nodes of import
+// paths are named after the package alone
+func (n CallNode) QualifiedName() string {
+	switch {
+	case n.FunctionName == "":
+		return n.PackagePath
+	case n.PackagePath == "":
+		return n.Name()
+	}
+	return n.PackagePath + "." + n.Name()
+}
+
+// SymbolNode returns the path node of a symbol, with the receiver of a method
+func SymbolNode(symbol *parser.Symbol) CallNode {
+	node := CallNode{FunctionName: symbol.Name, PackagePath: symbol.PackagePath}
+	if extra, ok := symbol.Extra.(parser.FunctionExtra); ok && extra.IsMethod {
+		if recv, err := goparser.ParseExpr(extra.ReceiverType); err == nil {
+			node.Receiver = receiverType(recv)
+		}
+	}
+	return node
+}
+
+// funcNode returns the path node of a function declared in the package pkgPath
+func funcNode(fd *ast.FuncDecl, pkgPath string) CallNode {
+	node := CallNode{FunctionName: fd.Name.Name, PackagePath: pkgPath}
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		node.Receiver = receiverType(fd.Recv.List[0].Type)
+	}
+	return node
+}
+
+// receiverType returns the type of a receiver as declared, keeping the pointer
+// but not the type parameters: "*Cache" for *Cache[K, V]
+func receiverType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		if name := receiverName(star.X); name != "" {
+			return "*" + name
+		}
+		return ""
+	}
+	return receiverName(expr)
+}
+
+// resolveReceivers fills in the receivers of the methods in a path returned by the
+// gopls tracer, which names them without their receiver types. The last node is
+// the traced symbol. A caller is looked up by name in its package: when several
+// types declare a method of that name, the one referring to its callee is taken,
+// and the receiver is left unknown if that is still ambiguous. dirs maps import
+// paths to package directories
+func (c *referenceWalk) resolveReceivers(nodes []CallNode, symbol *parser.Symbol, dirs map[string]string) {
+	for i := range nodes {
+		if i == len(nodes)-1 {
+			if last := SymbolNode(symbol); last.FunctionName == nodes[i].FunctionName {
+				nodes[i].Receiver = last.Receiver
+			}
+			break
+		}
+		dir, ok := dirs[nodes[i].PackagePath]
+		if !ok || nodes[i].FunctionName == "" || nodes[i].Receiver != "" {
+			continue
+		}
+		var candidates []*ast.FuncDecl
+		for _, file := range c.packageFiles(dir) {
+			for _, decl := range file.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok && fd.Name.Name == nodes[i].FunctionName {
+					candidates = append(candidates, fd)
+				}
+			}
+		}
+		if len(candidates) > 1 {
+			var calling []*ast.FuncDecl
+			for _, fd := range candidates {
+				if fd.Body != nil && uses(fd.Body, nodes[i+1].FunctionName) {
+					calling = append(calling, fd)
+				}
+			}
+			candidates = calling
+		}
+		if len(candidates) == 1 {
+			nodes[i].Receiver = funcNode(candidates[0], nodes[i].PackagePath).Receiver
+		}
+	}
+}
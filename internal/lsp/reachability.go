@@ -0,0 +1,246 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// Reachability describes whether code that runs in some binary uses a symbol
+type Reachability string
+
+const (
+	// Reachable means the symbol is used by a main or init function, a package-level
+	// initializer, or a function that is itself reachable. It is also the answer
+	// whenever the check gives up, so only the other values are conclusive
+	Reachable Reachability = ""
+	// Unreferenced means nothing refers to the symbol besides its declaration
+	Unreferenced Reachability = "no_references"
+	// TestsOnly means the symbol is only referenced from test files
+	TestsOnly Reachability = "tests_only"
+	// DeadCallers means every non-test reference is in a function that is unreachable
+	DeadCallers Reachability = "dead_callers"
+)
+
+// maxReachabilityDepth bounds the caller chain walked by Reachability
+const maxReachabilityDepth = 30
+
+// Reachability walks the references of symbol upwards until it finds code that
+// runs in a binary. Unlike TraceToMain it does not skip binaries already found by
+// earlier traces, so it can tell a symbol that really dead-ends from one whose
+// binaries another changed symbol already reported
+func (t *DirectCallTracer) Reachability(symbol *parser.Symbol) (Reachability, error) {
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(symbol)
+	if err != nil {
+		return Reachable, err
+	}
+	if file := c.files[pos.Filename]; file != nil && file.Name.Name == "main" && symbol.Name == "main" {
+		return Reachable, nil
+	}
+	return c.check(pos, symbol.Name, 0)
+}
+
+// referenceWalk holds the state of a single walk up the references of a symbol
+type referenceWalk struct {
+	tracer  *countingTracer
+	fset    *token.FileSet
+	files   map[string]*ast.File
+	visited map[ripplesapi.Position]bool
+	modules map[string]string // Directory -> module path of its nearest go.mod, "" if none
+	pkgs    map[string]string // Package directory -> import path of loaded packages, read-only
+	dirs    map[string]string // Import path -> package directory, the inverse of pkgs built on first use
+}
+
+func (t *DirectCallTracer) newReferenceWalk() *referenceWalk {
+	return &referenceWalk{
+		tracer:  t.tracer,
+		fset:    token.NewFileSet(),
+		files:   make(map[string]*ast.File),
+		visited: make(map[ripplesapi.Position]bool),
+		modules: make(map[string]string),
+		pkgs:    t.packages,
+	}
+}
+
+// check reports the reachability of the symbol named name declared at pos
+func (c *referenceWalk) check(pos ripplesapi.Position, name string, depth int) (Reachability, error) {
+	if c.visited[pos] {
+		// A cycle only reaches a binary through some other reference
+		return DeadCallers, nil
+	}
+	c.visited[pos] = true
+	if depth >= maxReachabilityDepth {
+		return Reachable, nil
+	}
+
+	refs, err := c.references(pos, name)
+	if err != nil {
+		return Reachable, err
+	}
+
+	var inTests, hasCallers bool
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.filename, "_test.go") {
+			inTests = true
+			continue
+		}
+		switch {
+		case ref.fn == nil:
+			// Package-level initializers run when the package is imported
+			return Reachable, nil
+		case ref.fn.Recv == nil && (ref.fn.Name.Name == "init" || ref.fn.Name.Name == "main" && ref.file.Name.Name == "main"):
+			return Reachable, nil
+		}
+
+		hasCallers = true
+		res, err := c.check(c.funcPosition(ref), ref.fn.Name.Name, depth+1)
+		if err != nil || res == Reachable {
+			return Reachable, err
+		}
+	}
+
+	switch {
+	case hasCallers:
+		return DeadCallers, nil
+	case inTests:
+		return TestsOnly, nil
+	default:
+		return Unreferenced, nil
+	}
+}
+
+// reference is a use of a symbol found while walking up references
+type reference struct {
+	filename string
+	file     *ast.File
+	pos      token.Pos     // Position of the referring identifier in file
+	fn       *ast.FuncDecl // Enclosing top-level function, nil for package-level declarations
+}
+
+// references returns the uses of the symbol named name declared at pos,
+// excluding the declaration itself
+func (c *referenceWalk) references(pos ripplesapi.Position, name string) ([]reference, error) {
+	refs, err := c.tracer.FindReferences(pos, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []reference
+	for _, ref := range refs {
+		filename := PathFromURI(ref.URI)
+		line := int(ref.Range.Start.Line) + 1
+		column := int(ref.Range.Start.Character) + 1
+		if filename == pos.Filename && line == pos.Line && column == pos.Column {
+			continue
+		}
+		file, err := c.parse(filename)
+		if err != nil {
+			return nil, err
+		}
+		tf := c.fset.File(file.Pos())
+		if line > tf.LineCount() {
+			continue
+		}
+		res = append(res, reference{
+			filename: filename,
+			file:     file,
+			pos:      tf.LineStart(line) + token.Pos(column-1),
+			fn:       enclosingFunc(c.fset, file, line),
+		})
+	}
+	return res, nil
+}
+
+// funcPosition returns the position of the name of the function enclosing ref
+func (c *referenceWalk) funcPosition(ref reference) ripplesapi.Position {
+	pos := c.fset.Position(ref.fn.Name.Pos())
+	return ripplesapi.Position{Filename: ref.filename, Line: pos.Line, Column: pos.Column}
+}
+
+// parse parses a file once per walk
+func (c *referenceWalk) parse(filename string) (*ast.File, error) {
+	if file, ok := c.files[filename]; ok {
+		return file, nil
+	}
+	file, err := goparser.ParseFile(c.fset, filename, nil, goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	c.files[filename] = file
+	return file, nil
+}
+
+// namePosition returns the position of the symbol's name. Function symbols are
+// positioned at the "func" keyword, which gopls does not resolve to the function.
+// Methods must also have the receiver type of the symbol, so that Get of
+// UserStore is not taken for Get of OrderStore declared in the same file. When
+// no matching declaration starts on the symbol's line, as after the file was
+// edited, the only matching declaration in the file is used
+func (c *referenceWalk) namePosition(symbol *parser.Symbol) (ripplesapi.Position, error) {
+	pos := ripplesapi.Position{
+		Filename: symbol.Position.Filename,
+		Line:     symbol.Position.Line,
+		Column:   symbol.Position.Column,
+	}
+	if symbol.Kind != parser.SymbolKindFunction {
+		return pos, nil
+	}
+
+	file, err := c.parse(pos.Filename)
+	if err != nil {
+		return pos, err
+	}
+	var matches []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != symbol.Name || !sameReceiver(symbol, fd) {
+			continue
+		}
+		if c.fset.Position(fd.Pos()).Line == pos.Line {
+			matches = []*ast.FuncDecl{fd}
+			break
+		}
+		matches = append(matches, fd)
+	}
+	if len(matches) != 1 {
+		return pos, fmt.Errorf("function %s not found in %s:%d", symbol.Name, pos.Filename, pos.Line)
+	}
+	name := c.fset.Position(matches[0].Name.Pos())
+	pos.Line, pos.Column = name.Line, name.Column
+	return pos, nil
+}
+
+// sameReceiver reports whether fd has the receiver type of the function symbol,
+// ignoring pointers and type parameters. Symbols without receiver information
+// match any declaration
+func sameReceiver(symbol *parser.Symbol, fd *ast.FuncDecl) bool {
+	extra, ok := symbol.Extra.(parser.FunctionExtra)
+	if !ok {
+		return true
+	}
+	if !extra.IsMethod {
+		return fd.Recv == nil
+	}
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return false
+	}
+	recv, err := goparser.ParseExpr(extra.ReceiverType)
+	return err != nil || receiverName(recv) == receiverName(fd.Recv.List[0].Type)
+}
+
+// enclosingFunc returns the top-level function declaration containing line
+func enclosingFunc(fset *token.FileSet, file *ast.File, line int) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if ok && fset.Position(fd.Pos()).Line <= line && line <= fset.Position(fd.End()).Line {
+			return fd
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package lsp
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// Registrations selects the kinds of registrations TraceRegistrations looks for
+type Registrations struct {
+	// Routes are handlers passed to net/http, gin, echo or chi style routers,
+	// and types passed to gRPC Register*Server functions
+	Routes bool
+	// Commands are functions run by cobra commands (Run, RunE, ...) or urfave/cli
+	// commands (Action, ...)
+	Commands bool
+}
+
+// TraceRegistrations walks the references of symbol upwards and returns a path for
+// every registration it finds on the way. Each path runs from the main function of
+// the binary serving the route or subcommand down to the symbol and carries the
+// route or the subcommand. Registrations are recognized syntactically, so only
+// string literal route patterns and command names are reported
+func (t *DirectCallTracer) TraceRegistrations(symbol *parser.Symbol, kinds Registrations) ([]CallPath, error) {
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+	w := &registrationWalk{
+		referenceWalk: c,
+		kinds:         kinds,
+		mains:         make(map[ripplesapi.Position][]CallPath),
+		importers:     make(map[string][]CallPath),
+		seen:          make(map[string]bool),
+	}
+	start := SymbolNode(symbol)
+	if err := w.walk(pos, symbol.Name, []CallNode{start}); err != nil {
+		return nil, err
+	}
+	return w.paths, nil
+}
+
+// registrationWalk collects paths through route and command registrations
+type registrationWalk struct {
+	*referenceWalk
+	kinds     Registrations
+	mains     map[ripplesapi.Position][]CallPath // Function -> paths from main functions to it, nil while being visited
+	importers map[string][]CallPath              // Package path -> main packages importing it
+	seen      map[string]bool                    // Binary and route or command already reported
+	paths     []CallPath
+}
+
+// walk visits the functions using the symbol declared at pos. chain holds the
+// nodes from the symbol up to and including the current one
+func (w *registrationWalk) walk(pos ripplesapi.Position, name string, chain []CallNode) error {
+	if w.visited[pos] {
+		return nil
+	}
+	w.visited[pos] = true
+	if len(chain) > maxReachabilityDepth {
+		return nil
+	}
+
+	if fd := w.funcDecl(pos); w.kinds.Routes && fd != nil && fd.Recv != nil && ast.IsExported(fd.Name.Name) {
+		if err := w.grpc(pos, fd, chain); err != nil {
+			return err
+		}
+	}
+
+	refs, err := w.references(pos, name)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.filename, "_test.go") {
+			continue
+		}
+		if w.kinds.Commands {
+			if command, ok := w.command(ref); ok {
+				if err := w.record(ref, chain, nil, command); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if ref.fn == nil {
+			continue
+		}
+		if w.kinds.Routes {
+			if route, ok := w.httpRoute(ref); ok {
+				route.Handler = w.handlerName(pos, name)
+				if err := w.record(ref, chain, &route, ""); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		node := funcNode(ref.fn, w.packagePath(ref.filename))
+		next := append(append([]CallNode(nil), chain...), node)
+		if err := w.walk(w.funcPosition(ref), ref.fn.Name.Name, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record adds a path carrying route or command for every binary that reaches
+// the registration at ref
+func (w *registrationWalk) record(ref reference, chain []CallNode, route *Route, command string) error {
+	mains, err := w.mainPaths(ref)
+	if err != nil {
+		return err
+	}
+	for _, main := range mains {
+		key := main.BinaryName + "\x00" + command
+		if route != nil {
+			key = main.BinaryName + "\x00" + route.Method + " " + route.Path
+		}
+		if w.seen[key] {
+			continue
+		}
+		w.seen[key] = true
+
+		nodes := append([]CallNode(nil), main.Path...)
+		for i := len(chain) - 1; i >= 0; i-- {
+			nodes = append(nodes, chain[i])
+		}
+		w.paths = append(w.paths, CallPath{
+			BinaryName: main.BinaryName,
+			MainURI:    main.MainURI,
+			Path:       nodes,
+			Route:      route,
+			Command:    command,
+		})
+	}
+	return nil
+}
+
+// mainPaths returns one path per binary from its main function down to the
+// function enclosing ref. Registrations in package-level declarations and init
+// functions run in every binary importing the package
+func (w *registrationWalk) mainPaths(ref reference) ([]CallPath, error) {
+	if ref.fn == nil || ref.fn.Recv == nil && ref.fn.Name.Name == "init" {
+		return w.importing(ref)
+	}
+	node := funcNode(ref.fn, w.packagePath(ref.filename))
+	if ref.fn.Recv == nil && ref.fn.Name.Name == "main" && ref.file.Name.Name == "main" {
+		return []CallPath{{
+			BinaryName: w.binaryName(ref.filename),
+			MainURI:    URIFromPath(ref.filename),
+			Path:       []CallNode{node},
+		}}, nil
+	}
+
+	pos := w.funcPosition(ref)
+	if paths, ok := w.mains[pos]; ok {
+		return paths, nil
+	}
+	w.mains[pos] = nil // Breaks cycles
+
+	refs, err := w.references(pos, ref.fn.Name.Name)
+	if err != nil {
+		return nil, err
+	}
+	var res []CallPath
+	binaries := make(map[string]bool)
+	for _, caller := range refs {
+		if strings.HasSuffix(caller.filename, "_test.go") {
+			continue
+		}
+		callerPaths, err := w.mainPaths(caller)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range callerPaths {
+			if binaries[path.BinaryName] {
+				continue
+			}
+			binaries[path.BinaryName] = true
+			path.Path = append(append([]CallNode(nil), path.Path...), node)
+			res = append(res, path)
+		}
+	}
+	w.mains[pos] = res
+	return res, nil
+}
+
+// importing returns a path for every main package importing the package of ref,
+// ending with the package-level declaration or init function containing ref
+func (w *registrationWalk) importing(ref reference) ([]CallPath, error) {
+	pkgPath := w.packagePath(ref.filename)
+	mains, ok := w.importers[pkgPath]
+	if !ok {
+		apiPaths, err := w.tracer.FindMainPackagesImporting(pkgPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, ap := range apiPaths {
+			mains = append(mains, CallPath{
+				BinaryName: w.mainBinaryName(ap),
+				MainURI:    ap.MainURI,
+				Path:       []CallNode{{FunctionName: "main", PackagePath: ap.Path[0].PackagePath}},
+			})
+		}
+		w.importers[pkgPath] = mains
+	}
+
+	name := declName(ref)
+	if name == "" {
+		return mains, nil
+	}
+	res := make([]CallPath, 0, len(mains))
+	for _, main := range mains {
+		main.Path = append(append([]CallNode(nil), main.Path...), CallNode{FunctionName: name, PackagePath: pkgPath})
+		res = append(res, main)
+	}
+	return res, nil
+}
+
+// declName returns the name of the init function or package-level variable containing ref
+func declName(ref reference) string {
+	if ref.fn != nil {
+		return ref.fn.Name.Name
+	}
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for _, n := range path {
+		if spec, ok := n.(*ast.ValueSpec); ok && len(spec.Names) > 0 {
+			return spec.Names[0].Name
+		}
+	}
+	return ""
+}
+
+// packageDirs returns the directories of the loaded packages by import path
+func (c *referenceWalk) packageDirs() map[string]string {
+	if c.dirs == nil {
+		c.dirs = make(map[string]string, len(c.pkgs))
+		for dir, pkgPath := range c.pkgs {
+			c.dirs[pkgPath] = dir
+		}
+	}
+	return c.dirs
+}
+
+// importDir returns the directory of the package the file filename refers to as
+// name, "" if it imports no such package or the directory is unknown
+func (c *referenceWalk) importDir(filename string, file *ast.File, name string) string {
+	var unnamed []string
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		dir := c.packageDir(path, filename)
+		switch {
+		case dir == "":
+		case imp.Name != nil:
+			if imp.Name.Name == name {
+				return dir
+			}
+		case pathpkg.Base(path) == name:
+			return dir
+		default:
+			unnamed = append(unnamed, dir)
+		}
+	}
+	// The package name may differ from the last element of the import path, e.g.
+	// billingv1 for billing/v1
+	for _, dir := range unnamed {
+		for _, f := range c.packageFiles(dir) {
+			if f.Name.Name == name {
+				return dir
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// packageFiles parses the non-test Go files in dir
+func (c *referenceWalk) packageFiles(dir string) map[string]*ast.File {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]*ast.File)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		filename := filepath.Join(dir, e.Name())
+		if file, err := c.parse(filename); err == nil {
+			files[filename] = file
+		}
+	}
+	return files
+}
+
+// funcDecl returns the function declared at pos, nil if pos is not a function name
+func (w *registrationWalk) funcDecl(pos ripplesapi.Position) *ast.FuncDecl {
+	file, err := w.parse(pos.Filename)
+	if err != nil {
+		return nil
+	}
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if p := w.fset.Position(fd.Name.Pos()); p.Line == pos.Line && p.Column == pos.Column {
+			return fd
+		}
+	}
+	return nil
+}
+
+// handlerName formats the function declared at pos as "pkg.Func" or, like path
+// nodes, "pkg.(*Type).Method"
+func (w *registrationWalk) handlerName(pos ripplesapi.Position, name string) string {
+	fd := w.funcDecl(pos)
+	pkg := filepath.Base(filepath.Dir(pos.Filename))
+	if file := w.files[pos.Filename]; file != nil {
+		pkg = file.Name.Name
+	}
+	if fd != nil {
+		return pkg + "." + funcNode(fd, "").Name()
+	}
+	return pkg + "." + name
+}
+
+// receiverName returns the type name of a receiver, without pointer and type parameters
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// stringLit returns the value of a string literal
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// contains reports whether pos lies within node
+func contains(node ast.Node, pos token.Pos) bool {
+	return node.Pos() <= pos && pos < node.End()
+}
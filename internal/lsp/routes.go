@@ -0,0 +1,314 @@
+package lsp
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// Route is an HTTP endpoint or gRPC method served by a binary
+type Route struct {
+	Method  string // HTTP method, empty if any method matches; GRPCMethod for gRPC methods
+	Path    string // URL pattern, or "Service/Method" for gRPC
+	Handler string // Registered function, e.g. "handler.(*Users).List"
+}
+
+// GRPCMethod is the Route.Method of gRPC methods
+const GRPCMethod = "gRPC"
+
+// httpMethods maps gin/echo (upper case) and chi (title case) registration
+// methods to the HTTP method they register
+var httpMethods = map[string]string{
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "DELETE": "DELETE", "PATCH": "PATCH",
+	"HEAD": "HEAD", "OPTIONS": "OPTIONS", "CONNECT": "CONNECT", "TRACE": "TRACE", "Any": "",
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Delete": "DELETE", "Patch": "PATCH",
+	"Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+}
+
+// registerServerRe matches generated gRPC registration functions such as RegisterUserServiceServer
+var registerServerRe = regexp.MustCompile(`^Register(\w+)Server$`)
+
+// httpMethodRe matches the method of a net/http pattern such as "GET /users"
+var httpMethodRe = regexp.MustCompile(`^[A-Z]+$`)
+
+// grpc records the gRPC methods served by the method fd declared at pos when its
+// receiver type is registered with a Register*Server function, directly or
+// through a constructor returning it
+func (w *registrationWalk) grpc(pos ripplesapi.Position, fd *ast.FuncDecl, chain []CallNode) error {
+	typePos, typeName, ok := w.receiverType(pos.Filename, fd)
+	if !ok {
+		return nil
+	}
+	refs, err := w.references(typePos, typeName)
+	if err != nil {
+		return err
+	}
+	handler := w.handlerName(pos, fd.Name.Name)
+	for _, ref := range refs {
+		if ref.fn == nil || strings.HasSuffix(ref.filename, "_test.go") {
+			continue
+		}
+		if service, ok := w.grpcRegistration(ref); ok {
+			route := Route{Method: GRPCMethod, Path: service + "/" + fd.Name.Name, Handler: handler}
+			if err := w.record(ref, chain, &route, ""); err != nil {
+				return err
+			}
+			continue
+		}
+		// A constructor returning the server, e.g. pb.RegisterUserServer(s, rpc.NewServer(db))
+		if ref.fn.Recv != nil || ref.fn.Type.Results == nil || !contains(ref.fn.Type.Results, ref.pos) {
+			continue
+		}
+		ctorRefs, err := w.references(w.funcPosition(ref), ref.fn.Name.Name)
+		if err != nil {
+			return err
+		}
+		for _, ctorRef := range ctorRefs {
+			if ctorRef.fn == nil || strings.HasSuffix(ctorRef.filename, "_test.go") {
+				continue
+			}
+			if service, ok := w.grpcRegistration(ctorRef); ok {
+				route := Route{Method: GRPCMethod, Path: service + "/" + fd.Name.Name, Handler: handler}
+				if err := w.record(ctorRef, chain, &route, ""); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// httpRoute recognizes ref as a handler argument of a router registration such as
+// mux.HandleFunc("GET /users", h.List), r.GET("/users", h.List) or
+// r.Get("/users", h.List), including handlers wrapped in other calls
+func (w *registrationWalk) httpRoute(ref reference) (Route, bool) {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for i, n := range path {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		route, handlers, ok := registration(sel.Sel.Name, call.Args)
+		if !ok || len(call.Args) <= handlers || ref.pos < call.Args[handlers].Pos() {
+			continue
+		}
+		prefix := groupPrefix(sel.X, ref.fn, call.Pos())
+		// chi mounts sub-routers with r.Route("/prefix", func(r chi.Router) { ... })
+		for _, outer := range path[i+1:] {
+			if call, ok := outer.(*ast.CallExpr); ok {
+				if p, ok := routePrefix(call); ok {
+					prefix = joinRoute(p, prefix)
+				}
+			}
+		}
+		route.Path = joinRoute(prefix, route.Path)
+		return route, true
+	}
+	return Route{}, false
+}
+
+// registration parses the arguments of a router method call and returns the
+// route and the index of the first handler argument
+func registration(method string, args []ast.Expr) (Route, int, bool) {
+	switch method {
+	case "Handle", "HandleFunc", "Method", "MethodFunc":
+		// gin r.Handle("GET", "/users", h) and chi r.Method("GET", "/users", h)
+		if len(args) >= 3 {
+			m, ok1 := stringLit(args[0])
+			p, ok2 := stringLit(args[1])
+			if ok1 && ok2 {
+				return Route{Method: strings.ToUpper(m), Path: p}, 2, true
+			}
+		}
+		// net/http patterns may start with a method: "GET /users"
+		pattern, ok := stringLit(args[0])
+		if !ok || len(args) < 2 {
+			return Route{}, 0, false
+		}
+		if m, p, ok := strings.Cut(pattern, " "); ok && httpMethodRe.MatchString(m) {
+			return Route{Method: m, Path: strings.TrimSpace(p)}, 1, true
+		}
+		return Route{Path: pattern}, 1, true
+	}
+	m, ok := httpMethods[method]
+	if !ok || len(args) < 2 {
+		return Route{}, 0, false
+	}
+	p, ok := stringLit(args[0])
+	if !ok {
+		return Route{}, 0, false
+	}
+	return Route{Method: m, Path: p}, 1, true
+}
+
+// groupPrefix resolves the prefix of a router group such as r.Group("/api"),
+// following variables assigned in fn before pos
+func groupPrefix(x ast.Expr, fn *ast.FuncDecl, pos token.Pos) string {
+	switch x := x.(type) {
+	case *ast.CallExpr:
+		sel, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Group" || len(x.Args) == 0 {
+			return ""
+		}
+		p, ok := stringLit(x.Args[0])
+		if !ok {
+			return ""
+		}
+		return joinRoute(groupPrefix(sel.X, fn, x.Pos()), p)
+	case *ast.Ident:
+		value := assignedValue(fn.Body, x.Name, pos)
+		if value == nil {
+			return ""
+		}
+		return groupPrefix(value, fn, value.Pos())
+	}
+	return ""
+}
+
+// assignedValue returns the last value assigned to the variable name in body before pos
+func assignedValue(body *ast.BlockStmt, name string, pos token.Pos) ast.Expr {
+	var value ast.Expr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil || n.Pos() >= pos {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == name && len(n.Rhs) == len(n.Lhs) {
+					value = n.Rhs[i]
+				}
+			}
+		case *ast.ValueSpec:
+			for i, id := range n.Names {
+				if id.Name == name && len(n.Values) == len(n.Names) {
+					value = n.Values[i]
+				}
+			}
+		}
+		return true
+	})
+	return value
+}
+
+// routePrefix returns the prefix of a chi r.Route("/prefix", func(r chi.Router) {...}) call
+func routePrefix(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Route" || len(call.Args) != 2 {
+		return "", false
+	}
+	if _, ok := call.Args[1].(*ast.FuncLit); !ok {
+		return "", false
+	}
+	return stringLit(call.Args[0])
+}
+
+// grpcRegistration recognizes ref as the server argument of a generated
+// Register*Server call and returns the service name. The name is qualified with
+// the proto package, e.g. "billing.v1.BillingService", when the ServiceDesc in the
+// generated code is found
+func (w *registrationWalk) grpcRegistration(ref reference) (string, bool) {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for _, n := range path {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 || ref.pos < call.Args[1].Pos() {
+			continue
+		}
+		var name string
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			name = fun.Name
+		case *ast.SelectorExpr:
+			name = fun.Sel.Name
+		}
+		if m := registerServerRe.FindStringSubmatch(name); m != nil {
+			if full := w.grpcServiceName(ref, call.Fun, m[1]); full != "" {
+				return full, true
+			}
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// grpcServiceName returns the ServiceName of the ServiceDesc for service in the
+// package declaring the registration function fun, "" if there is none
+func (w *registrationWalk) grpcServiceName(ref reference, fun ast.Expr, service string) string {
+	dir := filepath.Dir(ref.filename)
+	if sel, ok := fun.(*ast.SelectorExpr); ok {
+		x, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		if dir = w.importDir(ref.filename, ref.file, x.Name); dir == "" {
+			return ""
+		}
+	}
+	for _, file := range w.packageFiles(dir) {
+		found := ""
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return found == ""
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok || !isIdent(kv.Key, "ServiceName") {
+					continue
+				}
+				if name, ok := stringLit(kv.Value); ok && (name == service || strings.HasSuffix(name, "."+service)) {
+					found = name
+				}
+			}
+			return found == ""
+		})
+		if found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
+// receiverType returns the position and name of the declaration of fd's receiver
+// type, which is in the same package directory as filename
+func (w *registrationWalk) receiverType(filename string, fd *ast.FuncDecl) (ripplesapi.Position, string, bool) {
+	name := receiverName(fd.Recv.List[0].Type)
+	if name == "" {
+		return ripplesapi.Position{}, "", false
+	}
+	for _, file := range w.packageFiles(filepath.Dir(filename)) {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					p := w.fset.Position(ts.Name.Pos())
+					return ripplesapi.Position{Filename: p.Filename, Line: p.Line, Column: p.Column}, name, true
+				}
+			}
+		}
+	}
+	return ripplesapi.Position{}, "", false
+}
+
+// joinRoute joins a group prefix and a route path
+func joinRoute(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestHTTPRoute(t *testing.T) {
+	src := `package main
+
+func main() {
+	mux.HandleFunc("POST /orders", createOrder)
+	mux.Handle("/metrics", promhttp.HandlerFunc(metrics))
+	api := r.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/users/:id", auth(getUser))
+	e.Group("/admin").DELETE("/users/:id", deleteUser)
+	g.Handle("PUT", "/items", putItem)
+	c.Route("/books", func(r chi.Router) {
+		r.Get("/", listBooks)
+		r.Method("PATCH", "/{id}", patchBook)
+	})
+	mux.HandleFunc(pattern, dynamic)
+	run(notARoute)
+}
+`
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "main.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := file.Decls[0].(*ast.FuncDecl)
+	idents := make(map[string]token.Pos)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			idents[id.Name] = id.Pos()
+		}
+		return true
+	})
+
+	tests := []struct {
+		handler string
+		want    string
+	}{
+		{"createOrder", "POST /orders"},
+		{"metrics", "/metrics"},
+		{"getUser", "GET /api/v1/users/:id"},
+		{"deleteUser", "DELETE /admin/users/:id"},
+		{"putItem", "PUT /items"},
+		{"listBooks", "GET /books/"},
+		{"patchBook", "PATCH /books/{id}"},
+		{"dynamic", ""},
+		{"notARoute", ""},
+	}
+	w := &registrationWalk{}
+	for _, tt := range tests {
+		route, ok := w.httpRoute(reference{file: file, pos: idents[tt.handler], fn: fn})
+		got := ""
+		if ok {
+			got = route.Path
+			if route.Method != "" {
+				got = route.Method + " " + got
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.handler, got, tt.want)
+		}
+	}
+}
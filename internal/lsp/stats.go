@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// RequestStats summarizes the requests made to the gopls tracer with one method
+type RequestStats struct {
+	Method string
+	Count  int
+	Total  time.Duration // Sum of the request latencies
+	Max    time.Duration // Slowest request
+	// CacheHits counts requests repeating an earlier or in-flight one of the same
+	// run, which are answered from the shared results table.
+	// Hits of the persistent cache on disk happen inside gopls and are not
+	// visible here
+	CacheHits int
+}
+
+// countingTracer wraps the gopls tracer to record the count and latency of
+// every request. It is shared by all walks and safe for concurrent use.
+//
+// Results are shared per request across the whole run: a request with the same
+// method and arguments as an earlier or in-flight one waits for its result
+// instead of reaching gopls again. Only whole requests are deduplicated; the
+// callers explored inside one TraceToMain are not visible through the fork's
+// API, so traces of different symbols that funnel through the same callers
+// still explore them once per symbol. Failed requests are not kept
+type countingTracer struct {
+	tracer *ripplesapi.DirectTracer
+
+	mu      sync.Mutex
+	stats   map[string]*RequestStats
+	results map[string]*sharedResult // Method and arguments -> result
+}
+
+// sharedResult is the result of a tracer request, ready once done is closed
+type sharedResult struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newCountingTracer(tracer *ripplesapi.DirectTracer) *countingTracer {
+	return &countingTracer{
+		tracer:  tracer,
+		stats:   make(map[string]*RequestStats),
+		results: make(map[string]*sharedResult),
+	}
+}
+
+// shared returns the result of the request of method identified by key, calling
+// fn unless the same request was made before or is in flight
+func shared[T any](t *countingTracer, method, key string, fn func() (T, error)) (T, error) {
+	key = method + "|" + key
+	start := time.Now()
+	t.mu.Lock()
+	r, hit := t.results[key]
+	if !hit {
+		r = &sharedResult{done: make(chan struct{})}
+		t.results[key] = r
+	}
+	t.mu.Unlock()
+	defer t.observe(method, start, hit)
+
+	if !hit {
+		value, err := fn()
+		r.value, r.err = value, err
+		if err != nil {
+			t.mu.Lock()
+			delete(t.results, key)
+			t.mu.Unlock()
+		}
+		close(r.done)
+	}
+	<-r.done
+	value, _ := r.value.(T)
+	return value, r.err
+}
+
+// observe records a request of method that started at start
+func (t *countingTracer) observe(method string, start time.Time, hit bool) {
+	d := time.Since(start)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.stats[method]
+	if s == nil {
+		s = &RequestStats{Method: method}
+		t.stats[method] = s
+	}
+	s.Count++
+	s.Total += d
+	s.Max = max(s.Max, d)
+	if hit {
+		s.CacheHits++
+	}
+}
+
+func (t *countingTracer) TraceToMain(pos ripplesapi.Position, name string) ([]ripplesapi.CallPath, error) {
+	return shared(t, "TraceToMain", positionKey(pos, name), func() ([]ripplesapi.CallPath, error) {
+		return t.tracer.TraceToMain(pos, name)
+	})
+}
+
+func (t *countingTracer) TraceReferencesToMain(pos ripplesapi.Position, name string) ([]ripplesapi.CallPath, error) {
+	return shared(t, "TraceReferencesToMain", positionKey(pos, name), func() ([]ripplesapi.CallPath, error) {
+		return t.tracer.TraceReferencesToMain(pos, name)
+	})
+}
+
+func (t *countingTracer) FindReferences(pos ripplesapi.Position, name string) ([]ripplesapi.Reference, error) {
+	return shared(t, "FindReferences", positionKey(pos, name), func() ([]ripplesapi.Reference, error) {
+		return t.tracer.FindReferences(pos, name)
+	})
+}
+
+func (t *countingTracer) FindMainPackagesImporting(pkgPath string) ([]ripplesapi.CallPath, error) {
+	return shared(t, "FindMainPackagesImporting", pkgPath, func() ([]ripplesapi.CallPath, error) {
+		return t.tracer.FindMainPackagesImporting(pkgPath)
+	})
+}
+
+// positionKey identifies a request about the symbol named name at pos, in the
+// same format as the tracer's own cache
+func positionKey(pos ripplesapi.Position, name string) string {
+	return fmt.Sprintf("%s:%d:%d:%s", pos.Filename, pos.Line, pos.Column, name)
+}
+
+func (t *countingTracer) Close() error {
+	return t.tracer.Close()
+}
+
+// Stats returns the requests made to the gopls tracer so far, by method name
+func (t *DirectCallTracer) Stats() []RequestStats {
+	t.tracer.mu.Lock()
+	defer t.tracer.mu.Unlock()
+	res := make([]RequestStats, 0, len(t.tracer.stats))
+	for _, s := range t.tracer.stats {
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Method < res[j].Method })
+	return res
+}
@@ -0,0 +1,55 @@
+package lsp
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSharedResults(t *testing.T) {
+	tracer := newCountingTracer(nil)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	explore := func() ([]string, error) {
+		calls.Add(1)
+		<-release
+		return []string{"api"}, nil
+	}
+
+	// Requests for the same function, concurrent or later, share one exploration
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mains, err := shared(tracer, "TraceToMain", "serve", explore)
+			if err != nil || len(mains) != 1 || mains[0] != "api" {
+				t.Errorf("shared() = %v, %v", mains, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected one exploration, got %d", n)
+	}
+	if _, err := shared(tracer, "TraceToMain", "other", explore); err != nil {
+		t.Fatal(err)
+	}
+	stats := tracer.stats["TraceToMain"]
+	if stats.Count != 5 || stats.CacheHits != 3 {
+		t.Errorf("Expected 5 requests with 3 cache hits, got %+v", *stats)
+	}
+
+	// Failed requests are retried
+	fail := errors.New("canceled")
+	for range 2 {
+		if _, err := shared(tracer, "FindReferences", "serve", func() ([]string, error) { return nil, fail }); err != fail {
+			t.Errorf("Expected the request error, got %v", err)
+		}
+	}
+	if hits := tracer.stats["FindReferences"].CacheHits; hits != 0 {
+		t.Errorf("Expected failed requests not to be shared, got %d hits", hits)
+	}
+}
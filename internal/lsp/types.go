@@ -4,6 +4,9 @@ package lsp
 type CallNode struct {
 	FunctionName string
 	PackagePath  string
+	// Receiver is the receiver type of a method as declared, such as "*Handler",
+	// without type parameters. Empty for functions and when unknown
+	Receiver string
 }
 
 // CallPath represents a call path from a changed symbol to a main function
@@ -11,4 +14,11 @@ type CallPath struct {
 	BinaryName string
 	MainURI    string
 	Path       []CallNode
+	Custom     bool   // Rooted at a custom entrypoint rather than a main function
+	Route      *Route // Route registration the path goes through, set by TraceRegistrations
+	Command    string // Subcommand registration the path goes through, set by TraceRegistrations
+	Job        string // Configured job the path goes through, set by TraceToJobs
+	// Approximate marks a path derived from package imports rather than calls,
+	// set by TraceImporters
+	Approximate bool
 }
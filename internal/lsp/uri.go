@@ -0,0 +1,52 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// URIFromPath converts a file path to a file URI the way gopls does: the path
+// is percent-encoded, so spaces and non-ASCII characters survive, and Windows
+// drive letters get a leading slash (file:///C:/src/main.go)
+func URIFromPath(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	p := filepath.ToSlash(filename)
+	if isWindowsDrivePath(p) {
+		p = "/" + strings.ToUpper(p[:1]) + p[1:]
+	}
+	u := url.URL{Scheme: "file", Path: p}
+	return u.String()
+}
+
+// PathFromURI converts a file URI reported by gopls back to a file path. Values
+// that are not file URIs are returned unchanged
+func PathFromURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	p := u.Path
+	if len(p) > 0 && p[0] == '/' && isWindowsDrivePath(p[1:]) {
+		p = p[1:]
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.FromSlash(p)
+	}
+	return p
+}
+
+// isWindowsDrivePath reports whether a slash-separated path starts with a drive letter, e.g. C:/src
+func isWindowsDrivePath(p string) bool {
+	if len(p) < 3 || p[1] != ':' || p[2] != '/' {
+		return false
+	}
+	c := p[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,43 @@
+package lsp
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestURIFromPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix paths")
+	}
+	tests := map[string]string{
+		"/repo/cmd/api/main.go":    "file:///repo/cmd/api/main.go",
+		"/my repo/cmd/api/main.go": "file:///my%20repo/cmd/api/main.go",
+		"/仓库/cmd/api/main.go":      "file:///%E4%BB%93%E5%BA%93/cmd/api/main.go",
+		"/repo/cmd/a#b%c/main.go":  "file:///repo/cmd/a%23b%25c/main.go",
+	}
+	for path, want := range tests {
+		uri := URIFromPath(path)
+		if uri != want {
+			t.Errorf("URIFromPath(%q) = %q, want %q", path, uri, want)
+		}
+		if got := PathFromURI(uri); got != path {
+			t.Errorf("PathFromURI(%q) = %q, want %q", uri, got, path)
+		}
+	}
+}
+
+func TestPathFromURI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("slash-separated expectations")
+	}
+	tests := map[string]string{
+		"file:///C:/src/cmd/api/main.go": "C:/src/cmd/api/main.go",
+		"file:///c%3A/src/main.go":       "c:/src/main.go",
+		"not a uri":                      "not a uri",
+	}
+	for uri, want := range tests {
+		if got := PathFromURI(uri); got != want {
+			t.Errorf("PathFromURI(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
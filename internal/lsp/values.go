@@ -0,0 +1,394 @@
+package lsp
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/parser"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/pkg/ripplesapi"
+)
+
+// TraceFunctionValues follows a function that is passed around as a value. gopls
+// reports a function used inside another function as called by it, but loses track
+// once the value is stored in a package-level variable, such as a registry map
+// initialized with the function or a hook slice appended to in init. The walk
+// continues from the functions reading such variables, so a path may run through
+// variable nodes. Like Reachability it does not skip binaries found by earlier traces
+func (t *DirectCallTracer) TraceFunctionValues(symbol *parser.Symbol) ([]CallPath, error) {
+	return t.traceValues(symbol, "")
+}
+
+// TraceMethodValues follows a method used as a value rather than called: a bound
+// method value such as s.Status stored in a handler table, or a method expression
+// such as Codec.Encode. gopls reports the function creating the value as the caller,
+// so a table filled in a constructor is only attributed to the constructor's callers.
+// The walk continues from the variables and struct fields holding the value to the
+// functions reading them. Plain calls x.M() are left to the call hierarchy
+func (t *DirectCallTracer) TraceMethodValues(symbol *parser.Symbol) ([]CallPath, error) {
+	extra, ok := symbol.Extra.(parser.FunctionExtra)
+	if !ok || !extra.IsMethod {
+		return nil, nil
+	}
+	recv, err := goparser.ParseExpr(extra.ReceiverType)
+	if err != nil {
+		return nil, nil
+	}
+	return t.traceValues(symbol, receiverName(recv))
+}
+
+// traceValues walks the value uses of symbol. A non-empty receiver skips the plain
+// calls of a method of that type
+func (t *DirectCallTracer) traceValues(symbol *parser.Symbol, receiver string) ([]CallPath, error) {
+	c := t.newReferenceWalk()
+	pos, err := c.namePosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+	w := &registrationWalk{
+		referenceWalk: c,
+		mains:         make(map[ripplesapi.Position][]CallPath),
+		importers:     make(map[string][]CallPath),
+		seen:          make(map[string]bool),
+	}
+	v := &valueWalk{registrationWalk: w, receiver: receiver}
+	start := SymbolNode(symbol)
+	if err := v.walk(pos, symbol.Name, []CallNode{start}, false); err != nil {
+		return nil, err
+	}
+	return v.paths, nil
+}
+
+// valueWalk follows function values through package-level variables and struct fields
+type valueWalk struct {
+	*registrationWalk
+	receiver string // Receiver type name of a traced method, empty for functions
+}
+
+// walk visits the uses of the function or variable declared at pos. chain holds
+// the nodes from the symbol up to and including the current one
+func (w *valueWalk) walk(pos ripplesapi.Position, name string, chain []CallNode, variable bool) error {
+	if w.visited[pos] {
+		return nil
+	}
+	w.visited[pos] = true
+	if len(chain) > maxReachabilityDepth {
+		return nil
+	}
+
+	refs, err := w.references(pos, name)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.filename, "_test.go") || variable && isAssigned(ref) {
+			continue
+		}
+		if len(chain) == 1 && w.receiver != "" && isMethodCall(ref, w.receiver) {
+			continue
+		}
+		if id := w.storedIn(ref); id != nil {
+			p := w.fset.Position(id.Pos())
+			next := append(append([]CallNode(nil), chain...), CallNode{FunctionName: id.Name, PackagePath: w.packagePath(p.Filename)})
+			if err := w.walk(ripplesapi.Position{Filename: p.Filename, Line: p.Line, Column: p.Column}, id.Name, next, true); err != nil {
+				return err
+			}
+			continue
+		}
+		if typ, id := w.storedInField(ref); id != nil {
+			p := w.fset.Position(id.Pos())
+			next := append(append([]CallNode(nil), chain...), CallNode{FunctionName: typ + "." + id.Name, PackagePath: w.packagePath(p.Filename)})
+			if err := w.walk(ripplesapi.Position{Filename: p.Filename, Line: p.Line, Column: p.Column}, id.Name, next, true); err != nil {
+				return err
+			}
+			continue
+		}
+		if ref.fn == nil {
+			continue
+		}
+		isMain := ref.fn.Recv == nil && ref.fn.Name.Name == "main" && ref.file.Name.Name == "main"
+		if isMain || ref.fn.Recv == nil && ref.fn.Name.Name == "init" {
+			if err := w.record(ref, chain, nil, ""); err != nil {
+				return err
+			}
+			continue
+		}
+		node := funcNode(ref.fn, w.packagePath(ref.filename))
+		next := append(append([]CallNode(nil), chain...), node)
+		if err := w.walk(w.funcPosition(ref), ref.fn.Name.Name, next, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storedIn returns the package-level variable the value at ref is stored in: the
+// variable whose initializer contains it, the variable assigned to (including
+// through an index or field, or by append), or the variable whose method receives
+// it as an argument. Variables local to a function are followed through the
+// function itself, so they are not reported
+func (w *valueWalk) storedIn(ref reference) *ast.Ident {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			if ref.fn != nil {
+				return nil
+			}
+			for i, v := range n.Values {
+				if contains(v, ref.pos) && i < len(n.Names) {
+					return n.Names[i]
+				}
+			}
+			return nil
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				if !contains(rhs, ref.pos) {
+					continue
+				}
+				lhs := n.Lhs[0]
+				if len(n.Lhs) == len(n.Rhs) {
+					lhs = n.Lhs[i]
+				}
+				return w.packageVar(ref, rootIdent(lhs))
+			}
+			return nil
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || len(n.Args) == 0 || ref.pos < n.Args[0].Pos() {
+				continue
+			}
+			if id := w.packageVar(ref, rootIdent(sel.X)); id != nil {
+				return id
+			}
+		case ast.Stmt:
+			return nil
+		}
+	}
+	return nil
+}
+
+// storedInField returns the struct field the value at ref is stored in, with the
+// name of the struct type: the field assigned to, such as s.routes in
+// s.routes["a"] = v, or the field keyed in a struct literal. Only struct types
+// declared in ref's package are recognized
+func (w *valueWalk) storedInField(ref reference) (string, *ast.Ident) {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	dir := filepath.Dir(ref.filename)
+	for i, n := range path {
+		switch n := n.(type) {
+		case *ast.KeyValueExpr:
+			key, ok := n.Key.(*ast.Ident)
+			if !ok || !contains(n.Value, ref.pos) || i+1 == len(path) {
+				continue
+			}
+			lit, ok := path[i+1].(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			if typ, ok := lit.Type.(*ast.Ident); ok {
+				if name, id := w.structField(dir, typ.Name, key.Name); id != nil {
+					return name, id
+				}
+			}
+		case *ast.AssignStmt:
+			if !slices.ContainsFunc(n.Rhs, func(rhs ast.Expr) bool { return contains(rhs, ref.pos) }) {
+				return "", nil
+			}
+			for _, lhs := range n.Lhs {
+				if field := fieldSelector(lhs); field != nil {
+					return w.structField(dir, "", field.Name)
+				}
+			}
+			return "", nil
+		case ast.Stmt:
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// structField returns the declaration of the field named field of a struct type
+// declared in dir, with the type's name. An empty typ matches any struct type
+func (w *valueWalk) structField(dir, typ, field string) (string, *ast.Ident) {
+	for _, file := range w.packageFiles(dir) {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || typ != "" && ts.Name.Name != typ {
+					continue
+				}
+				for _, f := range st.Fields.List {
+					for _, name := range f.Names {
+						if name.Name == field {
+							return ts.Name.Name, name
+						}
+					}
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// fieldSelector returns the field selected by an assignment target, such as
+// routes in s.routes["a"], nil if the target is not a field
+func fieldSelector(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			return e.Sel
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+// isMethodCall reports whether ref is a plain call x.M() of a method. Calls through
+// a method expression of the receiver type, such as Codec.Encode(c, v) or
+// (*Server).Status(s), are not plain calls
+func isMethodCall(ref reference, receiver string) bool {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	if len(path) < 3 {
+		return false
+	}
+	sel, ok := path[1].(*ast.SelectorExpr)
+	if !ok || sel.Sel.Pos() != ref.pos {
+		return false
+	}
+	call, ok := path[2].(*ast.CallExpr)
+	if !ok || call.Fun != sel {
+		return false
+	}
+	x := sel.X
+	for {
+		switch e := x.(type) {
+		case *ast.ParenExpr:
+			x = e.X
+			continue
+		case *ast.StarExpr:
+			x = e.X
+			continue
+		case *ast.SelectorExpr:
+			return e.Sel.Name != receiver
+		case *ast.Ident:
+			return e.Name != receiver
+		}
+		return true
+	}
+}
+
+// packageVar returns the declaration of the package-level variable id refers to,
+// nil if id is nil, declared in a function or not a variable of ref's package
+func (w *valueWalk) packageVar(ref reference, id *ast.Ident) *ast.Ident {
+	if id == nil || ref.fn != nil && declaredIn(ref.fn, id.Name) {
+		return nil
+	}
+	for _, file := range w.packageFiles(filepath.Dir(ref.filename)) {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if name.Name == id.Name {
+						return name
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// declaredIn reports whether fn declares a parameter, result or local variable named name
+func declaredIn(fn *ast.FuncDecl, name string) bool {
+	found := false
+	ast.Inspect(fn, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			for _, id := range n.Names {
+				found = found || id.Name == name
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok && id.Name == name {
+						found = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				found = found || id.Name == name
+			}
+		case *ast.RangeStmt:
+			for _, e := range []ast.Expr{n.Key, n.Value} {
+				if id, ok := e.(*ast.Ident); ok && n.Tok == token.DEFINE && id.Name == name {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isAssigned reports whether ref is the variable written by an assignment, such as
+// registry in registry["a"] = f, rather than a read of its value
+func isAssigned(ref reference) bool {
+	path, _ := astutil.PathEnclosingInterval(ref.file, ref.pos, ref.pos)
+	for _, n := range path {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok {
+			continue
+		}
+		for _, lhs := range assign.Lhs {
+			if id := rootIdent(lhs); id != nil && id.Pos() == ref.pos {
+				return true
+			}
+			if field := fieldSelector(lhs); field != nil && field.Pos() == ref.pos {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// rootIdent returns the variable at the root of an index, field or dereference
+// expression, e.g. registry in registry["a"] or s.hooks
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
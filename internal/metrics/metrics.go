@@ -0,0 +1,158 @@
+// Package metrics 记录分析过程的性能指标并以 Prometheus 文本格式导出
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// 指标名称
+const (
+	PhaseDuration    = "ripples_phase_duration_seconds"
+	AnalysisDuration = "ripples_analysis_duration_seconds"
+	ChangedFiles     = "ripples_changed_files"
+	SymbolsAnalyzed  = "ripples_symbols_analyzed"
+	AffectedServices = "ripples_affected_services"
+	LastSuccess      = "ripples_last_success_timestamp_seconds"
+	PeakMemory       = "ripples_peak_rss_bytes"
+	TraceRequests    = "ripples_trace_requests"
+	TraceCacheHits   = "ripples_trace_cache_hits"
+)
+
+var helps = map[string]string{
+	PhaseDuration:    "Duration of each analysis phase in seconds.",
+	AnalysisDuration: "Total analysis duration in seconds.",
+	ChangedFiles:     "Number of changed Go files.",
+	SymbolsAnalyzed:  "Number of changed symbols analyzed.",
+	AffectedServices: "Number of affected services.",
+	LastSuccess:      "Unix time of the last successful analysis.",
+	PeakMemory:       "Peak resident set size of the ripples process in bytes.",
+	TraceRequests:    "Number of call hierarchy traces requested.",
+	TraceCacheHits:   "Number of call hierarchy traces answered from the in-run cache.",
+}
+
+// httpClient 推送指标使用的 HTTP 客户端。Pushgateway 无响应时不阻塞 CI 任务
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Recorder 收集一次分析的指标
+type Recorder struct {
+	mu     sync.Mutex
+	phases map[string]float64
+	gauges map[string]float64
+}
+
+// NewRecorder 创建指标收集器
+func NewRecorder() *Recorder {
+	return &Recorder{
+		phases: make(map[string]float64),
+		gauges: make(map[string]float64),
+	}
+}
+
+// ObservePhase 记录某个阶段的耗时
+func (r *Recorder) ObservePhase(phase string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phases[phase] = d.Seconds()
+}
+
+// Set 设置一个无标签的 gauge 指标
+func (r *Recorder) Set(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = value
+}
+
+// WriteTo 以 Prometheus 文本格式写出所有指标
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b bytes.Buffer
+	if len(r.phases) > 0 {
+		writeHeader(&b, PhaseDuration)
+		for _, phase := range sortedKeys(r.phases) {
+			fmt.Fprintf(&b, "%s{phase=%q} %g\n", PhaseDuration, phase, r.phases[phase])
+		}
+	}
+	for _, name := range sortedKeys(r.gauges) {
+		writeHeader(&b, name)
+		fmt.Fprintf(&b, "%s %g\n", name, r.gauges[name])
+	}
+	return b.WriteTo(w)
+}
+
+func writeHeader(b *bytes.Buffer, name string) {
+	if help, ok := helps[name]; ok {
+		fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Push 将指标推送到 Prometheus Pushgateway,覆盖同一 job/grouping 下的旧值
+func (r *Recorder) Push(ctx context.Context, gatewayURL, job string, grouping map[string]string) error {
+	if job == "" {
+		return i18n.Errorf("Pushgateway job 名称不能为空")
+	}
+
+	path := "/metrics/job/" + url.PathEscape(job)
+	keys := make([]string, 0, len(grouping))
+	for k := range grouping {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path += groupingSegment(k, grouping[k])
+	}
+
+	var body bytes.Buffer
+	if _, err := r.WriteTo(&body); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSuffix(gatewayURL, "/")+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return i18n.Errorf("推送指标失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return i18n.Errorf("Pushgateway 返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// groupingSegment 生成 grouping key 路径段,包含 "/" 或为空的值按 Pushgateway 约定使用 base64 编码
+func groupingSegment(key, value string) string {
+	if value == "" || strings.Contains(value, "/") {
+		return "/" + url.PathEscape(key) + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return "/" + url.PathEscape(key) + "/" + url.PathEscape(value)
+}
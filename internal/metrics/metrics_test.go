@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRecorder()
+	r.ObservePhase("trace", 1500*time.Millisecond)
+	r.ObservePhase("diff", 250*time.Millisecond)
+	r.Set(AffectedServices, 3)
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	want := `# HELP ripples_phase_duration_seconds Duration of each analysis phase in seconds.
+# TYPE ripples_phase_duration_seconds gauge
+ripples_phase_duration_seconds{phase="diff"} 0.25
+ripples_phase_duration_seconds{phase="trace"} 1.5
+# HELP ripples_affected_services Number of affected services.
+# TYPE ripples_affected_services gauge
+ripples_affected_services 3
+`
+	if b.String() != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestPush(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotMethod = req.Method
+		gotPath = req.URL.EscapedPath()
+		data, _ := io.ReadAll(req.Body)
+		gotBody = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	r := NewRecorder()
+	r.Set(SymbolsAnalyzed, 7)
+	err := r.Push(context.Background(), srv.URL+"/", "ripples", map[string]string{"repo": "org/app"})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	if gotMethod != http.MethodPut {
+		t.Errorf("Expected PUT, got %s", gotMethod)
+	}
+	if gotPath != "/metrics/job/ripples/repo@base64/b3JnL2FwcA" {
+		t.Errorf("Unexpected path %s", gotPath)
+	}
+	if !strings.Contains(gotBody, "ripples_symbols_analyzed 7") {
+		t.Errorf("Body missing metric:\n%s", gotBody)
+	}
+}
+
+func TestPushError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "bad", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if err := NewRecorder().Push(context.Background(), srv.URL, "ripples", nil); err == nil {
+		t.Error("Expected error on non-2xx response")
+	}
+}
+
+func TestPushTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	client := httpClient
+	defer func() { httpClient = client }()
+	httpClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	if err := NewRecorder().Push(context.Background(), srv.URL, "ripples", nil); err == nil {
+		t.Error("Expected error when the Pushgateway does not respond")
+	}
+}
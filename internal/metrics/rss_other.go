@@ -0,0 +1,8 @@
+//go:build !unix
+
+package metrics
+
+// PeakRSS 返回当前进程的峰值常驻内存(字节),该平台不支持时为 0
+func PeakRSS() int64 {
+	return 0
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package metrics
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// PeakRSS 返回当前进程的峰值常驻内存(字节),包括进程内运行的 gopls,不包括 go list 等子进程
+func PeakRSS() int64 {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	// macOS 以字节为单位,其余系统以 KiB 为单位
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return int64(usage.Maxrss)
+	}
+	return int64(usage.Maxrss) * 1024
+}
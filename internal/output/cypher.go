@@ -0,0 +1,146 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+)
+
+// cypherSuffixes 调用链节点上标记入口和变更符号的后缀
+var cypherSuffixes = []string{" (main)", " (entrypoint)", " (Changed)"}
+
+// RenderCypher 生成导入 Neo4j 的 Cypher 语句,每行一条:
+//   - (:Service {name}) 受影响的服务,带有包路径、目录、部署标识和负责人
+//   - (:Symbol {name}) 变更符号,(:Service)-[:AFFECTED_BY]->(:Symbol) 带有可信度和风险分数
+//   - (:Function {name}) 调用链上的函数,(:Service)-[:ENTRY]->(:Function) 指向入口,
+//     (:Function)-[:CALLS]->(:Function) 为调用链上的调用关系
+//
+// 全部使用 MERGE,重复导入不会产生重复的节点和关系;服务节点按 name 与已有的服务依赖图关联
+func (r *Reporter) RenderCypher() string {
+	var b strings.Builder
+	for _, label := range []string{"Service", "Symbol", "Function"} {
+		fmt.Fprintf(&b, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:%s) REQUIRE n.name IS UNIQUE;\n", label)
+	}
+
+	binaries := make(map[string]analyzer.AffectedBinary, len(r.results))
+	for _, res := range r.results {
+		binaries[res.Name] = res
+		props := []string{"package", cypherString(res.PkgPath)}
+		if res.Dir != "" {
+			props = append(props, "dir", cypherString(res.Dir))
+		}
+		if d := res.Deployment; d != nil {
+			for _, kv := range [][2]string{{"image", d.Image}, {"helm_release", d.HelmRelease}, {"kubernetes", d.Kubernetes}} {
+				if kv[1] != "" {
+					props = append(props, kv[0], cypherString(kv[1]))
+				}
+			}
+		}
+		if len(res.Owners) > 0 {
+			props = append(props, "owners", cypherList(res.Owners))
+		}
+		if res.Team != "" {
+			props = append(props, "team", cypherString(res.Team))
+		}
+		fmt.Fprintf(&b, "MERGE (s:Service {name: %s})%s;\n", cypherString(res.Name), cypherSet("s", props))
+	}
+
+	for _, change := range r.report.Changes {
+		props := []string{"kind", cypherString(change.Kind)}
+		if change.ChangeType != "" {
+			props = append(props, "change_type", cypherString(change.ChangeType))
+		}
+		if change.File != "" {
+			props = append(props, "file", cypherString(change.File), "line", strconv.Itoa(change.StartLine))
+		}
+		fmt.Fprintf(&b, "MERGE (c:Symbol {name: %s})%s;\n", cypherString(change.Symbol), cypherSet("c", props))
+		for _, name := range change.Binaries {
+			res, ok := binaries[name]
+			if !ok {
+				// 被 -min-risk 等过滤掉的服务
+				continue
+			}
+			props := []string{"confidence", cypherString(string(res.Confidence))}
+			if res.Risk > 0 {
+				props = append(props, "risk", strconv.Itoa(res.Risk))
+			}
+			if res.Approximate {
+				props = append(props, "approximate", "true")
+			}
+			fmt.Fprintf(&b, "MATCH (s:Service {name: %s}), (c:Symbol {name: %s}) MERGE (s)-[r:AFFECTED_BY]->(c)%s;\n",
+				cypherString(name), cypherString(change.Symbol), cypherSet("r", props))
+		}
+	}
+
+	// 多个服务的调用链经常共享同一段调用,每条关系只写一次
+	seen := make(map[string]bool)
+	for _, res := range r.results {
+		paths := res.Paths
+		if len(paths) == 0 {
+			paths = [][]string{res.TracePath}
+		}
+		for _, path := range paths {
+			if len(path) == 0 {
+				continue
+			}
+			entry := cypherNode(path[0])
+			if key := res.Name + "\x00" + entry; !seen[key] {
+				seen[key] = true
+				fmt.Fprintf(&b, "MATCH (s:Service {name: %s}) MERGE (f:Function {name: %s}) MERGE (s)-[:ENTRY]->(f);\n",
+					cypherString(res.Name), cypherString(entry))
+			}
+			for i := 0; i+1 < len(path); i++ {
+				caller, callee := cypherNode(path[i]), cypherNode(path[i+1])
+				if key := caller + "\x00" + callee; !seen[key] {
+					seen[key] = true
+					fmt.Fprintf(&b, "MERGE (a:Function {name: %s}) MERGE (b:Function {name: %s}) MERGE (a)-[:CALLS]->(b);\n",
+						cypherString(caller), cypherString(callee))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// PrintCypher 向 w 打印 Cypher 格式的报告
+func (r *Reporter) PrintCypher(w io.Writer) {
+	fmt.Fprint(w, r.RenderCypher())
+}
+
+// cypherNode 去掉调用链节点的入口和变更标记
+func cypherNode(node string) string {
+	for _, suffix := range cypherSuffixes {
+		node = strings.TrimSuffix(node, suffix)
+	}
+	return node
+}
+
+// cypherSet 生成设置变量 v 的属性的 SET 子句,props 为交替的属性名和 Cypher 值
+func cypherSet(v string, props []string) string {
+	if len(props) == 0 {
+		return ""
+	}
+	var sets []string
+	for i := 0; i+1 < len(props); i += 2 {
+		sets = append(sets, fmt.Sprintf("%s.%s = %s", v, props[i], props[i+1]))
+	}
+	return " SET " + strings.Join(sets, ", ")
+}
+
+// cypherString 生成单引号的 Cypher 字符串字面量
+func cypherString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`).Replace(s) + "'"
+}
+
+// cypherList 生成字符串列表字面量
+func cypherList(values []string) string {
+	quoted := slices.Clone(values)
+	for i, v := range quoted {
+		quoted[i] = cypherString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// ReportDiff 两次分析结果之间受影响服务的差异
+type ReportDiff struct {
+	Added     []string           `json:"added"`                        // 新增受影响的服务
+	Removed   []string           `json:"removed"`                      // 不再受影响的服务
+	Unchanged []string           `json:"unchanged"`                    // 两次都受影响的服务
+	Changed   []ConfidenceChange `json:"confidence_changed,omitempty"` // 两次都受影响但可信度变化的服务
+	Old       int                `json:"old_affected"`                 // 旧报告中受影响的服务数
+	New       int                `json:"new_affected"`                 // 新报告中受影响的服务数
+}
+
+// ConfidenceChange 服务可信度的变化
+type ConfidenceChange struct {
+	Name string              `json:"name"`
+	Old  analyzer.Confidence `json:"old"`
+	New  analyzer.Confidence `json:"new"`
+}
+
+// LoadReport 读取 -output json 保存的分析报告。早期版本保存的报告是受影响服务的数组,
+// 读取为只有 Affected 的报告
+func LoadReport(path string) (*analyzer.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("读取报告失败: %w", err)
+	}
+	var report analyzer.Report
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &report.Affected)
+	} else {
+		err = json.Unmarshal(data, &report)
+	}
+	if err != nil {
+		return nil, i18n.Errorf("解析报告 %s 失败: %w", path, err)
+	}
+	return &report, nil
+}
+
+// DiffReports 比较两次分析的受影响服务,结果按服务名排序
+func DiffReports(old, new *analyzer.Report) *ReportDiff {
+	oldByName := make(map[string]analyzer.AffectedBinary, len(old.Affected))
+	for _, b := range old.Affected {
+		oldByName[b.Name] = b
+	}
+	newByName := make(map[string]analyzer.AffectedBinary, len(new.Affected))
+	for _, b := range new.Affected {
+		newByName[b.Name] = b
+	}
+
+	d := &ReportDiff{Added: []string{}, Removed: []string{}, Unchanged: []string{}, Old: len(oldByName), New: len(newByName)}
+	for name, b := range newByName {
+		prev, ok := oldByName[name]
+		if !ok {
+			d.Added = append(d.Added, name)
+			continue
+		}
+		d.Unchanged = append(d.Unchanged, name)
+		if prev.Confidence != b.Confidence {
+			d.Changed = append(d.Changed, ConfidenceChange{Name: name, Old: prev.Confidence, New: b.Confidence})
+		}
+	}
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Unchanged)
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Name < d.Changed[j].Name })
+	return d
+}
+
+// PrintSimple 每行一个变化的服务,新增以 "+" 开头,移除以 "-" 开头
+func (d *ReportDiff) PrintSimple(w io.Writer) {
+	for _, name := range d.Added {
+		fmt.Fprintf(w, "+%s\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(w, "-%s\n", name)
+	}
+}
+
+// PrintText 向 w 打印可读的差异报告
+func (d *ReportDiff) PrintText(w io.Writer) {
+	i18n.Fprintf(w, "受影响的服务: %d -> %d\n", d.Old, d.New)
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 {
+		fmt.Fprintln(w, i18n.T("✅ 受影响的服务没有变化。"))
+		return
+	}
+	if len(d.Added) > 0 {
+		i18n.Fprintf(w, "新增受影响 (%d):\n", len(d.Added))
+		for _, name := range d.Added {
+			fmt.Fprintf(w, "  + %s\n", name)
+		}
+	}
+	if len(d.Removed) > 0 {
+		i18n.Fprintf(w, "不再受影响 (%d):\n", len(d.Removed))
+		for _, name := range d.Removed {
+			fmt.Fprintf(w, "  - %s\n", name)
+		}
+	}
+	if len(d.Changed) > 0 {
+		i18n.Fprintf(w, "可信度变化 (%d):\n", len(d.Changed))
+		for _, c := range d.Changed {
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", c.Name, c.Old, c.New)
+		}
+	}
+}
+
+// PrintJSON 以 JSON 格式向 w 打印差异
+func (d *ReportDiff) PrintJSON(w io.Writer) error {
+	jsonData, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return i18n.Errorf("生成JSON失败: %w", err)
+	}
+
+	fmt.Fprintln(w, string(jsonData))
+	return nil
+}
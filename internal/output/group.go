@@ -0,0 +1,112 @@
+package output
+
+import (
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// 分组方式
+const (
+	GroupByNone  = ""
+	GroupByOwner = "owner"
+	GroupByTeam  = "team"
+)
+
+// group 一组受影响的服务,key 为空表示未分组
+type group struct {
+	key     string
+	results []analyzer.AffectedBinary
+}
+
+// SetGroupBy 设置报告的分组方式
+func (r *Reporter) SetGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByNone, GroupByOwner, GroupByTeam:
+		r.groupBy = groupBy
+		return nil
+	default:
+		return i18n.Errorf("不支持的分组方式: %s", groupBy)
+	}
+}
+
+// groups 按分组方式返回服务,组按首次出现的顺序排列,无负责人(团队)的服务放在最后。
+// 有多个负责人的服务会出现在每个负责人的组中
+func (r *Reporter) groups() []group {
+	switch r.groupBy {
+	case GroupByOwner:
+		return groupResults(r.results, func(b analyzer.AffectedBinary) []string { return b.Owners }, i18n.T("(无负责人)"))
+	case GroupByTeam:
+		return groupResults(r.results, teamKeys, i18n.T("(无团队)"))
+	}
+	return []group{{results: r.results}}
+}
+
+// groupResults 按 keys 返回的键分组,没有键的服务归入最后名为 none 的组
+func groupResults(results []analyzer.AffectedBinary, keys func(analyzer.AffectedBinary) []string, none string) []group {
+	var res []group
+	index := make(map[string]int)
+	var rest []analyzer.AffectedBinary
+	for _, b := range results {
+		if len(keys(b)) == 0 {
+			rest = append(rest, b)
+			continue
+		}
+		for _, key := range keys(b) {
+			i, ok := index[key]
+			if !ok {
+				i = len(res)
+				index[key] = i
+				res = append(res, group{key: key})
+			}
+			res[i].results = append(res[i].results, b)
+		}
+	}
+	if len(rest) > 0 {
+		res = append(res, group{key: none, results: rest})
+	}
+	return res
+}
+
+// teamKeys 返回服务所属的团队,没有团队时为空
+func teamKeys(b analyzer.AffectedBinary) []string {
+	if b.Team == "" {
+		return nil
+	}
+	return []string{b.Team}
+}
+
+// TeamReport 一个团队的报告
+type TeamReport struct {
+	Team   string // 团队名,没有团队的服务为空
+	Report *analyzer.Report
+}
+
+// SplitByTeam 按 AffectedBinary.Team 把报告拆分为每个团队一份,团队按首次出现的顺序排列,
+// 没有团队的服务放在最后。每份报告只包含该团队的服务,以及影响到这些服务的变更
+// (Binaries 只保留该团队的服务);影响范围汇总、接口破坏、加载失败等其余内容与原报告相同
+func SplitByTeam(report *analyzer.Report) []TeamReport {
+	var res []TeamReport
+	for _, g := range groupResults(report.Affected, teamKeys, "") {
+		team := *report
+		team.Affected = g.results
+		members := make(map[string]bool, len(g.results))
+		for _, b := range g.results {
+			members[b.Name] = true
+		}
+		team.Changes = nil
+		for _, change := range report.Changes {
+			var binaries []string
+			for _, name := range change.Binaries {
+				if members[name] {
+					binaries = append(binaries, name)
+				}
+			}
+			if len(binaries) > 0 {
+				change.Binaries = binaries
+				team.Changes = append(team.Changes, change)
+			}
+		}
+		res = append(res, TeamReport{Team: g.key, Report: &team})
+	}
+	return res
+}
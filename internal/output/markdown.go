@@ -0,0 +1,434 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// RenderMarkdown 生成 Markdown 格式的报告(用于 MR 评论、CI 摘要等)
+func (r *Reporter) RenderMarkdown() string {
+	var b strings.Builder
+
+	b.WriteString(i18n.T("## ripples 影响分析"))
+	b.WriteString("\n\n")
+	r.writeRange(&b)
+
+	if len(r.results) == 0 {
+		b.WriteString(i18n.T("✅ 未检测到受影响的服务。"))
+		b.WriteString("\n")
+		r.writeSnippets(&b)
+		r.writeTests(&b)
+		r.writeInterfaceBreakage(&b)
+		r.writeFailedPackages(&b)
+		r.writeUnknown(&b)
+		r.writeNotAnalyzed(&b)
+		r.writeUnreached(&b)
+		return b.String()
+	}
+
+	b.WriteString(i18n.Sprintf("检测到 **%d** 个受影响的服务。\n\n", len(r.results)))
+	for i, g := range r.groups() {
+		if g.key != "" {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "### %s\n\n", g.key)
+		}
+		r.writeTable(&b, g.results)
+	}
+	r.writeRoutes(&b)
+	r.writeCommands(&b)
+	r.writeJobs(&b)
+	r.writePackages(&b)
+	r.writeTests(&b)
+
+	b.WriteString("\n<details><summary>")
+	b.WriteString(i18n.T("调用链"))
+	b.WriteString("</summary>\n\n")
+	for _, res := range r.results {
+		fmt.Fprintf(&b, "**%s**\n\n```\n", res.Name)
+		paths := res.Paths
+		if len(paths) == 0 {
+			paths = [][]string{res.TracePath}
+		}
+		for i, tracePath := range paths {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(strings.Join(tracePath, "\n  -> "))
+			b.WriteString("\n")
+			if i < len(res.Evidence) {
+				for _, line := range evidenceLines(res.Evidence[i]) {
+					fmt.Fprintf(&b, "  # %s\n", line)
+				}
+			}
+		}
+		b.WriteString("```\n\n")
+	}
+	b.WriteString("</details>\n")
+	r.writeSnippets(&b)
+
+	if len(r.report.Changes) > 0 {
+		br := r.report.BlastRadius
+		b.WriteString("\n")
+		b.WriteString(i18n.Sprintf("影响范围: %d 个变更符号, %d 个包, %d 个调用点, 最短路径 %d\n",
+			br.ChangedSymbols, br.AffectedPackages, br.CallSites, br.ShortestPath))
+	}
+
+	var constants []analyzer.ChangeMetrics
+	for _, c := range r.report.Changes {
+		if hasValues(c) {
+			constants = append(constants, c)
+		}
+	}
+	if len(constants) > 0 {
+		b.WriteString("\n")
+		b.WriteString(i18n.T("常量变更:"))
+		b.WriteString("\n\n")
+		for _, c := range constants {
+			fmt.Fprintf(&b, "- `%s`: `%s` → `%s`\n", c.Symbol, valueOrNone(c.OldValue), valueOrNone(c.NewValue))
+		}
+	}
+
+	r.writeComparisons(&b)
+	r.writeBlame(&b)
+	r.writeCoverage(&b)
+	r.writeInterfaceBreakage(&b)
+	r.writeFailedPackages(&b)
+	r.writeUnknown(&b)
+	r.writeNotAnalyzed(&b)
+	r.writeUnreached(&b)
+	return b.String()
+}
+
+// writeSnippets 写入变更符号的代码片段,变更行以 "+" 标记
+func (r *Reporter) writeSnippets(b *strings.Builder) {
+	var changes []analyzer.ChangeMetrics
+	for _, c := range r.report.Changes {
+		if c.Snippet != nil {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	b.WriteString("\n<details><summary>")
+	b.WriteString(i18n.T("变更代码"))
+	b.WriteString("</summary>\n\n")
+	for _, c := range changes {
+		s := c.Snippet
+		fmt.Fprintf(b, "**`%s`** `%s:%d`\n\n```diff\n", c.Symbol, c.File, s.StartLine)
+		for i, line := range s.Lines {
+			marker := " "
+			if s.IsChanged(s.StartLine + i) {
+				marker = "+"
+			}
+			fmt.Fprintf(b, "%s %s\n", marker, line)
+		}
+		if s.Truncated {
+			b.WriteString("  ...\n")
+		}
+		b.WriteString("```\n\n")
+	}
+	b.WriteString("</details>\n")
+}
+
+// writeRange 写入分析的提交范围和其中的提交
+func (r *Reporter) writeRange(b *strings.Builder) {
+	rg := r.report.Range
+	if rg == nil {
+		return
+	}
+	from, to := analyzer.Commit{Hash: rg.Old}, analyzer.Commit{Hash: rg.New}
+	b.WriteString(i18n.Sprintf("分析范围: `%s..%s`，%d 个提交", from.ShortHash(), to.ShortHash(), len(rg.Commits)))
+	b.WriteString("\n\n")
+	if len(rg.Commits) == 0 {
+		return
+	}
+	b.WriteString("<details><summary>")
+	b.WriteString(i18n.T("提交"))
+	b.WriteString("</summary>\n\n")
+	for _, c := range rg.Commits {
+		fmt.Fprintf(b, "- %s\n", commitLine(c))
+	}
+	b.WriteString("\n</details>\n\n")
+}
+
+// writeBlame 写入最后修改每个变更符号的提交
+func (r *Reporter) writeBlame(b *strings.Builder) {
+	var lines []string
+	for _, c := range r.report.Changes {
+		if len(c.Blame) > 0 {
+			lines = append(lines, fmt.Sprintf("- `%s`: %s\n", c.Symbol, commitLine(c.Blame[0])))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.T("变更符号最后修改于:"))
+	b.WriteString("\n\n")
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// commitLine 返回提交的单行描述: 短 hash、标题、作者和日期
+func commitLine(c analyzer.Commit) string {
+	return fmt.Sprintf("`%s` %s (%s, %s)", c.ShortHash(), c.Subject, c.Author, c.Date.Format("2006-01-02"))
+}
+
+// writeComparisons 写入比较了变更的枚举常量同类型常量的函数
+func (r *Reporter) writeComparisons(b *strings.Builder) {
+	for _, c := range r.report.Changes {
+		if len(c.ComparedIn) == 0 {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(i18n.Sprintf("`%s` 的值变化可能影响比较同类型常量的代码:", c.Symbol))
+		b.WriteString("\n\n")
+		for _, site := range c.ComparedIn {
+			fmt.Fprintf(b, "- `%s`\n", site)
+		}
+	}
+}
+
+// writeCoverage 写入受影响服务的变更代码的测试覆盖率和完全没有被覆盖的变更符号
+func (r *Reporter) writeCoverage(b *strings.Builder) {
+	var services, uncovered []string
+	for _, res := range r.results {
+		if res.Coverage != nil {
+			services = append(services, fmt.Sprintf("- `%s`: %s\n", res.Name, res.Coverage))
+		}
+	}
+	for _, c := range r.report.Changes {
+		if c.Coverage != nil && c.Coverage.Covered == 0 {
+			uncovered = append(uncovered, fmt.Sprintf("- ⚠️ `%s` `%s:%d`\n", c.Symbol, c.File, c.StartLine))
+		}
+	}
+	if len(services) > 0 {
+		b.WriteString("\n")
+		b.WriteString(i18n.T("变更代码的测试覆盖率:"))
+		b.WriteString("\n\n")
+		for _, line := range services {
+			b.WriteString(line)
+		}
+	}
+	if len(uncovered) > 0 {
+		b.WriteString("\n")
+		b.WriteString(i18n.T("未被测试覆盖的变更:"))
+		b.WriteString("\n\n")
+		for _, line := range uncovered {
+			b.WriteString(line)
+		}
+	}
+}
+
+// writeInterfaceBreakage 写入不再满足接口的类型转换位置
+func (r *Reporter) writeInterfaceBreakage(b *strings.Builder) {
+	if len(r.report.InterfaceBreakage) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("⚠️ 接口实现被破坏 (%d):", len(r.report.InterfaceBreakage)))
+	b.WriteString("\n\n")
+	for _, br := range r.report.InterfaceBreakage {
+		fmt.Fprintf(b, "- %s", breakageSummary(br))
+		if br.File != "" {
+			fmt.Fprintf(b, " `%s:%d`", br.File, br.Line)
+		}
+		if len(br.Binaries) > 0 {
+			b.WriteString(i18n.Sprintf(", 无法编译的服务: %s", strings.Join(br.Binaries, ", ")))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writePackages 写入调用链经过的包(-granularity package)
+func (r *Reporter) writePackages(b *strings.Builder) {
+	if len(r.report.Packages) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("📦 受影响的包 (%d):", len(r.report.Packages)))
+	b.WriteString("\n\n")
+	for _, p := range r.report.Packages {
+		fmt.Fprintf(b, "- `%s`: %s\n", p.Package, strings.Join(p.Binaries, ", "))
+	}
+}
+
+// writeTests 写入执行过变更行的测试,以及单独重新运行它们的命令
+func (r *Reporter) writeTests(b *strings.Builder) {
+	if len(r.report.Tests) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("🧪 执行变更代码的测试 (%d):", len(r.report.Tests)))
+	b.WriteString("\n\n")
+	for _, t := range r.report.Tests {
+		b.WriteString(i18n.Sprintf("- `%s`: %d 行 (%s)", t.Name, t.Lines, strings.Join(t.Changes, ", ")))
+		b.WriteString("\n")
+	}
+	if commands := testCommands(r.report.Tests); len(commands) > 0 {
+		b.WriteString("\n```bash\n")
+		for _, cmd := range commands {
+			b.WriteString(cmd)
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+	}
+}
+
+// writeFailedPackages 写入加载失败或不在稀疏检出范围内而未分析的包
+func (r *Reporter) writeFailedPackages(b *strings.Builder) {
+	if len(r.report.FailedPackages)+len(r.report.OutOfCone) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	for _, f := range r.report.FailedPackages {
+		fmt.Fprintf(b, "> %s: `%s`\n", i18n.Sprintf("⚠️ 分析不完整: 包 %s 加载失败", f.Package), f.Error)
+	}
+	for _, dir := range r.report.OutOfCone {
+		fmt.Fprintf(b, "> %s\n", i18n.Sprintf("⚠️ 分析不完整: %s 不在稀疏检出范围内,其中的变更未分析", "`"+dir+"`"))
+	}
+}
+
+// writeUnknown 写入追踪失败、影响未知的变更符号
+func (r *Reporter) writeUnknown(b *strings.Builder) {
+	if len(r.report.Unknown) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("❓ 影响未知 (%d):", len(r.report.Unknown)))
+	b.WriteString("\n\n")
+	for _, u := range r.report.Unknown {
+		fmt.Fprintf(b, "- `%s` `%s:%d`: %s\n", u.Symbol, u.File, u.Line, u.Error)
+	}
+}
+
+// writeNotAnalyzed 写入时间预算耗尽而未分析的变更符号
+func (r *Reporter) writeNotAnalyzed(b *strings.Builder) {
+	skipped := r.report.NotAnalyzed()
+	if len(skipped) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("⏱️ 时间预算耗尽,未分析 (%d):", len(skipped)))
+	b.WriteString("\n\n")
+	for _, c := range skipped {
+		fmt.Fprintf(b, "- `%s` `%s:%d`\n", c.Symbol, c.File, c.StartLine)
+	}
+}
+
+// writeUnreached 写入没有任何服务会执行的变更符号
+func (r *Reporter) writeUnreached(b *strings.Builder) {
+	unreached := r.unreached()
+	if len(unreached) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.Sprintf("💤 无运行时影响 / 可能是死代码 (%d):", len(unreached)))
+	b.WriteString("\n\n")
+	for _, c := range unreached {
+		fmt.Fprintf(b, "- `%s`", c.Symbol)
+		if c.File != "" {
+			fmt.Fprintf(b, " `%s:%d`", c.File, c.StartLine)
+		}
+		fmt.Fprintf(b, ": %s\n", unreachedReason(c.Unreached))
+	}
+}
+
+// writeRoutes 写入受影响服务的 HTTP 路由和 gRPC 方法
+func (r *Reporter) writeRoutes(b *strings.Builder) {
+	var rows []string
+	for _, res := range r.results {
+		for _, route := range res.Routes {
+			rows = append(rows, fmt.Sprintf("| `%s` | `%s` | `%s` |\n", res.Name, route, route.Handler))
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.T("受影响的接口:"))
+	b.WriteString("\n\n")
+	b.WriteString(i18n.T("| 服务 | 接口 | 处理函数 |"))
+	b.WriteString("\n| --- | --- | --- |\n")
+	for _, row := range rows {
+		b.WriteString(row)
+	}
+}
+
+// writeCommands 写入受影响服务的子命令
+func (r *Reporter) writeCommands(b *strings.Builder) {
+	var lines []string
+	for _, res := range r.results {
+		if len(res.Commands) > 0 {
+			lines = append(lines, fmt.Sprintf("- `%s`: `%s`\n", res.Name, strings.Join(res.Commands, "`, `")))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.T("受影响的子命令:"))
+	b.WriteString("\n\n")
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// writeJobs 写入受影响服务的定时任务
+func (r *Reporter) writeJobs(b *strings.Builder) {
+	var lines []string
+	for _, res := range r.results {
+		if len(res.Jobs) > 0 {
+			lines = append(lines, fmt.Sprintf("- `%s`: `%s`\n", res.Name, strings.Join(res.Jobs, "`, `")))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	b.WriteString(i18n.T("受影响的定时任务:"))
+	b.WriteString("\n\n")
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// writeTable 写入受影响服务表格
+func (r *Reporter) writeTable(b *strings.Builder, results []analyzer.AffectedBinary) {
+	if r.hasDeployments() {
+		b.WriteString(i18n.T("| 服务 | Main 包 | 可信度 | 风险 | 部署 |"))
+		b.WriteString("\n| --- | --- | --- | --- | --- |\n")
+		for _, res := range results {
+			fmt.Fprintf(b, "| `%s` | `%s` | %s | %d | %s |\n", res.Name, res.PkgPath, confidenceLabel(res), res.Risk, res.Deployment)
+		}
+		return
+	}
+	b.WriteString(i18n.T("| 服务 | Main 包 | 可信度 | 风险 |"))
+	b.WriteString("\n| --- | --- | --- | --- |\n")
+	for _, res := range results {
+		fmt.Fprintf(b, "| `%s` | `%s` | %s | %d |\n", res.Name, res.PkgPath, confidenceLabel(res), res.Risk)
+	}
+}
+
+// hasDeployments 判断是否有服务配置了部署标识
+func (r *Reporter) hasDeployments() bool {
+	for _, res := range r.results {
+		if res.Deployment != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintMarkdown 向 w 打印 Markdown 格式的报告
+func (r *Reporter) PrintMarkdown(w io.Writer) {
+	fmt.Fprint(w, r.RenderMarkdown())
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// rdjson 格式定义,参考 reviewdog Diagnostic Format:
+// https://github.com/reviewdog/reviewdog/tree/master/proto/rdf
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Severity    string             `json:"severity,omitempty"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdjsonDiagnostic struct {
+	Message  string         `json:"message"`
+	Location rdjsonLocation `json:"location"`
+	Severity string         `json:"severity,omitempty"`
+	Code     *rdjsonCode    `json:"code,omitempty"`
+}
+
+type rdjsonLocation struct {
+	Path  string      `json:"path"`
+	Range rdjsonRange `json:"range"`
+}
+
+type rdjsonRange struct {
+	Start rdjsonPosition `json:"start"`
+	End   rdjsonPosition `json:"end"`
+}
+
+type rdjsonPosition struct {
+	Line int `json:"line"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+}
+
+// RenderRDJSON 生成 reviewdog rdjson 格式的报告,每个影响到服务的变更符号
+// 生成一条锚定在其变更行上的诊断信息
+func (r *Reporter) RenderRDJSON() ([]byte, error) {
+	result := rdjsonResult{
+		Source:      rdjsonSource{Name: "ripples", URL: "https://github.com/jimyag/ripples"},
+		Severity:    "INFO",
+		Diagnostics: []rdjsonDiagnostic{},
+	}
+
+	for _, change := range r.report.Changes {
+		if len(change.Binaries) == 0 || change.File == "" {
+			continue
+		}
+		message := i18n.Sprintf("%s 的变更影响 %d 个服务: %s",
+			change.Symbol, len(change.Binaries), strings.Join(r.withRisk(change.Binaries), ", "))
+		if hasValues(change) {
+			message += i18n.Sprintf(" (值: %s -> %s)", valueOrNone(change.OldValue), valueOrNone(change.NewValue))
+		}
+		if change.Coverage != nil {
+			message += i18n.Sprintf(" (测试覆盖率: %s)", coverageLabel(*change.Coverage))
+		}
+		result.Diagnostics = append(result.Diagnostics, rdjsonDiagnostic{
+			Message: message,
+			Location: rdjsonLocation{
+				Path: change.File,
+				Range: rdjsonRange{
+					Start: rdjsonPosition{Line: change.StartLine},
+					End:   rdjsonPosition{Line: change.EndLine},
+				},
+			},
+			Severity: "INFO",
+			Code:     &rdjsonCode{Value: change.Kind},
+		})
+	}
+
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// withRisk 为报告中的服务附带风险分数,被 -min-risk 过滤的服务只有名称
+func (r *Reporter) withRisk(names []string) []string {
+	risk := make(map[string]int, len(r.results))
+	for _, res := range r.results {
+		risk[res.Name] = res.Risk
+	}
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		if score, ok := risk[name]; ok && score > 0 {
+			name += i18n.Sprintf(" (风险 %d)", score)
+		}
+		res = append(res, name)
+	}
+	return res
+}
+
+// PrintRDJSON 向 w 打印 reviewdog rdjson 格式的报告
+func (r *Reporter) PrintRDJSON(w io.Writer) error {
+	data, err := r.RenderRDJSON()
+	if err != nil {
+		return i18n.Errorf("生成JSON失败: %w", err)
+	}
+
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -3,81 +3,487 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
 )
 
 // Reporter 结果报告器
 type Reporter struct {
+	report  *analyzer.Report
 	results []analyzer.AffectedBinary
+	groupBy string
 }
 
 // NewReporter 创建报告器
-func NewReporter(results []analyzer.AffectedBinary) *Reporter {
+func NewReporter(report *analyzer.Report) *Reporter {
+	if report == nil {
+		report = &analyzer.Report{}
+	}
 	return &Reporter{
-		results: results,
+		report:  report,
+		results: report.Affected,
 	}
 }
 
-// PrintText 打印文本格式的报告
-func (r *Reporter) PrintText() {
+// PrintText 向 w 打印文本格式的报告
+func (r *Reporter) PrintText(w io.Writer) {
 	if len(r.results) == 0 {
-		fmt.Println("✅ 未检测到受影响的服务。")
+		fmt.Fprintln(w, i18n.T("✅ 未检测到受影响的服务。"))
+		r.printTests(w)
+		r.printInterfaceBreakage(w)
+		r.printFailedPackages(w)
+		r.printUnknown(w)
+		r.printNotAnalyzed(w)
+		r.printUnreached(w)
+		r.printBlastRadius(w)
 		return
 	}
 
-	fmt.Printf("🔍 检测到 %d 个受影响的服务:\n", len(r.results))
-	fmt.Println(strings.Repeat("-", 50))
+	i18n.Fprintf(w, "🔍 检测到 %d 个受影响的服务:\n", len(r.results))
+	fmt.Fprintln(w, strings.Repeat("-", 50))
 
-	for _, res := range r.results {
-		fmt.Printf("📦 Service: \033[1;32m%s\033[0m\n", res.Name) // Green color for service name
-		fmt.Printf("   📍 Main Package: %s\n", res.PkgPath)
-		fmt.Println("   🔗 Call Chain:")
-
-		for i, node := range res.TracePath {
-			prefix := "      "
-			if i == 0 {
-				prefix = "      🚀 " // Start
-			} else if i == len(res.TracePath)-1 {
-				prefix = "      🏁 " // End
+	for _, g := range r.groups() {
+		if g.key != "" {
+			if r.groupBy == GroupByTeam {
+				i18n.Fprintf(w, "👥 团队: %s (%d 个服务)\n", g.key, len(g.results))
 			} else {
-				prefix = "      ⬇️ "
+				i18n.Fprintf(w, "👥 负责人: %s (%d 个服务)\n", g.key, len(g.results))
 			}
+			fmt.Fprintln(w, strings.Repeat("-", 50))
+		}
+		for _, res := range g.results {
+			printBinary(w, res)
+		}
+	}
 
-			// Highlight changed symbol
-			if strings.Contains(node, "(Changed)") {
-				fmt.Printf("%s\033[1;31m%s\033[0m\n", prefix, node) // Red for changed symbol
-			} else {
-				fmt.Printf("%s%s\n", prefix, node)
+	r.printPackages(w)
+	r.printTests(w)
+	r.printInterfaceBreakage(w)
+	r.printFailedPackages(w)
+	r.printUnknown(w)
+	r.printNotAnalyzed(w)
+	r.printUnreached(w)
+	r.printBlastRadius(w)
+}
+
+// printBinary 打印单个受影响服务的详情
+func printBinary(w io.Writer, res analyzer.AffectedBinary) {
+	fmt.Fprintf(w, "📦 Service: \033[1;32m%s\033[0m\n", res.Name) // Green color for service name
+	fmt.Fprintf(w, "   📍 Main Package: %s\n", res.PkgPath)
+	fmt.Fprintf(w, "   🎯 Confidence: %s\n", confidenceLabel(res))
+	fmt.Fprintf(w, "   ⚠️ Risk: %d\n", res.Risk)
+	if res.Coverage != nil {
+		fmt.Fprintf(w, "   🧪 Coverage: %s\n", res.Coverage)
+	}
+	if res.Deployment != nil {
+		fmt.Fprintf(w, "   🚢 Deployment: %s\n", res.Deployment)
+	}
+	if res.BazelTarget != "" {
+		fmt.Fprintf(w, "   🧱 Bazel: %s\n", res.BazelTarget)
+	}
+	if len(res.Owners) > 0 {
+		fmt.Fprintf(w, "   👥 Owners: %s\n", strings.Join(res.Owners, ", "))
+	}
+	if len(res.Routes) > 0 {
+		fmt.Fprintln(w, "   🌐 Routes:")
+		for _, route := range res.Routes {
+			fmt.Fprintf(w, "      - %s (%s)\n", route, route.Handler)
+		}
+	}
+	if len(res.Commands) > 0 {
+		fmt.Fprintf(w, "   ⌨️ Commands: %s\n", strings.Join(res.Commands, ", "))
+	}
+	if len(res.Jobs) > 0 {
+		fmt.Fprintf(w, "   ⏰ Jobs: %s\n", strings.Join(res.Jobs, ", "))
+	}
+	paths := res.Paths
+	if len(paths) == 0 {
+		paths = [][]string{res.TracePath}
+	}
+
+	for i, tracePath := range paths {
+		if len(paths) > 1 {
+			fmt.Fprintf(w, "   🔗 Call Chain %d/%d:\n", i+1, len(paths))
+		} else {
+			fmt.Fprintln(w, "   🔗 Call Chain:")
+		}
+		printTracePath(w, tracePath)
+		if i < len(res.Evidence) {
+			fmt.Fprintln(w, "   🔎 Evidence:")
+			for _, line := range evidenceLines(res.Evidence[i]) {
+				fmt.Fprintf(w, "      %s\n", line)
 			}
 		}
-		fmt.Println(strings.Repeat("-", 50))
 	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// evidenceLines 返回一条调用链的证据: 每一步调用所在的文件和行号,以及变更符号所在的 diff hunk
+func evidenceLines(ev analyzer.Evidence) []string {
+	var lines []string
+	for _, site := range ev.CallSites {
+		location := "(not located)"
+		if site.File != "" {
+			var nums []string
+			for _, line := range site.Lines {
+				nums = append(nums, strconv.Itoa(line))
+			}
+			location = site.File + ":" + strings.Join(nums, ",")
+		}
+		lines = append(lines, fmt.Sprintf("%s %s -> %s", location, shortName(site.Caller), shortName(site.Callee)))
+	}
+	for _, hunk := range ev.Hunks {
+		lines = append(lines, fmt.Sprintf("%s %s", ev.File, hunk))
+	}
+	return lines
+}
+
+// shortName 去掉限定名中包路径的目录部分,如 example.com/app/internal/db.Open 为 db.Open
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// confidenceLabel 返回服务的可信度,近似结果(只经由包导入关系找到)附带标记
+func confidenceLabel(res analyzer.AffectedBinary) string {
+	if res.Approximate {
+		return string(res.Confidence) + ", approximate"
+	}
+	return string(res.Confidence)
+}
+
+// printInterfaceBreakage 打印不再满足接口的类型转换位置
+func (r *Reporter) printInterfaceBreakage(w io.Writer) {
+	if len(r.report.InterfaceBreakage) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("⚠️ 接口实现被破坏 (%d):", len(r.report.InterfaceBreakage)))
+	for _, b := range r.report.InterfaceBreakage {
+		fmt.Fprintf(w, "   - %s\n", breakageSummary(b))
+		if b.File != "" {
+			i18n.Fprintf(w, "     位置: %s:%d\n", b.File, b.Line)
+		}
+		if len(b.Binaries) > 0 {
+			i18n.Fprintf(w, "     无法编译的服务: %s\n", strings.Join(b.Binaries, ", "))
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// printPackages 打印调用链经过的包(-granularity package)
+func (r *Reporter) printPackages(w io.Writer) {
+	if len(r.report.Packages) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("📦 受影响的包 (%d):", len(r.report.Packages)))
+	for _, p := range r.report.Packages {
+		fmt.Fprintf(w, "   - %s (%s)\n", p.Package, strings.Join(p.Binaries, ", "))
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// printTests 打印执行过变更行的测试,以及单独重新运行它们的命令
+func (r *Reporter) printTests(w io.Writer) {
+	if len(r.report.Tests) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("🧪 执行变更代码的测试 (%d):", len(r.report.Tests)))
+	for _, t := range r.report.Tests {
+		i18n.Fprintf(w, "   - %s: %d 行 (%s)\n", t.Name, t.Lines, strings.Join(t.Changes, ", "))
+	}
+	if commands := testCommands(r.report.Tests); len(commands) > 0 {
+		fmt.Fprintln(w, i18n.T("   重新运行:"))
+		for _, cmd := range commands {
+			fmt.Fprintf(w, "     %s\n", cmd)
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// testCommands 按包合并可以单独运行的测试,返回 go test 命令,包的顺序与测试首次出现的顺序一致
+func testCommands(tests []analyzer.CoveringTest) []string {
+	var pkgs []string
+	byPkg := make(map[string][]string)
+	for _, t := range tests {
+		if t.Test == "" {
+			continue
+		}
+		if _, ok := byPkg[t.Package]; !ok {
+			pkgs = append(pkgs, t.Package)
+		}
+		byPkg[t.Package] = append(byPkg[t.Package], t.Test)
+	}
+	res := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		res = append(res, fmt.Sprintf("go test %s -run '^(%s)$'", pkg, strings.Join(byPkg[pkg], "|")))
+	}
+	return res
 }
 
-// PrintJSON 打印JSON格式的报告
-func (r *Reporter) PrintJSON() error {
-	jsonData, err := json.MarshalIndent(r.results, "", "  ")
+// printFailedPackages 打印加载失败或不在稀疏检出范围内而未分析的包
+func (r *Reporter) printFailedPackages(w io.Writer) {
+	if len(r.report.FailedPackages)+len(r.report.OutOfCone) == 0 {
+		return
+	}
+	for _, f := range r.report.FailedPackages {
+		fmt.Fprintln(w, i18n.Sprintf("⚠️ 分析不完整: 包 %s 加载失败", f.Package))
+		i18n.Fprintf(w, "     错误: %s\n", f.Error)
+	}
+	for _, dir := range r.report.OutOfCone {
+		fmt.Fprintln(w, i18n.Sprintf("⚠️ 分析不完整: %s 不在稀疏检出范围内,其中的变更未分析", dir))
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// printUnknown 打印追踪失败、影响未知的变更符号
+func (r *Reporter) printUnknown(w io.Writer) {
+	if len(r.report.Unknown) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("❓ 影响未知 (%d):", len(r.report.Unknown)))
+	for _, u := range r.report.Unknown {
+		fmt.Fprintf(w, "   - %s (%s:%d)\n", u.Symbol, u.File, u.Line)
+		i18n.Fprintf(w, "     错误: %s\n", u.Error)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// printNotAnalyzed 打印时间预算耗尽而未分析的变更符号
+func (r *Reporter) printNotAnalyzed(w io.Writer) {
+	skipped := r.report.NotAnalyzed()
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("⏱️ 时间预算耗尽,未分析 (%d):", len(skipped)))
+	for _, c := range skipped {
+		fmt.Fprintf(w, "   - %s (%s:%d)\n", c.Symbol, c.File, c.StartLine)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// breakageSummary 描述类型因哪个方法不再满足接口
+func breakageSummary(b analyzer.InterfaceBreakage) string {
+	if b.Missing {
+		return i18n.Sprintf("%s 不再满足 %s (缺少接口新增的 %s)", b.Type, b.Interface, b.Method)
+	}
+	if b.Removed {
+		return i18n.Sprintf("%s 不再满足 %s (%s 被删除)", b.Type, b.Interface, b.Method)
+	}
+	return i18n.Sprintf("%s 不再满足 %s (%s 签名变化)", b.Type, b.Interface, b.Method)
+}
+
+// printUnreached 打印没有任何服务会执行的变更符号
+func (r *Reporter) printUnreached(w io.Writer) {
+	unreached := r.unreached()
+	if len(unreached) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Sprintf("💤 无运行时影响 / 可能是死代码 (%d):", len(unreached)))
+	for _, c := range unreached {
+		fmt.Fprintf(w, "   - %s [%s]: %s\n", c.Symbol, c.Kind, unreachedReason(c.Unreached))
+		if c.File != "" {
+			i18n.Fprintf(w, "     位置: %s:%d\n", c.File, c.StartLine)
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+}
+
+// unreached 返回没有任何服务会执行的变更符号
+func (r *Reporter) unreached() []analyzer.ChangeMetrics {
+	var res []analyzer.ChangeMetrics
+	for _, c := range r.report.Changes {
+		if c.Unreached != "" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// unreachedReason 描述符号为什么不会被执行
+func unreachedReason(reason string) string {
+	switch reason {
+	case "no_references":
+		return i18n.T("没有被引用")
+	case "tests_only":
+		return i18n.T("只在测试中被引用")
+	case "dead_callers":
+		return i18n.T("引用它的函数同样不会被执行")
+	}
+	return reason
+}
+
+// printBlastRadius 打印影响范围指标
+func (r *Reporter) printBlastRadius(w io.Writer) {
+	if len(r.report.Changes) == 0 {
+		return
+	}
+
+	br := r.report.BlastRadius
+	fmt.Fprintln(w, "💥 Blast Radius:")
+	i18n.Fprintf(w, "   变更符号: %d, 受影响服务: %d, 受影响包: %d, 调用点: %d, 最短路径: %d\n",
+		br.ChangedSymbols, br.AffectedBinaries, br.AffectedPackages, br.CallSites, br.ShortestPath)
+	for _, c := range r.report.Changes {
+		if c.Skipped != "" {
+			i18n.Fprintf(w, "   - %s [%s]: 未追踪, %s\n", c.Symbol, kindLabel(c), skippedReason(c.Skipped))
+			continue
+		}
+		i18n.Fprintf(w, "   - %s [%s]: 服务 %d, 包 %d, 调用点 %d, 最短路径 %d\n",
+			c.Symbol, kindLabel(c), c.AffectedBinaries, c.AffectedPackages, c.CallSites, c.ShortestPath)
+		if hasValues(c) {
+			i18n.Fprintf(w, "     值: %s -> %s\n", valueOrNone(c.OldValue), valueOrNone(c.NewValue))
+		}
+		for _, site := range c.ComparedIn {
+			i18n.Fprintf(w, "     比较同类型常量: %s\n", site)
+		}
+		if c.Coverage != nil {
+			i18n.Fprintf(w, "     测试覆盖率: %s\n", coverageLabel(*c.Coverage))
+		}
+	}
+}
+
+// coverageLabel 返回变更代码的测试覆盖率,完全没有被覆盖时附带标记
+func coverageLabel(c analyzer.Coverage) string {
+	if c.Covered == 0 {
+		return c.String() + ", " + i18n.T("未被测试覆盖")
+	}
+	return c.String()
+}
+
+// skippedReason 描述符号为什么没有被追踪
+func skippedReason(reason string) string {
+	switch reason {
+	case analyzer.SkipTargetsResolved:
+		return i18n.T("所有目标服务均已受影响")
+	case analyzer.SkipSaturated:
+		return i18n.T("所有服务均已受影响")
+	case analyzer.SkipDeleted:
+		return i18n.T("符号已删除")
+	case analyzer.SkipBudget:
+		return i18n.T("时间预算耗尽")
+	}
+	return reason
+}
+
+// kindLabel 返回符号种类,新增和删除的符号附带变更类型
+func kindLabel(c analyzer.ChangeMetrics) string {
+	switch analyzer.ChangeType(c.ChangeType) {
+	case analyzer.ChangeTypeAdd:
+		return c.Kind + ", " + i18n.T("新增")
+	case analyzer.ChangeTypeDelete:
+		return c.Kind + ", " + i18n.T("删除")
+	}
+	return c.Kind
+}
+
+// hasValues 判断变更是否带有常量值
+func hasValues(c analyzer.ChangeMetrics) bool {
+	return c.OldValue != "" || c.NewValue != ""
+}
+
+// valueOrNone 返回常量值,为空时返回占位符
+func valueOrNone(v string) string {
+	if v == "" {
+		return i18n.T("(无)")
+	}
+	return v
+}
+
+// printTracePath 打印一条调用链
+func printTracePath(w io.Writer, tracePath []string) {
+	for i, node := range tracePath {
+		prefix := "      "
+		if i == 0 {
+			prefix = "      🚀 " // Start
+		} else if i == len(tracePath)-1 {
+			prefix = "      🏁 " // End
+		} else {
+			prefix = "      ⬇️ "
+		}
+
+		// Highlight changed symbol
+		if strings.Contains(node, "(Changed)") {
+			fmt.Fprintf(w, "%s\033[1;31m%s\033[0m\n", prefix, node) // Red for changed symbol
+		} else {
+			fmt.Fprintf(w, "%s%s\n", prefix, node)
+		}
+	}
+}
+
+// PrintJSON 向 w 打印JSON格式的报告
+func (r *Reporter) PrintJSON(w io.Writer) error {
+	jsonData, err := json.MarshalIndent(r.report, "", "  ")
 	if err != nil {
-		return fmt.Errorf("生成JSON失败: %w", err)
+		return i18n.Errorf("生成JSON失败: %w", err)
 	}
 
-	fmt.Println(string(jsonData))
+	fmt.Fprintln(w, string(jsonData))
 	return nil
 }
 
-// PrintSummary 打印简短摘要
-func (r *Reporter) PrintSummary() {
-	fmt.Printf("受影响的服务: %d 个\n", len(r.results))
-	for _, res := range r.results {
-		fmt.Printf("- %s\n", res.Name)
+// PrintSummary 向 w 打印简短摘要
+func (r *Reporter) PrintSummary(w io.Writer) {
+	i18n.Fprintf(w, "受影响的服务: %d 个\n", len(r.results))
+	for _, g := range r.groups() {
+		indent := ""
+		if g.key != "" {
+			fmt.Fprintf(w, "%s:\n", g.key)
+			indent = "  "
+		}
+		for _, res := range g.results {
+			if res.Deployment != nil {
+				fmt.Fprintf(w, "%s- %s (%s, risk %d) [%s]\n", indent, res.Name, confidenceLabel(res), res.Risk, res.Deployment)
+			} else {
+				fmt.Fprintf(w, "%s- %s (%s, risk %d)\n", indent, res.Name, confidenceLabel(res), res.Risk)
+			}
+		}
+	}
+
+	br := r.report.BlastRadius
+	i18n.Fprintf(w, "影响范围: %d 个变更符号, %d 个包, %d 个调用点, 最短路径 %d\n",
+		br.ChangedSymbols, br.AffectedPackages, br.CallSites, br.ShortestPath)
+}
+
+// PrintSimple 向 w 打印简化格式 - 仅服务名，每行一个（适合脚本解析）
+func (r *Reporter) PrintSimple(w io.Writer) {
+	for _, g := range r.groups() {
+		indent := ""
+		if g.key != "" {
+			fmt.Fprintf(w, "%s:\n", g.key)
+			indent = "  "
+		}
+		for _, res := range g.results {
+			fmt.Fprintf(w, "%s%s\n", indent, res.Name)
+		}
 	}
 }
 
-// PrintSimple 打印简化格式 - 仅服务名，每行一个（适合脚本解析）
-func (r *Reporter) PrintSimple() {
+// PrintBazel 向 w 打印受影响服务的 Bazel 目标,每行一个,已排序且去重,可直接传给 bazel build。
+// 没有对应 Bazel 目标的服务(如仍用 go build 构建)在 stderr 中提示
+func (r *Reporter) PrintBazel(w io.Writer) {
+	for _, target := range r.bazelTargets() {
+		fmt.Fprintln(w, target)
+	}
 	for _, res := range r.results {
-		fmt.Println(res.Name)
+		if res.BazelTarget == "" {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("⚠️ 服务 %s 没有对应的 Bazel 目标\n", res.Name))
+		}
+	}
+}
+
+// bazelTargets 返回受影响服务的 Bazel 目标,已排序且去重
+func (r *Reporter) bazelTargets() []string {
+	var res []string
+	for _, b := range r.results {
+		if b.BazelTarget != "" {
+			res = append(res, b.BazelTarget)
+		}
 	}
+	sort.Strings(res)
+	return slices.Compact(res)
 }
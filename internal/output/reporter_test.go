@@ -0,0 +1,550 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+)
+
+func sampleReport() *analyzer.Report {
+	return &analyzer.Report{
+		Affected: []analyzer.AffectedBinary{
+			{
+				Name:       "api-server",
+				PkgPath:    "example.com/project/cmd/api-server",
+				TracePath:  []string{"example.com/project/cmd/api-server.main (main)", "example.com/project/internal/service.Process (Changed)"},
+				Confidence: analyzer.ConfidenceHigh,
+				Risk:       10,
+			},
+		},
+		Changes: []analyzer.ChangeMetrics{
+			{Symbol: "example.com/project/internal/service.Process", Kind: "Function", AffectedBinaries: 1, AffectedPackages: 2, CallSites: 1, ShortestPath: 1,
+				File: "internal/service/process.go", StartLine: 12, EndLine: 15, Binaries: []string{"api-server"}},
+		},
+		BlastRadius: analyzer.BlastRadius{ChangedSymbols: 1, AffectedBinaries: 1, AffectedPackages: 2, CallSites: 1, ShortestPath: 1},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	md := NewReporter(sampleReport()).RenderMarkdown()
+
+	for _, want := range []string{
+		"| `api-server` | `example.com/project/cmd/api-server` | high |",
+		"example.com/project/cmd/api-server.main (main)\n  -> example.com/project/internal/service.Process (Changed)",
+		"<details>",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderMarkdownEmpty(t *testing.T) {
+	md := NewReporter(nil).RenderMarkdown()
+	if strings.Contains(md, "<details>") {
+		t.Errorf("Empty report should not contain call chains:\n%s", md)
+	}
+}
+
+func TestRenderRDJSON(t *testing.T) {
+	data, err := NewReporter(sampleReport()).RenderRDJSON()
+	if err != nil {
+		t.Fatalf("RenderRDJSON failed: %v", err)
+	}
+
+	var result rdjsonResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Invalid rdjson: %v", err)
+	}
+	if result.Source.Name != "ripples" {
+		t.Errorf("Expected source ripples, got %q", result.Source.Name)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d", len(result.Diagnostics))
+	}
+
+	d := result.Diagnostics[0]
+	if d.Location.Path != "internal/service/process.go" ||
+		d.Location.Range.Start.Line != 12 || d.Location.Range.End.Line != 15 {
+		t.Errorf("Unexpected location: %+v", d.Location)
+	}
+	if !strings.Contains(d.Message, "api-server (风险 10)") {
+		t.Errorf("Message should list affected services: %q", d.Message)
+	}
+}
+
+func TestRenderRDJSONEmpty(t *testing.T) {
+	data, err := NewReporter(nil).RenderRDJSON()
+	if err != nil {
+		t.Fatalf("RenderRDJSON failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"diagnostics": []`) {
+		t.Errorf("Expected empty diagnostics array:\n%s", data)
+	}
+}
+
+func TestRenderCypher(t *testing.T) {
+	report := sampleReport()
+	report.Affected[0].Owners = []string{"@org/o'neil"}
+	// 第二个服务与第一个共享调用链的后半段
+	report.Affected = append(report.Affected, analyzer.AffectedBinary{
+		Name:       "worker",
+		PkgPath:    "example.com/project/cmd/worker",
+		TracePath:  []string{"example.com/project/cmd/worker.main (main)", "example.com/project/cmd/api-server.main", "example.com/project/internal/service.Process (Changed)"},
+		Confidence: analyzer.ConfidenceLow,
+	})
+
+	cypher := NewReporter(report).RenderCypher()
+	for _, want := range []string{
+		`MERGE (s:Service {name: 'api-server'}) SET s.package = 'example.com/project/cmd/api-server', s.owners = ['@org/o\'neil'];`,
+		`MERGE (c:Symbol {name: 'example.com/project/internal/service.Process'}) SET c.kind = 'Function', c.file = 'internal/service/process.go', c.line = 12;`,
+		`MERGE (s)-[r:AFFECTED_BY]->(c) SET r.confidence = 'high', r.risk = 10;`,
+		`MATCH (s:Service {name: 'worker'}) MERGE (f:Function {name: 'example.com/project/cmd/worker.main'}) MERGE (s)-[:ENTRY]->(f);`,
+	} {
+		if !strings.Contains(cypher, want) {
+			t.Errorf("Cypher missing %q:\n%s", want, cypher)
+		}
+	}
+	// worker 不在变更符号的服务列表中,不生成 AFFECTED_BY
+	if strings.Contains(cypher, "{name: 'worker'}), (c:Symbol") {
+		t.Errorf("Unexpected AFFECTED_BY for worker:\n%s", cypher)
+	}
+	if n := strings.Count(cypher, "-[:CALLS]->"); n != 2 {
+		t.Errorf("Expected 2 distinct CALLS relationships, got %d:\n%s", n, cypher)
+	}
+}
+
+func TestRenderMarkdownDeployment(t *testing.T) {
+	report := sampleReport()
+	report.Affected[0].Deployment = &analyzer.Deployment{Image: "registry/api-server", HelmRelease: "api"}
+
+	md := NewReporter(report).RenderMarkdown()
+	want := "| `api-server` | `example.com/project/cmd/api-server` | high | 10 | image=registry/api-server, helm=api |"
+	if !strings.Contains(md, want) {
+		t.Errorf("Markdown missing deployment column %q:\n%s", want, md)
+	}
+}
+
+func TestRenderMarkdownEvidence(t *testing.T) {
+	report := sampleReport()
+	report.Affected[0].Evidence = []analyzer.Evidence{{
+		Symbol: "example.com/project/internal/db.Open",
+		File:   "internal/db/db.go",
+		Hunks:  []string{"@@ -10,2 +10,3 @@ func Open() {"},
+		CallSites: []analyzer.CallSite{
+			{Caller: "example.com/project/cmd/api-server.main", Callee: "example.com/project/internal/db.Open", File: "cmd/api-server/main.go", Lines: []int{12, 20}},
+			{Caller: "example.com/project/internal/db.Open", Callee: "example.com/project/internal/db.dial"},
+		},
+	}}
+
+	md := NewReporter(report).RenderMarkdown()
+	for _, want := range []string{
+		"  # cmd/api-server/main.go:12,20 api-server.main -> db.Open\n",
+		"  # (not located) db.Open -> db.dial\n",
+		"  # internal/db/db.go @@ -10,2 +10,3 @@ func Open() {\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing evidence %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestBazelTargets(t *testing.T) {
+	report := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "worker", BazelTarget: "//cmd/worker:worker"},
+		{Name: "legacy"},
+		{Name: "api", BazelTarget: "//cmd/api:api"},
+		{Name: "api-canary", BazelTarget: "//cmd/api:api"},
+	}}
+	got := NewReporter(report).bazelTargets()
+	want := []string{"//cmd/api:api", "//cmd/worker:worker"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("bazelTargets = %v, want %v", got, want)
+	}
+}
+
+func TestWorkloads(t *testing.T) {
+	report := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "worker", Confidence: analyzer.ConfidenceHigh, Deployment: &analyzer.Deployment{Kubernetes: "prod/deployment/worker", HelmRelease: "jobs"}},
+		{Name: "legacy", Confidence: analyzer.ConfidenceLow},
+		{Name: "api", Confidence: analyzer.ConfidenceMedium, Deployment: &analyzer.Deployment{Image: "registry/api", HelmRelease: "api"}},
+		{Name: "api-canary", Confidence: analyzer.ConfidenceHigh, Deployment: &analyzer.Deployment{Kubernetes: "api-canary", HelmRelease: "api"}},
+	}}
+	got := NewReporter(report).Workloads()
+	want := Workloads{
+		Workloads: []Workload{
+			{Service: "worker", Namespace: "prod", Kind: "deployment", Name: "worker", HelmRelease: "jobs", Confidence: "high"},
+			{Service: "api", HelmRelease: "api", Image: "registry/api", Confidence: "medium"},
+			{Service: "api-canary", Name: "api-canary", HelmRelease: "api", Confidence: "high"},
+		},
+		HelmReleases: []string{"api", "jobs"},
+		Unmapped:     []string{"legacy"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Workloads() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPrintWriter(t *testing.T) {
+	r := NewReporter(sampleReport())
+
+	var simple, text strings.Builder
+	r.PrintSimple(&simple)
+	if simple.String() != "api-server\n" {
+		t.Errorf("PrintSimple wrote %q", simple.String())
+	}
+	r.PrintText(&text)
+	for _, want := range []string{"api-server", "internal/service.Process (Changed)", "Blast Radius"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("PrintText output missing %q:\n%s", want, text.String())
+		}
+	}
+}
+
+func TestGroupByOwner(t *testing.T) {
+	report := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "api", Owners: []string{"@team-api"}},
+		{Name: "worker"},
+		{Name: "gateway", Owners: []string{"@team-edge", "@team-api"}},
+	}}
+	r := NewReporter(report)
+	if err := r.SetGroupBy("repo"); err == nil {
+		t.Error("Expected error for unsupported grouping")
+	}
+	if err := r.SetGroupBy(GroupByOwner); err != nil {
+		t.Fatalf("SetGroupBy failed: %v", err)
+	}
+
+	var got []string
+	for _, g := range r.groups() {
+		var names []string
+		for _, b := range g.results {
+			names = append(names, b.Name)
+		}
+		got = append(got, g.key+"="+strings.Join(names, ","))
+	}
+	want := []string{"@team-api=api,gateway", "@team-edge=gateway", "(无负责人)=worker"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("groups() = %v, want %v", got, want)
+	}
+
+	md := r.RenderMarkdown()
+	if !strings.Contains(md, "### @team-api\n\n| 服务") {
+		t.Errorf("Markdown missing owner section:\n%s", md)
+	}
+}
+
+func TestGroupByTeam(t *testing.T) {
+	report := &analyzer.Report{
+		Affected: []analyzer.AffectedBinary{
+			{Name: "api", Team: "payments"},
+			{Name: "worker"},
+			{Name: "gateway", Team: "edge"},
+			{Name: "billing", Team: "payments"},
+		},
+		Changes: []analyzer.ChangeMetrics{
+			{Symbol: "pkg/db.Open", Binaries: []string{"api", "gateway", "worker"}},
+			{Symbol: "pkg/pay.Charge", Binaries: []string{"billing"}},
+		},
+	}
+	r := NewReporter(report)
+	if err := r.SetGroupBy(GroupByTeam); err != nil {
+		t.Fatalf("SetGroupBy failed: %v", err)
+	}
+	var got []string
+	for _, g := range r.groups() {
+		var names []string
+		for _, b := range g.results {
+			names = append(names, b.Name)
+		}
+		got = append(got, g.key+"="+strings.Join(names, ","))
+	}
+	want := []string{"payments=api,billing", "edge=gateway", "(无团队)=worker"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("groups() = %v, want %v", got, want)
+	}
+
+	teams := SplitByTeam(report)
+	if len(teams) != 3 || teams[0].Team != "payments" || teams[2].Team != "" {
+		t.Fatalf("SplitByTeam = %+v", teams)
+	}
+	payments := teams[0].Report
+	if len(payments.Affected) != 2 || len(payments.Changes) != 2 {
+		t.Fatalf("Unexpected payments report: %+v", payments)
+	}
+	if strings.Join(payments.Changes[0].Binaries, ",") != "api" {
+		t.Errorf("Change binaries = %v, want [api]", payments.Changes[0].Binaries)
+	}
+	edge := teams[1].Report
+	if len(edge.Changes) != 1 || edge.Changes[0].Symbol != "pkg/db.Open" {
+		t.Errorf("Unexpected edge changes: %+v", edge.Changes)
+	}
+	// 拆分不修改原报告
+	if len(report.Changes[0].Binaries) != 3 {
+		t.Errorf("Original report modified: %v", report.Changes[0].Binaries)
+	}
+}
+
+func TestDiffReports(t *testing.T) {
+	old := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "api-server", Confidence: analyzer.ConfidenceHigh},
+		{Name: "worker", Confidence: analyzer.ConfidenceHigh},
+		{Name: "cron", Confidence: analyzer.ConfidenceLow},
+	}}
+	new := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "api-server", Confidence: analyzer.ConfidenceMedium},
+		{Name: "cron", Confidence: analyzer.ConfidenceLow},
+		{Name: "gateway", Confidence: analyzer.ConfidenceHigh},
+	}}
+
+	d := DiffReports(old, new)
+	if strings.Join(d.Added, ",") != "gateway" {
+		t.Errorf("Added = %v, want [gateway]", d.Added)
+	}
+	if strings.Join(d.Removed, ",") != "worker" {
+		t.Errorf("Removed = %v, want [worker]", d.Removed)
+	}
+	if strings.Join(d.Unchanged, ",") != "api-server,cron" {
+		t.Errorf("Unchanged = %v, want [api-server cron]", d.Unchanged)
+	}
+	want := []ConfidenceChange{{Name: "api-server", Old: analyzer.ConfidenceHigh, New: analyzer.ConfidenceMedium}}
+	if len(d.Changed) != 1 || d.Changed[0] != want[0] {
+		t.Errorf("Changed = %v, want %v", d.Changed, want)
+	}
+	if d.Old != 3 || d.New != 3 {
+		t.Errorf("Counts = %d -> %d, want 3 -> 3", d.Old, d.New)
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	data, err := json.Marshal(sampleReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := LoadReport(path)
+	if err != nil {
+		t.Fatalf("LoadReport failed: %v", err)
+	}
+	if len(report.Affected) != 1 || report.Affected[0].Name != "api-server" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReport(path); err == nil {
+		t.Error("Expected an error for an invalid report")
+	}
+}
+
+func TestLoadReportArray(t *testing.T) {
+	// 早期版本的 -output json 输出受影响服务的数组
+	old, err := LoadReport(filepath.Join("..", "..", "testdata", "reports", "array-report.json"))
+	if err != nil {
+		t.Fatalf("LoadReport failed: %v", err)
+	}
+	if len(old.Affected) != 2 || old.Affected[0].Name != "api-server" || old.Affected[1].Confidence != analyzer.ConfidenceLow {
+		t.Fatalf("Unexpected report: %+v", old)
+	}
+
+	d := DiffReports(old, sampleReport())
+	if !reflect.DeepEqual(d.Removed, []string{"worker"}) || !reflect.DeepEqual(d.Unchanged, []string{"api-server"}) {
+		t.Errorf("Unexpected diff against the array report: %+v", d)
+	}
+}
+
+func TestRenderMarkdownConstantValues(t *testing.T) {
+	report := sampleReport()
+	report.Changes = append(report.Changes, analyzer.ChangeMetrics{
+		Symbol: "example.com/project/internal/config.MaxRetries", Kind: "Constant", OldValue: "3", NewValue: "10",
+	})
+
+	md := NewReporter(report).RenderMarkdown()
+	if !strings.Contains(md, "- `example.com/project/internal/config.MaxRetries`: `3` → `10`") {
+		t.Errorf("Markdown missing constant change:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownCommits(t *testing.T) {
+	commit := analyzer.Commit{
+		Hash: "0123456789abcdef0123456789abcdef01234567", Author: "Ann", Subject: "Add retries",
+		Date: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	report := sampleReport()
+	report.Range = &analyzer.CommitRange{Old: "fedcba9876543210fedcba9876543210fedcba98", New: commit.Hash, Commits: []analyzer.Commit{commit}}
+	report.Changes[0].Blame = []analyzer.Commit{commit}
+
+	md := NewReporter(report).RenderMarkdown()
+	for _, want := range []string{
+		"分析范围: `fedcba9..0123456`，1 个提交",
+		"- `0123456` Add retries (Ann, 2024-05-01)",
+		"- `example.com/project/internal/service.Process`: `0123456` Add retries (Ann, 2024-05-01)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderMarkdownSnippets(t *testing.T) {
+	report := sampleReport()
+	report.Changes[0].File = "internal/service/process.go"
+	report.Changes[0].Snippet = &analyzer.Snippet{
+		StartLine: 20,
+		Lines:     []string{"func Process() {", "\treturn retry(3)", "}"},
+		Changed:   []int{21},
+	}
+
+	md := NewReporter(report).RenderMarkdown()
+	want := "**`example.com/project/internal/service.Process`** `internal/service/process.go:20`\n\n" +
+		"```diff\n  func Process() {\n+ \treturn retry(3)\n  }\n```\n"
+	if !strings.Contains(md, want) {
+		t.Errorf("Markdown missing snippet %q:\n%s", want, md)
+	}
+}
+
+func TestRenderMarkdownCoverage(t *testing.T) {
+	report := sampleReport()
+	report.Affected[0].Coverage = &analyzer.Coverage{Statements: 10, Covered: 4}
+	report.Changes[0].Coverage = &analyzer.Coverage{Statements: 3}
+
+	md := NewReporter(report).RenderMarkdown()
+	for _, want := range []string{
+		"- `api-server`: 40% (4/10)",
+		"- ⚠️ `example.com/project/internal/service.Process` `internal/service/process.go:12`",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderMarkdownTests(t *testing.T) {
+	report := sampleReport()
+	report.Tests = []analyzer.CoveringTest{
+		{Name: "internal/service/TestProcess", Package: "./internal/service", Test: "TestProcess", Changes: []string{"example.com/project/internal/service.Process"}, Lines: 3},
+		{Name: "e2e", Changes: []string{"example.com/project/internal/service.Process"}, Lines: 2},
+		{Name: "internal/service/TestRetry", Package: "./internal/service", Test: "TestRetry", Changes: []string{"example.com/project/internal/service.Process"}, Lines: 1},
+	}
+
+	md := NewReporter(report).RenderMarkdown()
+	for _, want := range []string{
+		"- `e2e`: 2 行 (example.com/project/internal/service.Process)",
+		"go test ./internal/service -run '^(TestProcess|TestRetry)$'\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderMarkdownInterfaceBreakage(t *testing.T) {
+	report := &analyzer.Report{InterfaceBreakage: []analyzer.InterfaceBreakage{{
+		Type: "*example.com/project/internal/worker.Worker", Method: "Base.Close", Removed: true,
+		Interface: "io.Closer", File: "internal/worker/worker.go", Line: 22, Binaries: []string{"worker"},
+	}}}
+
+	md := NewReporter(report).RenderMarkdown()
+	want := "- *example.com/project/internal/worker.Worker 不再满足 io.Closer (Base.Close 被删除) `internal/worker/worker.go:22`, 无法编译的服务: worker"
+	if !strings.Contains(md, want) {
+		t.Errorf("Markdown missing interface breakage:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownFailedPackages(t *testing.T) {
+	report := &analyzer.Report{FailedPackages: []analyzer.FailedPackage{{
+		Package: "example.com/project/internal/experimental", Error: "experimental.go:3:2: undefined: x",
+	}}}
+
+	md := NewReporter(report).RenderMarkdown()
+	want := "> ⚠️ 分析不完整: 包 example.com/project/internal/experimental 加载失败: `experimental.go:3:2: undefined: x`"
+	if !strings.Contains(md, want) {
+		t.Errorf("Markdown missing failed package:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownUnknown(t *testing.T) {
+	report := &analyzer.Report{Unknown: []analyzer.UnknownImpact{{
+		Symbol: "example.com/project/internal/service.Process", Kind: "Function",
+		File: "internal/service/process.go", Line: 12, Error: "context deadline exceeded",
+	}}}
+
+	md := NewReporter(report).RenderMarkdown()
+	want := "- `example.com/project/internal/service.Process` `internal/service/process.go:12`: context deadline exceeded"
+	if !strings.Contains(md, "影响未知 (1)") || !strings.Contains(md, want) {
+		t.Errorf("Markdown missing unknown section:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownNotAnalyzed(t *testing.T) {
+	report := &analyzer.Report{Changes: []analyzer.ChangeMetrics{
+		{Symbol: "example.com/p.Traced", Kind: "Function", AffectedBinaries: 1},
+		{Symbol: "example.com/p.Charge", Kind: "Function", File: "p/charge.go", StartLine: 42, Skipped: analyzer.SkipBudget},
+	}}
+
+	md := NewReporter(report).RenderMarkdown()
+	if !strings.Contains(md, "时间预算耗尽,未分析 (1)") || !strings.Contains(md, "- `example.com/p.Charge` `p/charge.go:42`") {
+		t.Errorf("Markdown missing not analyzed section:\n%s", md)
+	}
+	if strings.Contains(md, "p.Traced`") {
+		t.Errorf("Markdown lists a traced symbol as not analyzed:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownUnreached(t *testing.T) {
+	report := &analyzer.Report{Changes: []analyzer.ChangeMetrics{
+		{Symbol: "example.com/p.Used", Kind: "Function", AffectedBinaries: 1},
+		{Symbol: "example.com/p.Backoff", Kind: "Function", File: "p/backoff.go", StartLine: 5, Unreached: "tests_only"},
+	}}
+
+	md := NewReporter(report).RenderMarkdown()
+	if !strings.Contains(md, "无运行时影响 / 可能是死代码 (1)") {
+		t.Errorf("Markdown missing unreached section:\n%s", md)
+	}
+	if !strings.Contains(md, "- `example.com/p.Backoff` `p/backoff.go:5`: 只在测试中被引用") {
+		t.Errorf("Markdown missing unreached symbol:\n%s", md)
+	}
+	if strings.Contains(md, "p.Used`") {
+		t.Errorf("Markdown lists a reached symbol as unreached:\n%s", md)
+	}
+}
+
+func TestRenderMarkdownRoutes(t *testing.T) {
+	report := &analyzer.Report{Affected: []analyzer.AffectedBinary{
+		{Name: "api", PkgPath: "example.com/cmd/api", Confidence: analyzer.ConfidenceHigh, Routes: []analyzer.Route{
+			{Method: "GET", Path: "/users", Handler: "handler.(*Users).List"},
+		}},
+		{Name: "worker", PkgPath: "example.com/cmd/worker", Confidence: analyzer.ConfidenceHigh},
+	}}
+
+	md := NewReporter(report).RenderMarkdown()
+	if !strings.Contains(md, "| `api` | `GET /users` | `handler.(*Users).List` |") {
+		t.Errorf("Markdown missing route:\n%s", md)
+	}
+	if strings.Count(md, "| `worker`") != 1 {
+		t.Errorf("Markdown lists routes for a binary without routes:\n%s", md)
+	}
+}
+
+func TestPrintJSONEmpty(t *testing.T) {
+	var b strings.Builder
+	if err := NewReporter(&analyzer.Report{Range: &analyzer.CommitRange{}}).PrintJSON(&b); err != nil {
+		t.Fatalf("PrintJSON failed: %v", err)
+	}
+	for _, want := range []string{`"affected": []`, `"changes": []`, `"commits": []`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("PrintJSON output missing %s:\n%s", want, b.String())
+		}
+	}
+}
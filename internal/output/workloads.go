@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"go.yaml.in/yaml/v3"
+)
+
+// Workloads 受影响服务对应的 Kubernetes 工作负载和 Helm release,来自部署映射,
+// 供 ArgoCD、Spinnaker 等按影响范围确定一次发布涉及的应用
+type Workloads struct {
+	Workloads    []Workload `json:"workloads" yaml:"workloads"`
+	HelmReleases []string   `json:"helm_releases,omitempty" yaml:"helm_releases,omitempty"` // 已排序且去重
+	Unmapped     []string   `json:"unmapped,omitempty" yaml:"unmapped,omitempty"`           // 没有部署映射的受影响服务
+}
+
+// Workload 一个受影响服务的部署标识。kubernetes 写作 "命名空间/类型/名称" 时拆分为
+// Namespace、Kind 和 Name,其他写法原样放在 Name 中
+type Workload struct {
+	Service     string `json:"service" yaml:"service"`
+	Namespace   string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Kind        string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	HelmRelease string `json:"helm_release,omitempty" yaml:"helm_release,omitempty"`
+	Image       string `json:"image,omitempty" yaml:"image,omitempty"`
+	Confidence  string `json:"confidence" yaml:"confidence"`
+}
+
+// Workloads 返回受影响服务的部署标识,顺序与报告一致
+func (r *Reporter) Workloads() Workloads {
+	res := Workloads{Workloads: []Workload{}}
+	for _, b := range r.results {
+		d := b.Deployment
+		if d == nil || d.Kubernetes == "" && d.HelmRelease == "" && d.Image == "" {
+			res.Unmapped = append(res.Unmapped, b.Name)
+			continue
+		}
+		w := Workload{Service: b.Name, HelmRelease: d.HelmRelease, Image: d.Image, Confidence: string(b.Confidence)}
+		if parts := strings.Split(d.Kubernetes, "/"); len(parts) == 3 {
+			w.Namespace, w.Kind, w.Name = parts[0], parts[1], parts[2]
+		} else {
+			w.Name = d.Kubernetes
+		}
+		res.Workloads = append(res.Workloads, w)
+		if d.HelmRelease != "" {
+			res.HelmReleases = append(res.HelmReleases, d.HelmRelease)
+		}
+	}
+	slices.Sort(res.HelmReleases)
+	res.HelmReleases = slices.Compact(res.HelmReleases)
+	return res
+}
+
+// PrintWorkloads 以 YAML 向 w 打印受影响的工作负载,asJSON 时打印 JSON
+func (r *Reporter) PrintWorkloads(w io.Writer, asJSON bool) error {
+	workloads := r.Workloads()
+	if asJSON {
+		data, err := json.MarshalIndent(workloads, "", "  ")
+		if err != nil {
+			return i18n.Errorf("生成JSON失败: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+	data, err := yaml.Marshal(workloads)
+	if err != nil {
+		return i18n.Errorf("生成YAML失败: %w", err)
+	}
+	fmt.Fprint(w, string(data))
+	return nil
+}
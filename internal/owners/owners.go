@@ -0,0 +1,103 @@
+// Package owners 解析 CODEOWNERS 文件,确定文件的负责团队
+package owners
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Locations CODEOWNERS 文件的查找位置(与 GitHub/GitLab 一致)
+var Locations = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+// rule 一条 CODEOWNERS 规则
+type rule struct {
+	patterns []string // 转换后的 config.Match 模式
+	owners   []string
+}
+
+// Resolver 按 CODEOWNERS 规则查找文件的负责人,后出现的规则优先
+type Resolver struct {
+	rules []rule
+}
+
+// Parse 解析 CODEOWNERS 内容
+func Parse(content string) *Resolver {
+	r := &Resolver{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// GitLab 的 [Section] 标题
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		r.Add(fields[0], fields[1:]...)
+	}
+	return r
+}
+
+// Load 在仓库中查找并解析 CODEOWNERS,不存在时返回 nil
+func Load(repoPath string) (*Resolver, error) {
+	for _, loc := range Locations {
+		data, err := os.ReadFile(filepath.Join(repoPath, loc))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, i18n.Errorf("读取 CODEOWNERS 失败: %w", err)
+		}
+		return Parse(string(data)), nil
+	}
+	return nil, nil
+}
+
+// Add 追加一条规则,优先级高于已有规则。owners 为空表示取消该路径的负责人
+func (r *Resolver) Add(pattern string, owners ...string) {
+	r.rules = append(r.rules, rule{patterns: translate(pattern), owners: owners})
+}
+
+// Owners 返回相对仓库根目录的文件路径的负责人
+func (r *Resolver) Owners(file string) []string {
+	if r == nil {
+		return nil
+	}
+	file = filepath.ToSlash(file)
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if config.MatchAny(r.rules[i].patterns, file) {
+			return r.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// translate 将 gitignore 风格的 CODEOWNERS 模式转换为 config.Match 模式
+func translate(pattern string) []string {
+	if pattern == "*" {
+		return []string{"**"}
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+
+	if dirOnly {
+		return []string{pattern + "/**"}
+	}
+	// 模式既可以匹配文件,也可以匹配目录下的所有文件
+	return []string{pattern, pattern + "/**"}
+}
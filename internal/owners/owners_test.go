@@ -0,0 +1,63 @@
+package owners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOwners(t *testing.T) {
+	r := Parse(`
+# 默认负责人
+*                       @platform
+/cmd/api-server/        @team-api @alice
+cmd/worker              @team-jobs
+*.proto                 @team-api
+internal/billing/**     @team-billing # inline comment
+/cmd/legacy/
+`)
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"README.md", []string{"@platform"}},
+		{"cmd/api-server/main.go", []string{"@team-api", "@alice"}},
+		{"cmd/worker/main.go", []string{"@team-jobs"}},
+		{"api/v1/service.proto", []string{"@team-api"}},
+		{"internal/billing/invoice/invoice.go", []string{"@team-billing"}},
+		{"cmd/legacy/main.go", []string{}},
+	}
+	for _, tt := range tests {
+		got := r.Owners(tt.file)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if r, err := Load(dir); err != nil || r != nil {
+		t.Fatalf("Expected nil resolver without CODEOWNERS, got %v, %v", r, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("cmd/ @team\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := r.Owners("cmd/api/main.go"); len(got) != 1 || got[0] != "@team" {
+		t.Errorf("Unexpected owners %v", got)
+	}
+}
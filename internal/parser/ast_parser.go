@@ -6,15 +6,28 @@ import (
 	"go/token"
 	"go/types"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
 	"golang.org/x/tools/go/packages"
 )
 
 // Parser 符号解析器
 type Parser struct {
-	fset     *token.FileSet
-	packages []*packages.Package
+	fset      *token.FileSet
+	packages  []*packages.Package
+	importers []*packages.Package // 直接或间接导入变更包的包,没有函数体,用于查找其中声明的接口
+	modules   []string            // 多模块仓库中各模块的根目录,在其中查找反向依赖
+	failed    []*packages.Package // 有语法或类型错误而被排除的包
+	closure   []string            // 变更包及其反向依赖的导入路径,加载整个项目时为 nil
+	index     *Index              // 设置后由索引计算反向依赖,不再列出所有包
+
+	ifacesByMethod    map[string][]*types.Interface // 方法名 -> 声明该方法的接口(惰性构建)
+	goDeferCallers    map[string][]*Symbol          // go/defer 语句调用的函数全名 -> 语句所在的函数(惰性构建)
+	initRegistrations map[string][]Registration     // 包初始化时登记的函数全名 -> 登记的位置(惰性构建)
+	files             map[string]*packageFile       // 文件绝对路径 -> 文件符号和顶层声明(按包惰性构建)
 }
 
 // NewParser 创建新的符号解析器
@@ -24,99 +37,149 @@ func NewParser() *Parser {
 	}
 }
 
-// LoadProject 加载整个项目
+// SetModules 设置多模块仓库中各模块的根目录(工作区模式),变更包的反向依赖在这些模块中
+// 查找。未设置时只在项目根目录所在的模块中查找
+func (p *Parser) SetModules(dirs []string) {
+	p.modules = dirs
+}
+
+// SetIndex 设置已更新(见 Index.Update)的符号索引,变更包的反向依赖由索引计算
+func (p *Parser) SetIndex(idx *Index) {
+	p.index = idx
+}
+
+// LoadProject 加载整个项目,仓库外的依赖不解析函数体
 func (p *Parser) LoadProject(projectPath string) error {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return i18n.Errorf("获取绝对路径失败: %w", err)
+	}
 	cfg := &packages.Config{
-		Mode: packages.LoadAllSyntax,
-		Fset: p.fset,
-		Dir:  projectPath,
+		Mode:      packages.LoadAllSyntax,
+		Fset:      p.fset,
+		Dir:       projectPath,
+		ParseFile: parseKeeping(underDir(absPath)),
 	}
 
 	pkgs, err := packages.Load(cfg, "./...")
 	if err != nil {
-		return fmt.Errorf("加载项目失败: %w", err)
-	}
-
-	// 检查是否有错误
-	var hasErrors bool
-	for _, pkg := range pkgs {
-		if len(pkg.Errors) > 0 {
-			hasErrors = true
-			for _, err := range pkg.Errors {
-				fmt.Printf("包 %s 错误: %v\n", pkg.PkgPath, err)
-			}
-		}
-	}
-
-	if hasErrors {
-		return fmt.Errorf("部分包加载失败")
+		return i18n.Errorf("加载项目失败: %w", err)
 	}
 
-	p.packages = pkgs
+	p.packages, p.failed = partitionFailed(pkgs)
+	p.importers, p.closure = nil, nil
+	p.ifacesByMethod, p.goDeferCallers, p.initRegistrations, p.files = nil, nil, nil, nil
 	return nil
 }
 
-// LoadChangedFiles 只加载包含变更文件的包（性能优化）
+// LoadChangedFiles 只加载包含变更文件的包（性能优化）。只有变更包保留函数体,
+// 它们的依赖和反向依赖只做声明的类型检查
 func (p *Parser) LoadChangedFiles(projectPath string, changedFiles []string) error {
 	if len(changedFiles) == 0 {
 		// 没有变更文件，使用标准加载
 		return p.LoadProject(projectPath)
 	}
 
-	// 将文件路径转换为包路径
-	packagePatterns := make(map[string]bool)
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return i18n.Errorf("获取绝对路径失败: %w", err)
+	}
+
+	// 变更文件所在的目录,目录被整个删除时没有对应的包
+	changedDirs := make(map[string]bool)
 	for _, file := range changedFiles {
-		// 获取文件所在的目录作为包路径
-		dir := filepath.Dir(file)
-		if dir == "." {
-			packagePatterns["."] = true
-		} else {
-			// 支持通配符，加载包及其子包
-			packagePatterns["./"+dir] = true
-		}
+		changedDirs[filepath.Join(absPath, filepath.Dir(file))] = true
 	}
 
-	// 转换为切片
-	var patterns []string
-	for pattern := range packagePatterns {
-		patterns = append(patterns, pattern)
+	// 反向依赖中可能声明了变更的类型所满足的接口,与变更包一起加载
+	modules := p.modules
+	if len(modules) == 0 {
+		modules = []string{projectPath}
+	}
+	var changed, importers []string
+	if p.index != nil {
+		changed, importers = p.index.reverseDeps(modules, changedDirs)
+	} else if changed, importers, err = reverseDeps(modules, changedDirs); err != nil {
+		return err
+	}
+	logger.Debug("变更包及其反向依赖", "changed", len(changed), "importers", len(importers))
+	p.packages, p.importers, p.failed = nil, nil, nil
+	p.ifacesByMethod, p.goDeferCallers, p.initRegistrations, p.files = nil, nil, nil, nil
+	patterns := slices.Concat(changed, importers)
+	p.closure = patterns
+	if len(changed) == 0 {
+		return nil
 	}
 
 	cfg := &packages.Config{
-		Mode: packages.LoadAllSyntax,
-		Fset: p.fset,
-		Dir:  projectPath,
+		Mode:      packages.LoadAllSyntax,
+		Fset:      p.fset,
+		Dir:       projectPath,
+		ParseFile: parseKeeping(inDirs(changedDirs)),
 	}
 
-	pkgs, err := packages.Load(cfg, patterns...)
+	loaded, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		return fmt.Errorf("加载变更包失败: %w", err)
+		return i18n.Errorf("加载变更包失败: %w", err)
 	}
 
-	// 检查是否有错误
-	var hasErrors bool
-	for _, pkg := range pkgs {
-		if len(pkg.Errors) > 0 {
-			hasErrors = true
-			for _, err := range pkg.Errors {
-				fmt.Printf("包 %s 错误: %v\n", pkg.PkgPath, err)
-			}
-		}
+	isImporter := make(map[string]bool, len(importers))
+	for _, pkgPath := range importers {
+		isImporter[pkgPath] = true
 	}
-
-	if hasErrors {
-		return fmt.Errorf("部分包加载失败")
+	var pkgs []*packages.Package
+	for _, pkg := range loaded {
+		if isImporter[pkg.PkgPath] {
+			p.importers = append(p.importers, pkg)
+		} else {
+			pkgs = append(pkgs, pkg)
+		}
 	}
 
-	p.packages = pkgs
+	// 反向依赖没有函数体,其错误忽略
+	p.packages, p.failed = partitionFailed(pkgs)
 	return nil
 }
 
-// ParseFile 解析单个文件的符号
+// partitionFailed 把有错误的包与其余的包分开。一个实验性的包编译失败不应阻止整个分析,
+// 这些包被排除并在报告中列出
+func partitionFailed(pkgs []*packages.Package) (loaded, failed []*packages.Package) {
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			loaded = append(loaded, pkg)
+			continue
+		}
+		for _, err := range pkg.Errors {
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+		failed = append(failed, pkg)
+	}
+	return loaded, failed
+}
+
+// ParseFile 返回单个文件的顶层符号(导入和声明,按源码顺序)。符号位于所在包的层级中
+// (见 buildHierarchy),可以经由 Parent 找到所属的类型、文件和包。同一个包只解析一次,
+// 返回的符号在多次调用之间共享
 func (p *Parser) ParseFile(filename string) ([]*Symbol, error) {
+	targetPkg, _, absFilename, err := p.findFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.files[absFilename]; !ok {
+		p.buildHierarchy(targetPkg)
+	}
+	f, ok := p.files[absFilename]
+	if !ok {
+		return nil, i18n.Errorf("未找到文件: %s", absFilename)
+	}
+	return slices.Clone(f.decls), nil
+}
+
+// findFile 查找已加载的文件及其所在的包
+func (p *Parser) findFile(filename string) (*packages.Package, *ast.File, string, error) {
 	absFilename, err := filepath.Abs(filename)
 	if err != nil {
-		return nil, fmt.Errorf("获取绝对路径失败: %w", err)
+		return nil, nil, "", i18n.Errorf("获取绝对路径失败: %w", err)
 	}
 
 	var targetPkg *packages.Package
@@ -138,10 +201,9 @@ func (p *Parser) ParseFile(filename string) ([]*Symbol, error) {
 	}
 
 	if targetFile == nil || targetPkg == nil {
-		return nil, fmt.Errorf("未找到文件: %s", absFilename)
+		return nil, nil, "", i18n.Errorf("未找到文件: %s", absFilename)
 	}
-
-	return p.extractSymbolsFromFile(targetFile, targetPkg, absFilename)
+	return targetPkg, targetFile, absFilename, nil
 }
 
 // extractSymbolsFromFile 从文件中提取符号
@@ -208,8 +270,15 @@ func (p *Parser) extractFunction(funcDecl *ast.FuncDecl, pkg *packages.Package,
 	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
 		funcExtra.IsMethod = true
 		funcExtra.ReceiverType = p.getTypeString(funcDecl.Recv.List[0].Type)
+		if pkg.TypesInfo != nil {
+			if fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+				funcExtra.ImplementsInterface = p.implementsAnyInterface(fn)
+			}
+		}
 	}
 
+	funcExtra.Closures = p.returnedClosures(funcDecl)
+
 	symbol := &Symbol{
 		Name:        funcDecl.Name.Name,
 		Kind:        kind,
@@ -224,6 +293,59 @@ func (p *Parser) extractFunction(funcDecl *ast.FuncDecl, pkg *packages.Package,
 	return symbols
 }
 
+// returnedClosures 返回函数直接返回的函数字面量,以及返回的局部变量被赋值的函数字面量的行范围,
+// 如 "return func() {...}" 和 "h := func() {...}; return h"。嵌套函数字面量中的 return 不计入
+func (p *Parser) returnedClosures(funcDecl *ast.FuncDecl) []LineRange {
+	if funcDecl.Body == nil {
+		return nil
+	}
+	var returned []ast.Expr
+	literals := make(map[string][]*ast.FuncLit) // 局部变量 -> 赋值给它的函数字面量
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			returned = append(returned, n.Results...)
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, rhs := range n.Rhs {
+				lit, ok := ast.Unparen(rhs).(*ast.FuncLit)
+				if id, isIdent := n.Lhs[i].(*ast.Ident); ok && isIdent {
+					literals[id.Name] = append(literals[id.Name], lit)
+				}
+			}
+		case *ast.ValueSpec:
+			for i, value := range n.Values {
+				if lit, ok := ast.Unparen(value).(*ast.FuncLit); ok && i < len(n.Names) {
+					literals[n.Names[i].Name] = append(literals[n.Names[i].Name], lit)
+				}
+			}
+		}
+		return true
+	})
+
+	var ranges []LineRange
+	add := func(lit *ast.FuncLit) {
+		ranges = append(ranges, LineRange{Start: p.fset.Position(lit.Pos()).Line, End: p.fset.Position(lit.End()).Line})
+	}
+	for _, expr := range returned {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.FuncLit:
+			add(e)
+		case *ast.Ident:
+			for _, lit := range literals[e.Name] {
+				add(lit)
+			}
+			// 同一个变量返回多次时只记录一次
+			delete(literals, e.Name)
+		}
+	}
+	return ranges
+}
+
 // extractGenDecl 提取通用声明
 func (p *Parser) extractGenDecl(genDecl *ast.GenDecl, pkg *packages.Package, filename string) []*Symbol {
 	var symbols []*Symbol
@@ -237,7 +359,7 @@ func (p *Parser) extractGenDecl(genDecl *ast.GenDecl, pkg *packages.Package, fil
 				kind = SymbolKindConstant
 			}
 
-			for _, name := range s.Names {
+			for i, name := range s.Names {
 				symbol := &Symbol{
 					Name:        name.Name,
 					Kind:        kind,
@@ -246,6 +368,13 @@ func (p *Parser) extractGenDecl(genDecl *ast.GenDecl, pkg *packages.Package, fil
 					EndPos:      s.End(),
 					PackagePath: pkg.PkgPath,
 				}
+				if kind == SymbolKindVariable {
+					extra := VariableExtra{InitCall: p.hasInitCall(s, i, pkg)}
+					if len(s.Names) > 1 && len(s.Values) == len(s.Names) {
+						extra.ValuePos, extra.ValueEnd = s.Values[i].Pos(), s.Values[i].End()
+					}
+					symbol.Extra = extra
+				}
 				symbols = append(symbols, symbol)
 			}
 
@@ -311,6 +440,10 @@ func (p *Parser) extractTypeSpec(typeSpec *ast.TypeSpec, pkg *packages.Package,
 		Extra:       typeExtra,
 		PackagePath: pkg.PkgPath,
 	}
+	for _, child := range slices.Concat(typeExtra.Fields, typeExtra.Methods) {
+		child.Parent = symbol
+		symbol.Children = append(symbol.Children, child)
+	}
 
 	symbols = append(symbols, symbol)
 	return symbols
@@ -382,6 +515,36 @@ func (p *Parser) extractInterfaceMethod(method *ast.Field, pkg *packages.Package
 	return symbols
 }
 
+// hasInitCall 判断第 i 个变量的初始化表达式是否调用了函数。
+// 类型转换和内置函数不算调用;"a, b = f()" 这种多值初始化共享同一个表达式
+func (p *Parser) hasInitCall(spec *ast.ValueSpec, i int, pkg *packages.Package) bool {
+	var value ast.Expr
+	switch {
+	case i < len(spec.Values) && len(spec.Values) == len(spec.Names):
+		value = spec.Values[i]
+	case len(spec.Values) == 1:
+		value = spec.Values[0]
+	default:
+		return false
+	}
+
+	found := false
+	ast.Inspect(value, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		if pkg.TypesInfo != nil {
+			if tv, ok := pkg.TypesInfo.Types[call.Fun]; ok && (tv.IsType() || tv.IsBuiltin()) {
+				return true
+			}
+		}
+		found = true
+		return false
+	})
+	return found
+}
+
 // getTypeString 获取类型字符串
 func (p *Parser) getTypeString(expr ast.Expr) string {
 	if expr == nil {
@@ -402,6 +565,14 @@ func (p *Parser) getTypeString(expr ast.Expr) string {
 		return "map[" + p.getTypeString(t.Key) + "]" + p.getTypeString(t.Value)
 	case *ast.SelectorExpr:
 		return p.getTypeString(t.X) + "." + t.Sel.Name
+	case *ast.IndexExpr:
+		return p.getTypeString(t.X) + "[" + p.getTypeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		var indices []string
+		for _, index := range t.Indices {
+			indices = append(indices, p.getTypeString(index))
+		}
+		return p.getTypeString(t.X) + "[" + strings.Join(indices, ", ") + "]"
 	case *ast.InterfaceType:
 		return "interface{}"
 	case *ast.StructType:
@@ -411,6 +582,69 @@ func (p *Parser) getTypeString(expr ast.Expr) string {
 	}
 }
 
+// implementsAnyInterface 判断方法的接收者是否通过该方法满足已加载包中的某个接口
+func (p *Parser) implementsAnyInterface(method *types.Func) bool {
+	sig, ok := method.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return false
+	}
+
+	if p.ifacesByMethod == nil {
+		p.ifacesByMethod = p.collectInterfaces()
+	}
+
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	for _, iface := range p.ifacesByMethod[method.Name()] {
+		if types.Implements(recv, iface) || types.Implements(types.NewPointer(recv), iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectInterfaces 收集所有已加载包(含依赖和反向依赖)中声明的非空接口,按方法名索引
+func (p *Parser) collectInterfaces() map[string][]*types.Interface {
+	res := make(map[string][]*types.Interface)
+	visited := make(map[*packages.Package]bool)
+
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		if visited[pkg] {
+			return
+		}
+		visited[pkg] = true
+
+		if pkg.Types != nil {
+			scope := pkg.Types.Scope()
+			for _, name := range scope.Names() {
+				tn, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok {
+					continue
+				}
+				iface, ok := tn.Type().Underlying().(*types.Interface)
+				if !ok || iface.NumMethods() == 0 {
+					continue
+				}
+				for i := 0; i < iface.NumMethods(); i++ {
+					res[iface.Method(i).Name()] = append(res[iface.Method(i).Name()], iface)
+				}
+			}
+		}
+
+		for _, imp := range pkg.Imports {
+			visit(imp)
+		}
+	}
+
+	for _, pkg := range slices.Concat(p.packages, p.importers) {
+		visit(pkg)
+	}
+	return res
+}
+
 // GetTypeInfo 获取类型信息(用于依赖分析)
 func (p *Parser) GetTypeInfo(pkgPath string) (*types.Package, *types.Info, error) {
 	for _, pkg := range p.packages {
@@ -418,7 +652,7 @@ func (p *Parser) GetTypeInfo(pkgPath string) (*types.Package, *types.Info, error
 			return pkg.Types, pkg.TypesInfo, nil
 		}
 	}
-	return nil, nil, fmt.Errorf("未找到包: %s", pkgPath)
+	return nil, nil, i18n.Errorf("未找到包: %s", pkgPath)
 }
 
 // GetPackages 返回所有加载的包
@@ -426,6 +660,17 @@ func (p *Parser) GetPackages() []*packages.Package {
 	return p.packages
 }
 
+// ReverseDeps 返回变更包及直接或间接导入它们的包的导入路径。LoadProject 加载整个项目时
+// 不计算反向依赖,返回 nil
+func (p *Parser) ReverseDeps() []string {
+	return p.closure
+}
+
+// FailedPackages 返回加载失败而被排除的包,其中的变更不会被分析
+func (p *Parser) FailedPackages() []*packages.Package {
+	return p.failed
+}
+
 // GetFileSet 返回文件集
 func (p *Parser) GetFileSet() *token.FileSet {
 	return p.fset
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestMethodImplementsInterface(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "shared-package-test")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/service-a/handler.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+
+	symbols, err := p.ParseFile(filepath.Join(testProject, "internal/service-a/handler.go"))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	expected := map[string]bool{
+		"Run":            true,  // Satisfies common.Runner
+		"ProcessRequest": false, // No interface declares it
+	}
+
+	for _, s := range symbols {
+		want, ok := expected[s.Name]
+		if !ok {
+			continue
+		}
+		extra, ok := s.Extra.(FunctionExtra)
+		if !ok {
+			t.Fatalf("Symbol %s missing FunctionExtra", s.Name)
+		}
+		if extra.ImplementsInterface != want {
+			t.Errorf("%s.ImplementsInterface = %v, want %v", s.Name, extra.ImplementsInterface, want)
+		}
+		delete(expected, s.Name)
+	}
+
+	for name := range expected {
+		t.Errorf("Symbol %s not found", name)
+	}
+}
+
+func TestVariableInitCall(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "init-test")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/cache/cache.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+
+	symbols, err := p.ParseFile(filepath.Join(testProject, "internal/cache/cache.go"))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	expected := map[string]bool{
+		"Size":       false, // No initializer
+		"defaultTTL": true,  // Calls computeTTL at package initialization
+	}
+	for _, s := range symbols {
+		want, ok := expected[s.Name]
+		if !ok {
+			continue
+		}
+		extra, ok := s.Extra.(VariableExtra)
+		if !ok {
+			t.Fatalf("Symbol %s missing VariableExtra", s.Name)
+		}
+		if extra.InitCall != want {
+			t.Errorf("%s.InitCall = %v, want %v", s.Name, extra.InitCall, want)
+		}
+		delete(expected, s.Name)
+	}
+	for name := range expected {
+		t.Errorf("Symbol %s not found", name)
+	}
+}
+
+func TestReturnedClosures(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "closure-test")
+	file := filepath.Join(testProject, "internal/handlers/handlers.go")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/handlers/handlers.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	symbols, err := p.ParseFile(file)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	expected := map[string][]LineRange{
+		"NewGreeter": {{Start: 7, End: 9}},   // 直接返回的函数字面量
+		"NewCounter": {{Start: 15, End: 18}}, // 返回的局部变量
+		"Upper":      nil,
+	}
+	for _, s := range symbols {
+		want, ok := expected[s.Name]
+		if !ok {
+			continue
+		}
+		extra, ok := s.Extra.(FunctionExtra)
+		if !ok {
+			t.Fatalf("Symbol %s missing FunctionExtra", s.Name)
+		}
+		if fmt.Sprint(extra.Closures) != fmt.Sprint(want) {
+			t.Errorf("%s.Closures = %v, want %v", s.Name, extra.Closures, want)
+		}
+		delete(expected, s.Name)
+	}
+	for name := range expected {
+		t.Errorf("Symbol %s not found", name)
+	}
+}
+
+func TestParseFileHierarchy(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "receiver-test")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/store/store.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	symbols, err := p.ParseFile(filepath.Join(testProject, "internal/store/store.go"))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	cacheSymbols, err := p.ParseFile(filepath.Join(testProject, "internal/store/cache.go"))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	find := func(symbols []*Symbol, name string) *Symbol {
+		for _, s := range symbols {
+			if s.Name == name {
+				return s
+			}
+		}
+		t.Fatalf("Symbol %s not found", name)
+		return nil
+	}
+
+	loader := find(symbols, "Loader")
+	file := loader.Parent
+	if file == nil || file.Kind != SymbolKindFile || file.Parent == nil || file.Parent.Kind != SymbolKindPackage {
+		t.Fatalf("Expected Loader in a file of a package, got parent %+v", file)
+	}
+	if pkg := file.Parent; pkg.Name != "store" || len(pkg.Children) != 2 {
+		t.Errorf("Expected package store with 2 files, got %s with %d", pkg.Name, len(pkg.Children))
+	}
+	if find(symbols, "NewLoader").Parent != file {
+		t.Error("Expected NewLoader to be a child of its file")
+	}
+
+	// 字段和方法(包括其他文件中的方法)都是类型的子符号,按文件顺序排列
+	var children []string
+	for _, c := range loader.Children {
+		if c.Parent != loader {
+			t.Errorf("Child %s of Loader has parent %v", c.Name, c.Parent)
+		}
+		children = append(children, fmt.Sprintf("%s %s", c.Kind, c.Name))
+	}
+	if want := "[StructField path Function Path Function Load]"; fmt.Sprint(children) != want {
+		t.Errorf("Loader children = %v, want %s", children, want)
+	}
+	if get := find(cacheSymbols, "Get"); get.Parent != find(cacheSymbols, "Cache") {
+		t.Errorf("Expected Get to be a method of the generic Cache, got parent %v", get.Parent)
+	}
+	if extra := find(cacheSymbols, "Get").Extra.(FunctionExtra); extra.ReceiverType != "*Cache[K, V]" {
+		t.Errorf("ReceiverType = %q, want *Cache[K, V]", extra.ReceiverType)
+	}
+}
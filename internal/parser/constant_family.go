@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"go/types"
+	"sort"
+)
+
+// ConstantFamily 返回与枚举式常量类型相同的其他包级常量,按声明位置排序。枚举式常量的
+// 类型是同一个包中定义、底层为基本类型的具名类型(如 type Status int),且该类型声明了
+// 不止一个常量;其他常量返回 nil
+func (p *Parser) ConstantFamily(symbol *Symbol) []*Symbol {
+	if symbol.Kind != SymbolKindConstant {
+		return nil
+	}
+	pkg, _, _, err := p.findFile(symbol.Position.Filename)
+	if err != nil || pkg.Types == nil {
+		return nil
+	}
+	scope := pkg.Types.Scope()
+	target, ok := scope.Lookup(symbol.Name).(*types.Const)
+	if !ok {
+		return nil
+	}
+	named, ok := target.Type().(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg.Types {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Basic); !ok {
+		return nil
+	}
+
+	var family []*types.Const
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if ok && c != target && types.Identical(c.Type(), named) {
+			family = append(family, c)
+		}
+	}
+	sort.Slice(family, func(i, j int) bool { return family[i].Pos() < family[j].Pos() })
+
+	var res []*Symbol
+	for _, c := range family {
+		res = append(res, &Symbol{
+			Name:        c.Name(),
+			Kind:        SymbolKindConstant,
+			Position:    p.fset.Position(c.Pos()),
+			StartPos:    c.Pos(),
+			EndPos:      c.Pos(),
+			PackagePath: pkg.PkgPath,
+		})
+	}
+	return res
+}
@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConstantFamily(t *testing.T) {
+	project := filepath.Join("..", "..", "testdata", "enum-switch-test")
+	p, symbol := findSymbol(t, project, "pkg/order/status.go", "StatusPaid")
+
+	var names []string
+	for _, s := range p.ConstantFamily(symbol) {
+		names = append(names, s.Name)
+		if s.PackagePath != "example.com/enum-switch-test/pkg/order" || s.Position.Line == 0 {
+			t.Errorf("Expected %s to be positioned in pkg/order, got %s at %v", s.Name, s.PackagePath, s.Position)
+		}
+	}
+	if len(names) != 2 || names[0] != "StatusPending" || names[1] != "StatusShipped" {
+		t.Errorf("ConstantFamily(StatusPaid) = %v, want [StatusPending StatusShipped]", names)
+	}
+
+	// 无类型常量不属于任何枚举
+	_, untyped := findSymbol(t, project, "pkg/order/status.go", "DefaultRetries")
+	if family := p.ConstantFamily(untyped); family != nil {
+		t.Errorf("ConstantFamily(DefaultRetries) = %v, want nil", family)
+	}
+}
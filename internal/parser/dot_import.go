@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// DotImportUsers 返回文件中直接使用了点导入(import . "path")包中标识符的顶层符号。
+// 点导入变更(如换成另一个提供同名标识符的包)时,这些符号实际调用的目标随之改变
+func (p *Parser) DotImportUsers(filename, importPath string) ([]*Symbol, error) {
+	pkg, file, absFilename, err := p.findFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.TypesInfo == nil {
+		return nil, nil
+	}
+
+	// 收集通过点导入解析的标识符位置: 不带包名限定、引用 importPath 的包级对象。
+	// x.Sel 中的 Sel 要么带包名限定,要么是字段或方法,都不经过点导入
+	selectors := make(map[*ast.Ident]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			selectors[sel.Sel] = true
+		}
+		return true
+	})
+	var uses []token.Pos
+	for id, obj := range pkg.TypesInfo.Uses {
+		if selectors[id] || obj.Pkg() == nil || obj.Pkg().Path() != importPath {
+			continue
+		}
+		if obj.Parent() != obj.Pkg().Scope() || id.Pos() < file.Pos() || id.Pos() >= file.End() {
+			continue
+		}
+		uses = append(uses, id.Pos())
+	}
+	if len(uses) == 0 {
+		return nil, nil
+	}
+
+	symbols, err := p.extractSymbolsFromFile(file, pkg, absFilename)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Symbol
+	for _, s := range symbols {
+		if s.Kind == SymbolKindImport {
+			continue
+		}
+		for _, pos := range uses {
+			if pos >= s.StartPos && pos < s.EndPos {
+				res = append(res, s)
+				break
+			}
+		}
+	}
+	return res, nil
+}
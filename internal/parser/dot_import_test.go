@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDotImportUsers(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "dot-import-test")
+	file := filepath.Join(testProject, "internal/svc/svc.go")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/svc/svc.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+
+	users, err := p.DotImportUsers(file, "example.com/dot-import-test/pkg/util")
+	if err != nil {
+		t.Fatalf("DotImportUsers failed: %v", err)
+	}
+	if len(users) != 1 || users[0].Name != "Run" {
+		t.Errorf("Expected only Run to use the dot import, got %v", symbolNames(users))
+	}
+
+	users, err = p.DotImportUsers(file, "strings")
+	if err != nil {
+		t.Fatalf("DotImportUsers failed: %v", err)
+	}
+	if len(users) != 0 {
+		t.Errorf("Qualified identifiers are not dot imports, got %v", symbolNames(users))
+	}
+}
+
+func symbolNames(symbols []*Symbol) []string {
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name)
+	}
+	return names
+}
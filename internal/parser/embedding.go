@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"context"
+	"go/ast"
+	"path"
+	"strconv"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// Embeddings 仓库内结构体的嵌入关系,用于查找方法被提升到了哪些外层类型
+type Embeddings struct {
+	embedders map[typeKey][]embedder      // 被嵌入的类型 -> 嵌入它的结构体
+	members   map[typeKey]map[string]bool // 类型自身声明的方法和字段名
+}
+
+// typeKey 包级类型的唯一标识
+type typeKey struct {
+	pkgPath string
+	name    string
+}
+
+// embedder 嵌入了某个类型的结构体
+type embedder struct {
+	key    typeKey
+	symbol *Symbol
+}
+
+// LoadEmbeddings 加载 dir 所在模块下的所有包(只解析语法,不做类型检查),
+// 收集结构体的嵌入字段。导入包的包名按导入路径的最后一段推断
+func LoadEmbeddings(ctx context.Context, dir string) (*Embeddings, error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Dir:     dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+
+	e := &Embeddings{
+		embedders: make(map[typeKey][]embedder),
+		members:   make(map[typeKey]map[string]bool),
+	}
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+		for _, file := range pkg.Syntax {
+			e.addFile(pkg, file)
+		}
+	}
+	return e, nil
+}
+
+// addFile 收集文件中的结构体嵌入字段和方法声明
+func (e *Embeddings) addFile(pkg *packages.Package, file *ast.File) {
+	imports := make(map[string]string) // 包名 -> 导入路径
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				continue
+			}
+			if name := baseTypeName(d.Recv.List[0].Type); name != "" {
+				e.addMember(typeKey{pkg.PkgPath, name}, d.Name.Name)
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				outer := typeKey{pkg.PkgPath, ts.Name.Name}
+				symbol := &Symbol{
+					Name:        ts.Name.Name,
+					Kind:        SymbolKindStruct,
+					Position:    pkg.Fset.Position(ts.Name.Pos()),
+					PackagePath: pkg.PkgPath,
+				}
+				for _, field := range st.Fields.List {
+					for _, name := range field.Names {
+						e.addMember(outer, name.Name)
+					}
+					if len(field.Names) > 0 {
+						continue
+					}
+					inner, ok := embeddedType(field.Type, pkg.PkgPath, imports)
+					if !ok {
+						continue
+					}
+					// 嵌入字段的字段名就是类型名
+					e.addMember(outer, inner.name)
+					e.embedders[inner] = append(e.embedders[inner], embedder{key: outer, symbol: symbol})
+				}
+			}
+		}
+	}
+}
+
+// addMember 记录类型自身声明的方法或字段
+func (e *Embeddings) addMember(key typeKey, name string) {
+	if e.members[key] == nil {
+		e.members[key] = make(map[string]bool)
+	}
+	e.members[key][name] = true
+}
+
+// PromotingTypes 返回通过(多层)嵌入获得 pkgPath.typeName 的 method 方法的结构体,
+// 自身声明了同名方法或字段的类型会遮蔽该方法,不再向外提升
+func (e *Embeddings) PromotingTypes(pkgPath, typeName, method string) []*Symbol {
+	var res []*Symbol
+	seen := map[typeKey]bool{{pkgPath, typeName}: true}
+	queue := []typeKey{{pkgPath, typeName}}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, outer := range e.embedders[key] {
+			if seen[outer.key] || e.members[outer.key][method] {
+				continue
+			}
+			seen[outer.key] = true
+			res = append(res, outer.symbol)
+			queue = append(queue, outer.key)
+		}
+	}
+	return res
+}
+
+// embeddedType 解析嵌入字段的类型,支持 T、*T、pkg.T 和泛型实例化
+func embeddedType(expr ast.Expr, pkgPath string, imports map[string]string) (typeKey, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedType(t.X, pkgPath, imports)
+	case *ast.IndexExpr:
+		return embeddedType(t.X, pkgPath, imports)
+	case *ast.IndexListExpr:
+		return embeddedType(t.X, pkgPath, imports)
+	case *ast.Ident:
+		return typeKey{pkgPath, t.Name}, true
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			return typeKey{}, false
+		}
+		importPath, ok := imports[x.Name]
+		if !ok {
+			return typeKey{}, false
+		}
+		return typeKey{importPath, t.Sel.Name}, true
+	}
+	return typeKey{}, false
+}
+
+// baseTypeName 返回接收者的类型名(去掉 * 和类型参数)
+func baseTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return baseTypeName(t.X)
+	case *ast.IndexExpr:
+		return baseTypeName(t.X)
+	case *ast.IndexListExpr:
+		return baseTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
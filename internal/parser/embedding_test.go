@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPromotingTypes(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "embed-test")
+
+	e, err := LoadEmbeddings(context.Background(), testProject)
+	if err != nil {
+		t.Fatalf("LoadEmbeddings failed: %v", err)
+	}
+
+	names := func(symbols []*Symbol) []string {
+		var res []string
+		for _, s := range symbols {
+			res = append(res, s.PackagePath+"."+s.Name)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	// Quiet 自己声明了 Close,Admin 通过 Server 间接嵌入 Base
+	got := names(e.PromotingTypes("example.com/embed-test/pkg/base", "Base", "Close"))
+	want := []string{
+		"example.com/embed-test/internal/svc.Admin",
+		"example.com/embed-test/internal/svc.Server",
+		"example.com/embed-test/internal/worker.Worker",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PromotingTypes(Close) = %v, want %v", got, want)
+	}
+
+	got = names(e.PromotingTypes("example.com/embed-test/pkg/base", "Base", "Name"))
+	want = append(want[:2:2], "example.com/embed-test/internal/worker.Quiet", "example.com/embed-test/internal/worker.Worker")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PromotingTypes(Name) = %v, want %v", got, want)
+	}
+
+	if got := e.PromotingTypes("example.com/embed-test/internal/svc", "Admin", "Close"); len(got) != 0 {
+		t.Errorf("Expected no type embedding Admin, got %v", names(got))
+	}
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"context"
+	"go/ast"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// EntrypointDirective 标记自定义入口函数的注释指令
+const EntrypointDirective = "//ripples:entrypoint"
+
+// Entrypoint main 之外的入口函数,如 Lambda handler、定时任务、测试工具,
+// 在报告中与 main 包一样作为一个服务
+type Entrypoint struct {
+	Name   string  // 服务名,来自指令的 name= 或配置,未指定时为函数名
+	Symbol *Symbol // 入口函数,Position 指向函数名
+	Main   bool    // main 包的 main 函数(见 FindMains),报告中不标记为自定义入口
+}
+
+// FindEntrypoints 返回 dir(仓库根目录)中带有 "//ripples:entrypoint name=xxx" 注释的
+// 函数,以及 functions 中配置的函数。functions 的键为服务名,值为 "包目录.函数" 或
+// "包目录.接收者.方法",包目录相对 dir。只加载包含指令的文件(git grep)和配置的
+// 函数所在的包(含测试文件,只解析语法)
+func FindEntrypoints(ctx context.Context, dir string, functions map[string]string) ([]Entrypoint, error) {
+	files, err := git.GrepFiles(dir, EntrypointDirective, "*.go")
+	if err != nil {
+		return nil, err
+	}
+	patterns := make(map[string]bool)
+	for _, file := range files {
+		patterns["./"+path.Dir(file)] = true
+	}
+	configured := make(map[string]string) // "包目录.函数" -> 服务名
+	for name, fn := range functions {
+		configured[fn] = name
+		if dir, ok := functionDir(fn); ok {
+			patterns["./"+dir] = true
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	var res []Entrypoint
+	found := make(map[string]bool)
+	err = eachFunction(ctx, dir, patterns, func(pkg *packages.Package, fd *ast.FuncDecl, key string) {
+		name, ok := configured[key]
+		if ok {
+			found[key] = true
+		} else if name, ok = entrypointDirective(fd); !ok {
+			return
+		}
+		res = append(res, Entrypoint{Name: name, Symbol: functionSymbol(pkg, fd)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for key, name := range configured {
+		if !found[key] {
+			logger.Warn("未找到配置的入口函数", "name", name, "function", key)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// eachFunction 加载 patterns 中的包(含测试文件,只解析语法),对每个函数声明调用 visit,
+// key 为 "包目录.函数" 或 "包目录.接收者.方法",包目录相对 dir
+func eachFunction(ctx context.Context, dir string, patterns map[string]bool, visit func(pkg *packages.Package, fd *ast.FuncDecl, key string)) error {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Dir:     dir,
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(cfg, slices.Sorted(maps.Keys(patterns))...)
+	if err != nil {
+		return i18n.Errorf("加载项目失败: %w", err)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	seenFiles := make(map[string]bool) // 测试变体会重复包含同一文件
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.Position(file.Package).Filename
+			if seenFiles[filename] {
+				continue
+			}
+			seenFiles[filename] = true
+
+			rel, err := filepath.Rel(root, filepath.Dir(filename))
+			if err != nil {
+				continue
+			}
+			for _, decl := range file.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok {
+					visit(pkg, fd, filepath.ToSlash(rel)+"."+funcName(fd))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// functionSymbol 返回函数声明对应的符号,Position 指向函数名
+func functionSymbol(pkg *packages.Package, fd *ast.FuncDecl) *Symbol {
+	return &Symbol{
+		Name:        fd.Name.Name,
+		Kind:        SymbolKindFunction,
+		Position:    pkg.Fset.Position(fd.Name.Pos()),
+		PackagePath: strings.TrimSuffix(pkg.PkgPath, "_test"),
+	}
+}
+
+// functionDir 返回 "包目录.函数" 中的包目录,包目录中的 "." 只可能出现在最后一个 "/" 之前
+func functionDir(fn string) (string, bool) {
+	slash := strings.LastIndex(fn, "/")
+	dot := strings.Index(fn[slash+1:], ".")
+	if dot < 0 {
+		return "", false
+	}
+	return fn[:slash+1+dot], true
+}
+
+// entrypointDirective 解析函数文档中的入口指令,返回服务名
+func entrypointDirective(fd *ast.FuncDecl) (string, bool) {
+	if fd.Doc == nil {
+		return "", false
+	}
+	for _, c := range fd.Doc.List {
+		rest, ok := strings.CutPrefix(c.Text, EntrypointDirective)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		for _, field := range strings.Fields(rest) {
+			if name, ok := strings.CutPrefix(field, "name="); ok && name != "" {
+				return name, true
+			}
+		}
+		return fd.Name.Name, true
+	}
+	return "", false
+}
+
+// funcName 返回函数名,方法写作 "接收者.方法"
+func funcName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	return baseTypeName(fd.Recv.List[0].Type) + "." + fd.Name.Name
+}
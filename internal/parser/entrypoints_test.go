@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"context"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindEntrypoints(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	testProject := filepath.Join("..", "..", "testdata", "entrypoint-test")
+
+	eps, err := FindEntrypoints(context.Background(), testProject, map[string]string{
+		"orders-lambda": "lambda/orders.Handle",
+		"missing":       "lambda/orders.Missing",
+	})
+	if err != nil {
+		t.Fatalf("FindEntrypoints failed: %v", err)
+	}
+
+	want := []struct {
+		name, function, pkg string
+		line                int
+	}{
+		{"cron-reconciler", "Reconcile", "example.com/entrypoint-test/internal/jobs", 8},
+		{"orders-lambda", "Handle", "example.com/entrypoint-test/lambda/orders", 10},
+	}
+	if len(eps) != len(want) {
+		t.Fatalf("Expected %d entrypoints, got %+v", len(want), eps)
+	}
+	for i, ep := range eps {
+		w := want[i]
+		if ep.Name != w.name || ep.Symbol.Name != w.function || ep.Symbol.PackagePath != w.pkg || ep.Symbol.Position.Line != w.line {
+			t.Errorf("eps[%d] = %s %s.%s:%d, want %+v", i, ep.Name, ep.Symbol.PackagePath, ep.Symbol.Name, ep.Symbol.Position.Line, w)
+		}
+	}
+}
+
+func TestEntrypointDirective(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		ok   bool
+	}{
+		"//ripples:entrypoint name=cron":  {"cron", true},
+		"//ripples:entrypoint":            {"Run", true},
+		"//ripples:entrypoints name=cron": {"", false},
+		"// ripples:entrypoint name=cron": {"", false},
+	}
+	for text, want := range tests {
+		fd := parseFuncDecl(t, text+"\nfunc Run() {}")
+		name, ok := entrypointDirective(fd)
+		if name != want.name || ok != want.ok {
+			t.Errorf("%q: got (%q, %v), want (%q, %v)", text, name, ok, want.name, want.ok)
+		}
+	}
+}
+
+// parseFuncDecl 解析一个只含单个函数声明的源码片段
+func parseFuncDecl(t *testing.T, src string) *ast.FuncDecl {
+	t.Helper()
+	file, err := goparser.ParseFile(token.NewFileSet(), "", "package p\n\n"+src, goparser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	return file.Decls[0].(*ast.FuncDecl)
+}
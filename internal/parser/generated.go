@@ -0,0 +1,17 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+)
+
+// IsGenerated 判断文件是否是生成的代码: package 子句之前有
+// "// Code generated ... DO NOT EDIT." 注释。文件无法读取或解析时返回 false
+func IsGenerated(filename string) bool {
+	file, err := goparser.ParseFile(token.NewFileSet(), filename, nil, goparser.PackageClauseOnly|goparser.ParseComments)
+	if err != nil {
+		return false
+	}
+	return ast.IsGenerated(file)
+}
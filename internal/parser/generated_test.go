@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGenerated(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n", true},
+		{"doc.go", "// Package p 的文档\n//\n// Code generated by hand. DO NOT EDIT.\npackage p\n", true},
+		{"plain.go", "package p\n\n// Code generated by x. DO NOT EDIT.\nvar X = 1\n", false},
+		{"almost.go", "// Code generated by x. Please do not edit.\npackage p\n", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := IsGenerated(path); got != tt.want {
+			t.Errorf("IsGenerated(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if IsGenerated(filepath.Join(dir, "missing.go")) {
+		t.Error("Expected missing file not to be generated")
+	}
+}
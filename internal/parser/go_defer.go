@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// GoDeferCallers 返回在 go 或 defer 语句中调用函数或方法 symbol 的函数(语句也可以位于
+// 函数中的函数字面量内),如 go w.loop() 所在的 Run。这些调用与直接调用一样执行变更的
+// 代码,调用链分析没有结果时由它们继续追踪。调用方按已加载包中的类型信息确定
+func (p *Parser) GoDeferCallers(symbol *Symbol) []*Symbol {
+	if symbol.Kind != SymbolKindFunction {
+		return nil
+	}
+	pkg, _, _, err := p.findFile(symbol.Position.Filename)
+	if err != nil || pkg.TypesInfo == nil {
+		return nil
+	}
+	target, _ := p.packageDecls(pkg, symbol)
+	fn, ok := target.(*types.Func)
+	if !ok {
+		return nil
+	}
+	if p.goDeferCallers == nil {
+		p.goDeferCallers = p.collectGoDeferCallers()
+	}
+	return p.goDeferCallers[fn.FullName()]
+}
+
+// collectGoDeferCallers 收集已加载包中 go 和 defer 语句调用的函数,按函数全名索引
+// 语句所在的函数,每个函数只记录一次
+func (p *Parser) collectGoDeferCallers() map[string][]*Symbol {
+	res := make(map[string][]*Symbol)
+	for _, pkg := range p.packages {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			filename := p.fset.Position(file.Pos()).Filename
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				var caller *Symbol
+				seen := make(map[string]bool)
+				ast.Inspect(fd.Body, func(n ast.Node) bool {
+					var call *ast.CallExpr
+					switch n := n.(type) {
+					case *ast.GoStmt:
+						call = n.Call
+					case *ast.DeferStmt:
+						call = n.Call
+					default:
+						return true
+					}
+					fn := calledFunc(pkg.TypesInfo, call.Fun)
+					if fn == nil || seen[fn.FullName()] {
+						return true
+					}
+					seen[fn.FullName()] = true
+					if caller == nil {
+						symbols := p.extractFunction(fd, pkg, filename)
+						if len(symbols) == 0 {
+							return false
+						}
+						caller = symbols[0]
+					}
+					res[fn.FullName()] = append(res[fn.FullName()], caller)
+					return true
+				})
+			}
+		}
+	}
+	return res
+}
+
+// calledFunc 返回调用表达式调用的函数或方法(泛型实例化时为原始函数),
+// 调用函数值或接口方法时返回 nil
+func calledFunc(info *types.Info, fun ast.Expr) *types.Func {
+	for {
+		switch e := fun.(type) {
+		case *ast.ParenExpr:
+			fun = e.X
+			continue
+		case *ast.IndexExpr:
+			fun = e.X
+			continue
+		case *ast.IndexListExpr:
+			fun = e.X
+			continue
+		}
+		break
+	}
+	var id *ast.Ident
+	switch e := fun.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok {
+		return nil
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil && types.IsInterface(sig.Recv().Type()) {
+		return nil
+	}
+	return fn.Origin()
+}
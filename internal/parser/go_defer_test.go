@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGoDeferCallers(t *testing.T) {
+	tests := []struct {
+		name    string
+		callers []string
+	}{
+		{"Loop", []string{"Run"}},       // go w.Loop()
+		{"Poll", []string{"Start"}},     // go Poll(1)
+		{"Cleanup", []string{"Finish"}}, // defer Cleanup("finish")
+		{"Version", nil},
+	}
+	project := filepath.Join("..", "..", "testdata", "go-defer-test")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, symbol := findSymbol(t, project, "internal/jobs/jobs.go", tt.name)
+			var names []string
+			for _, s := range p.GoDeferCallers(symbol) {
+				names = append(names, s.Name)
+			}
+			if len(names) != len(tt.callers) || len(names) > 0 && names[0] != tt.callers[0] {
+				t.Errorf("GoDeferCallers(%s) = %v, want %v", tt.name, names, tt.callers)
+			}
+		})
+	}
+}
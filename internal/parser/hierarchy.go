@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// packageFile 已解析文件的文件符号和顶层声明(按源码顺序,包括方法)
+type packageFile struct {
+	symbol *Symbol
+	decls  []*Symbol
+}
+
+// buildHierarchy 解析包中的所有文件,建立符号层级: 包 -> 文件 -> 顶层声明,
+// 结构体 -> 字段,接口 -> 方法,类型 -> 在包内任一文件中声明的方法。
+// 接收者类型不在包内(如类型别名指向的外部类型)的方法挂在所在文件下
+func (p *Parser) buildHierarchy(pkg *packages.Package) {
+	if p.files == nil {
+		p.files = make(map[string]*packageFile)
+	}
+	pkgSymbol := &Symbol{
+		Name:        pkg.Name,
+		Kind:        SymbolKindPackage,
+		PackagePath: pkg.PkgPath,
+	}
+	typeSymbols := make(map[string]*Symbol)
+	type method struct {
+		symbol *Symbol
+		file   *Symbol
+	}
+	var methods []method
+	for i, file := range pkg.Syntax {
+		if i >= len(pkg.GoFiles) {
+			break
+		}
+		filename, err := filepath.Abs(pkg.GoFiles[i])
+		if err != nil {
+			continue
+		}
+		decls, _ := p.extractSymbolsFromFile(file, pkg, filename)
+		fileSymbol := &Symbol{
+			Parent:      pkgSymbol,
+			Name:        filename,
+			Kind:        SymbolKindFile,
+			Position:    p.fset.Position(file.Package),
+			StartPos:    file.FileStart,
+			EndPos:      file.FileEnd,
+			PackagePath: pkg.PkgPath,
+		}
+		if i == 0 {
+			pkgSymbol.Position = fileSymbol.Position
+		}
+		pkgSymbol.Children = append(pkgSymbol.Children, fileSymbol)
+		for _, d := range decls {
+			if extra, ok := d.Extra.(FunctionExtra); ok && extra.IsMethod {
+				methods = append(methods, method{symbol: d, file: fileSymbol})
+				continue
+			}
+			d.Parent = fileSymbol
+			fileSymbol.Children = append(fileSymbol.Children, d)
+			if _, ok := d.Extra.(TypeExtra); ok {
+				typeSymbols[d.Name] = d
+			}
+		}
+		p.files[filename] = &packageFile{symbol: fileSymbol, decls: decls}
+	}
+
+	for _, m := range methods {
+		extra := m.symbol.Extra.(FunctionExtra)
+		recv, _, _ := strings.Cut(strings.TrimLeft(extra.ReceiverType, "*"), "[")
+		parent := typeSymbols[recv]
+		if parent == nil {
+			parent = m.file
+		}
+		m.symbol.Parent = parent
+		parent.Children = append(parent.Children, m.symbol)
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// ImportGraph 仓库中各包的导入关系
+type ImportGraph struct {
+	Mains []MainPackage // 声明了 main 函数的 main 包,按导入路径排序
+
+	imports map[string][]string // 导入路径 -> 导入的包(已排序),只包含仓库中的包
+	byDir   map[string]string   // 包目录 -> 导入路径
+}
+
+// ImportChain main 包经由导入关系到达某个包的一条链
+type ImportChain struct {
+	Main     MainPackage
+	Packages []string // 导入路径,从 main 包到目标包(两端都包含)
+}
+
+// LoadImportGraph 加载 modules(各模块根目录)下的所有包,只解析导入关系和
+// 查找 main 函数所需的语法,不做类型检查
+func LoadImportGraph(ctx context.Context, modules []string) (*ImportGraph, error) {
+	g := &ImportGraph{
+		imports: make(map[string][]string),
+		byDir:   make(map[string]string),
+	}
+	var mains []*packages.Package
+	for _, dir := range modules {
+		cfg := &packages.Config{
+			Context: ctx,
+			Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedModule | packages.NeedImports | packages.NeedSyntax,
+			Dir:     dir,
+		}
+		pkgs, err := packages.Load(cfg, "./...")
+		if err != nil {
+			return nil, i18n.Errorf("加载项目失败: %w", err)
+		}
+		for _, pkg := range pkgs {
+			for _, err := range pkg.Errors {
+				logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+			}
+			g.imports[pkg.PkgPath] = nil
+			for _, imp := range pkg.Imports {
+				g.imports[pkg.PkgPath] = append(g.imports[pkg.PkgPath], imp.PkgPath)
+			}
+			sort.Strings(g.imports[pkg.PkgPath])
+			if pkg.Name == "main" {
+				mains = append(mains, pkg)
+			}
+			if len(pkg.GoFiles) > 0 {
+				g.byDir[filepath.Dir(pkg.GoFiles[0])] = pkg.PkgPath
+			}
+		}
+	}
+
+	for _, pkg := range mains {
+		file := mainFuncFile(pkg)
+		if file == "" {
+			continue
+		}
+		mp := MainPackage{PkgPath: pkg.PkgPath, Dir: filepath.Dir(file), File: file}
+		if pkg.Module != nil {
+			mp.Module = pkg.Module.Path
+		}
+		g.Mains = append(g.Mains, mp)
+	}
+	sort.Slice(g.Mains, func(i, j int) bool { return g.Mains[i].PkgPath < g.Mains[j].PkgPath })
+	return g, nil
+}
+
+// PkgPath 返回目录 dir(绝对路径)中的包的导入路径,没有包时返回空字符串
+func (g *ImportGraph) PkgPath(dir string) string {
+	return g.byDir[dir]
+}
+
+// Importers 返回直接或间接导入 pkgPath 的 main 包(包括 pkgPath 本身是 main 包的情况),
+// 每个 main 包一条最短的导入链,按 main 包导入路径排序
+func (g *ImportGraph) Importers(pkgPath string) []ImportChain {
+	var res []ImportChain
+	for _, main := range g.Mains {
+		if chain := g.chain(main.PkgPath, pkgPath); chain != nil {
+			res = append(res, ImportChain{Main: main, Packages: chain})
+		}
+	}
+	return res
+}
+
+// chain 从 from 开始广度优先遍历导入图,返回到 to 的最短导入链,到达不了时返回 nil。
+// 只经过仓库中的包,标准库和第三方依赖不会导入仓库中的包
+func (g *ImportGraph) chain(from, to string) []string {
+	parent := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		if pkgPath == to {
+			var chain []string
+			for p := pkgPath; p != ""; p = parent[p] {
+				chain = append(chain, p)
+			}
+			slices.Reverse(chain)
+			return chain
+		}
+		for _, next := range g.imports[pkgPath] {
+			if _, seen := parent[next]; seen {
+				continue
+			}
+			if _, ok := g.imports[next]; !ok {
+				continue
+			}
+			parent[next] = pkgPath
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
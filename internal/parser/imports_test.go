@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestImportGraph(t *testing.T) {
+	testProject, err := filepath.Abs(filepath.Join("..", "..", "testdata", "main-package-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := LoadImportGraph(context.Background(), []string{testProject})
+	if err != nil {
+		t.Fatalf("LoadImportGraph failed: %v", err)
+	}
+	if len(g.Mains) != 3 {
+		t.Errorf("Expected 3 main packages, got %+v", g.Mains)
+	}
+
+	pkgPath := g.PkgPath(filepath.Join(testProject, "internal", "greet"))
+	if pkgPath != "example.com/main-package-test/internal/greet" {
+		t.Fatalf("Unexpected package path %q", pkgPath)
+	}
+	chains := g.Importers(pkgPath)
+	want := [][]string{
+		{"example.com/main-package-test", pkgPath},
+		{"example.com/main-package-test/cmd/api", pkgPath},
+	}
+	if len(chains) != len(want) {
+		t.Fatalf("Expected %d importers, got %+v", len(want), chains)
+	}
+	for i, chain := range chains {
+		if !slices.Equal(chain.Packages, want[i]) || chain.Main.PkgPath != want[i][0] {
+			t.Errorf("chains[%d] = %+v, want %v", i, chain, want[i])
+		}
+	}
+
+	// A main package is its own importer
+	migrate := "example.com/main-package-test/tools/migrate"
+	if chains := g.Importers(migrate); len(chains) != 1 || !slices.Equal(chains[0].Packages, []string{migrate}) {
+		t.Errorf("Expected migrate to import itself only, got %+v", chains)
+	}
+}
@@ -0,0 +1,503 @@
+package parser
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// IndexFile ripples index 默认生成的索引文件,相对仓库根目录
+const IndexFile = ".ripples-index"
+
+// indexVersion 索引格式的版本,格式变化后旧的索引整体失效
+const indexVersion = 1
+
+// Index 仓库中所有包的顶层符号、位置和导入关系,由 ripples index 生成。
+// 分析时目录内容哈希没有变化的包直接使用索引,不再由 go list 列出、解析,
+// 用于计算变更包的反向依赖、导入图和查找 main 函数
+type Index struct {
+	Version  int               `json:"version"`
+	Build    string            `json:"build"`    // 生成时的 GOOS/GOARCH 和 GOFLAGS,决定包中有哪些文件
+	Modules  map[string]string `json:"modules"`  // 模块根目录(相对仓库根目录) -> go.mod 的内容哈希
+	Packages []*IndexedPackage `json:"packages"` // 按目录排序
+
+	root string // 仓库根目录(绝对路径),Update 后设置
+}
+
+// IndexedPackage 索引中的一个包
+type IndexedPackage struct {
+	PkgPath   string            `json:"path"`              // 导入路径,目录中的文件都被构建约束排除时为空
+	Name      string            `json:"name,omitempty"`    // 包名
+	Module    string            `json:"module,omitempty"`  // 所属模块路径
+	ModuleDir string            `json:"module_dir"`        // 所属模块的根目录(相对仓库根目录)
+	Dir       string            `json:"dir"`               // 包目录(相对仓库根目录,以 / 分隔)
+	Files     map[string]string `json:"files"`             // 目录中非测试 .go 文件名 -> 内容哈希
+	Imports   []string          `json:"imports,omitempty"` // 导入的包,已排序
+	Symbols   []IndexedSymbol   `json:"symbols,omitempty"` // 顶层符号,按文件和位置排序
+}
+
+// IndexedSymbol 索引中的一个顶层符号
+type IndexedSymbol struct {
+	Name     string     `json:"name"`
+	Kind     SymbolKind `json:"kind"`
+	Receiver string     `json:"recv,omitempty"` // 方法接收者的类型名(不含 * 和类型参数)
+	File     string     `json:"file"`           // 所在文件名(包目录中)
+	Line     int        `json:"line"`
+	Column   int        `json:"col"`
+	Offset   int        `json:"off"`
+}
+
+// ReadIndex 读取 ripples index 生成的索引文件,版本不一致时返回错误
+func ReadIndex(filename string) (*Index, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, i18n.Errorf("解析索引文件 %s 失败: %w", filename, err)
+	}
+	var idx Index
+	if err := json.NewDecoder(zr).Decode(&idx); err != nil {
+		return nil, i18n.Errorf("解析索引文件 %s 失败: %w", filename, err)
+	}
+	if idx.Version != indexVersion {
+		return nil, i18n.Errorf("索引文件 %s 的版本为 %d,需要重新生成", filename, idx.Version)
+	}
+	return &idx, nil
+}
+
+// Write 以 gzip 压缩的 JSON 写入索引文件
+func (idx *Index) Write(filename string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Update 使索引与 root(仓库根目录)的当前内容一致,modules 为各模块根目录(绝对路径)。
+// 目录中非测试 .go 文件的内容哈希变化、新增的包重新加载,删除的包移除;构建环境或
+// go.mod 变化时整个索引重新生成。返回重新加载的包数
+func (idx *Index) Update(ctx context.Context, root string, modules []string) (int, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return 0, err
+	}
+	idx.root = root
+
+	mods := make(map[string]string, len(modules))
+	for _, dir := range modules {
+		rel, err := relDir(root, dir)
+		if err != nil {
+			return 0, err
+		}
+		// GOPATH 项目没有 go.mod,哈希为空
+		mods[rel], _ = hashFile(filepath.Join(dir, "go.mod"))
+	}
+	if build := buildKey(); idx.Version != indexVersion || idx.Build != build || !maps.Equal(idx.Modules, mods) {
+		if len(idx.Packages) > 0 {
+			logger.Info("构建环境或 go.mod 已变化,重新生成整个索引")
+		}
+		idx.Version, idx.Build, idx.Modules, idx.Packages = indexVersion, build, mods, nil
+	}
+
+	dirs, err := packageDirs(root)
+	if err != nil {
+		return 0, err
+	}
+	fresh := make(map[string]*IndexedPackage, len(idx.Packages))
+	for _, pkg := range idx.Packages {
+		if maps.Equal(pkg.Files, dirs[pkg.Dir]) {
+			fresh[pkg.Dir] = pkg
+		}
+	}
+
+	// 按模块分组需要重新加载的目录
+	stale := make(map[string][]string) // 模块根目录 -> 包目录
+	total := make(map[string]int)      // 模块根目录 -> 包目录数
+	for dir := range dirs {
+		mod := moduleDirOf(mods, dir)
+		if mod == "" {
+			continue
+		}
+		total[mod]++
+		if fresh[dir] == nil {
+			stale[mod] = append(stale[mod], dir)
+		}
+	}
+
+	packages := slices.Collect(maps.Values(fresh))
+	reloaded := 0
+	for _, mod := range slices.Sorted(maps.Keys(stale)) {
+		loaded, err := loadIndexPackages(ctx, root, mod, stale[mod], len(stale[mod]) == total[mod])
+		if err != nil {
+			return 0, err
+		}
+		for _, dir := range stale[mod] {
+			pkg := loaded[dir]
+			if pkg == nil {
+				pkg = &IndexedPackage{ModuleDir: mod, Dir: dir}
+			}
+			pkg.Files = dirs[dir]
+			packages = append(packages, pkg)
+		}
+		reloaded += len(stale[mod])
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Dir < packages[j].Dir })
+	idx.Packages = packages
+	return reloaded, nil
+}
+
+// loadIndexPackages 在模块 mod 中加载 dirs 中的包,只解析声明,不做类型检查。
+// all 为 true 时 dirs 是模块中所有的包目录,直接加载 ./...
+func loadIndexPackages(ctx context.Context, root, mod string, dirs []string, all bool) (map[string]*IndexedPackage, error) {
+	modDir := filepath.Join(root, filepath.FromSlash(mod))
+	patterns := []string{"./..."}
+	if !all {
+		patterns = patterns[:0]
+		for _, dir := range dirs {
+			rel, err := filepath.Rel(modDir, filepath.Join(root, filepath.FromSlash(dir)))
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, "./"+filepath.ToSlash(rel))
+		}
+	}
+	cfg := &packages.Config{
+		Context:   ctx,
+		Mode:      packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedModule | packages.NeedSyntax,
+		Dir:       modDir,
+		Fset:      token.NewFileSet(),
+		ParseFile: parseKeeping(func(string) bool { return false }),
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+
+	res := make(map[string]*IndexedPackage)
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			logger.Debug("索引包时出错", "package", pkg.PkgPath, "error", err)
+		}
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		dir, err := relDir(root, filepath.Dir(pkg.GoFiles[0]))
+		if err != nil {
+			return nil, err
+		}
+		ip := &IndexedPackage{
+			PkgPath:   pkg.PkgPath,
+			Name:      pkg.Name,
+			ModuleDir: mod,
+			Dir:       dir,
+		}
+		if pkg.Module != nil {
+			ip.Module = pkg.Module.Path
+		}
+		for _, imp := range pkg.Imports {
+			ip.Imports = append(ip.Imports, imp.PkgPath)
+		}
+		sort.Strings(ip.Imports)
+		for _, file := range pkg.Syntax {
+			ip.Symbols = append(ip.Symbols, indexSymbols(cfg.Fset, file)...)
+		}
+		res[dir] = ip
+	}
+	return res, nil
+}
+
+// indexSymbols 返回文件中的顶层函数、方法、类型、常量和变量
+func indexSymbols(fset *token.FileSet, file *ast.File) []IndexedSymbol {
+	var res []IndexedSymbol
+	add := func(name *ast.Ident, kind SymbolKind, recv string) {
+		if name.Name == "_" {
+			return
+		}
+		pos := fset.Position(name.Pos())
+		res = append(res, IndexedSymbol{
+			Name:     name.Name,
+			Kind:     kind,
+			Receiver: recv,
+			File:     filepath.Base(pos.Filename),
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Offset:   pos.Offset,
+		})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			switch {
+			case d.Recv != nil && len(d.Recv.List) > 0:
+				add(d.Name, SymbolKindFunction, baseTypeName(d.Recv.List[0].Type))
+			case d.Name.Name == "init":
+				add(d.Name, SymbolKindInit, "")
+			default:
+				add(d.Name, SymbolKindFunction, "")
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					kind := SymbolKindTypeAlias
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = SymbolKindStruct
+					case *ast.InterfaceType:
+						kind = SymbolKindInterface
+					}
+					add(s.Name, kind, "")
+				case *ast.ValueSpec:
+					kind := SymbolKindVariable
+					if d.Tok == token.CONST {
+						kind = SymbolKindConstant
+					}
+					for _, name := range s.Names {
+						add(name, kind, "")
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Symbols 返回包 pkgPath 的顶层符号,索引中没有该包时 ok 为 false
+func (idx *Index) Symbols(pkgPath string) (symbols []IndexedSymbol, ok bool) {
+	for _, pkg := range idx.Packages {
+		if pkg.PkgPath == pkgPath {
+			return pkg.Symbols, true
+		}
+	}
+	return nil, false
+}
+
+// Mains 返回所有 main 包的 main 函数,与 FindMains 的结果一致
+func (idx *Index) Mains() []Entrypoint {
+	var res []Entrypoint
+	for _, pkg := range idx.Packages {
+		if pkg.Name != "main" {
+			continue
+		}
+		for _, s := range pkg.Symbols {
+			if s.Name != "main" || s.Kind != SymbolKindFunction || s.Receiver != "" {
+				continue
+			}
+			res = append(res, Entrypoint{
+				Name: BinaryName(pkg.PkgPath),
+				Symbol: &Symbol{
+					Name:        "main",
+					Kind:        SymbolKindFunction,
+					Position:    idx.position(pkg, s),
+					PackagePath: pkg.PkgPath,
+				},
+				Main: true,
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// ImportGraph 由索引构建 modules(各模块根目录)中的包的导入图,与 LoadImportGraph 的结果一致
+func (idx *Index) ImportGraph(modules []string) *ImportGraph {
+	g := &ImportGraph{
+		imports: make(map[string][]string),
+		byDir:   make(map[string]string),
+	}
+	for _, pkg := range idx.packagesIn(modules) {
+		g.imports[pkg.PkgPath] = pkg.Imports
+		g.byDir[filepath.Join(idx.root, filepath.FromSlash(pkg.Dir))] = pkg.PkgPath
+		if pkg.Name != "main" {
+			continue
+		}
+		for _, s := range pkg.Symbols {
+			if s.Name == "main" && s.Kind == SymbolKindFunction && s.Receiver == "" {
+				file := idx.position(pkg, s).Filename
+				g.Mains = append(g.Mains, MainPackage{PkgPath: pkg.PkgPath, Module: pkg.Module, Dir: filepath.Dir(file), File: file})
+				break
+			}
+		}
+	}
+	sort.Slice(g.Mains, func(i, j int) bool { return g.Mains[i].PkgPath < g.Mains[j].PkgPath })
+	return g
+}
+
+// reverseDeps 与同名函数相同,由索引计算 dirs(绝对路径)中的包和它们在 modules 中的反向依赖
+func (idx *Index) reverseDeps(modules []string, dirs map[string]bool) (changed, importers []string) {
+	importedBy := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, pkg := range idx.packagesIn(modules) {
+		for _, imp := range pkg.Imports {
+			importedBy[imp] = append(importedBy[imp], pkg.PkgPath)
+		}
+		if dirs[filepath.Join(idx.root, filepath.FromSlash(pkg.Dir))] && !seen[pkg.PkgPath] {
+			seen[pkg.PkgPath] = true
+			changed = append(changed, pkg.PkgPath)
+		}
+	}
+
+	queue := slices.Clone(changed)
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		for _, importer := range importedBy[pkgPath] {
+			if seen[importer] {
+				continue
+			}
+			seen[importer] = true
+			queue = append(queue, importer)
+			importers = append(importers, importer)
+		}
+	}
+	return changed, importers
+}
+
+// packagesIn 返回属于 modules(各模块根目录,绝对路径)的包,跳过没有导入路径的目录
+func (idx *Index) packagesIn(modules []string) []*IndexedPackage {
+	in := make(map[string]bool, len(modules))
+	for _, dir := range modules {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := relDir(idx.root, abs); err == nil {
+			in[rel] = true
+		}
+	}
+	var res []*IndexedPackage
+	for _, pkg := range idx.Packages {
+		if pkg.PkgPath != "" && in[pkg.ModuleDir] {
+			res = append(res, pkg)
+		}
+	}
+	return res
+}
+
+// position 返回符号的位置,文件为绝对路径
+func (idx *Index) position(pkg *IndexedPackage, s IndexedSymbol) token.Position {
+	return token.Position{
+		Filename: filepath.Join(idx.root, filepath.FromSlash(pkg.Dir), s.File),
+		Offset:   s.Offset,
+		Line:     s.Line,
+		Column:   s.Column,
+	}
+}
+
+// packageDirs 返回 root 下包含非测试 .go 文件的目录(相对 root,以 / 分隔)及其中
+// 每个文件的内容哈希。与 go 的 ./... 一样跳过 vendor、testdata 和以 . 或 _ 开头的目录
+func packageDirs(root string) (map[string]map[string]string, error) {
+	res := make(map[string]map[string]string)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return nil
+		}
+		dir, err := relDir(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		hash, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		if res[dir] == nil {
+			res[dir] = make(map[string]string)
+		}
+		res[dir][name] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("计算文件哈希失败: %w", err)
+	}
+	return res, nil
+}
+
+// moduleDirOf 返回包含目录 dir 的最内层模块根目录,没有时返回空字符串
+func moduleDirOf(mods map[string]string, dir string) string {
+	best := ""
+	for mod := range mods {
+		if (mod == "." || dir == mod || strings.HasPrefix(dir, mod+"/")) && (best == "" || best == "." || len(mod) > len(best)) {
+			best = mod
+		}
+	}
+	return best
+}
+
+// relDir 返回 dir 相对 root 的路径,以 / 分隔
+func relDir(root, dir string) (string, error) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", err
+	}
+	return path.Clean(filepath.ToSlash(rel)), nil
+}
+
+// hashFile 返回文件内容的 SHA-256 哈希的前 16 个十六进制字符
+func hashFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// buildKey 返回影响包中文件的构建环境
+func buildKey() string {
+	goos, goarch := os.Getenv("GOOS"), os.Getenv("GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos + "/" + goarch + " " + os.Getenv("GOFLAGS")
+}
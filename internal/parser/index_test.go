@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIndexMatchesLoaders(t *testing.T) {
+	ctx := context.Background()
+	root, err := filepath.Abs(filepath.Join("..", "..", "testdata", "service-boundary-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := &Index{}
+	if _, err := idx.Update(ctx, root, []string{root}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	dirs := map[string]bool{filepath.Join(root, "internal", "bill", "api"): true}
+	wantChanged, wantImporters, err := reverseDeps([]string{root}, dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, importers := idx.reverseDeps([]string{root}, dirs)
+	slices.Sort(importers)
+	slices.Sort(wantImporters)
+	if !slices.Equal(changed, wantChanged) || !slices.Equal(importers, wantImporters) {
+		t.Errorf("reverseDeps = %v, %v, want %v, %v", changed, importers, wantChanged, wantImporters)
+	}
+
+	root, err = filepath.Abs(filepath.Join("..", "..", "testdata", "main-package-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx = &Index{}
+	if _, err := idx.Update(ctx, root, []string{root}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	want, err := LoadImportGraph(ctx, []string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := idx.ImportGraph([]string{root})
+	if !slices.Equal(g.Mains, want.Mains) {
+		t.Errorf("Mains = %+v, want %+v", g.Mains, want.Mains)
+	}
+	greet := g.PkgPath(filepath.Join(root, "internal", "greet"))
+	if len(g.Importers(greet)) != len(want.Importers(greet)) {
+		t.Errorf("Importers = %+v, want %+v", g.Importers(greet), want.Importers(greet))
+	}
+
+	mains, err := FindMains(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := idx.Mains()
+	if len(got) != len(mains) {
+		t.Fatalf("Mains = %+v, want %+v", got, mains)
+	}
+	for i := range mains {
+		if got[i].Name != mains[i].Name || got[i].Symbol.Position != mains[i].Symbol.Position {
+			t.Errorf("Mains[%d] = %+v, want %+v", i, got[i].Symbol, mains[i].Symbol)
+		}
+	}
+}
+
+func TestIndexUpdate(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.CopyFS(root, os.DirFS(filepath.Join("..", "..", "testdata", "main-package-test"))); err != nil {
+		t.Fatal(err)
+	}
+	modules := []string{root}
+
+	idx := &Index{}
+	if n, err := idx.Update(ctx, root, modules); err != nil || n != 5 {
+		t.Fatalf("Update = %d, %v, want all 5 packages", n, err)
+	}
+	filename := filepath.Join(t.TempDir(), IndexFile)
+	if err := idx.Write(filename); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	idx, err := ReadIndex(filename)
+	if err != nil {
+		t.Fatalf("ReadIndex failed: %v", err)
+	}
+	if n, err := idx.Update(ctx, root, modules); err != nil || n != 0 {
+		t.Fatalf("Update = %d, %v, want no package reloaded", n, err)
+	}
+
+	// 修改一个包、新增一个包、删除一个包
+	greet := filepath.Join(root, "internal", "greet", "greet.go")
+	if err := os.WriteFile(greet, []byte("package greet\n\nfunc Hello() string { return \"hi\" }\n\nfunc Bye() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "internal", "extra"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "internal", "extra", "extra.go"), []byte("package extra\n\nconst Name = \"x\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "internal", "db")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := idx.Update(ctx, root, modules); err != nil || n != 2 {
+		t.Fatalf("Update = %d, %v, want the changed and the new package reloaded", n, err)
+	}
+
+	symbols, ok := idx.Symbols("example.com/main-package-test/internal/greet")
+	if !ok || !slices.ContainsFunc(symbols, func(s IndexedSymbol) bool { return s.Name == "Bye" && s.Line == 5 }) {
+		t.Errorf("Expected the new function Bye, got %+v", symbols)
+	}
+	if _, ok := idx.Symbols("example.com/main-package-test/internal/extra"); !ok {
+		t.Error("Expected the new package to be indexed")
+	}
+	if _, ok := idx.Symbols("example.com/main-package-test/internal/db"); ok {
+		t.Error("Expected the deleted package to be dropped")
+	}
+
+	// go.mod 变化后整个索引重新生成
+	gomod := filepath.Join(root, "go.mod")
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gomod, append(data, "\n// changed\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := idx.Update(ctx, root, modules); err != nil || n != len(idx.Packages) {
+		t.Fatalf("Update = %d, %v, want all %d packages reloaded", n, err, len(idx.Packages))
+	}
+}
+
+func TestIndexSymbols(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", "testdata", "service-boundary-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := &Index{}
+	if _, err := idx.Update(context.Background(), root, []string{root}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	symbols, ok := idx.Symbols("example.com/boundary/internal/bill/api")
+	if !ok {
+		t.Fatal("Package not indexed")
+	}
+	p := NewParser()
+	if err := p.LoadChangedFiles(root, []string{"internal/bill/api/api.go"}); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := p.ParseFile(filepath.Join(root, "internal", "bill", "api", "api.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range parsed {
+		if s.Kind == SymbolKindImport {
+			continue
+		}
+		if !slices.ContainsFunc(symbols, func(is IndexedSymbol) bool { return is.Name == s.Name && is.Kind == s.Kind }) {
+			t.Errorf("Symbol %s (%s) not indexed, got %+v", s.Name, s.Kind, symbols)
+		}
+	}
+}
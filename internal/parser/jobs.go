@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"context"
+	"go/ast"
+	"maps"
+	"slices"
+
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// Job 服务内作为调用链根的函数,如定时任务 jobs.RunNightlyReconcile。服务本身
+// 受影响时,报告经由哪些任务受影响
+type Job struct {
+	Name     string       // 配置中的 "包目录.函数" 或 "包目录.接收者.方法"
+	Symbol   *Symbol      // 任务函数,Position 指向函数名
+	Binaries []Entrypoint // 运行该任务的服务
+}
+
+// FindJobs 返回 functions 中配置的任务函数,格式与 FindEntrypoints 的配置相同,
+// Binaries 留空。只加载函数所在的包(含测试文件,只解析语法)
+func FindJobs(ctx context.Context, dir string, functions []string) ([]Job, error) {
+	patterns := make(map[string]bool)
+	for _, fn := range functions {
+		if dir, ok := functionDir(fn); ok {
+			patterns["./"+dir] = true
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	found := make(map[string]*Symbol)
+	err := eachFunction(ctx, dir, patterns, func(pkg *packages.Package, fd *ast.FuncDecl, key string) {
+		if _, ok := found[key]; !ok && slices.Contains(functions, key) {
+			found[key] = functionSymbol(pkg, fd)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range functions {
+		if found[fn] == nil {
+			logger.Warn("未找到配置的任务函数", "function", fn)
+		}
+	}
+
+	var res []Job
+	for _, key := range slices.Sorted(maps.Keys(found)) {
+		res = append(res, Job{Name: key, Symbol: found[key]})
+	}
+	return res, nil
+}
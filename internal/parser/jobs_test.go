@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindJobs(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "job-test")
+
+	jobs, err := FindJobs(context.Background(), testProject, []string{
+		"internal/jobs.RunNightlyReconcile",
+		"internal/jobs.RunHourlyCleanup",
+		"internal/jobs.Missing",
+	})
+	if err != nil {
+		t.Fatalf("FindJobs failed: %v", err)
+	}
+
+	want := []struct {
+		name, function string
+		line           int
+	}{
+		{"internal/jobs.RunHourlyCleanup", "RunHourlyCleanup", 15},
+		{"internal/jobs.RunNightlyReconcile", "RunNightlyReconcile", 10},
+	}
+	if len(jobs) != len(want) {
+		t.Fatalf("Expected %d jobs, got %+v", len(want), jobs)
+	}
+	for i, job := range jobs {
+		w := want[i]
+		if job.Name != w.name || job.Symbol.Name != w.function || job.Symbol.PackagePath != "example.com/job-test/internal/jobs" || job.Symbol.Position.Line != w.line {
+			t.Errorf("jobs[%d] = %s %s.%s:%d, want %+v", i, job.Name, job.Symbol.PackagePath, job.Symbol.Name, job.Symbol.Position.Line, w)
+		}
+	}
+}
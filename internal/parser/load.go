@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseKeeping 返回 packages.Config.ParseFile 的实现: keep 返回 false 的文件丢弃函数体。
+// 类型检查跳过函数体后只需处理声明,依赖和反向依赖的包级类型不受影响,加载快得多。
+// 丢弃函数体后未使用的导入会报错,这些包的错误应忽略
+func parseKeeping(keep func(filename string) bool) func(*token.FileSet, string, []byte) (*ast.File, error) {
+	return func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		if keep(filename) {
+			return goparser.ParseFile(fset, filename, src, goparser.AllErrors|goparser.ParseComments)
+		}
+		file, err := goparser.ParseFile(fset, filename, src, goparser.AllErrors|goparser.ParseComments|goparser.SkipObjectResolution)
+		if file != nil {
+			for _, decl := range file.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok {
+					fd.Body = nil
+				}
+			}
+		}
+		return file, err
+	}
+}
+
+// inDirs 判断文件是否直接位于 dirs(绝对路径)中的某个目录
+func inDirs(dirs map[string]bool) func(filename string) bool {
+	return func(filename string) bool {
+		return dirs[filepath.Dir(filename)]
+	}
+}
+
+// underDir 判断文件是否位于 dir(绝对路径)之下
+func underDir(dir string) func(filename string) bool {
+	prefix := dir + string(filepath.Separator)
+	return func(filename string) bool {
+		return strings.HasPrefix(filename, prefix)
+	}
+}
+
+// reverseDeps 返回目录 dirs(绝对路径)中的包,以及 modules(各模块根目录)中直接或间接
+// 导入它们的包(反向依赖闭包),都是导入路径。只列出导入关系,不做类型检查
+func reverseDeps(modules []string, dirs map[string]bool) (changed, importers []string, err error) {
+	var pkgs []*packages.Package
+	for _, dir := range modules {
+		cfg := &packages.Config{
+			Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports,
+			Dir:  dir,
+		}
+		loaded, err := packages.Load(cfg, "./...")
+		if err != nil {
+			return nil, nil, i18n.Errorf("计算变更包的反向依赖失败: %w", err)
+		}
+		for _, pkg := range loaded {
+			for _, err := range pkg.Errors {
+				logger.Warn("列出包时出错,反向依赖可能不完整", "package", pkg.PkgPath, "error", err)
+			}
+		}
+		pkgs = append(pkgs, loaded...)
+	}
+
+	// 导入路径 -> 导入它的包
+	importedBy := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, imp := range pkg.Imports {
+			importedBy[imp.PkgPath] = append(importedBy[imp.PkgPath], pkg.PkgPath)
+		}
+		if len(pkg.GoFiles) > 0 && dirs[filepath.Dir(pkg.GoFiles[0])] && !seen[pkg.PkgPath] {
+			seen[pkg.PkgPath] = true
+			changed = append(changed, pkg.PkgPath)
+		}
+	}
+
+	queue := slices.Clone(changed)
+	for len(queue) > 0 {
+		pkgPath := queue[0]
+		queue = queue[1:]
+		for _, importer := range importedBy[pkgPath] {
+			if seen[importer] {
+				continue
+			}
+			seen[importer] = true
+			queue = append(queue, importer)
+			importers = append(importers, importer)
+		}
+	}
+	return changed, importers, nil
+}
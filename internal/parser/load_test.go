@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadChangedFilesImporters(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "service-boundary-test")
+
+	p := NewParser()
+	if err := p.LoadChangedFiles(testProject, []string{"internal/bill/api/api.go"}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	if pkgs := p.GetPackages(); len(pkgs) != 1 || pkgs[0].PkgPath != "example.com/boundary/internal/bill/api" {
+		t.Fatalf("Expected only the changed package, got %d packages", len(pkgs))
+	}
+
+	symbols, err := p.ParseFile(filepath.Join(testProject, "internal/bill/api/api.go"))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	for _, s := range symbols {
+		if s.Name != "Start" {
+			continue
+		}
+		// grace.Runner is only reachable through cmd/bill, which imports api
+		if extra, ok := s.Extra.(FunctionExtra); !ok || !extra.ImplementsInterface {
+			t.Errorf("Expected Start to satisfy grace.Runner, got %+v", s.Extra)
+		}
+		return
+	}
+	t.Error("Symbol Start not found")
+}
+
+func TestReverseDeps(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", "testdata", "service-boundary-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := map[string]bool{
+		filepath.Join(root, "internal", "bill", "api"): true,
+		filepath.Join(root, "internal", "deleted"):     true, // 目录已删除,没有对应的包
+	}
+	changed, importers, err := reverseDeps([]string{root}, dirs)
+	if err != nil {
+		t.Fatalf("reverseDeps failed: %v", err)
+	}
+	if want := []string{"example.com/boundary/internal/bill/api"}; !slices.Equal(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	slices.Sort(importers)
+	if want := []string{"example.com/boundary/cmd/bill", "example.com/boundary/cmd/rfs"}; !slices.Equal(importers, want) {
+		t.Errorf("importers = %v, want %v", importers, want)
+	}
+}
+
+func TestLoadProjectExcludesBrokenPackages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                 "module example.com/broken\n\ngo 1.21\n",
+		"ok/ok.go":               "package ok\n\nfunc OK() int { return 1 }\n",
+		"experimental/broken.go": "package experimental\n\nfunc Broken() int { return missing }\n",
+		"cmd/app/main.go":        "package main\n\nimport \"example.com/broken/ok\"\n\nfunc main() { _ = ok.OK() }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewParser()
+	if err := p.LoadProject(dir); err != nil {
+		t.Fatalf("Expected the broken package not to fail the load: %v", err)
+	}
+	var loaded []string
+	for _, pkg := range p.GetPackages() {
+		loaded = append(loaded, pkg.PkgPath)
+	}
+	slices.Sort(loaded)
+	if want := []string{"example.com/broken/cmd/app", "example.com/broken/ok"}; !slices.Equal(loaded, want) {
+		t.Errorf("loaded = %v, want %v", loaded, want)
+	}
+	if failed := p.FailedPackages(); len(failed) != 1 || failed[0].PkgPath != "example.com/broken/experimental" {
+		t.Errorf("Expected experimental to be reported as failed, got %v", failed)
+	}
+}
@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"context"
+	"go/ast"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// MainPackage 声明了 main 函数的 main 包
+type MainPackage struct {
+	PkgPath string // 导入路径
+	Module  string // 所属模块路径
+	Dir     string // 包目录(绝对路径)
+	File    string // main 函数所在文件(绝对路径)
+}
+
+// FindMainPackages 加载 dir 所在模块下的所有包(只解析语法,不做类型检查),
+// 返回声明了 main 函数的 main 包,按导入路径排序
+func FindMainPackages(ctx context.Context, dir string) ([]MainPackage, error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedModule | packages.NeedSyntax,
+		Dir:     dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+
+	var res []MainPackage
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			for _, err := range pkg.Errors {
+				logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+			}
+		}
+		if pkg.Name != "main" {
+			continue
+		}
+		file := mainFuncFile(pkg)
+		if file == "" {
+			continue
+		}
+		mp := MainPackage{
+			PkgPath: pkg.PkgPath,
+			Dir:     filepath.Dir(file),
+			File:    file,
+		}
+		if pkg.Module != nil {
+			mp.Module = pkg.Module.Path
+		}
+		res = append(res, mp)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].PkgPath < res[j].PkgPath })
+	return res, nil
+}
+
+// mainFuncFile 返回声明 main 函数的文件,没有时返回空字符串
+func mainFuncFile(pkg *packages.Package) string {
+	for i, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && fn.Name.Name == "main" && i < len(pkg.CompiledGoFiles) {
+				return pkg.CompiledGoFiles[i]
+			}
+		}
+	}
+	return ""
+}
+
+// FindUntracedMains 返回 dir(仓库根目录)中 gopls 追踪器识别不到的 main 函数:
+// 包名为 main,但不在 cmd/ 下或名为 main 的目录中,如 tools/migrate、services/x
+// 或仓库根目录。它们作为入口交给引用遍历,在报告中与其他服务一样按 main 包处理。
+// 只加载包含 "package main" 的目录(git grep,只解析语法)
+func FindUntracedMains(ctx context.Context, dir string) ([]Entrypoint, error) {
+	return findMains(ctx, dir, func(d string) bool { return !TracedMain(d) })
+}
+
+// FindMains 返回 dir(仓库根目录)中所有 main 包的 main 函数,服务名见 BinaryName。
+// 与 FindMainPackages 不同,只加载包含 "package main" 的目录
+func FindMains(ctx context.Context, dir string) ([]Entrypoint, error) {
+	return findMains(ctx, dir, nil)
+}
+
+// findMains 返回所在目录满足 keep 的 main 函数,keep 为 nil 时不限制。
+// keep 会先后收到相对 dir 和绝对路径形式的目录
+func findMains(ctx context.Context, dir string, keep func(dir string) bool) ([]Entrypoint, error) {
+	files, err := git.GrepFiles(dir, "package main", "*.go")
+	if err != nil {
+		return nil, err
+	}
+	patterns := make(map[string]bool)
+	for _, file := range files {
+		if d := path.Dir(file); keep == nil || keep(d) {
+			patterns["./"+d] = true
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax,
+		Dir:     dir,
+	}
+	pkgs, err := packages.Load(cfg, slices.Sorted(maps.Keys(patterns))...)
+	if err != nil {
+		return nil, i18n.Errorf("加载项目失败: %w", err)
+	}
+
+	var res []Entrypoint
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			logger.Warn("包加载错误", "package", pkg.PkgPath, "error", err)
+		}
+		if pkg.Name != "main" {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || fn.Name.Name != "main" {
+					continue
+				}
+				pos := pkg.Fset.Position(fn.Name.Pos())
+				if keep != nil && !keep(filepath.Dir(pos.Filename)) {
+					continue
+				}
+				res = append(res, Entrypoint{
+					Name: BinaryName(pkg.PkgPath),
+					Symbol: &Symbol{
+						Name:        "main",
+						Kind:        SymbolKindFunction,
+						Position:    pos,
+						PackagePath: pkg.PkgPath,
+					},
+					Main: true,
+				})
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// TracedMain 报告 gopls 追踪器能否把 dir 中的 main 函数识别为服务入口,
+// 与追踪器的规则保持一致: 目录在 cmd/ 下或名为 main
+func TracedMain(dir string) bool {
+	dir = filepath.ToSlash(dir)
+	return strings.Contains("/"+dir, "/cmd/") || path.Base(dir) == "main"
+}
+
+// BinaryName 由 main 包的导入路径得出服务名: 包目录相对模块根目录的最后一段,
+// 模块根目录下的 main 包取模块路径的最后一段。cmd/ 下的程序与追踪器的命名一致
+func BinaryName(pkgPath string) string {
+	return path.Base(pkgPath)
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindMainPackages(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "shared-package-test")
+
+	pkgs, err := FindMainPackages(context.Background(), testProject)
+	if err != nil {
+		t.Fatalf("FindMainPackages failed: %v", err)
+	}
+
+	want := []string{
+		"example.com/shared-package-test/cmd/service-a",
+		"example.com/shared-package-test/cmd/service-b",
+	}
+	if len(pkgs) != len(want) {
+		t.Fatalf("Expected %d main packages, got %+v", len(want), pkgs)
+	}
+	for i, pkg := range pkgs {
+		if pkg.PkgPath != want[i] {
+			t.Errorf("pkgs[%d].PkgPath = %s, want %s", i, pkg.PkgPath, want[i])
+		}
+		if pkg.Module != "example.com/shared-package-test" {
+			t.Errorf("pkgs[%d].Module = %s", i, pkg.Module)
+		}
+		if filepath.Base(pkg.File) != "main.go" {
+			t.Errorf("pkgs[%d].File = %s, want main.go", i, pkg.File)
+		}
+	}
+}
+
+func TestFindUntracedMains(t *testing.T) {
+	testProject := filepath.Join("..", "..", "testdata", "main-package-test")
+
+	mains, err := FindUntracedMains(context.Background(), testProject)
+	if err != nil {
+		t.Fatalf("FindUntracedMains failed: %v", err)
+	}
+
+	// cmd/api 由追踪器识别,不在结果中
+	want := []struct{ name, pkg string }{
+		{"main-package-test", "example.com/main-package-test"},
+		{"migrate", "example.com/main-package-test/tools/migrate"},
+	}
+	if len(mains) != len(want) {
+		t.Fatalf("Expected %d mains, got %+v", len(want), mains)
+	}
+	for i, w := range want {
+		ep := mains[i]
+		if ep.Name != w.name || ep.Symbol.PackagePath != w.pkg || ep.Symbol.Name != "main" || !ep.Main {
+			t.Errorf("mains[%d] = %+v %+v, want %s in %s", i, ep, ep.Symbol, w.name, w.pkg)
+		}
+	}
+}
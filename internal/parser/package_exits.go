@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ExitPoint 包内可达性分析得到的出口: 包外可以直接调用到的函数
+type ExitPoint struct {
+	Symbol *Symbol   // 导出的函数或方法,或 main 包的 main 函数
+	Via    []*Symbol // 从出口到变更符号之间经过的包内函数,按调用顺序,不含两端
+}
+
+// PackageExits 对未导出的符号做包内可达性分析: 沿包内引用向上查找,直到遇到导出的
+// 函数或方法(出口)。未导出的符号只能在包内被引用,因此只需要追踪这些出口。
+// 遇到无法在包内确定的情况(包级变量初始化、init 函数、可能通过接口动态调用的方法等)
+// 时 ok 为 false,调用方应完整追踪该符号
+func (p *Parser) PackageExits(symbol *Symbol) (exits []ExitPoint, ok bool) {
+	switch symbol.Kind {
+	case SymbolKindFunction, SymbolKindVariable, SymbolKindConstant:
+	default:
+		return nil, false
+	}
+	if ast.IsExported(symbol.Name) || symbol.Name == "main" {
+		return nil, false
+	}
+	pkg, _, _, err := p.findFile(symbol.Position.Filename)
+	if err != nil || pkg.TypesInfo == nil {
+		return nil, false
+	}
+
+	target, decls := p.packageDecls(pkg, symbol)
+	if target == nil {
+		return nil, false
+	}
+	if fn, isFunc := target.(*types.Func); isFunc && p.implementsAnyInterface(fn) {
+		return nil, false
+	}
+	users := packageUsers(pkg)
+
+	// 广度优先向上查找,parent 记录每个函数是从哪个被引用者找到的
+	parent := map[types.Object]types.Object{target: nil}
+	queue := []types.Object{target}
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		for _, user := range users[obj] {
+			if _, seen := parent[user]; seen {
+				continue
+			}
+			parent[user] = obj
+
+			fd, isFunc := decls[user]
+			fn, _ := user.(*types.Func)
+			switch {
+			case !isFunc || fn == nil:
+				// 被包级变量、常量或类型声明引用,影响取决于初始化语义
+				return nil, false
+			case fd.Recv == nil && fn.Name() == "init":
+				return nil, false
+			case fn.Exported() || (fd.Recv == nil && fn.Name() == "main" && pkg.Name == "main"):
+				exits = append(exits, p.exitPoint(pkg, user, parent, decls))
+			case p.implementsAnyInterface(fn):
+				return nil, false
+			default:
+				queue = append(queue, user)
+			}
+		}
+	}
+	return exits, true
+}
+
+// packageDecls 返回变更符号对应的对象,以及包内所有函数和方法声明(按对象索引)
+func (p *Parser) packageDecls(pkg *packages.Package, symbol *Symbol) (types.Object, map[types.Object]*ast.FuncDecl) {
+	var target types.Object
+	for ident, obj := range pkg.TypesInfo.Defs {
+		if isPackageLevel(pkg, obj) && ident.Name == symbol.Name && ident.Pos() >= symbol.StartPos && ident.Pos() < symbol.EndPos {
+			target = obj
+			break
+		}
+	}
+
+	decls := make(map[types.Object]*ast.FuncDecl)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				if obj := pkg.TypesInfo.Defs[fd.Name]; obj != nil {
+					decls[obj] = fd
+				}
+			}
+		}
+	}
+	return target, decls
+}
+
+// packageUsers 返回包级对象被哪些顶层声明引用。函数和方法声明以其对象表示,
+// 其他声明(变量、常量、类型)以声明的第一个名字表示
+func packageUsers(pkg *packages.Package) map[types.Object][]types.Object {
+	users := make(map[types.Object][]types.Object)
+	seen := make(map[[2]types.Object]bool)
+	record := func(node ast.Node, user types.Object) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			used := originOf(pkg.TypesInfo.Uses[ident])
+			if !isPackageLevel(pkg, used) || used == user {
+				return true
+			}
+			if key := [2]types.Object{used, user}; !seen[key] {
+				seen[key] = true
+				users[used] = append(users[used], user)
+			}
+			return true
+		})
+	}
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if obj := pkg.TypesInfo.Defs[d.Name]; obj != nil && d.Body != nil {
+					record(d.Body, obj)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if user := specObject(pkg, spec); user != nil {
+						record(spec, user)
+					}
+				}
+			}
+		}
+	}
+	return users
+}
+
+// specObject 返回声明中第一个名字对应的对象
+func specObject(pkg *packages.Package, spec ast.Spec) types.Object {
+	switch s := spec.(type) {
+	case *ast.ValueSpec:
+		for _, name := range s.Names {
+			if obj := pkg.TypesInfo.Defs[name]; obj != nil {
+				return obj
+			}
+		}
+	case *ast.TypeSpec:
+		return pkg.TypesInfo.Defs[s.Name]
+	}
+	return nil
+}
+
+// isPackageLevel 判断对象是否是本包的包级对象或方法
+func isPackageLevel(pkg *packages.Package, obj types.Object) bool {
+	if obj == nil || obj.Pkg() != pkg.Types {
+		return false
+	}
+	if _, ok := obj.(*types.Func); ok {
+		return true
+	}
+	return obj.Parent() == pkg.Types.Scope()
+}
+
+// originOf 泛型实例化的函数和变量返回其原始对象
+func originOf(obj types.Object) types.Object {
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Origin()
+	case *types.Var:
+		return o.Origin()
+	}
+	return obj
+}
+
+// exitPoint 构造出口及其到变更符号的中间函数
+func (p *Parser) exitPoint(pkg *packages.Package, exit types.Object, parent map[types.Object]types.Object, decls map[types.Object]*ast.FuncDecl) ExitPoint {
+	symbolOf := func(obj types.Object) *Symbol {
+		fd := decls[obj]
+		return p.extractFunction(fd, pkg, p.fset.Position(fd.Pos()).Filename)[0]
+	}
+
+	ep := ExitPoint{Symbol: symbolOf(exit)}
+	for obj := parent[exit]; obj != nil && parent[obj] != nil; obj = parent[obj] {
+		ep.Via = append(ep.Via, symbolOf(obj))
+	}
+	return ep
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// findSymbol 加载 file 所在的包并返回其中名为 name 的符号
+func findSymbol(t *testing.T, project, file, name string) (*Parser, *Symbol) {
+	t.Helper()
+	p := NewParser()
+	if err := p.LoadChangedFiles(project, []string{file}); err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	symbols, err := p.ParseFile(filepath.Join(project, file))
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	for _, s := range symbols {
+		if s.Name == name {
+			return p, s
+		}
+	}
+	t.Fatalf("Symbol %s not found in %s", name, file)
+	return nil, nil
+}
+
+func TestPackageExits(t *testing.T) {
+	tests := []struct {
+		project, file, name string
+		exits               []string // 出口名,为 nil 时期望 ok 为 false
+	}{
+		// 只被导出函数 DoWithRetry 调用
+		{"constant-test", "internal/service/retry.go", "performOperation", []string{"DoWithRetry"}},
+		// 未导出方法只被导出的 Run 调用
+		{"shared-package-test", "internal/service-a/handler.go", "internalServiceLogic", []string{"Run"}},
+		// 被包级变量的初始化表达式调用,需要完整追踪
+		{"init-test", "internal/cache/cache.go", "computeTTL", nil},
+		// 导出的符号不走包内分析
+		{"constant-test", "internal/service/retry.go", "DoWithRetry", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, symbol := findSymbol(t, filepath.Join("..", "..", "testdata", tt.project), tt.file, tt.name)
+			exits, ok := p.PackageExits(symbol)
+			if ok != (tt.exits != nil) {
+				t.Fatalf("PackageExits ok = %v, want %v", ok, tt.exits != nil)
+			}
+			var names []string
+			for _, e := range exits {
+				names = append(names, e.Symbol.Name)
+				if len(e.Via) != 0 {
+					t.Errorf("Expected a direct exit, got via %d functions", len(e.Via))
+				}
+			}
+			if len(names) != len(tt.exits) || (len(names) > 0 && names[0] != tt.exits[0]) {
+				t.Errorf("PackageExits = %v, want %v", names, tt.exits)
+			}
+		})
+	}
+}
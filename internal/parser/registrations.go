@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Registration 包初始化时把函数作为值登记到注册表的位置,如 init 中的
+// handlers["foo"] = handleFoo 或 registry.Register("foo", handleFoo)
+type Registration struct {
+	PackagePath     string // 进行登记的包,导入它的程序在初始化时完成登记
+	RegistryPackage string // 注册表(包级变量或登记函数)所在的包
+	Registry        string // 注册表的名字,如 "handlers" 或 "Register"
+}
+
+// InitRegistrations 返回包初始化时登记函数 symbol 的位置: init 函数中对 map 的赋值、
+// append 以及以函数为参数的调用,和包级变量初始值中的复合字面量。登记后函数可能被任何
+// 导入登记包的程序通过注册表调用,读取注册表的位置往往无法静态确定
+func (p *Parser) InitRegistrations(symbol *Symbol) []Registration {
+	if symbol.Kind != SymbolKindFunction {
+		return nil
+	}
+	pkg, _, _, err := p.findFile(symbol.Position.Filename)
+	if err != nil || pkg.TypesInfo == nil {
+		return nil
+	}
+	target, _ := p.packageDecls(pkg, symbol)
+	fn, ok := target.(*types.Func)
+	if !ok {
+		return nil
+	}
+	if p.initRegistrations == nil {
+		p.initRegistrations = p.collectInitRegistrations()
+	}
+	return p.initRegistrations[fn.FullName()]
+}
+
+// collectInitRegistrations 收集已加载包在初始化时登记的函数,按函数全名索引
+func (p *Parser) collectInitRegistrations() map[string][]Registration {
+	res := make(map[string][]Registration)
+	for _, pkg := range p.packages {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		add := func(value ast.Expr, registry types.Object) {
+			fn := funcValue(pkg.TypesInfo, value)
+			if fn == nil || registry == nil || registry.Pkg() == nil {
+				return
+			}
+			reg := Registration{PackagePath: pkg.PkgPath, RegistryPackage: registry.Pkg().Path(), Registry: registry.Name()}
+			for _, r := range res[fn.FullName()] {
+				if r == reg {
+					return
+				}
+			}
+			res[fn.FullName()] = append(res[fn.FullName()], reg)
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil && d.Name.Name == "init" && d.Body != nil {
+						initRegistrations(pkg, d.Body, add)
+					}
+				case *ast.GenDecl:
+					if d.Tok != token.VAR {
+						continue
+					}
+					for _, spec := range d.Specs {
+						vs := spec.(*ast.ValueSpec)
+						for i, v := range vs.Values {
+							if lit, ok := v.(*ast.CompositeLit); ok && i < len(vs.Names) {
+								for _, elt := range lit.Elts {
+									if kv, ok := elt.(*ast.KeyValueExpr); ok {
+										elt = kv.Value
+									}
+									add(elt, pkg.TypesInfo.Defs[vs.Names[i]])
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// initRegistrations 查找 init 函数体中的登记: m[k] = f、s = append(s, f) 和 register(..., f)
+func initRegistrations(pkg *packages.Package, body *ast.BlockStmt, add func(value ast.Expr, registry types.Object)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				registry := registryOf(pkg.TypesInfo, lhs)
+				if _, isIndex := lhs.(*ast.IndexExpr); isIndex {
+					add(n.Rhs[i], registry)
+				}
+				if call, ok := n.Rhs[i].(*ast.CallExpr); ok && isIdentNamed(call.Fun, "append") {
+					for _, arg := range call.Args[1:] {
+						add(arg, registry)
+					}
+				}
+			}
+			return false
+		case *ast.CallExpr:
+			if isIdentNamed(n.Fun, "append") {
+				return true
+			}
+			registry := registryOf(pkg.TypesInfo, n.Fun)
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
+				// 包级变量上的方法调用,如 mux.Handle("/a", h),登记到变量中
+				if v := registryOf(pkg.TypesInfo, sel.X); v != nil {
+					registry = v
+				}
+			}
+			for _, arg := range n.Args {
+				add(arg, registry)
+			}
+		}
+		return true
+	})
+}
+
+// registryOf 返回表达式所表示的包级变量(忽略索引和字段)或函数,其他情况返回 nil
+func registryOf(info *types.Info, expr ast.Expr) types.Object {
+	for {
+		switch e := expr.(type) {
+		case *ast.IndexExpr:
+			expr = e.X
+			continue
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.StarExpr:
+			expr = e.X
+			continue
+		}
+		break
+	}
+	var id *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+		if x := registryOf(info, e.X); x != nil {
+			// 包级变量的字段,如 app.handlers
+			if _, isVar := x.(*types.Var); isVar {
+				return x
+			}
+		}
+	default:
+		return nil
+	}
+	switch obj := info.Uses[id].(type) {
+	case *types.Var:
+		if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			return obj
+		}
+	case *types.Func:
+		return obj
+	}
+	return nil
+}
+
+// funcValue 返回作为值使用(而不是调用)的函数或方法
+func funcValue(info *types.Info, expr ast.Expr) *types.Func {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.X
+	}
+	var id *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok {
+		return nil
+	}
+	return fn.Origin()
+}
+
+// isIdentNamed 判断表达式是否是名为 name 的标识符
+func isIdentNamed(expr ast.Expr, name string) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == name
+}
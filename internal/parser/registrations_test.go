@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestInitRegistrations(t *testing.T) {
+	tests := []struct {
+		file, name string
+		want       []Registration
+	}{
+		// init 中对包级 map 的赋值
+		{"internal/handlers/handlers.go", "handleUpper", []Registration{{
+			PackagePath:     "example.com/registry-test/internal/handlers",
+			RegistryPackage: "example.com/registry-test/internal/handlers",
+			Registry:        "handlers",
+		}}},
+		// init 中以函数为参数调用其他包的登记函数
+		{"internal/plugins/plugins.go", "Trim", []Registration{{
+			PackagePath:     "example.com/registry-test/internal/plugins",
+			RegistryPackage: "example.com/registry-test/internal/registry",
+			Registry:        "Register",
+		}}},
+		{"internal/handlers/handlers.go", "Version", nil},
+	}
+	project := filepath.Join("..", "..", "testdata", "registry-test")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, symbol := findSymbol(t, project, tt.file, tt.name)
+			got := p.InitRegistrations(symbol)
+			if len(got) != len(tt.want) || len(got) > 0 && got[0] != tt.want[0] {
+				t.Errorf("InitRegistrations(%s) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import "go/token"
 
 // Symbol 表示一个符号
 type Symbol struct {
+	// 符号层级: 包 -> 文件 -> 顶层声明,结构体 -> 字段,接口 -> 方法,类型 -> 方法。
+	// 由 Parser.ParseFile 建立,其他方式构造的符号可能没有
 	Parent   *Symbol   // 父符号
 	Children []*Symbol // 子符号
 
@@ -49,8 +51,41 @@ func (i ImportExtra) IsBlankImport() bool {
 
 // FunctionExtra 函数符号的额外信息
 type FunctionExtra struct {
-	ReceiverType string // 接收者类型(如果是方法)
-	IsMethod     bool   // 是否是方法
+	ReceiverType        string // 接收者类型(如果是方法)
+	IsMethod            bool   // 是否是方法
+	ImplementsInterface bool   // 方法是否满足已加载的某个接口(可能通过接口动态调用)
+
+	// Closures 函数返回的函数字面量所在的行范围(起止行),字面量中的代码在调用返回值时才执行
+	Closures []LineRange
+}
+
+// LineRange 文件中的行范围,包含起止行
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Contains 判断行号是否在范围内
+func (r LineRange) Contains(line int) bool {
+	return line >= r.Start && line <= r.End
+}
+
+// VariableExtra 包级变量的额外信息
+type VariableExtra struct {
+	InitCall bool // 初始化表达式中调用了函数,会在包初始化时执行
+
+	// 变量自己的初始值的范围。一条声明定义多个变量时(var a, b = T{...}, U{...})
+	// 符号的范围是整条声明,用它区分变更的行属于哪个变量;否则为 token.NoPos
+	ValuePos token.Pos
+	ValueEnd token.Pos
+}
+
+// ValueContainsLine 判断变量自己的初始值是否包含指定行
+func (e VariableExtra) ValueContainsLine(fset *token.FileSet, line int) bool {
+	if !e.ValuePos.IsValid() {
+		return false
+	}
+	return line >= fset.Position(e.ValuePos).Line && line <= fset.Position(e.ValueEnd).Line
 }
 
 // TypeExtra 类型符号的额外信息
@@ -59,7 +94,7 @@ type TypeExtra struct {
 	IsStruct       bool      // 是否是结构体
 	IsInterface    bool      // 是否是接口
 	Fields         []*Symbol // 字段(如果是结构体)
-	Methods        []*Symbol // 方法
+	Methods        []*Symbol // 方法(如果是接口)
 }
 
 // ContainsLine 判断符号是否包含指定行
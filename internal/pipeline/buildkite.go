@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"go.yaml.in/yaml/v3"
+)
+
+// Buildkite 生成交给 buildkite-agent pipeline upload 的 JSON 流水线: Header 中的
+// steps 之后是每个受影响服务的步骤。Jobs 是步骤列表,不同服务生成的步骤 key 不能重复
+func (t Template) Buildkite(services []Service) ([]byte, error) {
+	doc, err := t.header("buildkite", yaml.SequenceNode)
+	if err != nil {
+		return nil, err
+	}
+	steps := &yaml.Node{Kind: yaml.SequenceNode}
+	if header := lookup(doc, "steps"); header != nil && header.Kind == yaml.SequenceNode {
+		steps.Content = append(steps.Content, header.Content...)
+	}
+	seen := make(map[string]bool)
+	for _, step := range steps.Content {
+		if key := stepKey(step); key != "" {
+			seen[key] = true
+		}
+	}
+	jobs, err := t.jobs(services, seen, func(_ int, step *yaml.Node) string { return stepKey(step) })
+	if err != nil {
+		return nil, err
+	}
+	steps.Content = append(steps.Content, jobs...)
+	if len(services) == 0 {
+		steps.Content = append(steps.Content, t.buildkiteEmpty()...)
+	}
+
+	// 用合并后的 steps 替换 Header 中的 steps
+	res := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "steps" {
+			res.Content = append(res.Content, doc.Content[i], doc.Content[i+1])
+		}
+	}
+	res.Content = append(res.Content, scalar("steps"), steps)
+	return encodeJSON(res)
+}
+
+// stepKey 返回 Buildkite 步骤的 key(旧写法为 id、identifier),没有时为空
+func stepKey(step *yaml.Node) string {
+	for _, name := range []string{"key", "id", "identifier"} {
+		if key := lookup(step, name); key != nil && key.Kind == yaml.ScalarNode {
+			return key.Value
+		}
+	}
+	return ""
+}
+
+// buildkiteEmpty 返回没有受影响的服务时的步骤: 配置的 Empty,或者一个只输出提示的步骤
+func (t Template) buildkiteEmpty() []*yaml.Node {
+	if steps := t.empty(yaml.SequenceNode); steps != nil {
+		return steps
+	}
+	return []*yaml.Node{{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			scalar("label"), scalar(noopJob),
+			scalar("key"), scalar(noopJob),
+			scalar("command"), scalar(noopCommand),
+		},
+	}}
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const buildkiteTemplate = `
+header:
+  env:
+    GOFLAGS: -mod=vendor
+  steps:
+    - label: lint
+      key: lint
+      command: make lint
+jobs:
+  - label: ":go: build {{.Name}}"
+    key: "build-{{.Name}}"
+    command: "go build ./{{.Dir}}"
+  - wait
+`
+
+func TestBuildkite(t *testing.T) {
+	data, err := parseTemplate(t, buildkiteTemplate).Buildkite([]Service{
+		{Name: "api", Dir: "cmd/api"},
+		{Name: "worker", Dir: "cmd/worker"},
+	})
+	if err != nil {
+		t.Fatalf("Buildkite failed: %v", err)
+	}
+
+	var pipeline struct {
+		Env   map[string]string `json:"env"`
+		Steps []any             `json:"steps"`
+	}
+	if err := json.Unmarshal(data, &pipeline); err != nil {
+		t.Fatalf("Expected JSON, got %v:\n%s", err, data)
+	}
+	if pipeline.Env["GOFLAGS"] != "-mod=vendor" {
+		t.Errorf("Expected the header env, got %v", pipeline.Env)
+	}
+	var keys []string
+	for _, step := range pipeline.Steps {
+		switch s := step.(type) {
+		case string:
+			keys = append(keys, s)
+		case map[string]any:
+			keys = append(keys, s["key"].(string))
+		}
+	}
+	want := []string{"lint", "build-api", "wait", "build-worker", "wait"}
+	if len(keys) != len(want) {
+		t.Fatalf("Steps = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("Steps = %v, want %v", keys, want)
+			break
+		}
+	}
+	if cmd := pipeline.Steps[1].(map[string]any)["command"]; cmd != "go build ./cmd/api" {
+		t.Errorf("Expected the rendered command, got %v", cmd)
+	}
+}
+
+func TestBuildkiteEmpty(t *testing.T) {
+	data, err := parseTemplate(t, buildkiteTemplate).Buildkite(nil)
+	if err != nil {
+		t.Fatalf("Buildkite failed: %v", err)
+	}
+	var pipeline struct {
+		Steps []map[string]string `json:"steps"`
+	}
+	if err := json.Unmarshal(data, &pipeline); err != nil {
+		t.Fatalf("Expected JSON, got %v:\n%s", err, data)
+	}
+	if len(pipeline.Steps) != 2 || pipeline.Steps[1]["key"] != noopJob {
+		t.Errorf("Expected a no-op step after the header steps, got:\n%s", data)
+	}
+}
+
+func TestBuildkiteErrors(t *testing.T) {
+	tests := []struct {
+		name, src string
+	}{
+		{"duplicate key", "jobs:\n  - key: build\n    command: make\n"},
+		{"key of a header step", "header:\n  steps:\n    - key: build-api\njobs:\n  - key: build-{{.Name}}\n"},
+		{"jobs not a list", "jobs:\n  build: {}\n"},
+	}
+	services := []Service{{Name: "api"}, {Name: "worker"}}
+	for _, tt := range tests {
+		if _, err := parseTemplate(t, tt.src).Buildkite(services); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
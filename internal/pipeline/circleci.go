@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"go.yaml.in/yaml/v3"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// defaultWorkflow CircleCI 中列出受影响服务作业的默认工作流名
+const defaultWorkflow = "ripples"
+
+// noopImage 默认作业使用的镜像
+const noopImage = "cimg/base:stable"
+
+// CircleCI 生成交给 continuation orb 的 CircleCI 后续配置 YAML: Header 中定义作业,
+// 如带参数的 build、deploy,Workflow 工作流中依次是每个受影响服务对作业的调用。
+// Jobs 是工作流中的作业列表,不同服务生成的作业名(name 参数或作业名)不能重复
+func (t Template) CircleCI(services []Service) ([]byte, error) {
+	doc, err := t.header("circleci", yaml.SequenceNode)
+	if err != nil {
+		return nil, err
+	}
+	workflow := t.Workflow
+	if workflow == "" {
+		workflow = defaultWorkflow
+	}
+	if lookup(lookup(doc, "workflows"), workflow) != nil {
+		return nil, i18n.Errorf("ci.circleci.header 中已有工作流 %s", workflow)
+	}
+
+	calls := &yaml.Node{Kind: yaml.SequenceNode}
+	jobs, err := t.jobs(services, make(map[string]bool), func(_ int, call *yaml.Node) string { return callName(call) })
+	if err != nil {
+		return nil, err
+	}
+	calls.Content = append(calls.Content, jobs...)
+	var noop bool
+	if len(services) == 0 {
+		if calls.Content = t.empty(yaml.SequenceNode); calls.Content == nil {
+			calls.Content, noop = []*yaml.Node{scalar(noopJob)}, true
+		}
+	}
+
+	res := &yaml.Node{Kind: yaml.MappingNode}
+	if lookup(doc, "version") == nil {
+		res.Content = append(res.Content, scalar("version"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: "2.1"})
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		switch key, value := doc.Content[i], doc.Content[i+1]; key.Value {
+		case "jobs", "workflows":
+			// 在下面与默认作业和工作流合并
+		default:
+			res.Content = append(res.Content, key, value)
+		}
+	}
+	defs := copyMapping(lookup(doc, "jobs"))
+	if noop && lookup(defs, noopJob) == nil {
+		defs.Content = append(defs.Content, scalar(noopJob), noopCircleCIJob())
+	}
+	if len(defs.Content) > 0 {
+		res.Content = append(res.Content, scalar("jobs"), defs)
+	}
+	workflows := copyMapping(lookup(doc, "workflows"))
+	workflows.Content = append(workflows.Content, scalar(workflow), &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{scalar("jobs"), calls},
+	})
+	res.Content = append(res.Content, scalar("workflows"), workflows)
+	return encodeYAML(res)
+}
+
+// callName 返回工作流中作业调用的名字: name 参数,没有时为调用的作业名
+func callName(call *yaml.Node) string {
+	switch call.Kind {
+	case yaml.ScalarNode:
+		return call.Value
+	case yaml.MappingNode:
+		if len(call.Content) != 2 {
+			return ""
+		}
+		if name := lookup(call.Content[1], "name"); name != nil && name.Kind == yaml.ScalarNode {
+			return name.Value
+		}
+		return call.Content[0].Value
+	}
+	return ""
+}
+
+// noopCircleCIJob 返回只输出提示的作业定义
+func noopCircleCIJob() *yaml.Node {
+	image := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("image"), scalar(noopImage)}}
+	run := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("run"), scalar(noopCommand)}}
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			scalar("docker"), {Kind: yaml.SequenceNode, Content: []*yaml.Node{image}},
+			scalar("steps"), {Kind: yaml.SequenceNode, Content: []*yaml.Node{run}},
+		},
+	}
+}
+
+// copyMapping 返回映射的浅拷贝,node 不是映射时返回空映射
+func copyMapping(node *yaml.Node) *yaml.Node {
+	res := &yaml.Node{Kind: yaml.MappingNode}
+	if node != nil && node.Kind == yaml.MappingNode {
+		res.Content = append(res.Content, node.Content...)
+	}
+	return res
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+const circleciTemplate = `
+header:
+  jobs:
+    build:
+      parameters:
+        dir: {type: string}
+      docker: [{image: cimg/go:1.24}]
+      steps: [checkout, {run: "go build ./<< parameters.dir >>"}]
+jobs:
+  - build:
+      name: "build-{{.Name}}"
+      dir: "{{.Dir}}"
+`
+
+func TestCircleCI(t *testing.T) {
+	data, err := parseTemplate(t, circleciTemplate).CircleCI([]Service{
+		{Name: "api", Dir: "cmd/api"},
+		{Name: "worker", Dir: "cmd/worker"},
+	})
+	if err != nil {
+		t.Fatalf("CircleCI failed: %v", err)
+	}
+
+	want := `version: 2.1
+jobs:
+  build:
+    parameters:
+      dir: {type: string}
+    docker: [{image: 'cimg/go:1.24'}]
+    steps: [checkout, {run: "go build ./<< parameters.dir >>"}]
+workflows:
+  ripples:
+    jobs:
+      - build:
+          name: "build-api"
+          dir: "cmd/api"
+      - build:
+          name: "build-worker"
+          dir: "cmd/worker"
+`
+	if string(data) != want {
+		t.Errorf("CircleCI =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestCircleCIEmpty(t *testing.T) {
+	data, err := parseTemplate(t, circleciTemplate+"workflow: affected\n").CircleCI(nil)
+	if err != nil {
+		t.Fatalf("CircleCI failed: %v", err)
+	}
+	for _, want := range []string{
+		"  build:\n",
+		"  " + noopJob + ":\n    docker:\n      - image: " + noopImage + "\n",
+		"workflows:\n  affected:\n    jobs:\n      - " + noopJob + "\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in:\n%s", want, data)
+		}
+	}
+}
+
+func TestCircleCIErrors(t *testing.T) {
+	tests := []struct {
+		name, src string
+	}{
+		{"duplicate name", "jobs:\n  - build:\n      name: build\n"},
+		{"duplicate job", "jobs:\n  - build\n"},
+		{"existing workflow", "header:\n  workflows:\n    ripples: {}\njobs:\n  - build-{{.Name}}\n"},
+		{"jobs not a list", "jobs:\n  build: {}\n"},
+	}
+	services := []Service{{Name: "api"}, {Name: "worker"}}
+	for _, tt := range tests {
+		if _, err := parseTemplate(t, tt.src).CircleCI(services); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
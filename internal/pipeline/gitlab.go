@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"go.yaml.in/yaml/v3"
+)
+
+// GitLab 生成 GitLab 动态子流水线 YAML: Header 之后是每个受影响服务的作业。
+// Jobs 是作业名到作业的映射,作业名也是模板,不同服务生成的作业名不能重复
+func (t Template) GitLab(services []Service) ([]byte, error) {
+	doc, err := t.header("gitlab", yaml.MappingNode)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < len(doc.Content); i += 2 {
+		seen[doc.Content[i].Value] = true
+	}
+	jobs, err := t.jobs(services, seen, func(i int, job *yaml.Node) string {
+		if i%2 == 0 {
+			return job.Value
+		}
+		return ""
+	})
+	if err != nil {
+		return nil, err
+	}
+	doc.Content = append(doc.Content, jobs...)
+	if len(services) == 0 {
+		doc.Content = append(doc.Content, t.gitlabEmpty()...)
+	}
+	return encodeYAML(doc)
+}
+
+// gitlabEmpty 返回没有受影响的服务时的作业: 配置的 Empty,或者一个只输出提示的作业,
+// 放在 Header 中 stages 的第一个阶段(未配置 stages 时使用 GitLab 的默认阶段)
+func (t Template) gitlabEmpty() []*yaml.Node {
+	if jobs := t.empty(yaml.MappingNode); jobs != nil {
+		return jobs
+	}
+	job := &yaml.Node{Kind: yaml.MappingNode}
+	if stages := lookup(t.Header, "stages"); stages != nil && stages.Kind == yaml.SequenceNode && len(stages.Content) > 0 {
+		job.Content = append(job.Content, scalar("stage"), scalar(stages.Content[0].Value))
+	}
+	job.Content = append(job.Content, scalar("script"), &yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: []*yaml.Node{scalar(noopCommand)},
+	})
+	return []*yaml.Node{scalar(noopJob), job}
+}
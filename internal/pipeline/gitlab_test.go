@@ -0,0 +1,117 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func parseTemplate(t *testing.T, src string) Template {
+	t.Helper()
+	var tmpl struct {
+		Header   yaml.Node `yaml:"header"`
+		Jobs     yaml.Node `yaml:"jobs"`
+		Empty    yaml.Node `yaml:"empty"`
+		Workflow string    `yaml:"workflow"`
+	}
+	if err := yaml.Unmarshal([]byte(src), &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	return Template{Header: &tmpl.Header, Jobs: &tmpl.Jobs, Empty: &tmpl.Empty, Workflow: tmpl.Workflow}
+}
+
+const gitlabTemplate = `
+header:
+  stages: [build, deploy]
+  variables:
+    GOFLAGS: -mod=vendor
+jobs:
+  "build-{{.Name}}":
+    stage: build
+    script:
+      - go build -o bin/{{.Name}} ./{{.Dir}}
+  "deploy-{{.Name}}":
+    stage: deploy
+    needs: ["build-{{.Name}}"]
+    script:
+      - helm upgrade {{.HelmRelease}} charts/{{.Name}}
+`
+
+func TestGitLab(t *testing.T) {
+	tmpl := parseTemplate(t, gitlabTemplate)
+	data, err := tmpl.GitLab([]Service{
+		{Name: "api", Dir: "cmd/api", HelmRelease: "api-prod"},
+		{Name: "worker", Dir: "cmd/worker"},
+	})
+	if err != nil {
+		t.Fatalf("GitLab failed: %v", err)
+	}
+
+	want := `stages: [build, deploy]
+variables:
+  GOFLAGS: -mod=vendor
+"build-api":
+  stage: build
+  script:
+    - go build -o bin/api ./cmd/api
+"deploy-api":
+  stage: deploy
+  needs: ["build-api"]
+  script:
+    - helm upgrade api-prod charts/api
+"build-worker":
+  stage: build
+  script:
+    - go build -o bin/worker ./cmd/worker
+"deploy-worker":
+  stage: deploy
+  needs: ["build-worker"]
+  script:
+    - helm upgrade  charts/worker
+`
+	if string(data) != want {
+		t.Errorf("GitLab =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestGitLabEmpty(t *testing.T) {
+	data, err := parseTemplate(t, gitlabTemplate).GitLab(nil)
+	if err != nil {
+		t.Fatalf("GitLab failed: %v", err)
+	}
+	if !strings.Contains(string(data), noopJob+":\n  stage: build\n  script:\n    - echo ripples found no affected services\n") {
+		t.Errorf("Expected a no-op job in the first stage, got:\n%s", data)
+	}
+
+	tmpl := parseTemplate(t, gitlabTemplate+`
+empty:
+  skip:
+    stage: .pre
+    script: ["true"]
+`)
+	if data, err = tmpl.GitLab(nil); err != nil {
+		t.Fatalf("GitLab failed: %v", err)
+	}
+	if !strings.Contains(string(data), "skip:\n  stage: .pre\n") || strings.Contains(string(data), noopJob) {
+		t.Errorf("Expected the configured empty job, got:\n%s", data)
+	}
+}
+
+func TestGitLabErrors(t *testing.T) {
+	tests := []struct {
+		name, src string
+	}{
+		{"no jobs", "header:\n  stages: [build]\n"},
+		{"duplicate job", "jobs:\n  build:\n    script: [make]\n"},
+		{"job named like a header key", "header:\n  build-api: {}\njobs:\n  build-{{.Name}}:\n    script: [make]\n"},
+		{"unknown field", "jobs:\n  build-{{.Name}}:\n    script:\n      - make {{.Service}}\n"},
+		{"jobs not a mapping", "jobs: [build]\n"},
+	}
+	services := []Service{{Name: "api"}, {Name: "worker"}}
+	for _, tt := range tests {
+		if _, err := parseTemplate(t, tt.src).GitLab(services); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package pipeline 按配置中的模板为受影响的服务生成 CI 动态流水线
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Service 流水线模板中可用的受影响服务信息,如 {{.Name}}、{{.Dir}}
+type Service struct {
+	Name        string   // 服务名
+	Package     string   // main 包导入路径
+	Dir         string   // main 包目录,相对仓库根目录
+	Confidence  string   // 可信度
+	Risk        int      // 风险分数
+	Image       string   // 部署映射中的镜像
+	HelmRelease string   // 部署映射中的 Helm release
+	Kubernetes  string   // 部署映射中的 Kubernetes 工作负载
+	BazelTarget string   // Bazel 目标
+	Owners      []string // 负责人
+}
+
+// Template 流水线模板,各 CI 平台对 Jobs 的写法见 GitLab、Buildkite、CircleCI
+type Template struct {
+	Header   *yaml.Node // 原样输出的顶层内容,如 stages、variables
+	Jobs     *yaml.Node // 每个服务的作业,其中的字符串是 Go 模板
+	Empty    *yaml.Node // 没有受影响的服务时的作业,为空时使用 noopJob
+	Workflow string     // CircleCI 中列出作业的工作流名,为空时为 defaultWorkflow
+}
+
+// noopJob 没有受影响的服务时默认生成的作业名: CI 平台不接受没有作业的流水线
+const noopJob = "ripples-no-affected-services"
+
+// noopCommand 默认作业执行的命令
+const noopCommand = "echo ripples found no affected services"
+
+// header 检查模板并返回 Header 的副本作为流水线的顶层映射。platform 是配置中
+// ci 下的键,jobs 是 Jobs 应有的节点类型
+func (t Template) header(platform string, jobs yaml.Kind) (*yaml.Node, error) {
+	if isEmpty(t.Jobs) {
+		return nil, i18n.Errorf("配置文件中缺少流水线作业模板 ci.%s.jobs", platform)
+	}
+	if !isEmpty(t.Header) && t.Header.Kind != yaml.MappingNode {
+		return nil, i18n.Errorf("ci.%s.header 必须是映射", platform)
+	}
+	if t.Jobs.Kind != jobs {
+		if jobs == yaml.MappingNode {
+			return nil, i18n.Errorf("ci.%s.jobs 必须是映射", platform)
+		}
+		return nil, i18n.Errorf("ci.%s.jobs 必须是列表", platform)
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	if !isEmpty(t.Header) {
+		doc.Content = append(doc.Content, t.Header.Content...)
+	}
+	return doc, nil
+}
+
+// jobs 按 Jobs 为每个服务生成作业,依次返回生成的映射键值或列表元素。
+// name 返回第 i 个节点中作业的名字(没有时为空),不同服务生成的作业名不能重复,也不能与 seen 中的重复
+func (t Template) jobs(services []Service, seen map[string]bool, name func(i int, job *yaml.Node) string) ([]*yaml.Node, error) {
+	var res []*yaml.Node
+	for _, svc := range services {
+		jobs, err := render(t.Jobs, svc)
+		if err != nil {
+			return nil, i18n.Errorf("渲染服务 %s 的作业失败: %w", svc.Name, err)
+		}
+		for i, job := range jobs.Content {
+			n := name(i, job)
+			if n == "" {
+				continue
+			}
+			if seen[n] {
+				return nil, i18n.Errorf("流水线中的 %s 重复,作业名应包含 {{.Name}} 等服务相关的内容", n)
+			}
+			seen[n] = true
+		}
+		res = append(res, jobs.Content...)
+	}
+	return res, nil
+}
+
+// empty 返回配置的 Empty 中的作业,未配置或类型不是 kind 时为 nil
+func (t Template) empty(kind yaml.Kind) []*yaml.Node {
+	if isEmpty(t.Empty) || t.Empty.Kind != kind {
+		return nil
+	}
+	return t.Empty.Content
+}
+
+// render 复制节点,把其中包含 "{{" 的标量作为 Go 模板用 svc 渲染
+func render(node *yaml.Node, svc Service) (*yaml.Node, error) {
+	res := *node
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "{{") {
+		tmpl, err := template.New("").Option("missingkey=error").Parse(node.Value)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, svc); err != nil {
+			return nil, err
+		}
+		res.Value = b.String()
+	}
+	res.Content = nil
+	for _, child := range node.Content {
+		c, err := render(child, svc)
+		if err != nil {
+			return nil, err
+		}
+		res.Content = append(res.Content, c)
+	}
+	return &res, nil
+}
+
+// encodeYAML 以两个空格缩进输出 YAML
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJSON 输出 JSON,映射的键按字母排序
+func encodeJSON(doc *yaml.Node) ([]byte, error) {
+	var v any
+	if err := doc.Decode(&v); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// lookup 返回映射中 key 对应的值,不存在时为 nil
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isEmpty 判断配置中的节点是否未设置
+func isEmpty(node *yaml.Node) bool {
+	return node == nil || node.Kind == 0 || node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// scalar 创建字符串标量节点
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,292 @@
+// Package store 在 SQLite 数据库中保存分析历史,供 ripples query 查询
+package store
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	// 注册 sqlite3 驱动
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Run 一次分析的记录
+type Run struct {
+	Time      time.Time `json:"time"`
+	Repo      string    `json:"repo"`                 // 仓库的绝对路径
+	OldCommit string    `json:"old_commit,omitempty"` // 解析后的旧 commit,按文件列表或符号分析时为空
+	NewCommit string    `json:"new_commit,omitempty"` // 解析后的新 commit
+	Tool      string    `json:"tool"`                 // ripples 版本
+	Duration  float64   `json:"duration_ms"`          // 总耗时
+	Phases    []Phase   `json:"phases,omitempty"`     // 各阶段耗时,按执行顺序
+	Affected  []Service `json:"affected"`
+}
+
+// Phase 一个分析阶段的耗时
+type Phase struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// Service 受影响的服务
+type Service struct {
+	Name       string `json:"name"`
+	Confidence string `json:"confidence"`
+	Risk       int    `json:"risk,omitempty"`
+}
+
+// ServiceSummary 一个服务在历史分析中受影响的情况
+type ServiceSummary struct {
+	Name       string    `json:"name"`
+	Runs       int       `json:"runs"`        // 受影响的分析次数
+	MaxRisk    int       `json:"max_risk"`    // 最高风险分数
+	LastCommit string    `json:"last_commit"` // 最近一次受影响的分析的新 commit
+	LastTime   time.Time `json:"last_time"`
+}
+
+// schema 数据库表结构,打开时创建。阶段和服务按写入顺序(rowid)读取
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	time        TEXT    NOT NULL,
+	repo        TEXT    NOT NULL,
+	old_commit  TEXT    NOT NULL DEFAULT '',
+	new_commit  TEXT    NOT NULL DEFAULT '',
+	tool        TEXT    NOT NULL DEFAULT '',
+	duration_ms REAL    NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS runs_repo_commit ON runs (repo, new_commit);
+CREATE TABLE IF NOT EXISTS phases (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	name        TEXT    NOT NULL,
+	duration_ms REAL    NOT NULL
+);
+CREATE TABLE IF NOT EXISTS services (
+	run_id     INTEGER NOT NULL REFERENCES runs (id),
+	name       TEXT    NOT NULL,
+	confidence TEXT    NOT NULL,
+	risk       INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// DB 保存分析历史的 SQLite 数据库
+type DB struct {
+	db *sql.DB
+}
+
+// Open 打开 path 处的数据库,文件不存在时创建。多个进程同时写入时等待锁而不是立即失败
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=10000&_txlock=immediate")
+	if err != nil {
+		return nil, i18n.Errorf("打开分析历史失败: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, i18n.Errorf("打开分析历史失败: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *DB) Close() error {
+	return s.db.Close()
+}
+
+// Append 在一个事务中写入一次分析
+func (s *DB) Append(run Run) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return i18n.Errorf("写入分析历史失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO runs (time, repo, old_commit, new_commit, tool, duration_ms) VALUES (?, ?, ?, ?, ?, ?)`,
+		run.Time.UTC().Format(time.RFC3339Nano), run.Repo, run.OldCommit, run.NewCommit, run.Tool, run.Duration)
+	if err != nil {
+		return i18n.Errorf("写入分析历史失败: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return i18n.Errorf("写入分析历史失败: %w", err)
+	}
+	for _, p := range run.Phases {
+		if _, err := tx.Exec(`INSERT INTO phases (run_id, name, duration_ms) VALUES (?, ?, ?)`, id, p.Name, p.Duration); err != nil {
+			return i18n.Errorf("写入分析历史失败: %w", err)
+		}
+	}
+	for _, svc := range run.Affected {
+		if _, err := tx.Exec(`INSERT INTO services (run_id, name, confidence, risk) VALUES (?, ?, ?, ?)`, id, svc.Name, svc.Confidence, svc.Risk); err != nil {
+			return i18n.Errorf("写入分析历史失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return i18n.Errorf("写入分析历史失败: %w", err)
+	}
+	return nil
+}
+
+// Runs 按写入顺序返回仓库 repo 的所有分析
+func (s *DB) Runs(repo string) ([]Run, error) {
+	return s.runs(`repo = ?`, repo)
+}
+
+// Find 返回仓库 repo 中新 commit 以 rev 开头的最近一次分析。rev 匹配多个不同的
+// commit 时返回错误,而不是猜测其中一个
+func (s *DB) Find(repo, rev string) (Run, error) {
+	if rev == "" {
+		return Run{}, i18n.Errorf("没有 commit %s 的分析记录", rev)
+	}
+	rows, err := s.db.Query(`SELECT DISTINCT new_commit FROM runs WHERE repo = ? AND substr(new_commit, 1, length(?)) = ?`, repo, rev, rev)
+	if err != nil {
+		return Run{}, i18n.Errorf("查询分析历史失败: %w", err)
+	}
+	var commits []string
+	for rows.Next() {
+		var commit string
+		if err := rows.Scan(&commit); err != nil {
+			rows.Close()
+			return Run{}, i18n.Errorf("查询分析历史失败: %w", err)
+		}
+		commits = append(commits, commit)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Run{}, i18n.Errorf("查询分析历史失败: %w", err)
+	}
+
+	switch len(commits) {
+	case 0:
+		return Run{}, i18n.Errorf("没有 commit %s 的分析记录", rev)
+	case 1:
+	default:
+		sort.Strings(commits)
+		return Run{}, i18n.Errorf("commit 前缀 %s 有歧义，匹配 %d 个 commit: %v", rev, len(commits), commits)
+	}
+
+	runs, err := s.runs(`id = (SELECT max(id) FROM runs WHERE repo = ? AND new_commit = ?)`, repo, commits[0])
+	if err != nil {
+		return Run{}, err
+	}
+	if len(runs) == 0 {
+		return Run{}, i18n.Errorf("没有 commit %s 的分析记录", rev)
+	}
+	return runs[0], nil
+}
+
+// runs 按写入顺序返回满足条件 where 的分析及其阶段和受影响的服务
+func (s *DB) runs(where string, args ...any) ([]Run, error) {
+	rows, err := s.db.Query(`SELECT id, time, repo, old_commit, new_commit, tool, duration_ms FROM runs WHERE `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, i18n.Errorf("查询分析历史失败: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	index := make(map[int64]int)
+	for rows.Next() {
+		var (
+			id  int64
+			at  string
+			run Run
+		)
+		if err := rows.Scan(&id, &at, &run.Repo, &run.OldCommit, &run.NewCommit, &run.Tool, &run.Duration); err != nil {
+			return nil, i18n.Errorf("查询分析历史失败: %w", err)
+		}
+		if run.Time, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, i18n.Errorf("查询分析历史失败: %w", err)
+		}
+		run.Affected = []Service{}
+		index[id] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, i18n.Errorf("查询分析历史失败: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	sub := `SELECT id FROM runs WHERE ` + where
+	err = s.each(`SELECT run_id, name, duration_ms FROM phases WHERE run_id IN (`+sub+`) ORDER BY rowid`, args, func(rows *sql.Rows) error {
+		var (
+			id int64
+			p  Phase
+		)
+		if err := rows.Scan(&id, &p.Name, &p.Duration); err != nil {
+			return err
+		}
+		run := &runs[index[id]]
+		run.Phases = append(run.Phases, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.each(`SELECT run_id, name, confidence, risk FROM services WHERE run_id IN (`+sub+`) ORDER BY rowid`, args, func(rows *sql.Rows) error {
+		var (
+			id  int64
+			svc Service
+		)
+		if err := rows.Scan(&id, &svc.Name, &svc.Confidence, &svc.Risk); err != nil {
+			return err
+		}
+		run := &runs[index[id]]
+		run.Affected = append(run.Affected, svc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// each 对查询 query 的每一行调用 scan
+func (s *DB) each(query string, args []any, scan func(*sql.Rows) error) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return i18n.Errorf("查询分析历史失败: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return i18n.Errorf("查询分析历史失败: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return i18n.Errorf("查询分析历史失败: %w", err)
+	}
+	return nil
+}
+
+// Summarize 按服务汇总历史分析,受影响次数多的在前,次数相同时按服务名排序
+func Summarize(runs []Run) []ServiceSummary {
+	byName := make(map[string]*ServiceSummary)
+	for _, run := range runs {
+		for _, s := range run.Affected {
+			sum, ok := byName[s.Name]
+			if !ok {
+				sum = &ServiceSummary{Name: s.Name}
+				byName[s.Name] = sum
+			}
+			sum.Runs++
+			sum.MaxRisk = max(sum.MaxRisk, s.Risk)
+			if !run.Time.Before(sum.LastTime) {
+				sum.LastCommit, sum.LastTime = run.NewCommit, run.Time
+			}
+		}
+	}
+
+	res := make([]ServiceSummary, 0, len(byName))
+	for _, sum := range byName {
+		res = append(res, *sum)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Runs != res[j].Runs {
+			return res[i].Runs > res[j].Runs
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if runs, err := db.Runs("/src/app"); err != nil || runs != nil {
+		t.Fatalf("Runs of an empty store = %v, %v", runs, err)
+	}
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{
+			Time:      base,
+			Repo:      "/src/app",
+			OldCommit: "aaa111",
+			NewCommit: "bbb222",
+			Tool:      "v1.2.0",
+			Duration:  1500,
+			Phases:    []Phase{{Name: "load", Duration: 300}, {Name: "trace", Duration: 1200}},
+			Affected:  []Service{{Name: "api", Confidence: "high", Risk: 40}},
+		},
+		{
+			Time:      base.Add(time.Hour),
+			Repo:      "/src/app",
+			OldCommit: "bbb222",
+			NewCommit: "ccc333",
+			Tool:      "v1.2.0",
+			Duration:  900,
+			Affected:  []Service{{Name: "api", Confidence: "medium", Risk: 70}, {Name: "worker", Confidence: "high", Risk: 20}},
+		},
+		{
+			Time:      base.Add(2 * time.Hour),
+			Repo:      "/src/app",
+			OldCommit: "ccc333",
+			NewCommit: "ccc444",
+			Tool:      "v1.2.0",
+			Duration:  100,
+			Affected:  []Service{},
+		},
+		// 共用同一个数据库的另一个仓库
+		{
+			Time:      base.Add(3 * time.Hour),
+			Repo:      "/src/other",
+			NewCommit: "bbb222",
+			Tool:      "v1.2.0",
+			Affected:  []Service{{Name: "billing", Confidence: "high"}},
+		},
+	}
+	for _, run := range runs {
+		if err := db.Append(run); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后仍能读取已写入的分析
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	got, err := db.Runs("/src/app")
+	if err != nil {
+		t.Fatalf("Runs failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, runs[:3]) {
+		t.Errorf("Runs = %+v, want %+v", got, runs[:3])
+	}
+
+	if run, err := db.Find("/src/app", "bbb"); err != nil || run.OldCommit != "aaa111" {
+		t.Errorf("Find(bbb) = %+v, %v", run, err)
+	}
+	if run, err := db.Find("/src/other", "bbb222"); err != nil || len(run.Affected) != 1 || run.Affected[0].Name != "billing" {
+		t.Errorf("Find(bbb222) in the other repository = %+v, %v", run, err)
+	}
+	if _, err := db.Find("/src/app", "ccc"); err == nil || !strings.Contains(err.Error(), "ccc333") {
+		t.Errorf("Expected an ambiguous prefix error, got %v", err)
+	}
+	if _, err := db.Find("/src/app", "ddd"); err == nil {
+		t.Error("Expected no run for an unknown commit")
+	}
+
+	want := []ServiceSummary{
+		{Name: "api", Runs: 2, MaxRisk: 70, LastCommit: "ccc333", LastTime: base.Add(time.Hour)},
+		{Name: "worker", Runs: 1, MaxRisk: 20, LastCommit: "ccc333", LastTime: base.Add(time.Hour)},
+	}
+	if sum := Summarize(got); !reflect.DeepEqual(sum, want) {
+		t.Errorf("Summarize = %+v, want %+v", sum, want)
+	}
+}
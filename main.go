@@ -2,16 +2,33 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
+	"unicode"
 
 	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/github"
+	"github.com/jimyag/ripples/internal/gitlab"
+	"github.com/jimyag/ripples/internal/hook"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/metrics"
 	"github.com/jimyag/ripples/internal/output"
-	"github.com/jimyag/ripples/internal/parser"
+	"github.com/jimyag/ripples/internal/pipeline"
+	"github.com/jimyag/ripples/internal/store"
+	"github.com/jimyag/ripples/pkg/ripples"
 )
 
 var (
@@ -20,185 +37,1264 @@ var (
 	newCommit  string
 	outputType string
 	verbose    bool
+
+	allPaths          bool
+	maxPathsPerBinary int
+	maxFanOut         int
+	minRisk           int
+	coverProfile      string
+	coverDir          string
+
+	quiet     bool
+	logLevel  string
+	logFormat string
+	lang      string
+
+	gitlabNote            bool
+	gitlabURL             string
+	gitlabToken           string
+	gitlabProject         string
+	gitlabMR              string
+	gitlabReviewLabel     string
+	gitlabReviewThreshold int
+
+	githubActions bool
+	stream        bool
+
+	configPath string
+	timeout    time.Duration
+	budget     time.Duration
+
+	groupBy       string
+	codeOwners    bool
+	teamReportDir string
+
+	services           stringList
+	targets            stringList
+	commonPackages     stringList
+	exclude            stringList
+	only               stringList
+	includeGenerated   bool
+	crossServiceFilter bool
+	interfaceFilter    string
+	mode               string
+	strategy           string
+	granularity        string
+	routes             bool
+	commands           bool
+	failOnUnknown      bool
+	buildFlags         string
+	env                stringList
+
+	pushgatewayURL string
+	pushgatewayJob string
+	timings        string
+	explain        bool
+	blame          bool
+	snippets       bool
+	timingsFile    string
+	manifestPath   string
+	storePath      string
+	force          bool
+
+	symbols     stringList
+	filesPath   string
+	symbolsPath string
+	stdinDiff   bool
+	graphFormat string
+
+	since      string
+	skipTypes  string
+	statsCache string
+	indexPath  string
 )
 
 func init() {
 	flag.StringVar(&repoPath, "repo", ".", "Git 仓库路径")
 	flag.StringVar(&oldCommit, "old", "", "旧 commit ID (必填)")
 	flag.StringVar(&newCommit, "new", "", "新 commit ID (必填)")
-	flag.StringVar(&outputType, "output", "simple", "输出格式: simple, text, json, summary")
+	flag.StringVar(&outputType, "output", "simple", "输出格式: simple, text, json, ndjson, summary, markdown, rdjson, bazel, cypher, workloads, workloads-json")
 	flag.BoolVar(&verbose, "verbose", false, "详细输出")
+	flag.BoolVar(&allPaths, "all-paths", false, "报告每个服务的所有调用链（默认只报告第一条）")
+	flag.IntVar(&maxPathsPerBinary, "max-paths-per-binary", 0, "每个服务最多报告的调用链数量（隐含 -all-paths）")
+	flag.BoolVar(&quiet, "quiet", false, "只输出错误日志")
+	flag.StringVar(&logLevel, "log-level", "", "日志级别: debug, info, warn, error (默认 warn，-verbose 时为 info)")
+	flag.StringVar(&logFormat, "log-format", "text", "日志格式: text, json")
+	flag.StringVar(&lang, "lang", "", "输出语言: zh, en (默认根据 LANG 检测)")
+	flag.BoolVar(&gitlabNote, "gitlab-note", false, "将报告发布为 GitLab 合并请求评论")
+	flag.StringVar(&gitlabURL, "gitlab-url", os.Getenv("CI_SERVER_URL"), "GitLab 地址 (默认 $CI_SERVER_URL)")
+	// 令牌不作为参数默认值,否则参数错误时 usage 会把它打印到 CI 日志中
+	flag.StringVar(&gitlabToken, "gitlab-token", "", "GitLab 访问令牌 (默认 $GITLAB_TOKEN)")
+	flag.StringVar(&gitlabProject, "gitlab-project", os.Getenv("CI_PROJECT_ID"), "GitLab 项目 ID (默认 $CI_PROJECT_ID)")
+	flag.StringVar(&gitlabMR, "gitlab-mr", os.Getenv("CI_MERGE_REQUEST_IID"), "GitLab 合并请求 IID (默认 $CI_MERGE_REQUEST_IID)")
+	flag.StringVar(&gitlabReviewLabel, "gitlab-review-label", "needs-extra-review", "受影响服务过多时添加的 MR 标签")
+	flag.IntVar(&gitlabReviewThreshold, "gitlab-review-threshold", 0, "受影响服务数超过该值时添加评审标签，否则移除 (0 表示不修改标签)")
+	flag.BoolVar(&githubActions, "github-actions", false, "写入 GitHub Actions job summary 并输出 affected-services")
+	flag.StringVar(&pushgatewayURL, "pushgateway-url", "", "Prometheus Pushgateway 地址，设置后推送分析指标")
+	flag.StringVar(&pushgatewayJob, "pushgateway-job", "ripples", "推送指标使用的 Pushgateway job 名称")
+	flag.StringVar(&timings, "timings", "", "输出各阶段耗时、gopls 请求等性能数据的格式: json (与分析报告分开输出)")
+	flag.StringVar(&timingsFile, "timings-file", "", "性能数据的输出文件 (默认输出到 stderr)")
+	flag.StringVar(&manifestPath, "manifest", "", "写入运行清单的文件，如 ripples.lock (记录解析后的 commit、工具版本、配置哈希、分析后端和结果哈希)")
+	flag.StringVar(&storePath, "store", "", "记录分析历史的 SQLite 数据库，如 results.db (记录 commit、受影响的服务和耗时)，query 子命令从中读取")
+	flag.BoolVar(&force, "force", false, "init 覆盖已存在的配置文件，hook install 覆盖已存在的、不是 ripples 安装的 git 钩子")
+	flag.BoolVar(&stream, "stream", false, "发现受影响服务时立即以 NDJSON 逐行输出（忽略 -output）")
+	flag.StringVar(&configPath, "config", "", "配置文件路径 (默认读取仓库根目录下的 ripples.yaml)")
+	flag.IntVar(&maxFanOut, "max-fanout", 0, "调用者扇出上限，超过时按导入包的服务近似报告 (0 表示不限制)")
+	flag.IntVar(&minRisk, "min-risk", 0, "只报告风险分数不低于该值的服务 (0-100)")
+	flag.StringVar(&coverProfile, "coverprofile", "", "go test -coverprofile 生成的覆盖率文件，用于标注变更代码的测试覆盖率")
+	flag.BoolVar(&explain, "explain", false, "说明每个服务受影响的原因: 调用链上每一步调用的文件和行号，以及变更符号所在的 diff hunk")
+	flag.BoolVar(&snippets, "snippets", false, "在报告中附上每个变更符号的变更行及其前后几行代码 (json/markdown)")
+	flag.BoolVar(&blame, "blame", false, "用 git blame 标注最后修改每个变更符号的提交 (json/markdown)")
+	flag.StringVar(&coverDir, "coverdir", "", "每个测试一个覆盖率文件的目录，用于列出执行过变更行的测试")
+	flag.DurationVar(&timeout, "timeout", 0, "分析超时时间，如 5m (0 表示不限制)")
+	flag.DurationVar(&budget, "budget", 0, "时间预算，如 2m，耗尽时返回已找到的结果并列出未分析的符号 (0 表示不限制)")
+	flag.Var(&targets, "targets", "只分析的服务，如 cmd/api,cmd/worker 或服务名 (逗号分隔或重复，覆盖配置文件)")
+	flag.Var(&services, "service", "服务边界模式，如 cmd/* 或 internal/* (可重复，覆盖配置文件)")
+	flag.Var(&commonPackages, "common-package", "公共包前缀，如 foundation/ (可重复，覆盖配置文件)")
+	flag.Var(&exclude, "exclude", "排除的文件路径模式，如 gen/** 或 **/*_mock.go (可重复，覆盖配置文件)")
+	flag.Var(&only, "only", "只分析匹配的文件路径模式，如 internal/billing/** (可重复，覆盖配置文件)")
+	flag.BoolVar(&includeGenerated, "include-generated", false, "分析带有 \"Code generated ... DO NOT EDIT.\" 头的生成文件（默认跳过）")
+	flag.BoolVar(&crossServiceFilter, "cross-service-filter", true, "根据服务边界和公共包过滤跨服务调用链")
+	flag.StringVar(&mode, "mode", "calls", "分析模式: calls (追踪调用层级) 或 imports (按导入图快速近似，不启动 gopls)")
+	flag.StringVar(&strategy, "strategy", "reverse", "追踪方向: reverse (从变更符号向上)、forward (从 main 函数向下) 或 auto (按扇出自动选择)")
+	flag.StringVar(&granularity, "granularity", "binary", "报告粒度: binary (只报告服务) 或 package (同时报告调用链经过的包)")
+	flag.StringVar(&interfaceFilter, "interface-filter", "strict", "跨服务过滤模式: strict (无法类型检查的调用视为接口调用)、loose (只过滤确定的接口调用) 或 off")
+	flag.BoolVar(&routes, "routes", false, "报告每个服务受影响的 HTTP 路由和 gRPC 方法")
+	flag.BoolVar(&commands, "commands", false, "报告每个服务受影响的 cobra/urfave-cli 子命令")
+	flag.BoolVar(&failOnUnknown, "fail-on-unknown", false, "有包加载失败、变更包不在稀疏检出范围内或变更符号追踪失败(影响未知)时以非零状态退出")
+	flag.StringVar(&buildFlags, "buildflags", "", "加载包时使用的构建参数，如 \"-mod=vendor -tags=integration\" (覆盖配置文件)")
+	flag.Var(&env, "env", "分析期间设置的环境变量，如 GOFLAGS=-mod=vendor (可重复，覆盖配置文件)")
+	flag.StringVar(&groupBy, "group-by", "", "报告分组方式: owner, team")
+	flag.StringVar(&teamReportDir, "team-report-dir", "", "按团队拆分报告，每个团队一个文件写入该目录 (格式由 -output 决定)")
+	flag.BoolVar(&codeOwners, "codeowners", true, "根据 CODEOWNERS 标注服务负责人")
+	flag.StringVar(&graphFormat, "format", "dot", "graph 子命令的输出格式: dot, json")
+	flag.StringVar(&since, "since", "90d", "stats 子命令统计的时间范围，如 90d、12w 或 2024-01-01")
+	flag.StringVar(&skipTypes, "skip-commit-types", "", "stats 子命令跳过的 Conventional Commits 提交类型，如 docs,chore,test")
+	flag.StringVar(&statsCache, "stats-cache", defaultStatsCache(), "stats 子命令缓存每个提交分析报告的目录 (为空时不缓存)")
+	flag.StringVar(&indexPath, "index", "", "符号索引文件 (默认为仓库根目录下的 .ripples-index，存在时自动使用)")
+	flag.StringVar(&filesPath, "files", "", "变更文件列表 (每行一个路径)，设置后不读取 git diff")
+	flag.StringVar(&symbolsPath, "symbols", "", "JSON 格式的变更符号列表，如 [\"internal/foo.Bar\"]，设置后不读取 git diff")
+	flag.BoolVar(&stdinDiff, "stdin", false, "从标准输入读取 diff (如 git diff A B 的输出)，不调用 git diff")
+	flag.Var(&symbols, "symbol", "trace 子命令追踪的符号，如 internal/foo.Bar 或 internal/foo/bar.go:42 (可重复)")
+	flag.Usage = usage
+}
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// readInput 用 read 读取文件 path,path 为空时返回 nil
+func readInput(path string, read func(io.Reader) ([]string, error)) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+// usage 按当前语言打印参数说明
+func usage() {
+	if lang != "" {
+		_ = i18n.SetLang(lang)
+	}
+	out := flag.CommandLine.Output()
+	fmt.Fprint(out, i18n.Sprintf("用法: %s [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s trace -symbol <符号> [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s binaries [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s graph [-format dot|json] [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s diff-report [参数] <旧报告.json> <新报告.json>\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s stats [-since 90d] [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s query runs|services|diff -store <文件> [参数] [<旧 commit> <新 commit>]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s ci gitlab|buildkite|circleci [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s index [-index 文件] [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s hook install pre-push [-force] [参数]\n", os.Args[0]))
+	fmt.Fprint(out, i18n.Sprintf("      %s init [-force] [参数]\n", os.Args[0]))
+	flag.VisitAll(func(f *flag.Flag) {
+		name, _ := flag.UnquoteUsage(f)
+		if name != "" {
+			fmt.Fprintf(out, "  -%s %s\n", f.Name, name)
+		} else {
+			fmt.Fprintf(out, "  -%s\n", f.Name)
+		}
+		fmt.Fprintf(out, "    \t%s", i18n.T(f.Usage))
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			fmt.Fprintf(out, " (default %q)", f.DefValue)
+		}
+		fmt.Fprintln(out)
+	})
 }
 
 func main() {
 	flag.Parse()
 
+	// 子命令前后都可以出现参数: ripples -repo . trace -symbol pkg.Func
+	var command, platform, query string
+	var hookArgs []string
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+		// ci 子命令后是 CI 平台: ripples ci gitlab -old main -new HEAD
+		if command == "ci" && flag.NArg() > 0 {
+			platform = flag.Arg(0)
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+		// query 子命令后是查询的内容: ripples query runs -store results.jsonl
+		if command == "query" && flag.NArg() > 0 {
+			query = flag.Arg(0)
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+		// hook 子命令后是操作和钩子名: ripples hook install pre-push
+		for command == "hook" && len(hookArgs) < 2 && flag.NArg() > 0 {
+			hookArgs = append(hookArgs, flag.Arg(0))
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+	}
+
+	// init 生成配置文件,不读取已有的配置文件
+	cfg := &config.Config{}
+	if command != "init" {
+		var err error
+		if cfg, err = config.Find(configPath, repoPath); err != nil {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+			os.Exit(1)
+		}
+	}
+	applyConfigDefaults(cfg)
+
+	if lang != "" {
+		if err := i18n.SetLang(lang); err != nil {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+			os.Exit(1)
+		}
+	}
+
+	// 日志输出到 stderr，stdout 只保留分析结果
+	level := logLevel
+	if level == "" && verbose {
+		level = "info"
+	}
+	if err := logger.Setup(logger.Config{Level: level, Format: logFormat, Quiet: quiet}); err != nil {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+		os.Exit(1)
+	}
+
 	// 验证必填参数
-	if oldCommit == "" || newCommit == "" {
-		fmt.Println("错误: 必须指定 -old 和 -new 参数")
+	switch command {
+	case "", "ci", "trace":
+		if !slices.Contains(reportOutputs, outputType) {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("错误: 不支持的输出格式 %q，可选 %s\n", outputType, strings.Join(reportOutputs, "/")))
+			os.Exit(1)
+		}
+	}
+	switch command {
+	case "", "ci":
+		if command == "ci" && !slices.Contains(ciPlatforms, platform) {
+			fmt.Fprint(os.Stderr, i18n.Sprintf("错误: 不支持的 CI 平台 %q，可选 %s\n", platform, strings.Join(ciPlatforms, "/")))
+			flag.Usage()
+			os.Exit(1)
+		}
+		if len(symbols) > 0 {
+			fmt.Fprintln(os.Stderr, i18n.T("错误: -symbol 只能用于 trace 子命令"))
+			os.Exit(1)
+		}
+		if filesPath == "" && symbolsPath == "" && !stdinDiff && (oldCommit == "" || newCommit == "") {
+			fmt.Fprintln(os.Stderr, i18n.T("错误: 必须指定 -old 和 -new 参数"))
+			flag.Usage()
+			os.Exit(1)
+		}
+		logger.Info("开始分析项目", "repo", repoPath, "old", oldCommit, "new", newCommit)
+
+	case "trace":
+		if len(symbols) == 0 {
+			fmt.Fprintln(os.Stderr, i18n.T("错误: trace 子命令必须指定 -symbol 参数"))
+			flag.Usage()
+			os.Exit(1)
+		}
+		logger.Info("开始追踪符号", "repo", repoPath, "symbols", symbols.String())
+
+	case "binaries":
+		listBinaries()
+		return
+
+	case "init":
+		initConfig()
+		return
+
+	case "graph":
+		exportGraph()
+		return
+
+	case "diff-report":
+		if flag.NArg() != 2 {
+			fmt.Fprintln(os.Stderr, i18n.T("错误: diff-report 需要两个报告文件"))
+			flag.Usage()
+			os.Exit(1)
+		}
+		diffReports(flag.Arg(0), flag.Arg(1))
+		return
+
+	case "stats":
+		logger.Info("开始统计历史提交", "repo", repoPath, "since", since)
+
+	case "query":
+		queryStore(query, flag.Args())
+		return
+
+	case "hook":
+		if len(hookArgs) != 2 || (hookArgs[0] != "install" && hookArgs[0] != "run") {
+			fmt.Fprintln(os.Stderr, i18n.T("错误: hook 子命令的用法为 hook install|run <钩子名>"))
+			flag.Usage()
+			os.Exit(1)
+		}
+		if hookArgs[0] == "install" {
+			installHook(hookArgs[1])
+			return
+		}
+
+	case "index":
+		logger.Info("开始生成符号索引", "repo", repoPath)
+
+	default:
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: 未知子命令 %s\n", command))
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// 打印开始信息
-	if verbose {
-		fmt.Printf("开始分析项目: %s\n", repoPath)
-		fmt.Printf("比较: %s -> %s\n", oldCommit, newCommit)
-		fmt.Println()
+	filterMode, err := ripples.ParseInterfaceFilter(interfaceFilter)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+		os.Exit(1)
 	}
 
-	startTime := time.Now()
+	analysisMode, err := ripples.ParseMode(mode)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+		os.Exit(1)
+	}
 
-	// 1. 获取变更文件列表（用于优化 Parser 加载）
-	if verbose {
-		fmt.Println("⏱️  步骤 1/6: 检测变更文件...")
+	tracingStrategy, err := ripples.ParseStrategy(strategy)
+	if err != nil {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
+		os.Exit(1)
 	}
-	detectFilesStart := time.Now()
-	diffContent, err := analyzer.GetGitDiffContent(repoPath, oldCommit, newCommit)
+
+	reportGranularity, err := ripples.ParseGranularity(granularity)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "获取 git diff 失败: %v\n", err)
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: %v\n", err))
 		os.Exit(1)
 	}
-	changedFiles := analyzer.ExtractChangedGoFiles(diffContent)
-	if verbose {
-		fmt.Printf("   ✅ 检测到 %d 个变更文件 (耗时: %v)\n", len(changedFiles), time.Since(detectFilesStart))
+
+	// ndjson 即流式输出: 每发现一个受影响的服务立即输出一行 JSON
+	if outputType == "ndjson" {
+		stream = true
 	}
 
-	// 2. 初始化 Parser（只加载变更文件相关的包）
-	if verbose {
-		fmt.Println("\n⏱️  步骤 2/6: 初始化 Parser (只加载变更包)...")
+	if timings != "" && timings != "json" {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("错误: 不支持的性能数据格式 %q，可选 json\n", timings))
+		os.Exit(1)
+	}
+
+	startTime := time.Now()
+	ctx := context.Background()
+
+	opts := ripples.Options{
+		RepoPath:            repoPath,
+		OldCommit:           oldCommit,
+		NewCommit:           newCommit,
+		AllPaths:            allPaths,
+		MaxPathsPerBinary:   maxPathsPerBinary,
+		Exclude:             cfg.Exclude,
+		Only:                cfg.Only,
+		IncludeGenerated:    includeGenerated,
+		Entrypoints:         cfg.Entrypoints,
+		Targets:             cfg.Targets,
+		BinariesOnly:        binaryListOutputs[outputType] && command != "ci" && !gitlabNote && !githubActions,
+		EntrypointFunctions: cfg.EntrypointFunctions,
+		Jobs:                cfg.Jobs,
+		Routes:              routes,
+		Commands:            commands,
+		Services:            cfg.Services,
+		CommonPackages:      cfg.CommonPackages,
+		Timeout:             timeout,
+		Budget:              budget,
+		MaxFanOut:           maxFanOut,
+		MinRisk:             minRisk,
+		CoverProfile:        coverProfile,
+		CoverDir:            coverDir,
+		Explain:             explain,
+		Blame:               blame,
+		Snippets:            snippets,
+
+		DisableCrossServiceFilter: !crossServiceFilter,
+		InterfaceFilter:           filterMode,
+		Mode:                      analysisMode,
+		Strategy:                  tracingStrategy,
+		Granularity:               reportGranularity,
+		Deployments:               deployments(cfg),
+		Bazel:                     outputType == "bazel" || command == "ci" || len(cfg.BazelTargets) > 0,
+		BazelTargets:              cfg.BazelTargets,
+		Owners:                    cfg.Owners,
+		CodeOwners:                codeOwners,
+		Teams:                     cfg.Teams,
+		BuildFlags:                cfg.BuildFlags,
+		Env:                       cfg.Env,
 	}
-	parseStart := time.Now()
-	p := parser.NewParser()
-	if err := p.LoadChangedFiles(repoPath, changedFiles); err != nil {
-		// 如果加载失败，回退到加载整个项目
-		if verbose {
-			fmt.Printf("   ⚠️  加载变更包失败，回退到加载整个项目: %v\n", err)
+	if command == "trace" {
+		opts.Symbols = symbols
+	}
+	if command == "" || command == "ci" {
+		if opts.Files, err = readInput(filesPath, ripples.ReadFileList); err != nil {
+			fatal("分析失败", err)
 		}
-		if err := p.LoadProject(repoPath); err != nil {
-			fmt.Fprintf(os.Stderr, "加载项目失败: %v\n", err)
-			os.Exit(1)
+		if opts.Symbols, err = readInput(symbolsPath, ripples.ReadSymbolList); err != nil {
+			fatal("分析失败", err)
+		}
+		if stdinDiff {
+			if opts.Diff, err = io.ReadAll(os.Stdin); err != nil {
+				fatal("读取标准输入失败", err)
+			}
 		}
 	}
-	if verbose {
-		fmt.Printf("   ✅ Parser 初始化完成 (耗时: %v)\n", time.Since(parseStart))
+	if len(targets) > 0 {
+		opts.Targets = nil
+		for _, t := range targets {
+			opts.Targets = append(opts.Targets, strings.Split(t, ",")...)
+		}
 	}
-
-	// 获取当前模块名
-	currentModule := getModulePath(repoPath)
-	if currentModule == "" {
-		// Fallback to package info
-		pkgs := p.GetPackages()
-		if len(pkgs) > 0 && pkgs[0].Module != nil {
-			currentModule = pkgs[0].Module.Path
+	if len(services) > 0 {
+		opts.Services = services
+	}
+	if len(commonPackages) > 0 {
+		opts.CommonPackages = commonPackages
+	}
+	if len(exclude) > 0 {
+		opts.Exclude = exclude
+	}
+	if len(only) > 0 {
+		opts.Only = only
+	}
+	if buildFlags != "" {
+		opts.BuildFlags = strings.Fields(buildFlags)
+	}
+	if len(env) > 0 {
+		opts.Env = env
+	}
+	if stream {
+		// 分析期间 os.Stdout 被重定向，直接写入原始 stdout
+		enc := json.NewEncoder(os.Stdout)
+		opts.OnAffected = func(b ripples.AffectedBinary) {
+			if err := enc.Encode(b); err != nil {
+				logger.Error("输出JSON失败", "error", err)
+			}
 		}
 	}
 
-	if verbose {
-		fmt.Printf("当前模块: %s\n", currentModule)
+	if command == "stats" {
+		collectStats(ctx, opts)
+		return
+	}
+	if command == "index" {
+		writeIndex(ctx, opts)
+		return
+	}
+	if command == "hook" {
+		runHook(ctx, opts, hookArgs[1], cfg.Hook)
+		return
+	}
+	opts.Index = indexPath
+	if opts.Index == "" {
+		if _, err := os.Stat(filepath.Join(repoPath, ripples.IndexFile)); err == nil {
+			opts.Index = filepath.Join(repoPath, ripples.IndexFile)
+		}
 	}
 
-	// 3. 初始化 LSP Impact Analyzer
-	if verbose {
-		fmt.Println("\n⏱️  步骤 3/6: 初始化 LSP 分析器 (gopls)...")
+	a, err := ripples.New(opts)
+	if err != nil {
+		fatal("分析失败", err)
 	}
-	lspStart := time.Now()
-	ctx := context.Background()
-	lspAnalyzer, err := analyzer.NewLSPImpactAnalyzer(ctx, repoPath)
+
+	res, err := a.Analyze(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "初始化 LSP 分析器失败: %v\n", err)
-		os.Exit(1)
+		fatal("分析失败", err)
 	}
-	defer lspAnalyzer.Close()
+	report := &res.Report
 
-	if verbose {
-		fmt.Printf("   ✅ LSP 分析器初始化完成 (耗时: %v)\n", time.Since(lspStart))
+	// 6. 输出结果
+	logger.Info("步骤 6/6: 输出结果")
+	reporter := output.NewReporter(report)
+	if err := reporter.SetGroupBy(groupBy); err != nil {
+		fatal("输出结果失败", err)
 	}
 
-	// 4. 检测变更符号
-	if verbose {
-		fmt.Println("\n⏱️  步骤 4/6: 检测变更符号...")
+	format := outputType
+	if explain && format == "simple" {
+		// 只有服务名的输出无法展示证据
+		format = "text"
 	}
-	detectStart := time.Now()
-	cd := analyzer.NewChangeDetector(p, repoPath)
-	changes, err := cd.DetectChanges(oldCommit, newCommit)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "检测变更失败: %v\n", err)
-		os.Exit(1)
+	if stream {
+		format = "stream"
+	}
+	if command == "ci" {
+		format = "pipeline"
+	}
+
+	switch format {
+	case "stream":
+		// 结果已在分析过程中逐行输出
+
+	case "pipeline":
+		printPipeline(cfg.CI, platform, report)
+
+	default:
+		printReport(os.Stdout, reporter, format)
 	}
 
-	if verbose {
-		fmt.Printf("   ✅ 检测到 %d 个变更符号 (耗时: %v)\n", len(changes), time.Since(detectStart))
+	if teamReportDir != "" {
+		writeTeamReports(report, format)
 	}
 
-	// 5. 分析影响
-	if verbose {
-		fmt.Println("\n⏱️  步骤 5/6: 追踪调用链到 main 函数...")
+	if timings != "" {
+		writeTimings(res.Timings(time.Since(startTime)))
 	}
-	analyzeStart := time.Now()
-	results, err := lspAnalyzer.Analyze(changes)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "分析失败: %v\n", err)
-		os.Exit(1)
+
+	if manifestPath != "" {
+		writeManifest(a, res, cfg.Hash)
 	}
 
-	if verbose {
-		fmt.Printf("   ✅ 调用链追踪完成 (耗时: %v)\n", time.Since(analyzeStart))
-		fmt.Printf("   📊 发现 %d 个受影响的服务\n", len(results))
+	if storePath != "" {
+		recordRun(a, res, cfg.Hash, time.Since(startTime))
 	}
 
-	// 6. 输出结果
-	if verbose {
-		fmt.Println("\n⏱️  步骤 6/6: 输出结果...")
+	if pushgatewayURL != "" {
+		pushMetrics(ctx, res, time.Since(startTime))
 	}
-	reporter := output.NewReporter(results)
 
-	switch outputType {
+	if githubActions {
+		publishGitHubActions(reporter, report)
+	}
+
+	if gitlabNote {
+		publishGitLab(ctx, reporter, len(report.Affected))
+	}
+
+	if failOnUnknown && report.Incomplete() {
+		logger.Error("分析结果不完整", "unknown", len(report.Unknown), "failed_packages", len(report.FailedPackages), "out_of_cone", len(report.OutOfCone))
+		os.Exit(1)
+	}
+	logger.Info("分析完成", "elapsed", time.Since(startTime))
+}
+
+// printReport 以 format 格式向 w 打印报告
+func printReport(w io.Writer, reporter *output.Reporter, format string) {
+	switch format {
 	case "json":
-		if err := reporter.PrintJSON(); err != nil {
-			fmt.Fprintf(os.Stderr, "输出JSON失败: %v\n", err)
-			os.Exit(1)
+		if err := reporter.PrintJSON(w); err != nil {
+			fatal("输出JSON失败", err)
+		}
+
+	case "rdjson":
+		if err := reporter.PrintRDJSON(w); err != nil {
+			fatal("输出JSON失败", err)
 		}
 
 	case "summary":
-		reporter.PrintSummary()
+		reporter.PrintSummary(w)
 
 	case "text":
-		reporter.PrintText()
+		reporter.PrintText(w)
+
+	case "markdown":
+		reporter.PrintMarkdown(w)
+
+	case "bazel":
+		reporter.PrintBazel(w)
+
+	case "cypher":
+		reporter.PrintCypher(w)
+
+	case "workloads", "workloads-json":
+		if err := reporter.PrintWorkloads(w, format == "workloads-json"); err != nil {
+			fatal("输出结果失败", err)
+		}
 
 	case "simple":
-		fallthrough
+		reporter.PrintSimple(w)
+
 	default:
-		reporter.PrintSimple()
+		fatal("输出结果失败", i18n.Errorf("不支持的输出格式 %q，可选 %s", format, strings.Join(reportOutputs, "/")))
+	}
+}
+
+// reportOutputs 分析和 trace 子命令支持的输出格式
+var reportOutputs = []string{"simple", "text", "json", "ndjson", "summary", "markdown", "rdjson", "bazel", "cypher", "workloads", "workloads-json"}
+
+// binaryListOutputs 只列出受影响服务的输出格式,所有服务都受影响后可以提前结束追踪。
+// 其他格式按变更报告调用链、风险和置信度,需要追踪所有变更符号
+var binaryListOutputs = map[string]bool{
+	"simple":         true,
+	"ndjson":         true,
+	"bazel":          true,
+	"workloads":      true,
+	"workloads-json": true,
+}
+
+// teamReportExts 各输出格式的团队报告文件扩展名
+var teamReportExts = map[string]string{
+	"json":           ".json",
+	"rdjson":         ".json",
+	"markdown":       ".md",
+	"cypher":         ".cypher",
+	"workloads":      ".yaml",
+	"workloads-json": ".json",
+}
+
+// writeTeamReports 按团队拆分报告,每个团队写入 -team-report-dir 下的 <团队>.<扩展名>,
+// 没有团队的服务写入 unassigned
+func writeTeamReports(report *analyzer.Report, format string) {
+	if format == "stream" || format == "pipeline" {
+		logger.Warn("流式输出和 CI 流水线不支持按团队拆分报告", "output", format)
+		return
+	}
+	if err := os.MkdirAll(teamReportDir, 0o755); err != nil {
+		fatal("写入团队报告失败", err)
+	}
+	ext, ok := teamReportExts[format]
+	if !ok {
+		ext = ".txt"
 	}
 
-	// 如果没有发现受影响的服务，返回非0退出码
-	if len(results) == 0 {
-		os.Exit(0) // 无影响也算成功
+	for _, team := range output.SplitByTeam(report) {
+		name := team.Team
+		if name == "" {
+			name = "unassigned"
+		}
+		path := filepath.Join(teamReportDir, teamFileName(name)+ext)
+		f, err := os.Create(path)
+		if err != nil {
+			fatal("写入团队报告失败", err)
+		}
+		printReport(f, output.NewReporter(team.Report), format)
+		if err := f.Close(); err != nil {
+			fatal("写入团队报告失败", err)
+		}
+		logger.Info("已写入团队报告", "team", name, "path", path, "affected", len(team.Report.Affected))
 	}
+}
 
-	// 打印总耗时
-	if verbose {
-		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("⏱️  总耗时: %v\n", time.Since(startTime))
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+// teamFileName 把团队名转换为文件名,如 "@org/payments" 为 "org-payments"
+func teamFileName(team string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, team)
+	if name = strings.Trim(name, "-."); name == "" {
+		return "team"
 	}
+	return name
 }
 
-// getModulePath 从 go.mod 文件获取模块路径
-func getModulePath(repoPath string) string {
-	goModPath := filepath.Join(repoPath, "go.mod")
-	content, err := os.ReadFile(goModPath)
+// listBinaries 列出仓库中所有的可执行程序
+func listBinaries() {
+	bins, err := ripples.Binaries(context.Background(), repoPath)
+	if err != nil {
+		fatal("列出可执行程序失败", err)
+	}
+
+	switch outputType {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if bins == nil {
+			bins = []ripples.Binary{}
+		}
+		if err := enc.Encode(bins); err != nil {
+			fatal("输出JSON失败", err)
+		}
+
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("名称\t目录\t模块\t可追踪"))
+		for _, b := range bins {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%v\n", b.Name, b.Dir, b.Module, b.Traced)
+		}
+		_ = w.Flush()
+
+	default:
+		for _, b := range bins {
+			fmt.Println(b.Name)
+		}
+	}
+}
+
+// initConfig 根据仓库结构生成初始的配置文件
+func initConfig() {
+	path := configPath
+	if path == "" {
+		path = filepath.Join(repoPath, config.FileName)
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		fatal("生成配置文件失败", i18n.Errorf("%s 已存在，使用 -force 覆盖", path))
+	}
+	s, err := ripples.DetectScaffold(context.Background(), repoPath)
+	if err != nil {
+		fatal("生成配置文件失败", err)
+	}
+	if err := os.WriteFile(path, s.Render(), 0o644); err != nil {
+		fatal("生成配置文件失败", err)
+	}
+	fmt.Fprint(os.Stderr, i18n.Sprintf("已写入 %s: %d 个服务，%d 个模块\n", path, len(s.Binaries), len(s.Modules)))
+}
+
+// exportGraph 输出仓库的完整调用图
+func exportGraph() {
+	if graphFormat != "dot" && graphFormat != "json" {
+		fatal("输出调用图失败", i18n.Errorf("不支持的格式: %s", graphFormat))
+	}
+	g, err := ripples.Graph(context.Background(), repoPath)
+	if err != nil {
+		fatal("构建调用图失败", err)
+	}
+
+	switch graphFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(g); err != nil {
+			fatal("输出JSON失败", err)
+		}
+	default:
+		if err := g.WriteDOT(os.Stdout); err != nil {
+			fatal("输出调用图失败", err)
+		}
+	}
+}
+
+// diffReports 比较两次保存的分析结果
+func diffReports(oldPath, newPath string) {
+	oldReport, err := output.LoadReport(oldPath)
+	if err != nil {
+		fatal("比较报告失败", err)
+	}
+	newReport, err := output.LoadReport(newPath)
+	if err != nil {
+		fatal("比较报告失败", err)
+	}
+
+	diff := output.DiffReports(oldReport, newReport)
+	switch outputType {
+	case "json":
+		if err := diff.PrintJSON(os.Stdout); err != nil {
+			fatal("输出JSON失败", err)
+		}
+	case "text", "summary", "markdown":
+		diff.PrintText(os.Stdout)
+	default:
+		diff.PrintSimple(os.Stdout)
+	}
+}
+
+// writeIndex 生成或更新符号索引
+func writeIndex(ctx context.Context, opts ripples.Options) {
+	opts.Index = indexPath
+	stats, err := ripples.WriteIndex(ctx, opts)
+	if err != nil {
+		fatal("生成索引失败", err)
+	}
+	fmt.Fprint(os.Stderr, i18n.Sprintf("已写入 %s: %d 个包，%d 个符号，重新解析了 %d 个包\n", stats.File, stats.Packages, stats.Symbols, stats.Reloaded))
+}
+
+// installHook 安装调用 ripples 的 git 钩子
+func installHook(name string) {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "ripples"
+	}
+	path, err := hook.Install(repoPath, name, exe, force)
+	if err != nil {
+		fatal("安装钩子失败", err)
+	}
+	fmt.Fprint(os.Stderr, i18n.Sprintf("已安装 %s 钩子: %s\n", name, path))
+}
+
+// runHook 运行 git 钩子: 按导入图快速分析此次推送影响的服务,
+// 超过配置的上限时以非零状态退出,git 据此拒绝推送
+func runHook(ctx context.Context, opts ripples.Options, name string, cfg config.Hook) {
+	if name != hook.PrePush {
+		fatal("运行钩子失败", i18n.Errorf("不支持的 git 钩子 %q，可选 %s", name, strings.Join(hook.Names, "/")))
+	}
+	updates, err := hook.ParsePrePush(os.Stdin)
+	if err != nil {
+		fatal("运行钩子失败", err)
+	}
+
+	opts.Mode = ripples.ModeImports
+	affected := make(map[string]bool)
+	for _, u := range updates {
+		if u.Deletes() {
+			continue
+		}
+		base := hook.Base(repoPath, u)
+		if base == "" {
+			logger.Warn("无法确定推送的比较基准，跳过", "ref", u.LocalRef)
+			continue
+		}
+		if base == u.LocalSHA {
+			continue
+		}
+		opts.OldCommit, opts.NewCommit = base, u.LocalSHA
+		a, err := ripples.New(opts)
+		if err != nil {
+			fatal("运行钩子失败", err)
+		}
+		res, err := a.Analyze(ctx)
+		if err != nil {
+			// 分析失败不阻止推送
+			logger.Warn("分析推送的影响失败", "ref", u.LocalRef, "error", err)
+			continue
+		}
+		for _, b := range res.Affected {
+			affected[b.Name] = true
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(affected))
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("ripples: 此次推送不影响任何服务"))
+		return
+	}
+	fmt.Fprint(os.Stderr, i18n.Sprintf("ripples: 此次推送影响 %d 个服务: %s\n", len(names), strings.Join(names, ", ")))
+	if cfg.MaxAffected > 0 && len(names) > cfg.MaxAffected {
+		fmt.Fprint(os.Stderr, i18n.Sprintf("ripples: 受影响的服务数超过上限 %d，已拒绝推送 (git push --no-verify 可跳过检查)\n", cfg.MaxAffected))
+		os.Exit(1)
+	}
+}
+
+// collectStats 统计历史提交中导致多服务影响的热点包
+func collectStats(ctx context.Context, opts ripples.Options) {
+	stats, err := ripples.CollectStats(ctx, ripples.StatsOptions{
+		Options:   opts,
+		Since:     since,
+		CacheDir:  statsCache,
+		SkipTypes: splitList(skipTypes),
+	})
+	if err != nil {
+		fatal("统计失败", err)
+	}
+
+	if outputType == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			fatal("输出JSON失败", err)
+		}
+		return
+	}
+
+	i18n.Printf("分析了 %d 个提交，其中 %d 个影响多个服务\n", stats.Commits, stats.MultiService)
+	if len(stats.Failed) > 0 {
+		i18n.Printf("⚠️  %d 个提交分析失败\n", len(stats.Failed))
+	}
+	if len(stats.Skipped) > 0 {
+		i18n.Printf("按提交类型跳过了 %d 个提交\n", len(stats.Skipped))
+	}
+	if len(stats.HotSpots) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("包\t提交数\t最多服务数\t服务"))
+	for _, spot := range stats.HotSpots {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", spot.Package, spot.Commits, spot.MaxServices, strings.Join(spot.Services, ","))
+	}
+	_ = w.Flush()
+}
+
+// splitList 按逗号拆分参数值,去掉空白和空项
+func splitList(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// defaultStatsCache 返回 stats 子命令默认的报告缓存目录
+func defaultStatsCache() string {
+	dir, err := os.UserCacheDir()
 	if err != nil {
 		return ""
 	}
+	return filepath.Join(dir, "ripples", "reports")
+}
 
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+// deployments 转换配置文件中的部署映射
+func deployments(cfg *config.Config) map[string]ripples.Deployment {
+	if len(cfg.Deployments) == 0 {
+		return nil
+	}
+	res := make(map[string]ripples.Deployment, len(cfg.Deployments))
+	for key, d := range cfg.Deployments {
+		res[key] = ripples.Deployment{Image: d.Image, HelmRelease: d.HelmRelease, Kubernetes: d.Kubernetes}
+	}
+	return res
+}
+
+// applyConfigDefaults 用配置文件中的值填充命令行未显式指定的参数
+func applyConfigDefaults(cfg *config.Config) {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	defaults := map[string]string{
+		"output":     cfg.Output.Format,
+		"lang":       cfg.Output.Lang,
+		"log-level":  cfg.Output.LogLevel,
+		"log-format": cfg.Output.LogFormat,
+	}
+	if cfg.Output.AllPaths {
+		defaults["all-paths"] = "true"
+	}
+	if cfg.Output.MaxPathsPerBinary > 0 {
+		defaults["max-paths-per-binary"] = strconv.Itoa(cfg.Output.MaxPathsPerBinary)
+	}
+	if cfg.CrossServiceFilter != nil {
+		defaults["cross-service-filter"] = strconv.FormatBool(*cfg.CrossServiceFilter)
+	}
+	if cfg.Strategy != "" {
+		defaults["strategy"] = cfg.Strategy
+	}
+	if cfg.Mode != "" {
+		defaults["mode"] = cfg.Mode
+	}
+	if cfg.Granularity != "" {
+		defaults["granularity"] = cfg.Granularity
+	}
+	if cfg.InterfaceFilter != "" {
+		defaults["interface-filter"] = cfg.InterfaceFilter
+	}
+	if cfg.FailOnUnknown {
+		defaults["fail-on-unknown"] = "true"
+	}
+	if cfg.MaxFanOut > 0 {
+		defaults["max-fanout"] = strconv.Itoa(cfg.MaxFanOut)
+	}
+	if cfg.MinRisk > 0 {
+		defaults["min-risk"] = strconv.Itoa(cfg.MinRisk)
+	}
+	if cfg.Timeout > 0 {
+		defaults["timeout"] = time.Duration(cfg.Timeout).String()
+	}
+	if cfg.Budget > 0 {
+		defaults["budget"] = time.Duration(cfg.Budget).String()
+	}
+
+	for name, value := range defaults {
+		if value != "" && !set[name] {
+			_ = flag.Set(name, value)
 		}
 	}
-	return ""
+}
+
+// pushMetrics 将分析指标推送到 Prometheus Pushgateway
+func pushMetrics(ctx context.Context, res *ripples.Result, elapsed time.Duration) {
+	rec := metrics.NewRecorder()
+	for _, phase := range res.Phases {
+		rec.ObservePhase(phase.Name, phase.Duration)
+	}
+	rec.Set(metrics.ChangedFiles, float64(len(res.ChangedFiles)))
+	rec.Set(metrics.SymbolsAnalyzed, float64(res.ChangedSymbols))
+	rec.Set(metrics.AffectedServices, float64(len(res.Affected)))
+	rec.Set(metrics.AnalysisDuration, elapsed.Seconds())
+	rec.Set(metrics.PeakMemory, float64(metrics.PeakRSS()))
+	cache := res.Timings(elapsed).Cache
+	rec.Set(metrics.TraceRequests, float64(cache.TraceRequests))
+	rec.Set(metrics.TraceCacheHits, float64(cache.TraceHits))
+	rec.Set(metrics.LastSuccess, float64(time.Now().Unix()))
+
+	var grouping map[string]string
+	if res.Module != "" {
+		grouping = map[string]string{"module": res.Module}
+	}
+	if err := rec.Push(ctx, pushgatewayURL, pushgatewayJob, grouping); err != nil {
+		logger.Warn("推送 Prometheus 指标失败", "error", err)
+	}
+}
+
+// writeTimings 以 JSON 输出性能数据到 -timings-file,未指定时输出到 stderr
+func writeTimings(t *ripples.Timings) {
+	out := os.Stderr
+	if timingsFile != "" {
+		f, err := os.Create(timingsFile)
+		if err != nil {
+			fatal("写入性能数据失败", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		fatal("写入性能数据失败", err)
+	}
+}
+
+// writeManifest 写入运行清单
+func writeManifest(a *ripples.Analyzer, res *ripples.Result, configHash string) {
+	m, err := a.Manifest(res, configHash)
+	if err != nil {
+		fatal("写入运行清单失败", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		fatal("写入运行清单失败", err)
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0o644); err != nil {
+		fatal("写入运行清单失败", err)
+	}
+}
+
+// recordRun 向 -store 写入本次分析: 解析后的 commit 与运行清单一致,耗时与 -timings 一致
+func recordRun(a *ripples.Analyzer, res *ripples.Result, configHash string, elapsed time.Duration) {
+	m, err := a.Manifest(res, configHash)
+	if err != nil {
+		fatal("写入分析历史失败", err)
+	}
+	t := res.Timings(elapsed)
+	run := store.Run{
+		Time:      time.Now().UTC(),
+		Repo:      storeRepo(),
+		OldCommit: m.OldCommit,
+		NewCommit: m.NewCommit,
+		Tool:      m.Tool,
+		Duration:  t.Total,
+		Affected:  []store.Service{},
+	}
+	for _, phase := range t.Phases {
+		run.Phases = append(run.Phases, store.Phase{Name: phase.Name, Duration: phase.Duration})
+	}
+	for _, b := range res.Affected {
+		run.Affected = append(run.Affected, store.Service{Name: b.Name, Confidence: string(b.Confidence), Risk: b.Risk})
+	}
+	db, err := store.Open(storePath)
+	if err != nil {
+		fatal("写入分析历史失败", err)
+	}
+	defer db.Close()
+	if err := db.Append(run); err != nil {
+		fatal("写入分析历史失败", err)
+	}
+}
+
+// storeRepo 返回分析历史中记录的仓库,即 -repo 的绝对路径
+func storeRepo() string {
+	repo, err := filepath.Abs(repoPath)
+	if err != nil {
+		return repoPath
+	}
+	return repo
+}
+
+// queryStore 查询 -store 中 -repo 的分析历史: runs 列出每次分析,services 按服务汇总,
+// diff 比较两个 commit 最近一次分析的受影响服务
+func queryStore(query string, args []string) {
+	if storePath == "" {
+		fatal("查询分析历史失败", i18n.Errorf("必须指定 -store 参数"))
+	}
+	if query == "diff" && len(args) != 2 {
+		fatal("查询分析历史失败", i18n.Errorf("query diff 需要两个 commit"))
+	}
+	db, err := store.Open(storePath)
+	if err != nil {
+		fatal("查询分析历史失败", err)
+	}
+	defer db.Close()
+	repo := storeRepo()
+	runs, err := db.Runs(repo)
+	if err != nil {
+		fatal("查询分析历史失败", err)
+	}
+
+	switch query {
+	case "runs":
+		if outputType == "json" {
+			if runs == nil {
+				runs = []store.Run{}
+			}
+			printJSON(runs)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("时间	旧 commit	新 commit	耗时	服务"))
+		for _, run := range runs {
+			var names []string
+			for _, s := range run.Affected {
+				names = append(names, s.Name)
+			}
+			elapsed := time.Duration(run.Duration * float64(time.Millisecond)).Round(time.Millisecond)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", run.Time.Local().Format(time.DateTime), shortCommit(run.OldCommit), shortCommit(run.NewCommit), elapsed, strings.Join(names, ","))
+		}
+		_ = w.Flush()
+
+	case "services":
+		summary := store.Summarize(runs)
+		if outputType == "json" {
+			printJSON(summary)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, i18n.T("服务	受影响次数	最高风险	最近的 commit"))
+		for _, s := range summary {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.Name, s.Runs, s.MaxRisk, shortCommit(s.LastCommit))
+		}
+		_ = w.Flush()
+
+	case "diff":
+		var reports [2]*analyzer.Report
+		for i, rev := range args {
+			// 分支名等引用按当前仓库解析,无法解析时按 commit 前缀匹配
+			if commit, err := git.ResolveCommit(repoPath, rev); err == nil {
+				rev = commit
+			}
+			run, err := db.Find(repo, rev)
+			if err != nil {
+				fatal("查询分析历史失败", err)
+			}
+			reports[i] = &analyzer.Report{}
+			for _, s := range run.Affected {
+				reports[i].Affected = append(reports[i].Affected, analyzer.AffectedBinary{Name: s.Name, Confidence: analyzer.Confidence(s.Confidence), Risk: s.Risk})
+			}
+		}
+		diff := output.DiffReports(reports[0], reports[1])
+		switch outputType {
+		case "json":
+			if err := diff.PrintJSON(os.Stdout); err != nil {
+				fatal("输出JSON失败", err)
+			}
+		case "text", "summary", "markdown":
+			diff.PrintText(os.Stdout)
+		default:
+			diff.PrintSimple(os.Stdout)
+		}
+
+	default:
+		fatal("查询分析历史失败", i18n.Errorf("不支持的查询 %q，可选 runs/services/diff", query))
+	}
+}
+
+// printJSON 以缩进的 JSON 格式向标准输出打印 v
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fatal("输出JSON失败", err)
+	}
+}
+
+// shortCommit 返回 commit 的前 12 位
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// publishGitHubActions 写入 job summary 和 step output
+func publishGitHubActions(reporter *output.Reporter, report *analyzer.Report) {
+	actions, err := github.FromEnv()
+	if err != nil {
+		fatal("写入 GitHub Actions 输出失败", err)
+	}
+	if err := actions.WriteSummary(reporter.RenderMarkdown()); err != nil {
+		fatal("写入 GitHub Actions 输出失败", err)
+	}
+
+	var names, images []string
+	for _, res := range report.Affected {
+		names = append(names, res.Name)
+		if res.Deployment != nil && res.Deployment.Image != "" {
+			images = append(images, res.Deployment.Image)
+		}
+	}
+	if err := actions.SetAffectedServices(names); err != nil {
+		fatal("写入 GitHub Actions 输出失败", err)
+	}
+	if err := actions.SetAffectedImages(images); err != nil {
+		fatal("写入 GitHub Actions 输出失败", err)
+	}
+}
+
+// ciPlatforms ci 子命令支持的 CI 平台
+var ciPlatforms = []string{"gitlab", "buildkite", "circleci"}
+
+// printPipeline 按配置中 platform 的模板输出只包含受影响服务作业的动态流水线
+func printPipeline(ci config.CI, platform string, report *analyzer.Report) {
+	var services []pipeline.Service
+	for _, res := range report.Affected {
+		svc := pipeline.Service{
+			Name:        res.Name,
+			Package:     res.PkgPath,
+			Dir:         res.Dir,
+			Confidence:  string(res.Confidence),
+			Risk:        res.Risk,
+			BazelTarget: res.BazelTarget,
+			Owners:      res.Owners,
+		}
+		if d := res.Deployment; d != nil {
+			svc.Image, svc.HelmRelease, svc.Kubernetes = d.Image, d.HelmRelease, d.Kubernetes
+		}
+		services = append(services, svc)
+	}
+
+	var data []byte
+	var err error
+	switch platform {
+	case "gitlab":
+		data, err = pipelineTemplate(ci.GitLab).GitLab(services)
+	case "buildkite":
+		data, err = pipelineTemplate(ci.Buildkite).Buildkite(services)
+	case "circleci":
+		data, err = pipelineTemplate(ci.CircleCI).CircleCI(services)
+	}
+	if err != nil {
+		fatal("生成 CI 流水线失败", err)
+	}
+	os.Stdout.Write(data)
+}
+
+// pipelineTemplate 将配置中的流水线模板转换为 pipeline.Template
+func pipelineTemplate(p config.Pipeline) pipeline.Template {
+	return pipeline.Template{Header: &p.Header, Jobs: &p.Jobs, Empty: &p.Empty, Workflow: p.Workflow}
+}
+
+// publishGitLab 将报告发布到 GitLab 合并请求
+func publishGitLab(ctx context.Context, reporter *output.Reporter, affected int) {
+	if gitlabToken == "" {
+		gitlabToken = os.Getenv("GITLAB_TOKEN")
+	}
+	client, err := gitlab.NewClient(gitlabURL, gitlabToken, gitlabProject, gitlabMR)
+	if err != nil {
+		fatal("发布 GitLab 评论失败", err)
+	}
+	if err := client.Publish(ctx, reporter.RenderMarkdown(), affected, gitlabReviewLabel, gitlabReviewThreshold); err != nil {
+		fatal("发布 GitLab 评论失败", err)
+	}
+	logger.Info("已发布 GitLab 评论", "project", gitlabProject, "mr", gitlabMR)
+}
+
+// fatal 输出错误日志并退出
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
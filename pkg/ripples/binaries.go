@@ -0,0 +1,64 @@
+package ripples
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// Binary 仓库中的一个可执行程序(声明了 main 函数的 main 包)
+type Binary struct {
+	Name    string `json:"name"`    // 服务名,与分析报告中的名称一致(见 parser.BinaryName)
+	Package string `json:"package"` // main 包导入路径
+	Module  string `json:"module"`  // 所属模块路径
+	Dir     string `json:"dir"`     // main 包目录(相对仓库根目录)
+
+	// Traced gopls 追踪器能否把它识别为服务入口: 追踪器只认 cmd/ 下或名为 main
+	// 的目录中的 main 函数。为 false 的程序改由引用遍历追踪,只覆盖函数、常量和变量的变更
+	Traced bool `json:"traced"`
+}
+
+// Binaries 列出仓库中所有的可执行程序,多模块仓库会遍历每个模块
+func Binaries(ctx context.Context, repoPath string) ([]Binary, error) {
+	if repoPath == "" {
+		repoPath = "."
+	}
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	mods, err := discoverModules(root)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{root}
+	if len(mods) > 0 {
+		dirs = dirs[:0]
+		for _, mod := range mods {
+			dirs = append(dirs, mod.Dir)
+		}
+	}
+
+	var res []Binary
+	for _, dir := range dirs {
+		pkgs, err := parser.FindMainPackages(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			rel, err := filepath.Rel(root, pkg.Dir)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, Binary{
+				Name:    parser.BinaryName(pkg.PkgPath),
+				Package: pkg.PkgPath,
+				Module:  pkg.Module,
+				Dir:     filepath.ToSlash(rel),
+				Traced:  parser.TracedMain(pkg.Dir),
+			})
+		}
+	}
+	return res, nil
+}
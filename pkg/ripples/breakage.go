@@ -0,0 +1,67 @@
+package ripples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// InterfaceBreakage 方法被删除或签名变化、或接口新增方法后,类型不再满足的接口转换位置
+type InterfaceBreakage = analyzer.InterfaceBreakage
+
+// interfaceBreakage 检查被删除或签名变化的方法、接口新增的方法是否破坏了旧代码中的接口实现。
+// 旧代码在临时 git worktree 中加载,此时它能通过编译,类型信息完整
+func (a *Analyzer) interfaceBreakage(ctx context.Context, cd *analyzer.ChangeDetector) ([]InterfaceBreakage, error) {
+	changes, ifaces, err := cd.DetectMethodChanges(a.opts.OldCommit, a.opts.NewCommit)
+	if err != nil {
+		return nil, err
+	}
+	// 只新增了方法时不会破坏已有的实现
+	if !slices.ContainsFunc(changes, func(c analyzer.MethodChange) bool { return !c.Added() }) && len(ifaces) == 0 {
+		return nil, nil
+	}
+	logger.Info("检查接口实现是否被破坏", "methods", len(changes), "interfaces", len(ifaces))
+
+	tmp, err := os.MkdirTemp("", "ripples-old-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "repo")
+	if err := git.AddWorktree(a.opts.RepoPath, dir, a.opts.OldCommit); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(a.opts.RepoPath, dir); err != nil {
+			logger.Warn("删除 git worktree 失败", "dir", dir, "error", err)
+		}
+	}()
+
+	// 多模块仓库逐个模块加载,类型按目录和名称匹配,不需要共同的工作区
+	mods, err := discoverModules(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{dir}
+	if len(mods) > 0 {
+		dirs = dirs[:0]
+		for _, mod := range mods {
+			dirs = append(dirs, mod.Dir)
+		}
+	}
+
+	var res []InterfaceBreakage
+	for _, d := range dirs {
+		found, err := analyzer.FindInterfaceBreakage(ctx, dir, d, changes, ifaces)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, found...)
+	}
+	return res, nil
+}
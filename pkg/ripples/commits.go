@@ -0,0 +1,58 @@
+package ripples
+
+import (
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// describeRange 在报告中记录分析的提交范围,设置 Blame 时为每个变更符号标注最后修改其变更行的提交,
+// 使报告脱离仓库也能说明分析了什么。只在变更来自 OldCommit 和 NewCommit 的 diff 时执行,
+// git 命令失败时只记录警告
+func (a *Analyzer) describeRange(root string, report *analyzer.Report) {
+	if len(a.opts.Symbols) > 0 || len(a.opts.Files) > 0 || a.opts.OldCommit == "" || a.opts.NewCommit == "" {
+		return
+	}
+	oldHash, err := git.ResolveCommit(root, a.opts.OldCommit)
+	if err != nil {
+		logger.Warn("读取提交信息失败", "error", err)
+		return
+	}
+	newHash, err := git.ResolveCommit(root, a.opts.NewCommit)
+	if err != nil {
+		logger.Warn("读取提交信息失败", "error", err)
+		return
+	}
+	commits, err := git.RangeCommits(root, oldHash, newHash)
+	if err != nil {
+		logger.Warn("读取提交信息失败", "error", err)
+		return
+	}
+	report.Range = &analyzer.CommitRange{Old: oldHash, New: newHash, Commits: reportCommits(commits)}
+
+	if !a.opts.Blame {
+		return
+	}
+	for i := range report.Changes {
+		c := &report.Changes[i]
+		// 删除的符号在新版本中没有对应的行
+		if c.File == "" || c.StartLine == 0 || c.ChangeType == string(analyzer.ChangeTypeDelete) {
+			continue
+		}
+		blamed, err := git.Blame(root, newHash, c.File, c.StartLine, max(c.EndLine, c.StartLine))
+		if err != nil {
+			logger.Warn("git blame 失败", "symbol", c.Symbol, "error", err)
+			continue
+		}
+		c.Blame = reportCommits(blamed)
+	}
+}
+
+// reportCommits 转换为报告中的提交信息,没有提交时返回空切片
+func reportCommits(commits []git.Commit) []analyzer.Commit {
+	res := make([]analyzer.Commit, 0, len(commits))
+	for _, c := range commits {
+		res = append(res, analyzer.Commit{Hash: c.Hash, Author: c.Author, Email: c.Email, Date: c.Date, Subject: c.Subject})
+	}
+	return res
+}
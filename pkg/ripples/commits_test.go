@@ -0,0 +1,42 @@
+package ripples
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAnalyzeCommitRange(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Blame: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	rg := res.Range
+	if rg == nil || len(rg.Old) != 40 || len(rg.New) != 40 {
+		t.Fatalf("Expected resolved commit range, got %+v", rg)
+	}
+	if len(rg.Commits) != 1 || rg.Commits[0].Hash != rg.New || rg.Commits[0].Subject != "change" || rg.Commits[0].Author != "test" {
+		t.Errorf("Unexpected commits %+v", rg.Commits)
+	}
+	if len(res.Changes) != 1 || len(res.Changes[0].Blame) != 1 || res.Changes[0].Blame[0].Hash != rg.New {
+		t.Errorf("Expected the change blamed on %s, got %+v", rg.New, res.Changes)
+	}
+
+	// 直接指定符号时没有提交范围
+	a, err = New(Options{RepoPath: repo, Symbols: []string{"example.com/shared-package-test/pkg/common.LogMessage"}, Mode: ModeImports})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if res, err = a.Analyze(context.Background()); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if res.Range != nil {
+		t.Errorf("Expected no range for direct symbols, got %+v", res.Range)
+	}
+}
@@ -0,0 +1,67 @@
+package ripples
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// envMu 串行化修改进程级环境变量(以及 os.Stdout)的分析: Analyze、WriteIndex 和 Graph
+// 持有它直到恢复环境变量,同时运行的两次分析不会看到对方的 GOFLAGS、GOWORK 等设置
+var envMu sync.Mutex
+
+// envOverrides 记录分析期间修改的进程级环境变量,Close 时按相反顺序恢复
+type envOverrides struct {
+	restore []func()
+}
+
+// setenv 设置环境变量并记录恢复方式
+func (e *envOverrides) setenv(key, value string) {
+	old, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	e.restore = append(e.restore, func() {
+		if had {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// Close 恢复环境变量
+func (e *envOverrides) Close() {
+	for i := len(e.restore) - 1; i >= 0; i-- {
+		e.restore[i]()
+	}
+	e.restore = nil
+}
+
+// newBuildEnv 让 go/packages 和 gopls 使用与实际构建相同的配置: 先设置 env 中的
+// "KEY=VALUE",再把 buildFlags(如 "-mod=vendor"、"-tags=integration")追加到 GOFLAGS。
+// go/packages 和 gopls 子进程都继承进程环境,因此两者看到的构建配置一致
+func newBuildEnv(buildFlags, env []string) (*envOverrides, error) {
+	for _, kv := range env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return nil, i18n.Errorf("无效的环境变量: %s (需要 KEY=VALUE 形式)", kv)
+		}
+	}
+	for _, f := range buildFlags {
+		// GOFLAGS 以空白分隔,不支持带空白的参数
+		if !strings.HasPrefix(f, "-") || strings.ContainsAny(f, " \t\n") {
+			return nil, i18n.Errorf("无效的构建参数: %s (需要 -flag=value 形式)", f)
+		}
+	}
+
+	e := &envOverrides{}
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		e.setenv(key, value)
+	}
+	if len(buildFlags) > 0 {
+		flags := strings.Fields(os.Getenv("GOFLAGS"))
+		e.setenv("GOFLAGS", strings.Join(append(flags, buildFlags...), " "))
+	}
+	return e, nil
+}
@@ -0,0 +1,83 @@
+package ripples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewBuildEnv(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("CGO_ENABLED", "1")
+	os.Unsetenv("RIPPLES_TEST_ENV")
+
+	env, err := newBuildEnv([]string{"-tags=integration"}, []string{"GOFLAGS=-mod=vendor", "CGO_ENABLED=0", "RIPPLES_TEST_ENV=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Env 先生效,BuildFlags 追加在其后
+	if got := os.Getenv("GOFLAGS"); got != "-mod=vendor -tags=integration" {
+		t.Errorf("GOFLAGS = %q", got)
+	}
+	if got := os.Getenv("CGO_ENABLED"); got != "0" {
+		t.Errorf("CGO_ENABLED = %q", got)
+	}
+	if got := os.Getenv("RIPPLES_TEST_ENV"); got != "a=b" {
+		t.Errorf("RIPPLES_TEST_ENV = %q", got)
+	}
+
+	env.Close()
+	if got := os.Getenv("GOFLAGS"); got != "-mod=mod" {
+		t.Errorf("GOFLAGS after Close = %q", got)
+	}
+	if got := os.Getenv("CGO_ENABLED"); got != "1" {
+		t.Errorf("CGO_ENABLED after Close = %q", got)
+	}
+	if _, ok := os.LookupEnv("RIPPLES_TEST_ENV"); ok {
+		t.Error("RIPPLES_TEST_ENV still set after Close")
+	}
+
+	for _, tt := range []struct {
+		flags, env []string
+	}{
+		{env: []string{"GOFLAGS"}},
+		{env: []string{"=x"}},
+		{flags: []string{"mod=vendor"}},
+		{flags: []string{"-tags=a b"}},
+	} {
+		if _, err := newBuildEnv(tt.flags, tt.env); err == nil {
+			t.Errorf("newBuildEnv(%q, %q) succeeded, want error", tt.flags, tt.env)
+		}
+	}
+}
+
+func TestAnalyzeWaitsForEnv(t *testing.T) {
+	repo, err := filepath.Abs(filepath.Join("..", "..", "testdata", "shared-package-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(Options{RepoPath: repo, Symbols: []string{"example.com/shared-package-test/pkg/common.LogMessage"}, Mode: ModeImports})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// 另一次分析修改环境变量期间,Analyze 等待其恢复
+	envMu.Lock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.Analyze(context.Background())
+		done <- err
+	}()
+	select {
+	case <-done:
+		envMu.Unlock()
+		t.Fatal("Analyze ran while another analysis held the environment")
+	case <-time.After(100 * time.Millisecond):
+	}
+	envMu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+}
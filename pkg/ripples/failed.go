@@ -0,0 +1,18 @@
+package ripples
+
+import (
+	"github.com/jimyag/ripples/internal/analyzer"
+	"golang.org/x/tools/go/packages"
+)
+
+// FailedPackage 加载失败而被排除的包,其中的变更没有被分析
+type FailedPackage = analyzer.FailedPackage
+
+// failedPackages 把加载失败的包转换为报告中的条目,只保留每个包的第一个错误
+func failedPackages(pkgs []*packages.Package) []FailedPackage {
+	var res []FailedPackage
+	for _, pkg := range pkgs {
+		res = append(res, FailedPackage{Package: pkg.PkgPath, Error: pkg.Errors[0].Error()})
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+package ripples
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// fileFilter 决定哪些变更文件和符号参与分析: Exclude、Only、生成的文件以及
+// 仓库根目录下 .ripplesignore 中的规则
+type fileFilter struct {
+	opts   Options
+	root   string // 仓库根目录(绝对路径)
+	ignore *config.Ignore
+}
+
+// newFileFilter 读取 root 下的 .ripplesignore 并创建过滤器
+func (a *Analyzer) newFileFilter(root string) (*fileFilter, error) {
+	ignore, err := config.LoadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+	return &fileFilter{opts: a.opts, root: root, ignore: ignore}, nil
+}
+
+// skip 判断文件(相对仓库根目录)是否被排除、不在 Only 范围内、被忽略或是生成的代码
+func (f *fileFilter) skip(file string) bool {
+	slashed := filepath.ToSlash(file)
+	if config.MatchAny(f.opts.Exclude, slashed) || f.ignore.File(slashed) {
+		return true
+	}
+	if len(f.opts.Only) > 0 && !config.MatchAny(f.opts.Only, slashed) {
+		return true
+	}
+	return !f.opts.IncludeGenerated && parser.IsGenerated(filepath.Join(f.root, file))
+}
+
+// files 过滤变更文件
+func (f *fileFilter) files(files []string) []string {
+	var res []string
+	for _, file := range files {
+		if f.skip(file) {
+			logger.Debug("排除变更文件", "file", file)
+			continue
+		}
+		res = append(res, file)
+	}
+	return res
+}
+
+// changes 过滤位于被排除文件中或被 .ripplesignore 忽略的变更符号
+func (f *fileFilter) changes(changes []analyzer.ChangedSymbol) []analyzer.ChangedSymbol {
+	var res []analyzer.ChangedSymbol
+	for _, c := range changes {
+		rel, err := filepath.Rel(f.root, c.Symbol.Position.Filename)
+		if err == nil && (f.skip(rel) || f.ignore.Symbol(path.Dir(filepath.ToSlash(rel)), symbolName(c.Symbol))) {
+			logger.Debug("排除变更符号", "symbol", symbolName(c.Symbol), "file", rel)
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// symbolName 返回符号名,方法写作 "接收者.方法"(不含类型参数),结构体字段写作 "结构体.字段"
+func symbolName(s *parser.Symbol) string {
+	if s.Kind == parser.SymbolKindStructField && s.Parent != nil {
+		return s.Parent.Name + "." + s.Name
+	}
+	if extra, ok := s.Extra.(parser.FunctionExtra); ok && extra.IsMethod {
+		recv, _, _ := strings.Cut(strings.TrimPrefix(extra.ReceiverType, "*"), "[")
+		return recv + "." + s.Name
+	}
+	return s.Name
+}
@@ -0,0 +1,46 @@
+package ripples
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gopathImportPath 返回 GOPATH 模式项目根目录的导入路径: root 及其上级目录都没有 go.mod,
+// 且 root 位于某个 GOPATH 的 src 目录下。不是 GOPATH 模式项目时返回空字符串
+func gopathImportPath(root string) string {
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return ""
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+	for _, dir := range filepath.SplitList(gopath) {
+		src, err := filepath.Abs(filepath.Join(dir, "src"))
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(src, root)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel)
+	}
+	return ""
+}
+
+// newGOPATHMode 通过 GO111MODULE=off 让 go/packages 和 gopls 按 GOPATH 布局加载,
+// 导入路径由 GOPATH/src 下的目录决定。与工作区一样修改的是进程级环境变量,调用方需持有 envMu
+func newGOPATHMode() *envOverrides {
+	e := &envOverrides{}
+	e.setenv("GO111MODULE", "off")
+	return e
+}
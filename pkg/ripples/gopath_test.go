@@ -0,0 +1,92 @@
+package ripples
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGOPATHImportPath(t *testing.T) {
+	gopath := t.TempDir()
+	t.Setenv("GOPATH", gopath)
+
+	legacy := filepath.Join(gopath, "src", "example.com", "legacy")
+	module := filepath.Join(gopath, "src", "example.com", "modern")
+	for _, dir := range []string{legacy, module} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), []byte("module example.com/modern\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		root string
+		want string
+	}{
+		{legacy, "example.com/legacy"},
+		{module, ""},                       // 有 go.mod 的模块项目
+		{filepath.Join(gopath, "src"), ""}, // GOPATH/src 本身没有导入路径
+		{t.TempDir(), ""},                  // 不在 GOPATH 下
+	}
+	for _, tt := range tests {
+		if got := gopathImportPath(tt.root); got != tt.want {
+			t.Errorf("gopathImportPath(%s) = %q, want %q", tt.root, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeGOPATH(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	gopath := t.TempDir()
+	t.Setenv("GOPATH", gopath)
+	repo := filepath.Join(gopath, "src", "example.com", "legacy")
+
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write("lib/lib.go", "package lib\n\nfunc Greet() string { return \"hi\" }\n")
+	write("cmd/app/main.go", "package main\n\nimport \"example.com/legacy/lib\"\n\nfunc main() { println(lib.Greet()) }\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+	write("lib/lib.go", "package lib\n\nfunc Greet() string { return \"hello\" }\n")
+	git("commit", "-q", "-am", "change")
+
+	before := os.Getenv("GO111MODULE")
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if res.Module != "example.com/legacy" {
+		t.Errorf("Expected the import path from the GOPATH layout, got %q", res.Module)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "app" || res.Affected[0].PkgPath != "example.com/legacy/cmd/app" {
+		t.Errorf("Expected app to be affected, got %+v", res.Affected)
+	}
+	if os.Getenv("GO111MODULE") != before {
+		t.Errorf("Expected GO111MODULE to be restored, got %q", os.Getenv("GO111MODULE"))
+	}
+}
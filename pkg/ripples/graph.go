@@ -0,0 +1,50 @@
+package ripples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/jimyag/ripples/internal/graph"
+)
+
+// CallGraph 仓库级调用图,以所有 main 函数为根,与 diff 无关
+type CallGraph = graph.Graph
+
+// Graph 构建仓库的完整调用图。多模块仓库在没有 go.work 时会使用临时工作区,
+// 一次加载所有模块
+func Graph(ctx context.Context, repoPath string) (*CallGraph, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	if repoPath == "" {
+		repoPath = "."
+	}
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	mods, err := discoverModules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := []string{"./..."}
+	if _, err := os.Stat(filepath.Join(root, "go.work")); len(mods) > 1 && err != nil {
+		ws, err := newWorkspace(mods)
+		if err != nil {
+			return nil, err
+		}
+		defer ws.Close()
+
+		patterns = patterns[:0]
+		for _, mod := range mods {
+			rel, err := filepath.Rel(root, mod.Dir)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, "./"+filepath.ToSlash(filepath.Join(rel, "...")))
+		}
+	}
+	return graph.Build(ctx, root, patterns...)
+}
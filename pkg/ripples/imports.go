@@ -0,0 +1,129 @@
+package ripples
+
+import (
+	"context"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/owners"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// Mode 分析模式
+type Mode string
+
+// 分析模式
+const (
+	// ModeCalls 追踪每个变更符号的调用层级(默认)
+	ModeCalls Mode = analyzer.ModeCalls
+	// ModeImports 不追踪调用层级,导入图中(直接或间接)包含变更包的服务都视为受影响。
+	// 结果偏多,但几秒即可完成,适合提交前的快速检查
+	ModeImports Mode = analyzer.ModeImports
+)
+
+// ParseMode 解析分析模式,空字符串为 calls
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeCalls:
+		return ModeCalls, nil
+	case ModeImports:
+		return ModeImports, nil
+	}
+	return "", i18n.Errorf("不支持的分析模式: %s", s)
+}
+
+// analyzeImports 按导入图分析 res.ChangedFiles 所在的包,不启动 gopls。outOfCone 为
+// 不在稀疏检出范围内而未分析的目录
+func (a *Analyzer) analyzeImports(ctx context.Context, root string, mods []module, idx *parser.Index, outOfCone []string, res *Result) (*Result, error) {
+	logger.Info("导入图模式: 加载导入图")
+	start := time.Now()
+	modules := []string{root}
+	if len(mods) > 0 {
+		modules = modules[:0]
+		for _, mod := range mods {
+			modules = append(modules, mod.Dir)
+			res.Modules = append(res.Modules, mod.Path)
+		}
+	}
+	if res.Module == "" {
+		res.Module = modulePath(root)
+	}
+
+	var graph *parser.ImportGraph
+	var err error
+	if idx != nil {
+		graph = idx.ImportGraph(modules)
+	} else if graph, err = parser.LoadImportGraph(ctx, modules); err != nil {
+		return nil, err
+	}
+	res.observe("load", start)
+	logger.Info("导入图加载完成", "elapsed", time.Since(start))
+
+	opts := analyzer.Options{
+		AllPaths:          a.opts.AllPaths,
+		MaxPathsPerBinary: a.opts.MaxPathsPerBinary,
+		OnAffected:        a.opts.OnAffected,
+		Entrypoints:       a.opts.Entrypoints,
+		Deployments:       a.opts.Deployments,
+		Bazel:             a.opts.Bazel,
+		BazelTargets:      a.opts.BazelTargets,
+		Owners:            a.opts.Owners,
+		Teams:             a.opts.Teams,
+		Granularity:       a.opts.Granularity,
+		MinRisk:           a.opts.MinRisk,
+	}
+	if len(a.opts.Targets) > 0 {
+		var services []parser.Entrypoint
+		for _, mp := range graph.Mains {
+			services = append(services, mainEntrypoint(mp))
+		}
+		if opts.Targets, err = resolveTargets(root, a.opts.Targets, services); err != nil {
+			return nil, err
+		}
+		logger.Info("目标服务", "targets", strings.Join(opts.Targets, ","))
+	}
+	if a.opts.CodeOwners {
+		codeOwners, err := owners.Load(root)
+		if err != nil {
+			return nil, err
+		}
+		if codeOwners != nil {
+			opts.CodeOwners = codeOwners
+		}
+	}
+
+	// 每个变更文件所在的包只分析一次。测试文件不会编译进服务,整个包被删除的目录没有对应的包
+	var changes []analyzer.PackageChange
+	seen := make(map[string]bool)
+	for _, file := range res.ChangedFiles {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		pkgPath := graph.PkgPath(filepath.Join(root, filepath.Dir(file)))
+		if pkgPath == "" || seen[pkgPath] {
+			continue
+		}
+		seen[pkgPath] = true
+		changes = append(changes, analyzer.PackageChange{PkgPath: pkgPath, Chains: graph.Importers(pkgPath)})
+	}
+	res.ChangedSymbols = len(changes)
+	res.Report = *analyzer.ImportImpact(root, changes, opts)
+	res.OutOfCone = outOfCone
+	a.describeRange(root, &res.Report)
+	logger.Info("导入图分析完成", "affected", len(res.Affected))
+	return res, nil
+}
+
+// mainEntrypoint 把 main 包转换为入口,用于匹配目标
+func mainEntrypoint(mp parser.MainPackage) parser.Entrypoint {
+	return parser.Entrypoint{
+		Name:   parser.BinaryName(mp.PkgPath),
+		Symbol: &parser.Symbol{Name: "main", Kind: parser.SymbolKindFunction, PackagePath: mp.PkgPath, Position: token.Position{Filename: mp.File}},
+		Main:   true,
+	}
+}
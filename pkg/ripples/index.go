@@ -0,0 +1,112 @@
+package ripples
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// IndexFile ripples index 默认生成的符号索引文件,相对仓库根目录
+const IndexFile = parser.IndexFile
+
+// IndexStats 生成符号索引的结果
+type IndexStats struct {
+	File     string // 索引文件路径
+	Packages int    // 索引中的包数
+	Symbols  int    // 索引中的顶层符号数
+	Reloaded int    // 重新解析的包数,其余的包沿用已有索引中的内容
+}
+
+// WriteIndex 为 opts.RepoPath 生成符号索引,写入 opts.Index(为空时为仓库根目录下的 IndexFile)。
+// 文件已存在时只重新解析内容哈希变化的包。opts.BuildFlags 和 opts.Env 应与分析时一致
+func WriteIndex(ctx context.Context, opts Options) (*IndexStats, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	if opts.RepoPath == "" {
+		opts.RepoPath = "."
+	}
+	env, err := newBuildEnv(opts.BuildFlags, opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+
+	root, err := filepath.Abs(opts.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	mods, err := discoverModules(root)
+	if err != nil {
+		return nil, err
+	}
+	filename := opts.Index
+	if filename == "" {
+		filename = filepath.Join(root, IndexFile)
+	}
+
+	idx, err := parser.ReadIndex(filename)
+	if err != nil {
+		idx = &parser.Index{}
+	}
+	reloaded, err := idx.Update(ctx, root, moduleDirs(root, mods))
+	if err != nil {
+		return nil, i18n.Errorf("生成索引失败: %w", err)
+	}
+	if err := idx.Write(filename); err != nil {
+		return nil, i18n.Errorf("写入索引失败: %w", err)
+	}
+
+	stats := &IndexStats{File: filename, Packages: indexedPackages(idx), Reloaded: reloaded}
+	for _, pkg := range idx.Packages {
+		stats.Symbols += len(pkg.Symbols)
+	}
+	return stats, nil
+}
+
+// indexedPackages 返回索引中的包数,不计没有 Go 包的目录
+func indexedPackages(idx *parser.Index) int {
+	n := 0
+	for _, pkg := range idx.Packages {
+		if pkg.PkgPath != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// loadIndex 读取 Options.Index 并更新其中内容哈希变化的包,在 res 中记录包数。
+// 未设置或失败时返回 nil,此时分析不使用索引
+func (a *Analyzer) loadIndex(ctx context.Context, root string, mods []module, res *Result) *parser.Index {
+	if a.opts.Index == "" {
+		return nil
+	}
+	idx, err := parser.ReadIndex(a.opts.Index)
+	if err != nil {
+		logger.Warn("读取索引失败,不使用索引", "error", err)
+		return nil
+	}
+	reloaded, err := idx.Update(ctx, root, moduleDirs(root, mods))
+	if err != nil {
+		logger.Warn("更新索引失败,不使用索引", "error", err)
+		return nil
+	}
+	res.IndexPackages, res.IndexReloaded = indexedPackages(idx), reloaded
+	logger.Info("使用符号索引", "file", a.opts.Index, "packages", res.IndexPackages, "reloaded", reloaded)
+	return idx
+}
+
+// moduleDirs 返回各模块的根目录,没有 go.mod 的 GOPATH 项目为 root
+func moduleDirs(root string, mods []module) []string {
+	if len(mods) == 0 {
+		return []string{root}
+	}
+	dirs := make([]string, 0, len(mods))
+	for _, mod := range mods {
+		dirs = append(dirs, mod.Dir)
+	}
+	return dirs
+}
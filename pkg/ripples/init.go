@@ -0,0 +1,156 @@
+package ripples
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// Scaffold ripples init 从仓库结构中检测到的内容,Render 生成初始的配置文件
+type Scaffold = config.Scaffold
+
+// commonPackageNames 常用作公共包的模块顶层目录名
+var commonPackageNames = []string{"pkg", "common", "shared", "lib", "libs", "util", "utils", "foundation", "x"}
+
+// DetectScaffold 扫描仓库,检测生成初始配置文件所需的内容:
+//   - 服务边界: 每个模块中 main 包的上级目录(如 "cmd/*"),以及有子目录的 internal/
+//   - 公共包前缀: 模块顶层名为 pkg/、common/、lib/ 等且含有 Go 文件的目录
+//   - 入口: main 包目录的上级目录模式(相对仓库根目录)
+//   - 含有 .proto 文件的目录,以及是否使用 Bazel 构建
+func DetectScaffold(ctx context.Context, repoPath string) (*Scaffold, error) {
+	if repoPath == "" {
+		repoPath = "."
+	}
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	mods, err := discoverModules(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(mods) == 0 {
+		return nil, i18n.Errorf("%s 中没有 go.mod", root)
+	}
+	bins, err := Binaries(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scaffold{}
+	for _, mod := range mods {
+		s.Modules = append(s.Modules, config.ScaffoldModule{Dir: relSlash(root, mod.Dir), Path: mod.Path})
+		if hasSubdir(filepath.Join(mod.Dir, "internal")) {
+			s.Services = appendUnique(s.Services, "internal/*")
+		}
+		for _, name := range commonPackageNames {
+			if hasGoFiles(filepath.Join(mod.Dir, name)) {
+				s.CommonPackages = appendUnique(s.CommonPackages, name+"/")
+			}
+		}
+	}
+	for _, bin := range bins {
+		s.Binaries = append(s.Binaries, config.ScaffoldBinary{Name: bin.Name, Dir: bin.Dir})
+		s.Entrypoints = appendUnique(s.Entrypoints, dirPattern(bin.Dir))
+		if mod := moduleFor(mods, filepath.Join(root, bin.Dir, "main.go")); mod != nil {
+			// 模块根目录的 main 包不属于任何服务目录
+			if rel := relSlash(mod.Dir, filepath.Join(root, bin.Dir)); rel != "." {
+				s.Services = appendUnique(s.Services, dirPattern(rel))
+			}
+		}
+	}
+	slices.Sort(s.Services)
+	slices.Sort(s.Entrypoints)
+
+	if s.ProtoDirs, err = protoDirs(root); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"WORKSPACE", "WORKSPACE.bazel", "MODULE.bazel"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			s.Bazel = true
+		}
+	}
+	return s, nil
+}
+
+// dirPattern 返回匹配目录及其同级目录的模式: "cmd/api" 为 "cmd/*",顶层目录原样返回
+func dirPattern(dir string) string {
+	if parent := path.Dir(dir); parent != "." {
+		return parent + "/*"
+	}
+	return dir
+}
+
+// protoDirs 返回含有 .proto 文件的目录(相对 root,已排序)
+func protoDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".proto") {
+			dirs = appendUnique(dirs, relSlash(root, filepath.Dir(p)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("扫描仓库失败: %w", err)
+	}
+	slices.Sort(dirs)
+	return dirs, nil
+}
+
+// hasSubdir 判断 dir 是否有子目录
+func hasSubdir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(entries, fs.DirEntry.IsDir)
+}
+
+// hasGoFiles 判断 dir 及其子目录中是否有 Go 文件
+func hasGoFiles(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || found {
+			return filepath.SkipAll
+		}
+		if d.IsDir() && p != dir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		found = !d.IsDir() && strings.HasSuffix(d.Name(), ".go")
+		return nil
+	})
+	return found
+}
+
+// relSlash 返回 target 相对 base 的 "/" 分隔路径
+func relSlash(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// appendUnique 在 list 中没有 s 时追加
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
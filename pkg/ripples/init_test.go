@@ -0,0 +1,54 @@
+package ripples
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/config"
+)
+
+func TestDetectScaffold(t *testing.T) {
+	repo := filepath.Join("..", "..", "testdata", "shared-package-test")
+	s, err := DetectScaffold(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("DetectScaffold failed: %v", err)
+	}
+	if want := []string{"cmd/*", "internal/*"}; !slices.Equal(s.Services, want) {
+		t.Errorf("Services = %v, want %v", s.Services, want)
+	}
+	if want := []string{"pkg/"}; !slices.Equal(s.CommonPackages, want) {
+		t.Errorf("CommonPackages = %v, want %v", s.CommonPackages, want)
+	}
+	if want := []string{"cmd/*"}; !slices.Equal(s.Entrypoints, want) {
+		t.Errorf("Entrypoints = %v, want %v", s.Entrypoints, want)
+	}
+	if len(s.Binaries) != 2 || len(s.Modules) != 1 || s.Modules[0].Dir != "." || len(s.ProtoDirs) != 0 || s.Bazel {
+		t.Errorf("Unexpected scaffold %+v", s)
+	}
+
+	repo = filepath.Join("..", "..", "testdata", "multi-module-test")
+	s, err = DetectScaffold(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("DetectScaffold failed: %v", err)
+	}
+	// 服务边界相对模块根目录,入口相对仓库根目录
+	if want := []string{"cmd/*", "internal/*"}; !slices.Equal(s.Services, want) {
+		t.Errorf("Services = %v, want %v", s.Services, want)
+	}
+	if want := []string{"services/api/cmd/*", "services/worker/cmd/*"}; !slices.Equal(s.Entrypoints, want) {
+		t.Errorf("Entrypoints = %v, want %v", s.Entrypoints, want)
+	}
+	if want := []config.ScaffoldModule{
+		{Dir: "libs/greet", Path: "example.com/greet"},
+		{Dir: "services/api", Path: "example.com/api"},
+		{Dir: "services/worker", Path: "example.com/worker"},
+	}; !slices.Equal(s.Modules, want) {
+		t.Errorf("Modules = %v, want %v", s.Modules, want)
+	}
+
+	if _, err := DetectScaffold(context.Background(), t.TempDir()); err == nil {
+		t.Error("Expected error without go.mod")
+	}
+}
@@ -0,0 +1,38 @@
+package ripples
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// ReadFileList 读取变更文件列表,用于 Options.Files: 每行一个路径,
+// 忽略空行和以 "#" 开头的注释行
+func ReadFileList(r io.Reader) ([]string, error) {
+	var files []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取变更文件列表失败: %w", err)
+	}
+	return files, nil
+}
+
+// ReadSymbolList 读取 JSON 格式的符号列表,用于 Options.Symbols,
+// 如 ["internal/foo.Bar", "internal/foo/bar.go:42"]
+func ReadSymbolList(r io.Reader) ([]string, error) {
+	var symbols []string
+	if err := json.NewDecoder(r).Decode(&symbols); err != nil {
+		return nil, i18n.Errorf("读取符号列表失败: %w", err)
+	}
+	return symbols, nil
+}
@@ -0,0 +1,31 @@
+package ripples
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	files, err := ReadFileList(strings.NewReader("# changed by bazel\ninternal/foo/bar.go\n\n  pkg/db/db.go  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"internal/foo/bar.go", "pkg/db/db.go"}; !slices.Equal(files, want) {
+		t.Errorf("ReadFileList = %v, want %v", files, want)
+	}
+}
+
+func TestReadSymbolList(t *testing.T) {
+	symbols, err := ReadSymbolList(strings.NewReader(`["internal/foo.Bar", "internal/foo/bar.go:42"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"internal/foo.Bar", "internal/foo/bar.go:42"}; !slices.Equal(symbols, want) {
+		t.Errorf("ReadSymbolList = %v, want %v", symbols, want)
+	}
+
+	if _, err := ReadSymbolList(strings.NewReader(`{"symbols": []}`)); err == nil {
+		t.Error("Expected an error for a JSON object")
+	}
+}
@@ -0,0 +1,57 @@
+package ripples
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// resolveJobs 查找配置的任务函数,并关联运行它们的服务。jobs 的键为 main 包目录
+// (相对仓库根目录)或服务名,没有匹配服务的键和找不到的函数只记录警告
+func resolveJobs(ctx context.Context, root string, jobs map[string][]string, services []parser.Entrypoint) []parser.Job {
+	if len(jobs) == 0 {
+		return nil
+	}
+	var functions []string
+	for _, fns := range jobs {
+		for _, fn := range fns {
+			if !slices.Contains(functions, fn) {
+				functions = append(functions, fn)
+			}
+		}
+	}
+	found, err := parser.FindJobs(ctx, root, functions)
+	if err != nil {
+		logger.Warn("查找任务函数失败", "error", err)
+		return nil
+	}
+
+	var res []parser.Job
+	for _, job := range found {
+		for _, key := range slices.Sorted(maps.Keys(jobs)) {
+			if !slices.Contains(jobs[key], job.Name) {
+				continue
+			}
+			key = strings.Trim(filepath.ToSlash(key), "/")
+			matched := false
+			for _, ep := range services {
+				if ep.Name == key || ep.Main && entrypointDir(root, ep) == key {
+					matched = true
+					job.Binaries = append(job.Binaries, ep)
+				}
+			}
+			if !matched {
+				logger.Warn("任务配置的服务不存在", "service", key, "function", job.Name)
+			}
+		}
+		if len(job.Binaries) > 0 {
+			res = append(res, job)
+		}
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package ripples
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+)
+
+// ManifestVersion 清单格式的版本,字段含义变化时递增
+const ManifestVersion = 1
+
+// Manifest 一次分析的输入和结果摘要。构建系统保存报告时一并保存清单,之后可以据此判断
+// 缓存的报告是否对应完全相同的输入: 两次分析的清单除 Result 外都相同时,分析的输入相同。
+// 哈希的写法为 "sha256:" 加十六进制
+type Manifest struct {
+	Version   int    `json:"version"`    // 清单格式的版本,即 ManifestVersion
+	Tool      string `json:"tool"`       // ripples 的版本
+	GoVersion string `json:"go_version"` // 编译 ripples 的 Go 版本
+
+	OldCommit string `json:"old_commit,omitempty"` // 解析后的完整 commit hash,未指定时为空
+	NewCommit string `json:"new_commit,omitempty"`
+
+	Config  string `json:"config_hash,omitempty"` // 配置文件内容的哈希,没有配置文件时为空
+	Options string `json:"options_hash"`          // 影响结果的分析选项(包括 Diff、Files 和 Symbols)的哈希
+
+	Backend  string   `json:"backend"`            // 分析后端: gopls(调用层级)或 imports(导入图)
+	Strategy Strategy `json:"strategy,omitempty"` // 实际使用的追踪方向,导入图模式下为空
+
+	Result string `json:"result_digest"` // JSON 格式报告(-output json 的输出)的哈希
+}
+
+// Manifest 生成 res 的清单,configHash 为配置文件内容的哈希。
+// 分支名等会被解析为完整的 commit hash,因此应在分析之后立即生成
+func (a *Analyzer) Manifest(res *Result, configHash string) (*Manifest, error) {
+	m := &Manifest{
+		Version:   ManifestVersion,
+		Tool:      toolVersion(),
+		GoVersion: runtime.Version(),
+		Config:    configHash,
+		Options:   optionsHash(a.opts),
+		Backend:   "gopls",
+		Strategy:  res.Metadata.Strategy,
+	}
+	if a.opts.Mode == ModeImports {
+		m.Backend = "imports"
+	}
+
+	var err error
+	if a.opts.OldCommit != "" {
+		if m.OldCommit, err = git.ResolveCommit(a.opts.RepoPath, a.opts.OldCommit); err != nil {
+			return nil, err
+		}
+	}
+	if a.opts.NewCommit != "" {
+		if m.NewCommit, err = git.ResolveCommit(a.opts.RepoPath, a.opts.NewCommit); err != nil {
+			return nil, err
+		}
+	}
+	if m.Result, err = ReportDigest(&res.Report); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReportDigest 返回报告的哈希,与对 -output json 输出的文件计算 SHA-256 的结果一致
+func ReportDigest(report *Report) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", i18n.Errorf("生成JSON失败: %w", err)
+	}
+	return digest(append(data, '\n')), nil
+}
+
+// optionsHash 返回影响分析结果的选项的哈希。仓库路径、commit(清单中单独记录解析后的值)、
+// 回调、索引和超时不影响结果,不参与计算
+func optionsHash(opts Options) string {
+	opts.RepoPath, opts.OldCommit, opts.NewCommit = "", "", ""
+	opts.OnAffected = nil
+	opts.Index = ""
+	opts.Timeout = 0
+	// %#v 按键排序输出 map,结果是确定的
+	return digest(fmt.Appendf(nil, "%#v", opts))
+}
+
+// toolVersion 返回 ripples 的版本: 模块版本(go install 安装或根据 VCS 生成的伪版本),
+// 没有版本信息时为 VCS 修订号,有未提交的修改时加 "-dirty"
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return "(devel)"
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// digest 返回 data 的 SHA-256 哈希
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,66 @@
+package ripples
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	opts := Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Mode: ModeImports}
+	a, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	m, err := a.Manifest(res, "sha256:config")
+	if err != nil {
+		t.Fatalf("Manifest failed: %v", err)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "HEAD~1", "HEAD")
+	cmd.Dir = repo
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits := strings.Fields(string(out))
+	if m.OldCommit != commits[0] || m.NewCommit != commits[1] {
+		t.Errorf("Expected resolved commits %v, got %s..%s", commits, m.OldCommit, m.NewCommit)
+	}
+	if m.Version != ManifestVersion || m.Tool == "" || m.Config != "sha256:config" || m.Backend != "imports" {
+		t.Errorf("Unexpected manifest %+v", m)
+	}
+
+	// 与 -output json 输出的文件的哈希一致
+	data, err := json.MarshalIndent(&res.Report, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(append(data, '\n'))
+	if want := "sha256:" + hex.EncodeToString(sum[:]); m.Result != want {
+		t.Errorf("Result digest = %s, want %s", m.Result, want)
+	}
+
+	// 回调和超时不影响结果,服务过滤影响结果
+	same := opts
+	same.OnAffected = func(AffectedBinary) {}
+	same.Timeout = 1
+	if optionsHash(same) != m.Options {
+		t.Error("Expected the callback and timeout to be ignored by the options hash")
+	}
+	other := opts
+	other.Targets = []string{"api"}
+	if optionsHash(other) == m.Options {
+		t.Error("Expected targets to change the options hash")
+	}
+}
@@ -0,0 +1,184 @@
+package ripples
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// module 仓库中的一个 Go 模块
+type module struct {
+	Dir       string // 模块根目录(绝对路径)
+	Path      string // 模块路径
+	GoVersion string // go 指令版本
+}
+
+// skipDir 判断遍历模块时是否跳过目录
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || name == "node_modules" ||
+		(strings.HasPrefix(name, ".") && name != ".") || strings.HasPrefix(name, "_")
+}
+
+// discoverModules 查找仓库中所有的 go.mod,按目录排序
+func discoverModules(root string) ([]module, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []module
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := modfile.ParseLax(path, data, nil)
+		if err != nil || f.Module == nil {
+			logger.Warn("跳过无法解析的 go.mod", "file", path, "error", err)
+			return nil
+		}
+		mod := module{Dir: filepath.Dir(path), Path: f.Module.Mod.Path}
+		if f.Go != nil {
+			mod.GoVersion = f.Go.Version
+		}
+		mods = append(mods, mod)
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("查找模块失败: %w", err)
+	}
+
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Dir < mods[j].Dir })
+	return mods, nil
+}
+
+// moduleFor 返回包含文件的最内层模块
+func moduleFor(mods []module, absFile string) *module {
+	var best *module
+	for i := range mods {
+		dir := mods[i].Dir + string(filepath.Separator)
+		if strings.HasPrefix(absFile, dir) && (best == nil || len(mods[i].Dir) > len(best.Dir)) {
+			best = &mods[i]
+		}
+	}
+	return best
+}
+
+// needsWorkspace 判断是否需要为多模块仓库生成临时 go.work:
+// 仓库根目录没有 go.work,且根目录不是模块(稀疏检出可能只剩下子目录中的模块)或存在
+// 不属于根模块的变更文件;或者 go.work 中的模块不在工作区中
+func needsWorkspace(root string, mods []module, changedFiles []string) bool {
+	if len(mods) == 0 {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(root, "go.work")); err == nil {
+		if missing := missingWorkspaceModules(root); len(missing) > 0 {
+			logger.Warn("go.work 中的模块不在工作区中,只分析已检出的模块", "modules", strings.Join(missing, ","))
+			return true
+		}
+		return false
+	}
+	if !slices.ContainsFunc(mods, func(mod module) bool { return mod.Dir == root }) {
+		return true
+	}
+	if len(mods) < 2 {
+		return false
+	}
+	for _, file := range changedFiles {
+		mod := moduleFor(mods, filepath.Join(root, file))
+		if mod == nil || mod.Dir != root {
+			return true
+		}
+	}
+	return false
+}
+
+// filesInModules 过滤掉不属于任何模块的变更文件
+func filesInModules(root string, mods []module, files []string) []string {
+	var res []string
+	for _, file := range files {
+		if moduleFor(mods, filepath.Join(root, file)) == nil {
+			logger.Debug("变更文件不属于任何模块，跳过", "file", file)
+			continue
+		}
+		res = append(res, file)
+	}
+	return res
+}
+
+// workspace 为多模块仓库生成的临时 go.work
+type workspace struct {
+	envOverrides
+	dir string
+}
+
+// newWorkspace 生成包含所有模块的 go.work,并通过 GOWORK 环境变量让
+// go/packages 和 gopls 以工作区模式加载,使跨模块的调用链可以被追踪。
+// 环境变量是进程级的,调用方需持有 envMu 直到 Close
+func newWorkspace(mods []module) (*workspace, error) {
+	dir, err := os.MkdirTemp("", "ripples-work-")
+	if err != nil {
+		return nil, i18n.Errorf("创建临时工作区失败: %w", err)
+	}
+	w := &workspace{dir: dir}
+
+	goVersion := "1.18"
+	var b strings.Builder
+	b.WriteString("use (\n")
+	for _, mod := range mods {
+		fmt.Fprintf(&b, "\t%s\n", modfile.AutoQuote(mod.Dir))
+		if mod.GoVersion != "" && semver.Compare("v"+mod.GoVersion, "v"+goVersion) > 0 {
+			goVersion = mod.GoVersion
+		}
+	}
+	b.WriteString(")\n")
+
+	content := fmt.Sprintf("go %s\n\n%s", goVersion, b.String())
+	path := filepath.Join(dir, "go.work")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		w.Close()
+		return nil, i18n.Errorf("创建临时工作区失败: %w", err)
+	}
+
+	w.setenv("GOWORK", path)
+	// 工作区模式下 -mod 只能是 readonly 或 vendor
+	if flags := os.Getenv("GOFLAGS"); flags != "" {
+		var kept []string
+		for _, f := range strings.Fields(flags) {
+			if !strings.HasPrefix(f, "-mod=") {
+				kept = append(kept, f)
+			}
+		}
+		w.setenv("GOFLAGS", strings.Join(kept, " "))
+	}
+	return w, nil
+}
+
+// Close 恢复环境变量并删除临时文件
+func (w *workspace) Close() {
+	w.envOverrides.Close()
+	os.RemoveAll(w.dir)
+}
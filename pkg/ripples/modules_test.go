@@ -0,0 +1,82 @@
+package ripples
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverModules(t *testing.T) {
+	root, _ := filepath.Abs(filepath.Join("..", "..", "testdata", "multi-module-test"))
+
+	mods, err := discoverModules(root)
+	if err != nil {
+		t.Fatalf("discoverModules failed: %v", err)
+	}
+
+	var paths []string
+	for _, m := range mods {
+		paths = append(paths, m.Path)
+	}
+	want := []string{"example.com/greet", "example.com/api", "example.com/worker"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected modules %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("Expected modules %v, got %v", want, paths)
+		}
+	}
+
+	mod := moduleFor(mods, filepath.Join(root, "services", "worker", "internal", "job", "job.go"))
+	if mod == nil || mod.Path != "example.com/worker" {
+		t.Errorf("Unexpected module for job.go: %+v", mod)
+	}
+	if moduleFor(mods, filepath.Join(root, "README.md")) != nil {
+		t.Error("Expected no module for a file at the root")
+	}
+
+	if !needsWorkspace(root, mods, []string{"libs/greet/greet.go"}) {
+		t.Error("Expected a workspace for changes outside the root module")
+	}
+}
+
+func TestNeedsWorkspaceSingleModule(t *testing.T) {
+	root, _ := filepath.Abs(filepath.Join("..", "..", "testdata", "shared-package-test"))
+
+	mods, err := discoverModules(root)
+	if err != nil {
+		t.Fatalf("discoverModules failed: %v", err)
+	}
+	if needsWorkspace(root, mods, []string{"pkg/common/logger.go"}) {
+		t.Error("Expected no workspace for a single-module repository")
+	}
+}
+
+func TestNeedsWorkspaceMissingModules(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.work":  "go 1.21\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod": "module example.com/a\n\ngo 1.21\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := missingWorkspaceModules(root); len(got) != 1 || got[0] != "./b" {
+		t.Errorf("Expected ./b to be missing, got %v", got)
+	}
+	mods, err := discoverModules(root)
+	if err != nil {
+		t.Fatalf("discoverModules failed: %v", err)
+	}
+	if !needsWorkspace(root, mods, []string{"a/a.go"}) {
+		t.Error("Expected a workspace when go.work lists modules that are not checked out")
+	}
+}
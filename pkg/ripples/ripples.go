@@ -0,0 +1,616 @@
+// Package ripples 提供可嵌入的变更影响分析 API。
+//
+// 给定仓库中的两个 commit,Analyzer 找出变更的符号,并沿调用链追踪到
+// 所有受影响的 main 包(服务):
+//
+//	a, err := ripples.New(ripples.Options{
+//		RepoPath:  ".",
+//		OldCommit: "origin/main",
+//		NewCommit: "HEAD",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	res, err := a.Analyze(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	for _, svc := range res.Affected {
+//		fmt.Println(svc.Name)
+//	}
+//
+// 设置 Options.Symbols 或 Options.Files 时跳过 git,直接从指定的符号或变更文件开始追踪。
+//
+// 分析期间会修改进程的环境变量,同一进程中的多次分析依次执行,见 Analyzer。
+//
+// 日志通过 log/slog 输出到 stderr,默认只输出警告及以上级别。底层的 gopls 追踪器直接向 stdout
+// 打印的警告在分析期间同样转为日志,不会混入调用方的输出。
+package ripples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/owners"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// Report 分析报告: 受影响的服务、每个变更符号的指标和汇总的影响范围
+type Report = analyzer.Report
+
+// AffectedBinary 受影响的服务(main 包)
+type AffectedBinary = analyzer.AffectedBinary
+
+// ChangeMetrics 单个变更符号的影响范围
+type ChangeMetrics = analyzer.ChangeMetrics
+
+// BlastRadius 所有变更符号的汇总影响范围
+type BlastRadius = analyzer.BlastRadius
+
+// Deployment 服务的部署标识
+type Deployment = analyzer.Deployment
+
+// Route 服务中受影响的 HTTP 路由或 gRPC 方法
+type Route = analyzer.Route
+
+// Coverage 变更代码的语句数和被测试覆盖的语句数
+type Coverage = analyzer.Coverage
+
+// CoveringTest 覆盖率文件执行过变更行的测试
+type CoveringTest = analyzer.CoveringTest
+
+// Evidence 服务的一条调用链上每个调用点的位置,以及引起变更符号的 diff hunk
+type Evidence = analyzer.Evidence
+
+// CallSite 调用链中调用者引用被调用者的位置
+type CallSite = analyzer.CallSite
+
+// InterfaceFilter 跨服务过滤对接口调用的处理方式
+type InterfaceFilter = analyzer.InterfaceFilter
+
+// 接口过滤模式
+const (
+	InterfaceFilterStrict = analyzer.InterfaceFilterStrict
+	InterfaceFilterLoose  = analyzer.InterfaceFilterLoose
+	InterfaceFilterOff    = analyzer.InterfaceFilterOff
+)
+
+// ParseInterfaceFilter 解析接口过滤模式,空字符串为 strict
+func ParseInterfaceFilter(s string) (InterfaceFilter, error) {
+	return analyzer.ParseInterfaceFilter(s)
+}
+
+// Strategy 调用链的追踪方向
+type Strategy = analyzer.Strategy
+
+// 追踪策略
+const (
+	StrategyReverse = analyzer.StrategyReverse
+	StrategyForward = analyzer.StrategyForward
+	StrategyAuto    = analyzer.StrategyAuto
+)
+
+// ParseStrategy 解析追踪策略,空字符串为 reverse
+func ParseStrategy(s string) (Strategy, error) {
+	return analyzer.ParseStrategy(s)
+}
+
+// Granularity 报告粒度
+type Granularity = analyzer.Granularity
+
+// 报告粒度
+const (
+	GranularityBinary  = analyzer.GranularityBinary
+	GranularityPackage = analyzer.GranularityPackage
+)
+
+// ParseGranularity 解析报告粒度,空字符串为 binary
+func ParseGranularity(s string) (Granularity, error) {
+	return analyzer.ParseGranularity(s)
+}
+
+// Confidence 结果可信度
+type Confidence = analyzer.Confidence
+
+// 可信度取值
+const (
+	ConfidenceHigh   = analyzer.ConfidenceHigh
+	ConfidenceMedium = analyzer.ConfidenceMedium
+	ConfidenceLow    = analyzer.ConfidenceLow
+)
+
+// Options 分析选项
+type Options struct {
+	RepoPath  string // Git 仓库路径,默认当前目录
+	OldCommit string // 旧 commit ID 或分支名(未设置 Symbols、Files 和 Diff 时必填)
+	NewCommit string // 新 commit ID 或分支名(未设置 Symbols、Files 和 Diff 时必填)
+
+	// Diff 预先生成的统一 diff(如 git diff A B 的输出,可以事先过滤或拼接),设置后不再调用 git diff。
+	// diff 的新版本应与 RepoPath 中的代码一致。同时指定 OldCommit 和 NewCommit 时用于读取常量
+	// 变更前后的值;不检查接口实现是否被破坏
+	Diff []byte
+
+	// Symbols 直接指定的追踪起点,设置后不再读取 git diff。
+	// 写法为 "internal/foo.Bar"、"internal/foo.(*Server).Start"(包可以是导入路径或
+	// 相对仓库根目录的目录)或 "internal/foo/bar.go:42"
+	Symbols []string
+	// Files 直接指定的变更文件(相对仓库根目录或绝对路径),设置后不再读取 git diff,
+	// 文件中的所有顶层符号都视为变更。适合 Bazel 等已经知道变更内容的构建系统;
+	// 非 Go 文件和已删除的文件会被忽略,Exclude、Only 等过滤规则仍然生效。可以与 Symbols 同时使用
+	Files []string
+
+	// AllPaths 报告每个服务的所有不同调用链,默认只保留第一条
+	AllPaths bool
+	// MaxPathsPerBinary 每个服务最多保留的调用链数量,大于 0 时隐含 AllPaths
+	MaxPathsPerBinary int
+
+	// OnAffected 在发现新的受影响服务时立即回调(每个服务一次,串行调用),
+	// 无需等待全部符号追踪完成。回调中的服务只包含首条调用链,
+	// 可信度以最终 Result 为准
+	OnAffected func(AffectedBinary)
+
+	// Exclude 排除的文件路径模式(相对仓库根目录,支持 "**"),匹配的文件不参与变更检测
+	Exclude []string
+	// Only 只分析匹配这些模式的文件(写法同 Exclude),为空时不限制。同时匹配 Exclude 的文件仍被排除
+	Only []string
+	// IncludeGenerated 分析带有 "// Code generated ... DO NOT EDIT." 头的生成文件,
+	// 默认跳过。Symbols 直接指定的符号不受影响
+	IncludeGenerated bool
+	// Entrypoints 作为服务入口的 main 包目录模式,为空时不限制
+	Entrypoints []string
+	// Targets 只分析这些服务: main 包目录模式(如 "cmd/api")或服务名。其余服务不出现在
+	// 报告中,设置 BinariesOnly 时所有目标都受影响后不再追踪剩余的变更符号(ChangeMetrics.Skipped
+	// 为 "targets_resolved")。为空时不限制
+	Targets []string
+	// BinariesOnly 调用方只使用受影响服务的列表: 所有目标服务(未指定 Targets 时为所有服务)都受影响后
+	// 不再追踪剩余的变更符号,被跳过的符号没有调用链、风险、置信度等按变更计算的结果。Routes、Commands、
+	// Jobs、Explain、CoverProfile、Snippets、MinRisk 需要每个符号的结果,设置它们时仍然追踪所有符号
+	BinariesOnly bool
+	// EntrypointFunctions main 之外作为服务入口的函数,键为服务名,值为 "包目录.函数" 或
+	// "包目录.接收者.方法"(包目录相对仓库根目录)。带有 "//ripples:entrypoint name=xxx"
+	// 注释的函数总是作为入口
+	EntrypointFunctions map[string]string
+	// Jobs 服务内作为调用链根的函数,如定时任务,键为 main 包目录或服务名,值为 "包目录.函数" 或
+	// "包目录.接收者.方法"。服务的 AffectedBinary.Jobs 列出使用变更符号的任务
+	Jobs map[string][]string
+	// Routes 报告每个服务经由哪些 HTTP 路由(net/http、gin、echo、chi)和 gRPC 方法
+	// (Register*Server)受到影响,只识别字符串字面量写法的路由
+	Routes bool
+	// Commands 报告每个服务经由哪些 cobra(Run、RunE 等)或 urfave/cli(Action 等)子命令受到影响,
+	// 子命令写作 "db migrate",不含作为服务本身的根命令
+	Commands bool
+	// Services 服务边界规则,如 "cmd/*"、"internal/*",通过接口调用或函数值进入另一个服务的调用链会被过滤
+	Services []string
+	// CommonPackages 公共包前缀(相对模块根目录),不属于任何服务。
+	// Services 和 CommonPackages 只设置其一时,另一项使用与 gopls 追踪器一致的默认值
+	CommonPackages []string
+	// DisableCrossServiceFilter 关闭基于 Services/CommonPackages 的跨服务过滤,等同于 InterfaceFilterOff
+	DisableCrossServiceFilter bool
+	// InterfaceFilter 跨服务过滤的严格程度: strict(默认)把无法类型检查的调用视为接口调用,
+	// loose 只过滤确定经由接口调用或函数值进入另一个服务的调用链,off 不过滤。
+	// gopls 追踪器内置的过滤不受影响。实际生效的模式记录在 Report.Metadata 中
+	InterfaceFilter InterfaceFilter
+	// Deployments 服务到部署标识的映射,键为 main 包目录(如 "cmd/api-server")或服务名
+	Deployments map[string]Deployment
+	// Bazel 在 AffectedBinary.BazelTarget 中标注构建每个服务的 Bazel 目标: 优先使用 BazelTargets,
+	// 否则读取 main 包目录下 BUILD.bazel(或 BUILD)文件中的 go_binary 规则
+	Bazel bool
+	// BazelTargets 服务到 Bazel 目标(如 "//cmd/api:api")的映射,键为 main 包目录或服务名
+	BazelTargets map[string]string
+	// Owners 服务到负责团队的映射,键为 main 包目录或服务名,优先于 CODEOWNERS
+	Owners map[string][]string
+	// Teams 团队到服务的映射,值为 main 包目录模式(如 "cmd/billing-*")或服务名。服务属于按名称排序后
+	// 第一个匹配的团队,记录在 AffectedBinary.Team 中,用于按团队分组和拆分报告
+	Teams map[string][]string
+	// CodeOwners 根据仓库中的 CODEOWNERS 文件标注服务 main 文件的负责人
+	CodeOwners bool
+	// MaxFanOut 变更符号向上前几层调用者中,任一层的函数数超过该值时(如到处使用的日志函数)
+	// 不再展开调用层级,改为报告导入其所在包的所有服务,结果标记为近似(Approximate)。
+	// 0 表示不限制
+	MaxFanOut int
+	// MinRisk 只在 Affected 中保留风险分数(AffectedBinary.Risk,1-100)不低于该值的服务,
+	// 0 表示不过滤。分数在分析结束时计算,OnAffected 回调的服务不受影响
+	MinRisk int
+	// CoverProfile "go test -coverprofile" 生成的覆盖率文件路径。设置后报告每个变更符号的变更行和每个服务
+	// 受到影响的变更代码被测试覆盖的语句数(Coverage),未被覆盖的变更在风险分数中权重更高。
+	// 导入图模式下不生效
+	CoverProfile string
+	// CoverDir 每个测试(或测试包)一个覆盖率文件的目录,设置后在 Report.Tests 中报告执行过变更行的测试。
+	// 文件相对该目录、不含扩展名的路径为测试名,写作 "包目录/TestXxx" 时可以单独运行该测试。
+	// 导入图模式下不生效
+	CoverDir string
+	// Explain 在每个服务的 Evidence 中说明报告的每条调用链: 每一步调用所在的文件和行号,
+	// 以及变更符号所在的 diff hunk。导入图模式下不生效
+	Explain bool
+	// Snippets 在每个变更符号的 Snippet 中附上新版本中的变更行及其前后几行代码。导入图模式下不生效
+	Snippets bool
+	// Blame 在每个变更符号的 Blame 中标注新版本里最后修改其变更行的提交(git blame)。
+	// 只在变更来自 OldCommit 和 NewCommit 的 diff 时生效
+	Blame bool
+	// Strategy 追踪方向: reverse(默认)从变更符号沿调用者向上追踪到 main;forward 从每个 main
+	// 函数沿调用图向下查找变更符号,被大量使用的符号更快;auto 在某个变更符号扇出较大时使用 forward
+	Strategy Strategy
+	// Granularity 为 GranularityPackage 时,除服务外还在 Report.Packages 中报告调用链经过的包,
+	// 限定在变更包的反向依赖闭包中(只通过接口调用到变更包的包不依赖它,不报告)。
+	// 此时不再在所有服务都受影响后提前结束追踪
+	Granularity Granularity
+	// Mode 分析模式,为空时为 ModeCalls。ModeImports 不启动 gopls,只按导入图报告服务,
+	// 此时 ChangedSymbols 为变更包的数量,服务边界、路由、子命令、自定义入口和扇出上限不生效
+	Mode Mode
+	// BuildFlags 追加到 GOFLAGS 的构建参数(如 "-mod=vendor"、"-tags=integration"),
+	// 使 go/packages 和 gopls 与 CI 的实际构建使用相同的配置
+	BuildFlags []string
+	// Env 分析期间设置的环境变量,写法为 "KEY=VALUE"(如 "GOFLAGS=-mod=vendor"、"CGO_ENABLED=0"),
+	// 先于 BuildFlags 生效。与工作区一样修改的是进程级环境变量,分析结束后恢复
+	Env []string
+	// Index ripples index 生成的符号索引文件(见 WriteIndex),为空时不使用。内容哈希没有变化的包
+	// 直接使用索引中的导入关系、符号和 main 函数,不再列出和解析整个仓库;读取失败时不使用索引
+	Index string
+	// Timeout 分析超时时间,0 表示不限制。超时后不再开始新的追踪,正在进行的追踪结束后返回错误
+	Timeout time.Duration
+	// Budget 分析的时间预算,0 表示不限制。与 Timeout 不同,预算耗尽时不会失败: 剩余的变更符号
+	// 不再追踪,耗尽时仍在追踪的符号等待其结束后同样丢弃(ChangeMetrics.Skipped 为 "budget_exceeded"),
+	// 返回已经找到的受影响服务,Report.Incomplete 为 true。只作用于调用链追踪,不影响 imports 模式
+	Budget time.Duration
+}
+
+// Phase 分析阶段耗时
+type Phase struct {
+	Name     string        // diff(指定 Symbols 或 Files 时没有), load, tracer_init, detect, trace, interface_check(指定 Symbols、Files 或 Diff 时没有)
+	Duration time.Duration // 耗时
+}
+
+// Result 分析结果
+type Result struct {
+	Report
+
+	Module         string   `json:"-"` // 仓库的 Go 模块路径
+	Modules        []string `json:"-"` // 多模块仓库中参与分析的所有模块路径
+	ChangedFiles   []string `json:"-"` // 变更的 Go 文件(相对仓库根目录)
+	ChangedSymbols int      `json:"-"` // 检测到的变更符号数
+	Phases         []Phase  `json:"-"` // 各阶段耗时,按执行顺序
+
+	Requests      []RequestStats `json:"-"` // 按方法汇总的 gopls 请求,导入图模式下为空
+	IndexPackages int            `json:"-"` // 使用的符号索引中的包数,未使用索引时为 0
+	IndexReloaded int            `json:"-"` // 符号索引中内容变化、重新解析的包数
+}
+
+// Analyzer 变更影响分析器。
+//
+// Analyze 期间会修改进程级的环境变量(Options.Env、Options.BuildFlags 追加到的 GOFLAGS,
+// 多模块仓库的 GOWORK,GOPATH 布局的 GO111MODULE)和 os.Stdout,返回前恢复。
+// go/packages 和 gopls 子进程只能通过进程环境获得这些配置,因此同一进程中的多次 Analyze
+// (以及 Graph、WriteIndex)会依次执行;调用方的其他 goroutine 在此期间读取环境变量或
+// 写 stdout 会受到影响
+type Analyzer struct {
+	opts Options
+}
+
+// New 创建分析器
+func New(opts Options) (*Analyzer, error) {
+	if len(opts.Symbols) == 0 && len(opts.Files) == 0 && opts.Diff == nil && (opts.OldCommit == "" || opts.NewCommit == "") {
+		return nil, i18n.Errorf("必须指定旧 commit 和新 commit")
+	}
+	if opts.RepoPath == "" {
+		opts.RepoPath = "."
+	}
+	return &Analyzer{opts: opts}, nil
+}
+
+// Analyze 执行一次完整的影响分析
+func (a *Analyzer) Analyze(ctx context.Context) (*Result, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+	// gopls 追踪器会直接向 stdout 打印警告,分析期间将其转为日志
+	defer logger.RedirectStdout()()
+
+	repoPath := a.opts.RepoPath
+	res := &Result{}
+
+	if a.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.opts.Timeout)
+		defer cancel()
+	}
+	var deadline time.Time
+	if a.opts.Budget > 0 {
+		deadline = time.Now().Add(a.opts.Budget)
+	}
+
+	env, err := newBuildEnv(a.opts.BuildFlags, a.opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+
+	mods, err := discoverModules(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := a.newFileFilter(root)
+	if err != nil {
+		return nil, err
+	}
+	idx := a.loadIndex(ctx, root, mods, res)
+
+	// 1. 获取变更文件列表（用于优化 Parser 加载）
+	var specs []analyzer.SymbolSpec
+	var diffContent []byte
+	direct := len(a.opts.Symbols) > 0 || len(a.opts.Files) > 0
+	start := time.Now()
+	if direct {
+		// 直接指定符号或变更文件时跳过 git，只加载它们所在的包
+		logger.Info("步骤 1/6: 解析指定符号")
+		files, err := changedGoFiles(root, a.opts.Files)
+		if err != nil {
+			return nil, err
+		}
+		if specs, res.ChangedFiles, err = resolveSpecs(root, mods, idx, a.opts.Symbols, filter.files(files)); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.Info("步骤 1/6: 检测变更文件")
+		diffContent = a.opts.Diff
+		if diffContent == nil {
+			if diffContent, err = analyzer.GetGitDiffContent(repoPath, a.opts.OldCommit, a.opts.NewCommit); err != nil {
+				return nil, i18n.Errorf("获取 git diff 失败: %w", err)
+			}
+		}
+		res.ChangedFiles = filter.files(analyzer.ExtractChangedGoFiles(diffContent))
+		res.observe("diff", start)
+	}
+	logger.Info("检测到变更文件", "count", len(res.ChangedFiles), "elapsed", time.Since(start))
+	var outOfCone []string
+	res.ChangedFiles, outOfCone = excludeOutOfCone(root, res.ChangedFiles)
+
+	// 没有 go.mod 的 GOPATH 模式项目: 导入路径来自 GOPATH 布局
+	var gopathPath string
+	if len(mods) == 0 {
+		if gopathPath = gopathImportPath(root); gopathPath != "" {
+			env := newGOPATHMode()
+			defer env.Close()
+			res.Module = gopathPath
+			logger.Info("GOPATH 模式项目", "import_path", gopathPath)
+		}
+	}
+
+	if a.opts.Mode == ModeImports {
+		return a.analyzeImports(ctx, root, mods, idx, outOfCone, res)
+	}
+
+	// 多模块仓库: 变更不在根模块中时，用临时 go.work 将所有模块加入同一工作区
+	var moduleDirs []string
+	if needsWorkspace(root, mods, res.ChangedFiles) {
+		ws, err := newWorkspace(mods)
+		if err != nil {
+			return nil, err
+		}
+		defer ws.Close()
+
+		res.ChangedFiles = filesInModules(root, mods, res.ChangedFiles)
+		for _, mod := range mods {
+			res.Modules = append(res.Modules, mod.Path)
+			moduleDirs = append(moduleDirs, mod.Dir)
+		}
+		logger.Info("多模块仓库，使用临时工作区", "modules", len(mods))
+	}
+
+	// 2. 初始化 Parser（只加载变更文件相关的包）
+	logger.Info("步骤 2/6: 初始化 Parser (只加载变更包)")
+	start = time.Now()
+	p := parser.NewParser()
+	p.SetModules(moduleDirs)
+	p.SetIndex(idx)
+	if err := p.LoadChangedFiles(repoPath, res.ChangedFiles); err != nil {
+		return nil, i18n.Errorf("加载变更包失败: %w", err)
+	}
+	res.observe("load", start)
+	logger.Info("Parser 初始化完成", "elapsed", time.Since(start))
+
+	// 获取当前模块名
+	if gopathPath == "" {
+		res.Module = modulePath(repoPath)
+	}
+	if res.Module == "" {
+		pkgs := p.GetPackages()
+		if len(pkgs) > 0 && pkgs[0].Module != nil {
+			res.Module = pkgs[0].Module.Path
+		}
+	}
+	logger.Info("当前模块", "module", res.Module)
+	modules := res.Modules
+	if len(modules) == 0 && res.Module != "" {
+		modules = []string{res.Module}
+	}
+
+	entrypoints, err := parser.FindEntrypoints(ctx, repoPath, a.opts.EntrypointFunctions)
+	if err != nil {
+		logger.Warn("查找自定义入口失败", "error", err)
+	}
+	var mains []parser.Entrypoint
+	if idx != nil {
+		mains = idx.Mains()
+	} else if mains, err = parser.FindMains(ctx, repoPath); err != nil {
+		logger.Warn("查找 main 包失败", "error", err)
+	}
+	services := slices.Concat(mains, entrypoints)
+	// gopls 追踪器只认 cmd/ 下或名为 main 的目录中的 main 函数,其余的 main 包按入口追踪
+	for _, ep := range mains {
+		if !parser.TracedMain(filepath.Dir(ep.Symbol.Position.Filename)) {
+			entrypoints = append(entrypoints, ep)
+		}
+	}
+
+	jobs := resolveJobs(ctx, root, a.opts.Jobs, services)
+
+	var targets []string
+	if len(a.opts.Targets) > 0 {
+		if targets, err = resolveTargets(root, a.opts.Targets, services); err != nil {
+			return nil, err
+		}
+		logger.Info("目标服务", "targets", strings.Join(targets, ","))
+	}
+
+	var codeOwners *owners.Resolver
+	if a.opts.CodeOwners {
+		if codeOwners, err = owners.Load(repoPath); err != nil {
+			return nil, err
+		}
+	}
+
+	var coverage analyzer.CoverProfile
+	if a.opts.CoverProfile != "" {
+		if coverage, err = analyzer.LoadCoverProfile(a.opts.CoverProfile); err != nil {
+			return nil, i18n.Errorf("读取覆盖率文件失败: %w", err)
+		}
+	}
+	var testProfiles map[string]analyzer.CoverProfile
+	if a.opts.CoverDir != "" {
+		if testProfiles, err = analyzer.LoadCoverDir(a.opts.CoverDir); err != nil {
+			return nil, i18n.Errorf("读取覆盖率目录失败: %w", err)
+		}
+	}
+
+	// 3. 初始化 LSP Impact Analyzer
+	logger.Info("步骤 3/6: 初始化 LSP 分析器 (gopls)")
+	start = time.Now()
+	// 追踪器的 context 只在初始化期间随 ctx 取消。gopls 中止的追踪不返回错误,而是把不完整的
+	// 调用链写入追踪缓存(包括磁盘上的持久缓存),因此之后超时和预算只阻止开始新的追踪
+	tracerCtx, cancelTracer := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelTracer()
+	stopInit := context.AfterFunc(ctx, cancelTracer)
+	lspAnalyzer, err := analyzer.NewLSPImpactAnalyzer(tracerCtx, repoPath)
+	stopInit()
+	if err != nil {
+		return nil, i18n.Errorf("初始化 LSP 分析器失败: %w", err)
+	}
+	defer lspAnalyzer.Close()
+	lspAnalyzer.SetPackages(p.GetPackages())
+	analyzerOpts := analyzer.Options{
+		AllPaths:          a.opts.AllPaths,
+		MaxPathsPerBinary: a.opts.MaxPathsPerBinary,
+		MaxFanOut:         a.opts.MaxFanOut,
+		MinRisk:           a.opts.MinRisk,
+		Explain:           a.opts.Explain,
+		Snippets:          a.opts.Snippets,
+		Coverage:          coverage,
+		Strategy:          a.opts.Strategy,
+		OnAffected:        a.opts.OnAffected,
+		Modules:           modules,
+		Entrypoints:       a.opts.Entrypoints,
+		CustomEntrypoints: entrypoints,
+		Jobs:              jobs,
+		Targets:           targets,
+		Binaries:          serviceNames(root, services, a.opts.Entrypoints),
+		BinariesOnly:      a.opts.BinariesOnly,
+		Routes:            a.opts.Routes,
+		Commands:          a.opts.Commands,
+		Services:          a.opts.Services,
+		CommonPackages:    a.opts.CommonPackages,
+
+		DisableCrossServiceFilter: a.opts.DisableCrossServiceFilter,
+		InterfaceFilter:           a.opts.InterfaceFilter,
+		Deployments:               a.opts.Deployments,
+		Bazel:                     a.opts.Bazel,
+		BazelTargets:              a.opts.BazelTargets,
+		Owners:                    a.opts.Owners,
+		Teams:                     a.opts.Teams,
+		Granularity:               a.opts.Granularity,
+		ReverseDeps:               p.ReverseDeps(),
+		Deadline:                  deadline,
+		Done:                      ctx.Done(),
+	}
+	if codeOwners != nil {
+		analyzerOpts.CodeOwners = codeOwners
+	}
+	lspAnalyzer.SetOptions(analyzerOpts)
+	res.observe("tracer_init", start)
+	logger.Info("LSP 分析器初始化完成", "elapsed", time.Since(start))
+
+	// 4. 检测变更符号
+	logger.Info("步骤 4/6: 检测变更符号")
+	start = time.Now()
+	cd := analyzer.NewChangeDetector(p, repoPath)
+	var changes []analyzer.ChangedSymbol
+	if direct {
+		if changes, err = cd.ResolveSymbols(specs); err != nil {
+			return nil, err
+		}
+	} else {
+		if changes, err = cd.DetectDiffChanges(diffContent, a.opts.OldCommit, a.opts.NewCommit); err != nil {
+			return nil, i18n.Errorf("检测变更失败: %w", err)
+		}
+		changes = filter.changes(changes)
+	}
+	res.ChangedSymbols = len(changes)
+	res.observe("detect", start)
+	logger.Info("检测到变更符号", "count", len(changes), "elapsed", time.Since(start))
+
+	// 5. 分析影响
+	logger.Info("步骤 5/6: 追踪调用链到 main 函数")
+	start = time.Now()
+	report, err := lspAnalyzer.Analyze(changes)
+	if err != nil {
+		return nil, i18n.Errorf("追踪调用链失败: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, i18n.Errorf("分析超时 (%s): %w", a.opts.Timeout, err)
+	}
+	res.Report = *report
+	res.FailedPackages = failedPackages(p.FailedPackages())
+	res.OutOfCone = outOfCone
+	if testProfiles != nil {
+		res.Tests = analyzer.CoveringTests(testProfiles, changes)
+	}
+	res.observe("trace", start)
+	logger.Info("调用链追踪完成", "elapsed", time.Since(start), "affected", len(report.Affected))
+
+	// 方法被删除或签名变化时,检查旧代码中依赖该方法满足接口的位置
+	if !direct && a.opts.Diff == nil && !deadline.IsZero() && time.Now().After(deadline) {
+		logger.Warn("时间预算耗尽,跳过接口实现检查")
+	} else if !direct && a.opts.Diff == nil {
+		start = time.Now()
+		breakage, err := a.interfaceBreakage(ctx, cd)
+		if err != nil {
+			logger.Warn("检查接口实现失败", "error", err)
+		}
+		res.InterfaceBreakage = breakage
+		res.observe("interface_check", start)
+	}
+	res.Requests = lspAnalyzer.RequestStats()
+	a.describeRange(root, &res.Report)
+
+	return res, nil
+}
+
+// observe 记录阶段耗时
+func (r *Result) observe(phase string, start time.Time) {
+	r.Phases = append(r.Phases, Phase{Name: phase, Duration: time.Since(start)})
+}
+
+// modulePath 从 go.mod 文件获取模块路径
+func modulePath(repoPath string) string {
+	content, err := os.ReadFile(filepath.Join(repoPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		}
+	}
+	return ""
+}
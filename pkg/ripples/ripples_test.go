@@ -0,0 +1,1686 @@
+package ripples
+
+import (
+	"context"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupRepo 将 testdata 下的测试项目复制到临时 git 仓库,提交后修改 file 中的
+// old 为 new 并再次提交
+func setupRepo(t *testing.T, project, file, old, new string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	src := filepath.Join("..", "..", "testdata", project)
+	dir := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("Failed to copy test project: %v", err)
+	}
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	path := filepath.Join(dir, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", file, err)
+	}
+	content := strings.Replace(string(data), old, new, 1)
+	if content == string(data) {
+		t.Fatalf("Failed to modify %s", file)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file, err)
+	}
+	git("commit", "-q", "-am", "change")
+
+	return dir
+}
+
+// setupSharedRepo 修改 shared-package-test 中 LogMessage 的函数体
+func setupSharedRepo(t *testing.T) string {
+	return setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"func LogMessage(message string) {\n", "func LogMessage(message string) {\n\t_ = len(message)\n")
+}
+
+func TestNewRequiresCommits(t *testing.T) {
+	if _, err := New(Options{OldCommit: "HEAD~1"}); err == nil {
+		t.Error("Expected error when NewCommit is missing")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if res.Module != "example.com/shared-package-test" {
+		t.Errorf("Unexpected module %q", res.Module)
+	}
+	if len(res.ChangedFiles) != 1 || res.ChangedSymbols != 1 {
+		t.Errorf("Expected 1 changed file and symbol, got %v and %d", res.ChangedFiles, res.ChangedSymbols)
+	}
+	if len(res.Phases) != 6 || res.Phases[5].Name != "interface_check" {
+		t.Errorf("Expected 6 phases ending with interface_check, got %v", res.Phases)
+	}
+	timings := res.Timings(time.Second)
+	if timings.Total != 1000 || len(timings.Phases) != 6 || timings.SymbolsTraced != 1 || timings.Cache.TraceRequests == 0 {
+		t.Errorf("Unexpected timings %+v", timings)
+	}
+	for _, req := range timings.Requests {
+		if req.Count == 0 || req.Max > req.Total || req.Mean > req.Max {
+			t.Errorf("Unexpected request timing %+v", req)
+		}
+	}
+
+	affected := make(map[string]bool)
+	for _, b := range res.Affected {
+		affected[b.Name] = true
+	}
+	for _, want := range []string{"service-a", "service-b"} {
+		if !affected[want] {
+			t.Errorf("Expected %s to be affected, got %v", want, res.Affected)
+		}
+	}
+}
+
+func TestAnalyzeDiff(t *testing.T) {
+	repo := setupSharedRepo(t)
+	cmd := exec.Command("git", "diff", "HEAD~1", "HEAD")
+	cmd.Dir = repo
+	diff, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git diff failed: %v", err)
+	}
+
+	// 只有 diff,没有 commit
+	a, err := New(Options{RepoPath: repo, Diff: diff})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if !slices.Equal(res.ChangedFiles, []string{"pkg/common/logger.go"}) || res.ChangedSymbols != 1 {
+		t.Errorf("Expected LogMessage to be changed, got %v and %d", res.ChangedFiles, res.ChangedSymbols)
+	}
+	var got []string
+	for _, b := range res.Affected {
+		got = append(got, b.Name)
+	}
+	sort.Strings(got)
+	if !slices.Equal(got, []string{"service-a", "service-b"}) {
+		t.Errorf("Expected service-a and service-b, got %v", got)
+	}
+	for _, p := range res.Phases {
+		if p.Name == "interface_check" {
+			t.Error("Interface breakage needs the old commit and should be skipped")
+		}
+	}
+}
+
+func TestAnalyzeChangeTypes(t *testing.T) {
+	// 修改 LogMessage,用 LogTwice 替换 LogMessageWithPrefix
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"\tfmt.Printf(\"[COMMON] %s\\n\", message)\n}\n\n// LogMessageWithPrefix calls LogMessage internally\nfunc LogMessageWithPrefix(prefix, message string) {\n\tLogMessage(fmt.Sprintf(\"[%s] %s\", prefix, message))\n}\n",
+		"\tfmt.Printf(\"[COMMON] %s\\n\", message)\n\t_ = len(message)\n}\n\n// LogTwice logs a message twice\nfunc LogTwice(message string) {\n\tLogMessage(message)\n\tLogMessage(message)\n}\n")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, c := range res.Changes {
+		got[c.Symbol] = c.ChangeType
+		if c.ChangeType == "DELETE" && c.Skipped != "deleted" {
+			t.Errorf("Expected deleted symbol %s to be skipped, got %+v", c.Symbol, c)
+		}
+		if c.ChangeType == "MODIFY" && c.Modification != "body" {
+			t.Errorf("Expected %s to have a modified body, got %q", c.Symbol, c.Modification)
+		}
+	}
+	want := map[string]string{
+		"example.com/shared-package-test/pkg/common.LogMessage":           "MODIFY",
+		"example.com/shared-package-test/pkg/common.LogTwice":             "ADD",
+		"example.com/shared-package-test/pkg/common.LogMessageWithPrefix": "DELETE",
+	}
+	for symbol, changeType := range want {
+		if got[symbol] != changeType {
+			t.Errorf("%s: expected %s, got %q (changes %v)", symbol, changeType, got[symbol], got)
+		}
+	}
+}
+
+func TestAnalyzeDeletedFile(t *testing.T) {
+	// 新增 pkg/common/format.go 和只有一个文件的包 pkg/legacy 后,在下一个提交中删除这两个文件
+	repo := setupSharedRepo(t)
+	files := map[string]string{
+		"pkg/common/format.go": "package common\n\nconst Separator = \":\"\n\nfunc Format(a, b string) string {\n\treturn a + Separator + b\n}\n",
+		"pkg/legacy/legacy.go": "package legacy\n\ntype Client struct{}\n\nfunc (c *Client) Call() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"commit", "-q", "-m", "add"},
+		{"rm", "-q", "pkg/common/format.go", "pkg/legacy/legacy.go"},
+		{"commit", "-q", "-m", "delete"},
+	} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, c := range res.Changes {
+		got[c.Symbol] = c.ChangeType
+	}
+	want := map[string]string{
+		"example.com/shared-package-test/pkg/common.Separator":      "DELETE",
+		"example.com/shared-package-test/pkg/common.Format":         "DELETE",
+		"example.com/shared-package-test/pkg/legacy.Client":         "DELETE",
+		"example.com/shared-package-test/pkg/legacy.(*Client).Call": "DELETE",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Expected changes %v, got %v", want, got)
+	}
+	if len(res.Affected) != 0 {
+		t.Errorf("Expected no affected services, got %+v", res.Affected)
+	}
+}
+
+func TestAnalyzeRisk(t *testing.T) {
+	// 给 LogMessage 增加可变参数,调用方不需要修改
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"func LogMessage(message string) {", "func LogMessage(message string, _ ...any) {")
+
+	for _, minRisk := range []int{0, 100} {
+		a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", MinRisk: minRisk})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		res, err := a.Analyze(context.Background())
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+
+		if len(res.Changes) != 1 || res.Changes[0].Modification != "signature" {
+			t.Fatalf("Expected the signature of LogMessage to change, got %+v", res.Changes)
+		}
+		if minRisk == 100 {
+			if len(res.Affected) != 0 {
+				t.Errorf("Expected no service with risk 100, got %+v", res.Affected)
+			}
+			continue
+		}
+		if len(res.Affected) != 2 {
+			t.Fatalf("Expected service-a and service-b to be affected, got %+v", res.Affected)
+		}
+		for _, b := range res.Affected {
+			if b.Risk <= 0 || b.Risk >= 100 {
+				t.Errorf("Expected %s to have a risk between 0 and 100, got %d", b.Name, b.Risk)
+			}
+		}
+	}
+}
+
+func TestAnalyzeCoverProfile(t *testing.T) {
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"func LogMessage(message string) {", "func LogMessage(message string, _ ...any) {")
+	profile := filepath.Join(t.TempDir(), "cover.out")
+	if err := os.WriteFile(profile, []byte("mode: set\n"+
+		"example.com/shared-package-test/pkg/common/logger.go:29.45,31.2 1 0\n"+
+		"example.com/shared-package-test/pkg/common/logger.go:45.32,48.2 2 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每个测试一个覆盖率文件,只有 TestLogMessage 执行了变更行
+	coverDir := t.TempDir()
+	for name, count := range map[string]string{"pkg/common/TestLogMessage.out": "1", "pkg/common/TestRunServer.out": "0"} {
+		filename := filepath.Join(coverDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("mode: set\n"+
+			"example.com/shared-package-test/pkg/common/logger.go:29.45,31.2 1 "+count+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", CoverProfile: profile, CoverDir: coverDir})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	uncovered := Coverage{Statements: 1}
+	if len(res.Changes) != 1 || res.Changes[0].Coverage == nil || *res.Changes[0].Coverage != uncovered {
+		t.Fatalf("Expected LogMessage to be uncovered, got %+v", res.Changes)
+	}
+	if len(res.Affected) != 2 {
+		t.Fatalf("Expected service-a and service-b to be affected, got %+v", res.Affected)
+	}
+	for _, b := range res.Affected {
+		if b.Coverage == nil || *b.Coverage != uncovered {
+			t.Errorf("Expected %s to reach uncovered code, got %v", b.Name, b.Coverage)
+		}
+	}
+	if len(res.Tests) != 1 || res.Tests[0].Name != "pkg/common/TestLogMessage" || res.Tests[0].Lines != 1 {
+		t.Errorf("Expected TestLogMessage to execute the changed line, got %+v", res.Tests)
+	}
+}
+
+func TestAnalyzeBazel(t *testing.T) {
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"\tfmt.Printf(\"[COMMON] %s\\n\", message)\n", "\tfmt.Printf(\"[COMMON] %s\\n\", message)\n\t_ = len(message)\n")
+	if err := os.WriteFile(filepath.Join(repo, "cmd", "service-a", "BUILD.bazel"), []byte(`go_binary(name = "service-a")`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Bazel: true,
+		BazelTargets: map[string]string{"service-b": "//images:service-b"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, b := range res.Affected {
+		got[b.Name] = b.BazelTarget
+	}
+	want := map[string]string{"service-a": "//cmd/service-a:service-a", "service-b": "//images:service-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Bazel targets = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeExplain(t *testing.T) {
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"\tfmt.Printf(\"[COMMON] %s\\n\", message)\n", "\tfmt.Printf(\"[COMMON] %s\\n\", message)\n\t_ = len(message)\n")
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Explain: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	for _, b := range res.Affected {
+		if len(b.Evidence) != 1 {
+			t.Fatalf("Expected one evidence for %s, got %+v", b.Name, b.Evidence)
+		}
+		ev := b.Evidence[0]
+		if ev.File != "pkg/common/logger.go" || len(ev.Hunks) != 1 || !strings.HasPrefix(ev.Hunks[0], "@@ -28,6 +28,7 @@") {
+			t.Errorf("Unexpected evidence of %s: %+v", b.Name, ev)
+		}
+		want := []CallSite{
+			{Caller: b.PkgPath + ".main", Callee: "example.com/shared-package-test/internal/" + b.Name + ".ProcessRequest", File: "cmd/" + b.Name + "/main.go", Lines: []int{11}},
+			{Caller: "example.com/shared-package-test/internal/" + b.Name + ".ProcessRequest", Callee: "example.com/shared-package-test/pkg/common.LogMessage", File: "internal/" + b.Name + "/handler.go", Lines: []int{23}},
+		}
+		if !reflect.DeepEqual(ev.CallSites, want) {
+			t.Errorf("Call sites of %s = %+v, want %+v", b.Name, ev.CallSites, want)
+		}
+	}
+}
+
+func TestAnalyzeIndex(t *testing.T) {
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"\tfmt.Printf(\"[COMMON] %s\\n\", message)\n", "\tfmt.Printf(\"[COMMON] %s\\n\", message)\n\t_ = len(message)\n")
+	stats, err := WriteIndex(context.Background(), Options{RepoPath: repo})
+	if err != nil {
+		t.Fatalf("WriteIndex failed: %v", err)
+	}
+	if stats.Packages != 5 || stats.Reloaded != 5 || stats.File != filepath.Join(repo, IndexFile) {
+		t.Errorf("Unexpected index stats %+v", stats)
+	}
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Index: stats.File})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"service-a", "service-b"}; !slices.Equal(names, want) {
+		t.Errorf("Affected = %v, want %v", names, want)
+	}
+	if res.IndexPackages != 5 || res.IndexReloaded != 0 {
+		t.Errorf("Expected 5 indexed packages and none reloaded, got %d and %d", res.IndexPackages, res.IndexReloaded)
+	}
+
+	// 索引中没有的符号在加载包之前报错
+	a, err = New(Options{RepoPath: repo, Symbols: []string{"pkg/common.Missing"}, Index: stats.File})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := a.Analyze(context.Background()); err == nil || !strings.Contains(err.Error(), "pkg/common.Missing") {
+		t.Errorf("Expected a missing symbol error, got %v", err)
+	}
+}
+
+func TestAnalyzeDeletionOnly(t *testing.T) {
+	// 只删除 RunServer 中的一行,没有新增行
+	repo := setupRepo(t, "shared-package-test", "pkg/common/logger.go",
+		"\tfmt.Println(\"Starting server via common runner...\")\n", "")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Changes) != 1 || res.Changes[0].Symbol != "example.com/shared-package-test/pkg/common.RunServer" || res.Changes[0].ChangeType != "MODIFY" {
+		t.Fatalf("Expected RunServer to be modified, got %+v", res.Changes)
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "service-a" || names[1] != "service-b" {
+		t.Errorf("Expected service-a and service-b to be affected, got %v", names)
+	}
+}
+
+func TestAnalyzeEnumComparisons(t *testing.T) {
+	// StatusPending 的值变化后,存储的 StatusPaid 和 StatusShipped 的含义随之变化
+	repo := setupRepo(t, "enum-switch-test", "pkg/order/status.go",
+		"StatusPending Status = iota\n", "StatusPending Status = iota + 1\n")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+	}
+	sort.Strings(names)
+	// cmd/api 只引用 StatusShipped 而不比较它
+	if len(names) != 2 || names[0] != "billing" || names[1] != "shipping" {
+		t.Errorf("Expected billing and shipping to be affected, got %v", names)
+	}
+	if len(res.Changes) != 1 {
+		t.Fatalf("Expected 1 change, got %+v", res.Changes)
+	}
+	want := []string{
+		"example.com/enum-switch-test/internal/billing.Charge internal/billing/billing.go:8",
+		"example.com/enum-switch-test/internal/shipping.Ship internal/shipping/shipping.go:7",
+	}
+	if got := res.Changes[0].ComparedIn; !slices.Equal(got, want) {
+		t.Errorf("Expected comparisons %v, got %v", want, got)
+	}
+}
+
+func TestAnalyzeGranularityPackage(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Granularity: GranularityPackage})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, p := range res.Packages {
+		got[p.Package] = p.Binaries
+	}
+	common := got["example.com/shared-package-test/pkg/common"]
+	if len(common) != 2 || common[0] != "service-a" || common[1] != "service-b" {
+		t.Errorf("Expected pkg/common to affect service-a and service-b, got %v (packages %v)", common, got)
+	}
+	if _, ok := got["example.com/shared-package-test/cmd/service-a"]; !ok {
+		t.Errorf("Expected cmd/service-a among the packages, got %v", got)
+	}
+}
+
+func TestAnalyzeStreaming(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	var streamed []string
+	a, err := New(Options{
+		RepoPath:  repo,
+		OldCommit: "HEAD~1",
+		NewCommit: "HEAD",
+		OnAffected: func(b AffectedBinary) {
+			streamed = append(streamed, b.Name)
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(streamed) != len(res.Affected) {
+		t.Fatalf("Expected %d streamed binaries, got %v", len(res.Affected), streamed)
+	}
+	for i, b := range res.Affected {
+		if streamed[i] != b.Name {
+			t.Errorf("Expected streamed binaries in discovery order, got %v", streamed)
+		}
+	}
+}
+
+func TestAnalyzeExclude(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Exclude: []string{"pkg/**"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(res.ChangedFiles) != 0 || res.ChangedSymbols != 0 || len(res.Affected) != 0 {
+		t.Errorf("Expected excluded change to be ignored, got files=%v symbols=%d affected=%v",
+			res.ChangedFiles, res.ChangedSymbols, res.Affected)
+	}
+}
+
+func TestAnalyzeOnly(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	for _, tt := range []struct {
+		only    []string
+		exclude []string
+		want    int
+	}{
+		{only: []string{"internal/**"}, want: 0},
+		{only: []string{"pkg/**"}, want: 1},
+		{only: []string{"pkg/**"}, exclude: []string{"**/logger.go"}, want: 0},
+	} {
+		a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Only: tt.only, Exclude: tt.exclude})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		res, err := a.Analyze(context.Background())
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		if len(res.ChangedFiles) != tt.want || res.ChangedSymbols != tt.want {
+			t.Errorf("only=%v exclude=%v: expected %d changed files and symbols, got files=%v symbols=%d",
+				tt.only, tt.exclude, tt.want, res.ChangedFiles, res.ChangedSymbols)
+		}
+	}
+}
+
+func TestAnalyzeGeneratedFiles(t *testing.T) {
+	repo := setupRepo(t, "constant-test", "internal/config/zz_generated.go", `"us-east-1"`, `"eu-west-1"`)
+
+	for _, include := range []bool{false, true} {
+		a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", IncludeGenerated: include})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		res, err := a.Analyze(context.Background())
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		want := 0
+		if include {
+			want = 1
+		}
+		if len(res.ChangedFiles) != want || res.ChangedSymbols != want {
+			t.Errorf("IncludeGenerated=%v: expected %d changed files and symbols, got files=%v symbols=%d",
+				include, want, res.ChangedFiles, res.ChangedSymbols)
+		}
+	}
+}
+
+func TestAnalyzeRipplesIgnore(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	for _, tt := range []struct {
+		rules   string
+		files   int
+		symbols int
+	}{
+		{rules: "# 公共包\npkg/\n", files: 0, symbols: 0},
+		{rules: "symbol:pkg/common.Log*\n", files: 1, symbols: 0},
+		{rules: "symbol:pkg/common.Log*\n!symbol:pkg/common.LogMessage\n", files: 1, symbols: 1},
+	} {
+		if err := os.WriteFile(filepath.Join(repo, ".ripplesignore"), []byte(tt.rules), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		res, err := a.Analyze(context.Background())
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		if len(res.ChangedFiles) != tt.files || res.ChangedSymbols != tt.symbols {
+			t.Errorf("rules %q: expected %d files and %d symbols, got files=%v symbols=%d",
+				tt.rules, tt.files, tt.symbols, res.ChangedFiles, res.ChangedSymbols)
+		}
+	}
+}
+
+func TestAnalyzeEntrypoints(t *testing.T) {
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Entrypoints: []string{"cmd/service-a"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "service-a" {
+		t.Errorf("Expected only service-a, got %v", res.Affected)
+	}
+}
+
+func TestAnalyzeTargets(t *testing.T) {
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Targets: []string{"cmd/api"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "api" {
+		t.Errorf("Expected only the api target, got %v", res.Affected)
+	}
+
+	a, err = New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Targets: []string{"cmd/missing"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := a.Analyze(context.Background()); err == nil {
+		t.Error("Expected an error for a target matching no service")
+	}
+}
+
+func TestAnalyzeMaxFanOut(t *testing.T) {
+	// message 的第二层调用者是根目录和 cmd/api 的 main 函数,超过上限 1
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", MaxFanOut: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+		if !b.Approximate || b.Confidence != ConfidenceLow {
+			t.Errorf("Expected %s to be an approximate low-confidence result, got %+v", b.Name, b)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"api", "main-package-test"}; !slices.Equal(names, want) {
+		t.Errorf("Expected the binaries importing greet, got %v", names)
+	}
+}
+
+func TestAnalyzeBudget(t *testing.T) {
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	// 预算在追踪开始前已经耗尽,变更符号都列为未分析,分析本身不失败
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Budget: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(res.Affected) != 0 {
+		t.Errorf("Expected no affected binaries, got %v", res.Affected)
+	}
+	if len(res.Report.Changes) == 0 || len(res.Report.NotAnalyzed()) != len(res.Report.Changes) {
+		t.Errorf("Expected every change to be skipped, got %+v", res.Report.Changes)
+	}
+	if !res.Report.Incomplete() {
+		t.Error("Expected the report to be incomplete")
+	}
+}
+
+func TestAnalyzeForwardStrategy(t *testing.T) {
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Strategy: StrategyForward})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if res.Metadata.Strategy != StrategyForward {
+		t.Errorf("Expected the forward strategy in the metadata, got %q", res.Metadata.Strategy)
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+		if b.Approximate || b.Confidence != ConfidenceHigh {
+			t.Errorf("Expected %s to be an exact high-confidence result, got %+v", b.Name, b)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"api", "main-package-test"}; !slices.Equal(names, want) {
+		t.Errorf("Expected the binaries calling greet, got %v", names)
+	}
+}
+
+func TestAnalyzeImportsMode(t *testing.T) {
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Mode: ModeImports})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if res.Metadata.Mode != "imports" || res.ChangedSymbols != 1 {
+		t.Errorf("Expected one changed package in imports mode, got %+v, %d", res.Metadata, res.ChangedSymbols)
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+		if !b.Approximate || b.Confidence != ConfidenceLow {
+			t.Errorf("Expected %s to be an approximate low-confidence result, got %+v", b.Name, b)
+		}
+		if want := "example.com/main-package-test/internal/greet (Changed)"; b.TracePath[len(b.TracePath)-1] != want {
+			t.Errorf("Expected the path to end at the changed package, got %v", b.TracePath)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"api", "main-package-test"}; !slices.Equal(names, want) {
+		t.Errorf("Expected the binaries importing greet, got %v", names)
+	}
+	if len(res.Changes) != 1 || res.Changes[0].Symbol != "example.com/main-package-test/internal/greet" || res.Changes[0].Kind != "Package" {
+		t.Errorf("Unexpected change metrics %+v", res.Changes)
+	}
+	if _, err := ParseMode("symbols"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestAnalyzeMultiModule(t *testing.T) {
+	// 仓库根目录没有 go.mod,变更位于被 services/* 通过 replace 引用的 libs/greet 模块
+	repo := setupRepo(t, "multi-module-test", "libs/greet/greet.go",
+		`return fmt.Sprintf("bye, %s", name)`, `return fmt.Sprintf("goodbye, %s", name)`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Modules) != 3 {
+		t.Errorf("Expected 3 modules, got %v", res.Modules)
+	}
+	if res.ChangedSymbols != 1 {
+		t.Errorf("Expected 1 changed symbol, got %d", res.ChangedSymbols)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "worker" {
+		t.Errorf("Expected only worker to be affected, got %v", res.Affected)
+	}
+	if os.Getenv("GOWORK") != "" {
+		t.Errorf("Expected GOWORK to be restored, got %q", os.Getenv("GOWORK"))
+	}
+}
+
+func TestTrace(t *testing.T) {
+	// 指定符号时不需要 git 仓库
+	repo := filepath.Join("..", "..", "testdata", "shared-package-test")
+
+	tests := []struct {
+		symbols []string
+		want    []string
+	}{
+		{[]string{"pkg/common.LogMessage"}, []string{"service-a", "service-b"}},
+		{[]string{"example.com/shared-package-test/internal/service-a.(*Handler).ProcessRequest"}, []string{"service-a"}},
+		{[]string{"internal/service-a/handler.go:19"}, []string{"service-a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbols[0], func(t *testing.T) {
+			a, err := New(Options{RepoPath: repo, Symbols: tt.symbols})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			res, err := a.Analyze(context.Background())
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+
+			var got []string
+			for _, b := range res.Affected {
+				got = append(got, b.Name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTraceUnknownSymbol(t *testing.T) {
+	repo := filepath.Join("..", "..", "testdata", "shared-package-test")
+
+	a, err := New(Options{RepoPath: repo, Symbols: []string{"pkg/common.Missing"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := a.Analyze(context.Background()); err == nil {
+		t.Error("Expected an error for an unknown symbol")
+	}
+}
+
+func TestAnalyzeFiles(t *testing.T) {
+	// 使用副本: 追踪结果按文件位置持久缓存,整个文件变更时的结果不应影响 TestTrace
+	repo := setupSharedRepo(t)
+
+	a, err := New(Options{RepoPath: repo, Files: []string{"internal/service-a/handler.go", "README.md", "internal/gone/gone.go"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	// 非 Go 文件和不存在的文件被忽略,文件中的每个顶层符号都视为变更
+	if !slices.Equal(res.ChangedFiles, []string{"internal/service-a/handler.go"}) {
+		t.Errorf("Unexpected changed files %v", res.ChangedFiles)
+	}
+	if res.ChangedSymbols < 4 {
+		t.Errorf("Expected every symbol of handler.go to be changed, got %d", res.ChangedSymbols)
+	}
+	if len(res.Unknown) != 0 {
+		t.Errorf("Expected no unknown impact, got %v", res.Unknown)
+	}
+	// service-b 经由 common.Runner 接口调用 service-a 的 Server.Run
+	var got []string
+	for _, b := range res.Affected {
+		got = append(got, b.Name)
+	}
+	sort.Strings(got)
+	if !slices.Equal(got, []string{"service-a", "service-b"}) {
+		t.Errorf("Expected service-a and service-b, got %v", got)
+	}
+}
+
+func TestBinaries(t *testing.T) {
+	repo := filepath.Join("..", "..", "testdata", "multi-module-test")
+
+	bins, err := Binaries(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Binaries failed: %v", err)
+	}
+
+	want := []Binary{
+		{Name: "api", Package: "example.com/api/cmd/api", Module: "example.com/api", Dir: "services/api/cmd/api", Traced: true},
+		{Name: "worker", Package: "example.com/worker/cmd/worker", Module: "example.com/worker", Dir: "services/worker/cmd/worker", Traced: true},
+	}
+	if len(bins) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, bins)
+	}
+	for i := range want {
+		if bins[i] != want[i] {
+			t.Errorf("bins[%d] = %+v, want %+v", i, bins[i], want[i])
+		}
+	}
+}
+
+func TestGraphMultiModule(t *testing.T) {
+	repo := filepath.Join("..", "..", "testdata", "multi-module-test")
+
+	g, err := Graph(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+
+	binaries := make(map[string]string)
+	for _, n := range g.Nodes {
+		binaries[n.ID] = strings.Join(n.Binaries, ",")
+	}
+	if binaries["example.com/greet.Hello"] != "api" {
+		t.Errorf("Expected greet.Hello to be reachable from api only, got %q", binaries["example.com/greet.Hello"])
+	}
+	if binaries["example.com/greet.Bye"] != "worker" {
+		t.Errorf("Expected greet.Bye to be reachable from worker only, got %q", binaries["example.com/greet.Bye"])
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	repo := setupSharedRepo(t)
+	cache := t.TempDir()
+
+	stats, err := CollectStats(context.Background(), StatsOptions{
+		Options:  Options{RepoPath: repo},
+		CacheDir: cache,
+	})
+	if err != nil {
+		t.Fatalf("CollectStats failed: %v", err)
+	}
+
+	// 根提交没有父提交,不参与统计
+	if stats.Commits != 1 || stats.MultiService != 1 {
+		t.Errorf("Expected 1 multi-service commit, got %+v", stats)
+	}
+	if len(stats.HotSpots) != 1 {
+		t.Fatalf("Expected 1 hot spot, got %+v", stats.HotSpots)
+	}
+	spot := stats.HotSpots[0]
+	if spot.Package != "pkg/common" || spot.Commits != 1 || strings.Join(spot.Services, ",") != "service-a,service-b" {
+		t.Errorf("Unexpected hot spot: %+v", spot)
+	}
+
+	entries, err := os.ReadDir(cache)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected 1 cached report, got %v (%v)", entries, err)
+	}
+}
+
+func TestAnalyzeConstantValues(t *testing.T) {
+	repo := setupRepo(t, "constant-test", "internal/config/config.go",
+		"const MaxRetries = 5", "const MaxRetries = 10")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Changes) != 1 {
+		t.Fatalf("Expected 1 change, got %+v", res.Changes)
+	}
+	if c := res.Changes[0]; c.OldValue != "5" || c.NewValue != "10" {
+		t.Errorf("Expected MaxRetries 5 -> 10, got %q -> %q", c.OldValue, c.NewValue)
+	}
+}
+
+func TestAnalyzeVariableInitializer(t *testing.T) {
+	// defaultTTL 没有读取者,但初始化表达式在导入 cache 包的服务启动时执行
+	repo := setupRepo(t, "init-test", "internal/cache/cache.go",
+		"var defaultTTL = computeTTL()", "var defaultTTL = computeTTL() * 2")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	var got []string
+	for _, b := range res.Affected {
+		got = append(got, b.Name)
+		if b.Confidence != ConfidenceLow {
+			t.Errorf("Expected low confidence for %s, got %s", b.Name, b.Confidence)
+		}
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "api-server,server" {
+		t.Errorf("Expected api-server and server, got %v", got)
+	}
+}
+
+func TestAnalyzeDotImportChange(t *testing.T) {
+	// 把点导入换成提供同名 Format 的另一个包,Run 的调用目标随之改变
+	repo := setupRepo(t, "dot-import-test", "internal/svc/svc.go",
+		`. "example.com/dot-import-test/pkg/util"`, `. "example.com/dot-import-test/pkg/legacy"`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Changes) != 1 || !strings.HasSuffix(res.Changes[0].Symbol, ".Run") {
+		t.Errorf("Expected the change to map to svc.Run, got %+v", res.Changes)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "app" {
+		t.Errorf("Expected app to be affected, got %v", res.Affected)
+	}
+}
+
+func TestAnalyzePromotedMethod(t *testing.T) {
+	// worker 只通过 io.Closer 调用 Worker 从 base.Base 获得的 Close
+	repo := setupRepo(t, "embed-test", "pkg/base/base.go",
+		"func (b *Base) Close() error {\n\treturn nil", "func (b *Base) Close() error {\n\tb.name = \"\"\n\treturn nil")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	affected := make(map[string]AffectedBinary)
+	for _, b := range res.Affected {
+		affected[b.Name] = b
+	}
+	if _, ok := affected["app"]; !ok {
+		t.Errorf("Expected app to be affected through svc.Server, got %v", res.Affected)
+	}
+	worker, ok := affected["worker"]
+	if !ok {
+		t.Fatalf("Expected worker to be affected through worker.Worker, got %v", res.Affected)
+	}
+	if worker.Confidence != ConfidenceMedium {
+		t.Errorf("Expected medium confidence for worker, got %s", worker.Confidence)
+	}
+	if last := worker.TracePath[len(worker.TracePath)-1]; !strings.Contains(last, "worker.Worker.Close") {
+		t.Errorf("Expected the path to end with the promoted method, got %v", worker.TracePath)
+	}
+}
+
+func TestAnalyzeInterfaceBreakage(t *testing.T) {
+	// 删除 Base.Close 后 *worker.Worker 不再满足 io.Closer
+	repo := setupRepo(t, "embed-test", "pkg/base/base.go",
+		"// Close 释放资源\nfunc (b *Base) Close() error {\n\treturn nil\n}\n\n", "")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.InterfaceBreakage) != 1 {
+		t.Fatalf("Expected 1 interface breakage, got %+v", res.InterfaceBreakage)
+	}
+	b := res.InterfaceBreakage[0]
+	if b.Interface != "io.Closer" || b.File != "internal/worker/worker.go" || !b.Removed {
+		t.Errorf("Unexpected breakage: %+v", b)
+	}
+	if len(b.Binaries) != 1 || b.Binaries[0] != "worker" {
+		t.Errorf("Expected worker to fail to compile, got %v", b.Binaries)
+	}
+}
+
+func TestAnalyzeInterfaceEmbedding(t *testing.T) {
+	// Reader 新增 Keys,嵌入它的 Store 随之新增;*memory.Memory 和 layered.Layered 没有 Keys,不再满足 Store
+	repo := setupRepo(t, "iface-embed-test", "pkg/store/store.go",
+		"\tGet(key string) (string, bool)\n", "\tGet(key string) (string, bool)\n\tKeys() []string\n")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.InterfaceBreakage) != 2 {
+		t.Fatalf("Expected 2 interface breakages, got %+v", res.InterfaceBreakage)
+	}
+	want := map[string]string{
+		"internal/layered/layered.go": "worker",
+		"internal/memory/memory.go":   "api",
+	}
+	for _, b := range res.InterfaceBreakage {
+		if !b.Missing || b.Method != "Reader.Keys" || !strings.HasSuffix(b.Interface, "pkg/store.Store") {
+			t.Errorf("Unexpected breakage: %+v", b)
+		}
+		if binary, ok := want[b.File]; !ok || len(b.Binaries) != 1 || b.Binaries[0] != binary {
+			t.Errorf("Expected %s to break %s, got %+v", b.File, binary, b)
+		}
+	}
+}
+
+func TestAnalyzePackageLocalSymbol(t *testing.T) {
+	// performOperation 未导出,只需追踪包的出口 DoWithRetry
+	repo := setupRepo(t, "constant-test", "internal/service/retry.go",
+		"func performOperation() error {\n\treturn nil", "func performOperation() error {\n\tvar err error\n\treturn err")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Affected) != 1 || res.Affected[0].Name != "server" {
+		t.Fatalf("Expected server to be affected, got %v", res.Affected)
+	}
+	path := res.Affected[0].TracePath
+	if len(path) < 3 || !strings.Contains(path[len(path)-2], "DoWithRetry") || !strings.Contains(path[len(path)-1], "performOperation") {
+		t.Errorf("Expected the path to end with DoWithRetry -> performOperation, got %v", path)
+	}
+}
+
+func TestAnalyzeUnreachedSymbol(t *testing.T) {
+	// Backoff 只在测试中使用,不会影响任何服务
+	repo := setupRepo(t, "constant-test", "internal/service/backoff.go", "attempt * 2", "attempt * 3")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Affected) != 0 {
+		t.Errorf("Expected no affected binaries, got %v", res.Affected)
+	}
+	if len(res.Changes) != 1 || res.Changes[0].Unreached != "tests_only" {
+		t.Errorf("Expected Backoff to be reported as only used by tests, got %+v", res.Changes)
+	}
+}
+
+func TestAnalyzeCustomEntrypoints(t *testing.T) {
+	repo := setupRepo(t, "entrypoint-test", "internal/billing/billing.go", "return amount", "return amount * 2")
+
+	a, err := New(Options{
+		RepoPath:            repo,
+		OldCommit:           "HEAD~1",
+		NewCommit:           "HEAD",
+		EntrypointFunctions: map[string]string{"orders-lambda": "lambda/orders.Handle"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	paths := make(map[string][]string)
+	for _, b := range res.Affected {
+		paths[b.Name] = b.TracePath
+	}
+	for _, name := range []string{"api", "cron-reconciler", "orders-lambda"} {
+		if _, ok := paths[name]; !ok {
+			t.Errorf("Expected %s to be affected, got %v", name, res.Affected)
+		}
+	}
+	cron := paths["cron-reconciler"]
+	if len(cron) != 3 || !strings.HasSuffix(cron[0], "jobs.Reconcile (entrypoint)") || !strings.Contains(cron[1], "jobs.settle") {
+		t.Errorf("Expected Reconcile -> settle -> Charge, got %v", cron)
+	}
+}
+
+func TestAnalyzeJobs(t *testing.T) {
+	repo := setupRepo(t, "job-test", "internal/store/store.go", "day % 7", "day % 5")
+
+	a, err := New(Options{
+		RepoPath:  repo,
+		OldCommit: "HEAD~1",
+		NewCommit: "HEAD",
+		Jobs: map[string][]string{
+			"cmd/worker": {"internal/jobs.RunNightlyReconcile", "internal/jobs.RunHourlyCleanup"},
+			"api":        {"internal/jobs.RunHourlyCleanup"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	jobs := make(map[string][]string)
+	for _, b := range res.Affected {
+		jobs[b.Name] = b.Jobs
+	}
+	if _, ok := jobs["api"]; !ok || len(jobs["api"]) != 0 {
+		t.Errorf("Expected api to be affected without jobs, got %v", res.Affected)
+	}
+	if got := jobs["worker"]; !slices.Equal(got, []string{"internal/jobs.RunNightlyReconcile"}) {
+		t.Errorf("Expected worker to be affected through RunNightlyReconcile, got %v", got)
+	}
+}
+
+func TestAnalyzeRoutes(t *testing.T) {
+	repo := setupRepo(t, "route-test", "internal/store/store.go", `"alice", "bob"`, `"alice"`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Routes: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	routes := make(map[string][]string)
+	confidence := make(map[string]Confidence)
+	for _, b := range res.Affected {
+		confidence[b.Name] = b.Confidence
+		for _, r := range b.Routes {
+			routes[b.Name] = append(routes[b.Name], r.String()+" "+r.Handler)
+		}
+	}
+	want := map[string][]string{
+		"api":   {"GET /users handler.(*Users).List"},
+		"admin": {"GET /admin/users handler.AdminUsers"},
+		"rpc":   {"gRPC user.v1.UserService/GetUser rpc.(*UserServer).GetUser"},
+	}
+	for name, w := range want {
+		if !slices.Equal(routes[name], w) {
+			t.Errorf("Expected routes %v for %s, got %v", w, name, routes[name])
+		}
+	}
+	// rpc 只通过 gRPC 注册到达,由生成代码经接口调用
+	if confidence["rpc"] != ConfidenceMedium {
+		t.Errorf("Expected medium confidence for rpc, got %q", confidence["rpc"])
+	}
+}
+
+func TestAnalyzeCommands(t *testing.T) {
+	repo := setupRepo(t, "command-test", "internal/store/store.go", "return nil", "return error(nil)")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", Commands: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	commands := make(map[string][]string)
+	for _, b := range res.Affected {
+		commands[b.Name] = b.Commands
+	}
+	want := map[string][]string{
+		"myctl":  {"db migrate", "serve"},
+		"admin":  {"users purge"},
+		"server": nil,
+	}
+	for name, w := range want {
+		got, ok := commands[name]
+		if !ok {
+			t.Errorf("Expected %s to be affected, got %v", name, res.Affected)
+			continue
+		}
+		if !slices.Equal(got, w) {
+			t.Errorf("Expected commands %v for %s, got %v", w, name, got)
+		}
+	}
+}
+
+func TestAnalyzeFunctionValues(t *testing.T) {
+	// warmCache 只在 init 中被追加到启动回调列表,由 Start 通过变量调用
+	repo := setupRepo(t, "callback-test", "internal/hooks/hooks.go", "1024", "2048")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Affected) != 1 || res.Affected[0].Name != "api" {
+		t.Fatalf("Expected only api to be affected, got %v", res.Affected)
+	}
+	api := res.Affected[0]
+	if api.Confidence != ConfidenceMedium {
+		t.Errorf("Expected medium confidence, got %q", api.Confidence)
+	}
+	want := []string{"hooks.Start", "hooks.onStart", "hooks.warmCache (Changed)"}
+	if len(api.TracePath) != 4 {
+		t.Fatalf("Expected main -> Start -> onStart -> warmCache, got %v", api.TracePath)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(api.TracePath[i+1], w) {
+			t.Errorf("Expected node %d to end with %q, got %v", i+1, w, api.TracePath)
+		}
+	}
+}
+
+func TestAnalyzeReturnedClosure(t *testing.T) {
+	repo := setupRepo(t, "closure-test", "internal/handlers/handlers.go",
+		"return prefix + name", "return prefix + name + \"!\"")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", AllPaths: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Affected) != 1 || res.Affected[0].Name != "api" {
+		t.Fatalf("Expected only api to be affected, got %v", res.Affected)
+	}
+	// 闭包在调用保存它的变量时执行: main -> handle -> greet -> NewGreeter
+	found := false
+	for _, path := range res.Affected[0].Paths {
+		found = found || slices.ContainsFunc(path, func(node string) bool { return strings.HasSuffix(node, "cmd/api.greet") })
+	}
+	if !found {
+		t.Errorf("Expected a path through the greet variable, got %v", res.Affected[0].Paths)
+	}
+}
+
+func TestAnalyzeMethodValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		binary   string
+		via      string
+	}{
+		// 绑定方法保存在结构体字段的处理函数表中,在读取该字段的 Handle 中被调用
+		{"bound method", `return "ok"`, `return "ok!"`, "api", "internal/server.Server.routes"},
+		// 方法表达式保存在包级变量中
+		{"method expression", `"<" + v + ">"`, `"[" + v + "]"`, "web", "internal/server.encode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupRepo(t, "method-value-test", "internal/server/server.go", tt.old, tt.new)
+
+			a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", AllPaths: true})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			res, err := a.Analyze(context.Background())
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+
+			if len(res.Affected) != 1 || res.Affected[0].Name != tt.binary {
+				t.Fatalf("Expected only %s to be affected, got %v", tt.binary, res.Affected)
+			}
+			found := false
+			for _, path := range res.Affected[0].Paths {
+				found = found || slices.ContainsFunc(path, func(node string) bool { return strings.HasSuffix(node, tt.via) })
+			}
+			if !found {
+				t.Errorf("Expected a path through %s, got %v", tt.via, res.Affected[0].Paths)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLazyInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		binary   string
+		via      []string
+	}{
+		// once.Do 中调用的函数: main -> Get -> sync.(*Once).Do -> parse
+		{"once.Do", "strings.TrimSpace(name)", "strings.TrimSpace(name) + \"!\"", "api",
+			[]string{"internal/config.Get", "sync.(*Once).Do"}},
+		// sync.OnceValue 包装的函数在第一次调用变量时执行,而不是在包初始化时
+		{"sync.OnceValue", "strings.ToLower(v)", "strings.ToUpper(v)", "web",
+			[]string{"internal/config.Region", "internal/config.getRegion", "sync.OnceValue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupRepo(t, "lazy-init-test", "internal/config/config.go", tt.old, tt.new)
+
+			a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD", AllPaths: true})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			res, err := a.Analyze(context.Background())
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+
+			if len(res.Affected) != 1 || res.Affected[0].Name != tt.binary {
+				t.Fatalf("Expected only %s to be affected, got %v", tt.binary, res.Affected)
+			}
+			found := false
+			for _, path := range res.Affected[0].Paths {
+				found = found || containsInOrder(path, tt.via) && !slices.ContainsFunc(path, func(node string) bool { return strings.HasSuffix(node, ".init") })
+			}
+			if !found {
+				t.Errorf("Expected a path through %v, got %v", tt.via, res.Affected[0].Paths)
+			}
+		})
+	}
+}
+
+// containsInOrder reports whether path has nodes ending with each of suffixes, in order
+func containsInOrder(path, suffixes []string) bool {
+	i := 0
+	for _, node := range path {
+		if i < len(suffixes) && strings.HasSuffix(node, suffixes[i]) {
+			i++
+		}
+	}
+	return i == len(suffixes)
+}
+
+func TestAnalyzeGoDefer(t *testing.T) {
+	// 只在 go 和 defer 语句中调用的函数与直接调用一样追踪
+	tests := []struct {
+		old, new string
+		binary   string
+	}{
+		{`"loop"`, `"loop!"`, "api"},
+		{`"poll", n`, `"poll", n+1`, "api"},
+		{`"cleanup", name`, `"cleanup:", name`, "cli"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.old, func(t *testing.T) {
+			repo := setupRepo(t, "go-defer-test", "internal/jobs/jobs.go", tt.old, tt.new)
+
+			a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			res, err := a.Analyze(context.Background())
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+			if len(res.Affected) != 1 || res.Affected[0].Name != tt.binary || res.Affected[0].Confidence != ConfidenceHigh {
+				t.Errorf("Expected only %s to be affected with high confidence, got %v", tt.binary, res.Affected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeInitRegistrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		old, new string
+		want     map[string]Confidence
+	}{
+		// api 通过 Dispatch 读取注册表;cli 只导入了登记包,可能通过注册表调用
+		{"map", "internal/handlers/handlers.go", "strings.ToUpper(v)", "strings.ToUpper(v) + \"!\"",
+			map[string]Confidence{"api": ConfidenceMedium, "cli": ConfidenceLow}},
+		// plugins 在 init 中调用 registry.Register,只有 cli 导入了 plugins
+		{"register call", "internal/plugins/plugins.go", "strings.TrimSpace(v)", "strings.TrimSpace(v) + \"!\"",
+			map[string]Confidence{"cli": ConfidenceMedium}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupRepo(t, "registry-test", tt.file, tt.old, tt.new)
+
+			a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			res, err := a.Analyze(context.Background())
+			if err != nil {
+				t.Fatalf("Analyze failed: %v", err)
+			}
+
+			got := make(map[string]Confidence)
+			for _, b := range res.Affected {
+				got[b.Name] = b.Confidence
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSparseCheckout(t *testing.T) {
+	repo := setupRepo(t, "shared-package-test", "internal/service-b/handler.go", `"ServiceB: "`, `"service-b: "`)
+	cmd := exec.Command("git", "sparse-checkout", "set", "cmd/service-a", "internal/service-a", "pkg")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git sparse-checkout not available: %v\n%s", err, out)
+	}
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(res.Affected) != 0 {
+		t.Errorf("Expected no affected binaries, got %v", res.Affected)
+	}
+	if !slices.Equal(res.OutOfCone, []string{"internal/service-b"}) {
+		t.Errorf("Expected internal/service-b to be out of cone, got %v", res.OutOfCone)
+	}
+	if !res.Incomplete() {
+		t.Error("Expected the report to be incomplete")
+	}
+}
+
+func TestAnalyzeReceiverPrefix(t *testing.T) {
+	// 方法名 Load 是接收者类型名 Loader 的前缀
+	repo := setupRepo(t, "receiver-test", "internal/store/store.go", `"loaded "`, `"read "`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(res.Report.Unknown) != 0 {
+		t.Errorf("Expected the method to be traced, got %+v", res.Report.Unknown)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "api" {
+		t.Fatalf("Expected api to be affected, got %v", res.Affected)
+	}
+	// 方法带着接收者出现在调用链中
+	path := res.Affected[0].TracePath
+	if want := "example.com/receiver-test/internal/store.(*Loader).Load (Changed)"; path[len(path)-1] != want {
+		t.Errorf("Expected the path to end with %s, got %v", want, path)
+	}
+}
+
+func TestAnalyzeSharedDeclaration(t *testing.T) {
+	// Hosts 和 Ports 在同一条声明中定义,只修改了 Ports 的初始值
+	repo := setupRepo(t, "var-literal-test", "internal/conf/conf.go", `"http": 80`, `"http": 8080`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var changed []string
+	for _, c := range res.Report.Changes {
+		changed = append(changed, c.Symbol)
+	}
+	if want := []string{"example.com/var-literal-test/internal/conf.Ports"}; !slices.Equal(changed, want) {
+		t.Errorf("Expected changes %v, got %v", want, changed)
+	}
+	if len(res.Affected) != 1 || res.Affected[0].Name != "api" {
+		t.Errorf("Expected api to be affected, got %v", res.Affected)
+	}
+}
+
+func TestAnalyzeStaticMethodCallConfidence(t *testing.T) {
+	// File.Close 满足 io.Closer 和 closer.Closer: api 直接调用它,worker 通过接口调用
+	repo := setupRepo(t, "confidence-test", "internal/file/file.go",
+		`fmt.Println("close", f.name)`, `fmt.Println("closing", f.name)`)
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	confidence := make(map[string]Confidence)
+	for _, b := range res.Affected {
+		confidence[b.Name] = b.Confidence
+	}
+	want := map[string]Confidence{"api": ConfidenceHigh, "worker": ConfidenceMedium}
+	if !maps.Equal(confidence, want) {
+		t.Errorf("Expected %v, got %v", want, confidence)
+	}
+}
+
+func TestAnalyzeUntracedMains(t *testing.T) {
+	// 根目录和 tools/migrate 下的 main 包不在 cmd/ 中,gopls 追踪器识别不到
+	repo := setupRepo(t, "main-package-test", "internal/greet/greet.go", "hello, ", "hi, ")
+
+	a, err := New(Options{RepoPath: repo, OldCommit: "HEAD~1", NewCommit: "HEAD"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	res, err := a.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	pkgs := map[string]string{
+		"api":               "example.com/main-package-test/cmd/api",
+		"main-package-test": "example.com/main-package-test",
+	}
+	var names []string
+	for _, b := range res.Affected {
+		names = append(names, b.Name)
+		if b.PkgPath != pkgs[b.Name] {
+			t.Errorf("Expected package of %s to be %q, got %q", b.Name, pkgs[b.Name], b.PkgPath)
+		}
+		if len(b.TracePath) == 0 || !strings.Contains(b.TracePath[0], "main (main)") {
+			t.Errorf("Expected %s to be traced from its main function, got %v", b.Name, b.TracePath)
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"api", "main-package-test"}; !slices.Equal(names, want) {
+		t.Errorf("Expected %v to be affected, got %v", want, names)
+	}
+
+	bins, err := Binaries(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Binaries failed: %v", err)
+	}
+	traced := make(map[string]bool)
+	for _, b := range bins {
+		traced[b.Name] = b.Traced
+	}
+	if len(traced) != 3 || !traced["api"] || traced["migrate"] || traced["main-package-test"] {
+		t.Errorf("Expected api to be traced and migrate, main-package-test not, got %+v", bins)
+	}
+}
@@ -0,0 +1,63 @@
+package ripples
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/logger"
+	"golang.org/x/mod/modfile"
+)
+
+// excludeOutOfCone 去掉不在稀疏检出范围内的变更文件。这些文件所在的包不在工作区中,
+// 无法加载和追踪,返回剩余的文件和被去掉的文件所在的目录(相对仓库根目录,已排序且去重)。
+// 不是 git 仓库时原样返回
+func excludeOutOfCone(root string, files []string) ([]string, []string) {
+	skipped, err := git.SkippedFiles(root, files)
+	if err != nil {
+		logger.Debug("检查稀疏检出失败", "error", err)
+		return files, nil
+	}
+	if len(skipped) == 0 {
+		return files, nil
+	}
+	var kept, dirs []string
+	for _, file := range files {
+		if slices.Contains(skipped, file) {
+			dirs = append(dirs, path.Dir(file))
+			continue
+		}
+		kept = append(kept, file)
+	}
+	slices.Sort(dirs)
+	dirs = slices.Compact(dirs)
+	logger.Warn("变更的包不在稀疏检出范围内,未分析", "packages", len(dirs))
+	return kept, dirs
+}
+
+// missingWorkspaceModules 返回仓库根目录的 go.work 中不在工作区中的模块目录,
+// 稀疏检出时 go 命令会因此无法加载整个工作区
+func missingWorkspaceModules(root string) []string {
+	filename := filepath.Join(root, "go.work")
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	work, err := modfile.ParseWork(filename, data, nil)
+	if err != nil {
+		return nil
+	}
+	var missing []string
+	for _, use := range work.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			missing = append(missing, use.Path)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,237 @@
+package ripples
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/git"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+)
+
+// StatsOptions 历史统计选项
+type StatsOptions struct {
+	// Options 每个提交的分析选项,OldCommit/NewCommit/Symbols/Files/Diff/OnAffected 会被忽略
+	Options
+
+	Ref   string // 统计的分支或 commit,默认 HEAD
+	Since string // 起始时间,如 "90d"、"12w"、"2024-01-01",为空时统计全部历史
+
+	// CacheDir 按 commit 缓存每次分析的报告,为空时不缓存。
+	// 缓存不区分分析选项,修改服务边界等配置后需要清空
+	CacheDir string
+
+	// SkipTypes 跳过标题为这些 Conventional Commits 类型(如 "docs"、"chore"、"test")的提交,
+	// 不做分析,记录在 Stats.Skipped 中。带有 "!" 破坏性变更标记的提交不跳过
+	SkipTypes []string
+}
+
+// Stats 历史统计结果
+type Stats struct {
+	Commits      int       `json:"commits"`               // 分析的提交数
+	MultiService int       `json:"multi_service_commits"` // 影响多个服务的提交数
+	HotSpots     []HotSpot `json:"hot_spots"`             // 按导致多服务影响的次数排序
+	Failed       []string  `json:"failed,omitempty"`      // 分析失败的提交
+
+	Skipped []SkippedCommit `json:"skipped,omitempty"` // 按提交类型跳过、没有分析的提交
+}
+
+// SkippedCommit 按 Conventional Commits 类型跳过的提交
+type SkippedCommit struct {
+	Hash    string `json:"hash"`
+	Type    string `json:"type"`
+	Subject string `json:"subject"`
+}
+
+// conventionalRe 匹配 Conventional Commits 格式的标题 "type(scope)!: description"
+var conventionalRe = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?(!)?: `)
+
+// CommitType 返回 Conventional Commits 格式的提交标题中的类型(小写)和是否标记了破坏性变更,
+// 不是该格式时类型为空
+func CommitType(subject string) (string, bool) {
+	m := conventionalRe.FindStringSubmatch(subject)
+	if m == nil {
+		return "", false
+	}
+	return strings.ToLower(m[1]), m[3] == "!"
+}
+
+// HotSpot 经常导致多服务影响的包
+type HotSpot struct {
+	Package     string   `json:"package"`      // 包目录(相对仓库根目录)
+	Commits     int      `json:"commits"`      // 该包的变更影响了多个服务的提交数
+	MaxServices int      `json:"max_services"` // 单次提交中影响的最多服务数
+	Services    []string `json:"services"`     // 曾受影响的所有服务
+}
+
+// CollectStats 逐个分析 Since 之后的提交(与第一父提交比较),统计哪些包的变更
+// 最常影响多个服务。每个提交在临时 git worktree 中分析,不会修改当前工作区
+func CollectStats(ctx context.Context, opts StatsOptions) (*Stats, error) {
+	defer logger.RedirectStdout()()
+
+	if opts.RepoPath == "" {
+		opts.RepoPath = "."
+	}
+	if opts.Ref == "" {
+		opts.Ref = "HEAD"
+	}
+	commits, err := git.ListCommits(opts.RepoPath, opts.Ref, opts.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "ripples-stats-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	stats := &Stats{}
+	spots := make(map[string]*HotSpot)
+	services := make(map[string]map[string]bool)
+	for i, c := range commits {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if c.Parent == "" {
+			continue
+		}
+		if typ, skip := skippedType(c.Subject, opts.SkipTypes); skip {
+			logger.Debug("跳过提交", "commit", c.Hash[:12], "type", typ)
+			stats.Skipped = append(stats.Skipped, SkippedCommit{Hash: c.Hash, Type: typ, Subject: c.Subject})
+			continue
+		}
+		logger.Info("分析历史提交", "commit", c.Hash[:12], "progress", i+1, "total", len(commits))
+
+		report, err := commitReport(ctx, opts, tmp, c)
+		if err != nil {
+			logger.Warn("分析历史提交失败", "commit", c.Hash, "error", err)
+			stats.Failed = append(stats.Failed, c.Hash)
+			continue
+		}
+		stats.Commits++
+		if len(report.Affected) > 1 {
+			stats.MultiService++
+		}
+
+		// 同一提交中同一个包的多个变更符号只计一次
+		counted := make(map[string]bool)
+		for _, change := range report.Changes {
+			if change.AffectedBinaries < 2 || change.File == "" {
+				continue
+			}
+			pkg := path.Dir(change.File)
+			spot, ok := spots[pkg]
+			if !ok {
+				spot = &HotSpot{Package: pkg}
+				spots[pkg] = spot
+				services[pkg] = make(map[string]bool)
+			}
+			if !counted[pkg] {
+				counted[pkg] = true
+				spot.Commits++
+			}
+			spot.MaxServices = max(spot.MaxServices, change.AffectedBinaries)
+			for _, b := range change.Binaries {
+				services[pkg][b] = true
+			}
+		}
+	}
+
+	for pkg, spot := range spots {
+		for name := range services[pkg] {
+			spot.Services = append(spot.Services, name)
+		}
+		sort.Strings(spot.Services)
+		stats.HotSpots = append(stats.HotSpots, *spot)
+	}
+	sort.Slice(stats.HotSpots, func(i, j int) bool {
+		a, b := stats.HotSpots[i], stats.HotSpots[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		if len(a.Services) != len(b.Services) {
+			return len(a.Services) > len(b.Services)
+		}
+		return a.Package < b.Package
+	})
+	return stats, nil
+}
+
+// skippedType 返回提交标题中的类型,以及是否因为类型在 types 中(不区分大小写)而跳过该提交
+func skippedType(subject string, types []string) (string, bool) {
+	typ, breaking := CommitType(subject)
+	if typ == "" || breaking {
+		return typ, false
+	}
+	return typ, slices.ContainsFunc(types, func(t string) bool { return strings.EqualFold(t, typ) })
+}
+
+// commitReport 返回单个提交的分析报告,优先读取缓存
+func commitReport(ctx context.Context, opts StatsOptions, tmp string, c git.Commit) (*Report, error) {
+	var cacheFile string
+	if opts.CacheDir != "" {
+		cacheFile = filepath.Join(opts.CacheDir, c.Hash+".json")
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			var report Report
+			if err := json.Unmarshal(data, &report); err == nil {
+				return &report, nil
+			}
+		}
+	}
+
+	dir := filepath.Join(tmp, c.Hash)
+	if err := git.AddWorktree(opts.RepoPath, dir, c.Hash); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := git.RemoveWorktree(opts.RepoPath, dir); err != nil {
+			logger.Warn("删除 git worktree 失败", "dir", dir, "error", err)
+		}
+	}()
+
+	analysis := opts.Options
+	analysis.RepoPath = dir
+	analysis.OldCommit = c.Parent
+	analysis.NewCommit = c.Hash
+	analysis.Symbols = nil
+	analysis.Files = nil
+	analysis.Diff = nil
+	analysis.OnAffected = nil
+	// 热点按每个变更影响的服务数统计,需要追踪所有符号
+	analysis.BinariesOnly = false
+	a, err := New(analysis)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.Analyze(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheFile != "" {
+		if err := saveReport(cacheFile, &res.Report); err != nil {
+			logger.Warn("写入报告缓存失败", "file", cacheFile, "error", err)
+		}
+	}
+	return &res.Report, nil
+}
+
+// saveReport 将报告写入缓存文件
+func saveReport(file string, report *Report) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return i18n.Errorf("生成JSON失败: %w", err)
+	}
+	return os.WriteFile(file, data, 0o644)
+}
@@ -0,0 +1,71 @@
+package ripples
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitType(t *testing.T) {
+	tests := []struct {
+		subject  string
+		typ      string
+		breaking bool
+	}{
+		{"docs: fix typo", "docs", false},
+		{"Chore(deps): bump x", "chore", false},
+		{"refactor!: drop v1 API", "refactor", true},
+		{"chore(api)!: remove endpoint", "chore", true},
+		{"Fix crash on start", "", false},
+		{"docs:missing space", "", false},
+	}
+	for _, tt := range tests {
+		typ, breaking := CommitType(tt.subject)
+		if typ != tt.typ || breaking != tt.breaking {
+			t.Errorf("CommitType(%q) = %q, %v; want %q, %v", tt.subject, typ, breaking, tt.typ, tt.breaking)
+		}
+	}
+
+	types := []string{"docs", "CHORE"}
+	for subject, want := range map[string]bool{
+		"docs: fix typo":         true,
+		"chore: tidy":            true,
+		"chore!: drop Go 1.20":   false,
+		"feat: add retries":      false,
+		"update docs and chores": false,
+	} {
+		if _, skip := skippedType(subject, types); skip != want {
+			t.Errorf("skippedType(%q) = %v, want %v", subject, skip, want)
+		}
+	}
+}
+
+func TestCollectStatsSkipTypes(t *testing.T) {
+	repo := setupSharedRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "NOTES.md"), []byte("notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "docs: add notes"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	stats, err := CollectStats(context.Background(), StatsOptions{
+		Options:   Options{RepoPath: repo, Mode: ModeImports},
+		SkipTypes: []string{"docs", "chore"},
+	})
+	if err != nil {
+		t.Fatalf("CollectStats failed: %v", err)
+	}
+	if stats.Commits != 1 {
+		t.Errorf("Expected only the code change analyzed, got %d commits", stats.Commits)
+	}
+	if len(stats.Skipped) != 1 || stats.Skipped[0].Type != "docs" || stats.Skipped[0].Subject != "docs: add notes" {
+		t.Errorf("Unexpected skipped commits %+v", stats.Skipped)
+	}
+}
@@ -0,0 +1,158 @@
+package ripples
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/analyzer"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/logger"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// resolveSpecs 解析 Options.Symbols,将包写法统一为导入路径,changed 中的文件(相对仓库根目录)
+// 整个视为变更。返回需要加载的 Go 文件(相对仓库根目录)。idx 不为 nil 时,索引中没有的符号
+// 在加载包之前报错
+func resolveSpecs(root string, mods []module, idx *parser.Index, symbols, changed []string) ([]analyzer.SymbolSpec, []string, error) {
+	var specs []analyzer.SymbolSpec
+	files := make(map[string]bool)
+	for _, file := range changed {
+		files[file] = true
+		specs = append(specs, analyzer.FileSpec(file))
+	}
+	for _, raw := range symbols {
+		spec, err := analyzer.ParseSymbolSpec(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if spec.IsFile() {
+			file := spec.File
+			if filepath.IsAbs(file) {
+				if file, err = filepath.Rel(root, file); err != nil {
+					return nil, nil, err
+				}
+			}
+			files[filepath.ToSlash(file)] = true
+			specs = append(specs, spec)
+			continue
+		}
+
+		path, dir, err := packageOf(root, mods, spec.Package)
+		if err != nil {
+			return nil, nil, err
+		}
+		goFiles, err := packageFiles(root, dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(goFiles) == 0 {
+			return nil, nil, i18n.Errorf("未找到包: %s", spec.Package)
+		}
+		if idx != nil && !indexed(idx, path, spec) {
+			return nil, nil, i18n.Errorf("未找到符号: %s", spec.Raw)
+		}
+		for _, f := range goFiles {
+			files[f] = true
+		}
+		spec.Package = path
+		specs = append(specs, spec)
+	}
+
+	res := make([]string, 0, len(files))
+	for f := range files {
+		res = append(res, f)
+	}
+	sort.Strings(res)
+	return specs, res, nil
+}
+
+// indexed 报告索引中包 pkgPath 是否声明了符号 spec,索引中没有该包时视为声明了
+func indexed(idx *parser.Index, pkgPath string, spec analyzer.SymbolSpec) bool {
+	symbols, ok := idx.Symbols(pkgPath)
+	if !ok {
+		return true
+	}
+	recv, _, _ := strings.Cut(spec.Receiver, "[")
+	for _, s := range symbols {
+		if s.Name == spec.Name && s.Receiver == recv {
+			return true
+		}
+	}
+	return false
+}
+
+// changedGoFiles 将 Options.Files 统一为相对仓库根目录的路径,只保留仍然存在的 Go 文件:
+// 外部构建系统给出的列表通常也包含被删除的文件和非 Go 文件
+func changedGoFiles(root string, files []string) ([]string, error) {
+	var res []string
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		if filepath.IsAbs(file) {
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				return nil, err
+			}
+			file = rel
+		}
+		if _, err := os.Stat(filepath.Join(root, file)); err != nil {
+			logger.Debug("跳过不存在的变更文件", "file", file)
+			continue
+		}
+		res = append(res, filepath.ToSlash(filepath.Clean(file)))
+	}
+	return res, nil
+}
+
+// packageOf 将导入路径或相对仓库根目录的目录转换为导入路径和绝对目录
+func packageOf(root string, mods []module, pkg string) (string, string, error) {
+	var best *module
+	for i := range mods {
+		if (pkg == mods[i].Path || strings.HasPrefix(pkg, mods[i].Path+"/")) &&
+			(best == nil || len(mods[i].Path) > len(best.Path)) {
+			best = &mods[i]
+		}
+	}
+	if best != nil {
+		return pkg, filepath.Join(best.Dir, filepath.FromSlash(strings.TrimPrefix(pkg, best.Path))), nil
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(pkg))
+	mod := moduleFor(mods, dir+string(filepath.Separator))
+	if mod == nil {
+		return "", "", i18n.Errorf("未找到包: %s", pkg)
+	}
+	rel, err := filepath.Rel(mod.Dir, dir)
+	if err != nil {
+		return "", "", err
+	}
+	if rel == "." {
+		return mod.Path, dir, nil
+	}
+	return mod.Path + "/" + filepath.ToSlash(rel), dir, nil
+}
+
+// packageFiles 列出目录中的非测试 Go 文件(相对仓库根目录)
+func packageFiles(root, dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, i18n.Errorf("未找到包: %s", dir)
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		rel, err := filepath.Rel(root, filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files, nil
+}
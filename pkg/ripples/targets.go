@@ -0,0 +1,60 @@
+package ripples
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jimyag/ripples/internal/config"
+	"github.com/jimyag/ripples/internal/i18n"
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+// resolveTargets 把目标解析为服务名。目标为 main 包目录模式(相对仓库根目录,
+// 如 "cmd/api"、"cmd/*")或服务名,服务名也可以是自定义入口的名称。
+// 没有匹配任何服务的目标视为错误,否则追踪永远无法提前结束
+func resolveTargets(root string, targets []string, services []parser.Entrypoint) ([]string, error) {
+	var names []string
+	for _, target := range targets {
+		target = strings.Trim(filepath.ToSlash(target), "/")
+		matched := false
+		for _, ep := range services {
+			if ep.Name == target || ep.Main && config.Match(target, entrypointDir(root, ep)) {
+				matched = true
+				if !slices.Contains(names, ep.Name) {
+					names = append(names, ep.Name)
+				}
+			}
+		}
+		if !matched {
+			return nil, i18n.Errorf("目标 %s 没有匹配的服务", target)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// serviceNames 返回可能出现在报告中的服务名: entrypoints 为空时是所有服务,
+// 否则是所在目录匹配 entrypoints 的服务
+func serviceNames(root string, services []parser.Entrypoint, entrypoints []string) []string {
+	var names []string
+	for _, ep := range services {
+		if len(entrypoints) > 0 && !config.MatchAny(entrypoints, entrypointDir(root, ep)) {
+			continue
+		}
+		if !slices.Contains(names, ep.Name) {
+			names = append(names, ep.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// entrypointDir 返回入口所在目录相对仓库根目录的路径
+func entrypointDir(root string, ep parser.Entrypoint) string {
+	dir, err := filepath.Rel(root, filepath.Dir(ep.Symbol.Position.Filename))
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(dir)
+}
@@ -0,0 +1,32 @@
+package ripples
+
+import (
+	"go/token"
+	"slices"
+	"testing"
+
+	"github.com/jimyag/ripples/internal/parser"
+)
+
+func TestServiceNames(t *testing.T) {
+	ep := func(name, file string, main bool) parser.Entrypoint {
+		return parser.Entrypoint{Name: name, Main: main, Symbol: &parser.Symbol{Position: token.Position{Filename: file}}}
+	}
+	services := []parser.Entrypoint{
+		ep("api", "/repo/cmd/api/main.go", true),
+		ep("migrate", "/repo/tools/migrate/main.go", true),
+		ep("orders", "/repo/lambda/orders/handler.go", false),
+	}
+
+	if got := serviceNames("/repo", services, nil); !slices.Equal(got, []string{"api", "migrate", "orders"}) {
+		t.Errorf("Expected every service, got %v", got)
+	}
+	if got := serviceNames("/repo", services, []string{"cmd/*"}); !slices.Equal(got, []string{"api"}) {
+		t.Errorf("Expected only services matching the entrypoints, got %v", got)
+	}
+
+	targets, err := resolveTargets("/repo", []string{"tools/*", "orders"}, services)
+	if err != nil || !slices.Equal(targets, []string{"migrate", "orders"}) {
+		t.Errorf("Expected migrate and orders, got %v, %v", targets, err)
+	}
+}
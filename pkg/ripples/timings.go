@@ -0,0 +1,86 @@
+package ripples
+
+import (
+	"time"
+
+	"github.com/jimyag/ripples/internal/lsp"
+	"github.com/jimyag/ripples/internal/metrics"
+)
+
+// RequestStats 按方法汇总的 gopls 请求次数和耗时
+type RequestStats = lsp.RequestStats
+
+// Timings ripples 自身的性能数据,与影响报告分开输出,用于跟踪 ripples 的性能回归。
+// 时长的单位为毫秒
+type Timings struct {
+	Total         float64         `json:"total_ms"`      // 总耗时
+	Phases        []PhaseTiming   `json:"phases"`        // 各阶段耗时,按执行顺序
+	Requests      []RequestTiming `json:"lsp_requests"`  // 按方法汇总的 gopls 请求,导入图模式下为空
+	ChangedFiles  int             `json:"changed_files"` // 变更的 Go 文件数
+	SymbolsTraced int             `json:"symbols_traced"`
+	Affected      int             `json:"affected"` // 受影响的服务数
+	Cache         CacheTiming     `json:"cache"`
+	PeakRSS       int64           `json:"peak_rss_bytes"` // 进程的峰值常驻内存,不支持的平台为 0
+}
+
+// PhaseTiming 一个分析阶段的耗时
+type PhaseTiming struct {
+	Name     string  `json:"name"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// RequestTiming 一种 gopls 请求的次数和延迟
+type RequestTiming struct {
+	Method string  `json:"method"`
+	Count  int     `json:"count"`
+	Total  float64 `json:"total_ms"`
+	Mean   float64 `json:"mean_ms"`
+	Max    float64 `json:"max_ms"`
+}
+
+// CacheTiming 缓存命中情况
+type CacheTiming struct {
+	TraceRequests int `json:"trace_requests"` // TraceToMain 请求数
+	TraceHits     int `json:"trace_hits"`     // 重复的 TraceToMain 请求,直接返回同一次运行中共享的结果
+	IndexPackages int `json:"index_packages"` // 符号索引中的包数,未使用索引时为 0
+	IndexReloaded int `json:"index_reloaded"` // 符号索引中内容变化、重新解析的包数
+}
+
+// Timings 汇总分析的性能数据,total 为包括输出结果在内的总耗时
+func (r *Result) Timings(total time.Duration) *Timings {
+	t := &Timings{
+		Total:         ms(total),
+		Phases:        []PhaseTiming{},
+		Requests:      []RequestTiming{},
+		ChangedFiles:  len(r.ChangedFiles),
+		SymbolsTraced: r.ChangedSymbols,
+		Affected:      len(r.Affected),
+		Cache: CacheTiming{
+			IndexPackages: r.IndexPackages,
+			IndexReloaded: r.IndexReloaded,
+		},
+		PeakRSS: metrics.PeakRSS(),
+	}
+	for _, phase := range r.Phases {
+		t.Phases = append(t.Phases, PhaseTiming{Name: phase.Name, Duration: ms(phase.Duration)})
+	}
+	for _, req := range r.Requests {
+		t.Requests = append(t.Requests, RequestTiming{
+			Method: req.Method,
+			Count:  req.Count,
+			Total:  ms(req.Total),
+			Mean:   ms(req.Total / time.Duration(req.Count)),
+			Max:    ms(req.Max),
+		})
+		if req.Method == "TraceToMain" {
+			t.Cache.TraceRequests = req.Count
+			t.Cache.TraceHits = req.CacheHits
+		}
+	}
+	return t
+}
+
+// ms 将时长转换为毫秒
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
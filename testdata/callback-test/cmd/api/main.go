@@ -0,0 +1,7 @@
+package main
+
+import "example.com/callback-test/internal/hooks"
+
+func main() {
+	hooks.Start()
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"example.com/callback-test/internal/jobs"
+)
+
+func main() {
+	if err := jobs.Run(os.Args[1]); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,3 @@
+module example.com/callback-test
+
+go 1.25
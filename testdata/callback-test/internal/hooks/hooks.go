@@ -0,0 +1,19 @@
+package hooks
+
+// onStart 启动时执行的回调
+var onStart []func()
+
+func init() {
+	onStart = append(onStart, warmCache)
+}
+
+func warmCache() {
+	_ = make([]byte, 1024)
+}
+
+// Start 执行所有启动回调
+func Start() {
+	for _, f := range onStart {
+		f()
+	}
+}
@@ -0,0 +1,18 @@
+package jobs
+
+import "fmt"
+
+// handlers 任务名到处理函数的注册表
+var handlers = map[string]func() error{
+	"cleanup": cleanup,
+	"report":  report,
+}
+
+// Run 执行指定的任务
+func Run(name string) error {
+	h, ok := handlers[name]
+	if !ok {
+		return fmt.Errorf("unknown job %q", name)
+	}
+	return h()
+}
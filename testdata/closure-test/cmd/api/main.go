@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/closure-test/internal/handlers"
+)
+
+// greet 在包初始化时创建,在 handle 中调用
+var greet = handlers.NewGreeter("hello ")
+
+func main() {
+	fmt.Println(handle("api"))
+}
+
+func handle(name string) string {
+	return greet(name)
+}
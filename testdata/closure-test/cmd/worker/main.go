@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/closure-test/internal/handlers"
+)
+
+func main() {
+	next := handlers.NewCounter()
+	fmt.Println(handlers.Upper("worker"), next())
+}
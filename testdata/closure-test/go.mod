@@ -0,0 +1,3 @@
+module example.com/closure-test
+
+go 1.25
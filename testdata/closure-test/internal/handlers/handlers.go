@@ -0,0 +1,25 @@
+package handlers
+
+import "strings"
+
+// NewGreeter 返回带前缀的问候函数,函数体在调用返回值时才执行
+func NewGreeter(prefix string) func(string) string {
+	return func(name string) string {
+		return prefix + name
+	}
+}
+
+// NewCounter 返回计数函数
+func NewCounter() func() int {
+	n := 0
+	next := func() int {
+		n++
+		return n
+	}
+	return next
+}
+
+// Upper 普通函数
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
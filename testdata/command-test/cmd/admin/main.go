@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+
+	"example.com/command-test/internal/app"
+	"example.com/command-test/internal/store"
+)
+
+func main() {
+	a := &app.App{
+		Name: "admin",
+		Commands: []*app.Command{
+			{
+				Name: "users",
+				Subcommands: []*app.Command{
+					{Name: "purge", Action: func(c *app.Context) error { return store.Open() }},
+					{Name: "list", Action: func(c *app.Context) error { return nil }},
+				},
+			},
+		},
+	}
+	_ = a.Run(os.Args)
+}
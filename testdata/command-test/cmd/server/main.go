@@ -0,0 +1,7 @@
+package main
+
+import "example.com/command-test/internal/store"
+
+func main() {
+	_ = store.Open()
+}
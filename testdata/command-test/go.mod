@@ -0,0 +1,3 @@
+module example.com/command-test
+
+go 1.25
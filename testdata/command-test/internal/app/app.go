@@ -0,0 +1,23 @@
+// Package app 模仿 urfave/cli 的命令定义
+package app
+
+// Context 命令上下文
+type Context struct{}
+
+// Command 子命令
+type Command struct {
+	Name        string
+	Action      func(c *Context) error
+	Subcommands []*Command
+}
+
+// App 命令行程序
+type App struct {
+	Name     string
+	Commands []*Command
+}
+
+// Run 运行程序
+func (a *App) Run(args []string) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+// Package cli 模仿 cobra 的命令定义
+package cli
+
+// Command 命令行命令
+type Command struct {
+	Use  string
+	Run  func(cmd *Command, args []string)
+	RunE func(cmd *Command, args []string) error
+
+	commands []*Command
+}
+
+// AddCommand 添加子命令
+func (c *Command) AddCommand(cmds ...*Command) {
+	c.commands = append(c.commands, cmds...)
+}
+
+// Execute 执行命令
+func (c *Command) Execute() error {
+	if c.RunE != nil {
+		return c.RunE(c, nil)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"example.com/command-test/internal/cli"
+	"example.com/command-test/internal/migrate"
+)
+
+var dbCmd = &cli.Command{Use: "db"}
+
+var migrateCmd = &cli.Command{
+	Use: "migrate [flags]",
+	RunE: func(cmd *cli.Command, args []string) error {
+		return migrate.Run()
+	},
+}
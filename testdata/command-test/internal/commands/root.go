@@ -0,0 +1,15 @@
+package commands
+
+import "example.com/command-test/internal/cli"
+
+var rootCmd = &cli.Command{Use: "myctl"}
+
+func init() {
+	rootCmd.AddCommand(dbCmd, serveCmd, newVersionCmd())
+	dbCmd.AddCommand(migrateCmd)
+}
+
+// Execute 执行根命令
+func Execute() error {
+	return rootCmd.Execute()
+}
@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"example.com/command-test/internal/cli"
+	"example.com/command-test/internal/store"
+)
+
+var serveCmd = &cli.Command{
+	Use:  "serve",
+	RunE: runServe,
+}
+
+func runServe(cmd *cli.Command, args []string) error {
+	return store.Open()
+}
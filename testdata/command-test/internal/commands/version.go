@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"fmt"
+
+	"example.com/command-test/internal/cli"
+)
+
+// Version 版本号
+const Version = "1.0.0"
+
+func newVersionCmd() *cli.Command {
+	return &cli.Command{
+		Use: "version",
+		Run: func(cmd *cli.Command, args []string) {
+			fmt.Println(Version)
+		},
+	}
+}
@@ -0,0 +1,11 @@
+package migrate
+
+import "example.com/command-test/internal/store"
+
+// Run 执行数据库迁移
+func Run() error {
+	if err := store.Open(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package store
+
+// Open 打开数据库连接
+func Open() error {
+	return nil
+}
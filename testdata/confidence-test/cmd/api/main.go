@@ -0,0 +1,8 @@
+package main
+
+import "example.com/confidence-test/internal/file"
+
+func main() {
+	f := file.Open("api.log")
+	defer f.Close()
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"example.com/confidence-test/internal/file"
+	"example.com/confidence-test/pkg/closer"
+)
+
+func main() {
+	closer.CloseAll(file.Open("worker.log"))
+}
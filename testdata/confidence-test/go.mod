@@ -0,0 +1,3 @@
+module example.com/confidence-test
+
+go 1.25
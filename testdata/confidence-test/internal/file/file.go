@@ -0,0 +1,24 @@
+package file
+
+import "fmt"
+
+// File 满足 io.Closer 和 fmt.Stringer
+type File struct {
+	name string
+}
+
+// Open 打开文件
+func Open(name string) *File {
+	return &File{name: name}
+}
+
+// Close 关闭文件
+func (f *File) Close() error {
+	fmt.Println("close", f.name)
+	return nil
+}
+
+// String 返回文件名
+func (f *File) String() string {
+	return f.name
+}
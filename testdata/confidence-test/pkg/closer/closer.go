@@ -0,0 +1,13 @@
+package closer
+
+// Closer 可关闭的资源
+type Closer interface {
+	Close() error
+}
+
+// CloseAll 依次关闭资源
+func CloseAll(cs ...Closer) {
+	for _, c := range cs {
+		_ = c.Close()
+	}
+}
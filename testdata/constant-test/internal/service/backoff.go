@@ -0,0 +1,6 @@
+package service
+
+// Backoff 返回第 attempt 次重试前的等待秒数,目前只有测试使用
+func Backoff(attempt int) int {
+	return attempt * 2
+}
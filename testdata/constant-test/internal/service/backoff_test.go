@@ -0,0 +1,9 @@
+package service
+
+import "testing"
+
+func TestBackoff(t *testing.T) {
+	if Backoff(2) != 4 {
+		t.Error("unexpected backoff")
+	}
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/dot-import-test/internal/svc"
+)
+
+func main() {
+	fmt.Println(svc.Run())
+}
@@ -0,0 +1,3 @@
+module example.com/dot-import-test
+
+go 1.21
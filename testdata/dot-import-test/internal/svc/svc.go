@@ -0,0 +1,17 @@
+package svc
+
+import (
+	"strings"
+
+	. "example.com/dot-import-test/pkg/util"
+)
+
+// Run 通过点导入调用 util.Format
+func Run() string {
+	return Format("svc")
+}
+
+// Upper 不使用点导入的标识符
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
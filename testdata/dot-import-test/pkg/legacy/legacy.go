@@ -0,0 +1,6 @@
+package legacy
+
+// Format 旧版格式化,与 util.Format 同名,可通过切换点导入替换
+func Format(name string) string {
+	return "hi " + name
+}
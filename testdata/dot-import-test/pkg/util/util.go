@@ -0,0 +1,9 @@
+package util
+
+// Greeting 问候语前缀
+const Greeting = "hello"
+
+// Format 格式化问候语
+func Format(name string) string {
+	return Greeting + ", " + name
+}
@@ -0,0 +1,8 @@
+package main
+
+import "example.com/embed-test/internal/svc"
+
+func main() {
+	s := svc.New()
+	defer s.Close()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/embed-test/internal/worker"
+
+func main() {
+	_ = worker.Run()
+}
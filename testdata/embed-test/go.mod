@@ -0,0 +1,3 @@
+module example.com/embed-test
+
+go 1.21
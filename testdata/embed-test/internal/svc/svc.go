@@ -0,0 +1,18 @@
+package svc
+
+import "example.com/embed-test/pkg/base"
+
+// Server 通过嵌入获得 Base 的方法
+type Server struct {
+	*base.Base
+}
+
+// New 创建 Server
+func New() *Server {
+	return &Server{Base: &base.Base{}}
+}
+
+// Admin 通过多层嵌入获得 Base 的方法
+type Admin struct {
+	Server
+}
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"io"
+
+	"example.com/embed-test/pkg/base"
+)
+
+// Worker 通过嵌入获得 Base 的方法
+type Worker struct {
+	base.Base
+}
+
+// Stop 通过接口关闭
+func Stop(c io.Closer) error {
+	return c.Close()
+}
+
+// Run 启动 Worker
+func Run() error {
+	w := &Worker{}
+	return Stop(w)
+}
+
+// Quiet 自己声明了 Close,不会获得 Base 的 Close
+type Quiet struct {
+	base.Base
+}
+
+// Close 什么也不做
+func (q *Quiet) Close() error {
+	return nil
+}
@@ -0,0 +1,16 @@
+package base
+
+// Base 提供通用的生命周期方法
+type Base struct {
+	name string
+}
+
+// Close 释放资源
+func (b *Base) Close() error {
+	return nil
+}
+
+// Name 返回名称
+func (b *Base) Name() string {
+	return b.name
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/entrypoint-test/internal/billing"
+)
+
+func main() {
+	fmt.Println(billing.Charge(3))
+}
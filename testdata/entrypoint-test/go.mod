@@ -0,0 +1,3 @@
+module example.com/entrypoint-test
+
+go 1.25
@@ -0,0 +1,6 @@
+package billing
+
+// Charge 计算应收金额
+func Charge(amount int) int {
+	return amount
+}
@@ -0,0 +1,14 @@
+package jobs
+
+import "example.com/entrypoint-test/internal/billing"
+
+// Reconcile 由定时任务平台直接调用,没有 main 函数
+//
+//ripples:entrypoint name=cron-reconciler
+func Reconcile() int {
+	return settle()
+}
+
+func settle() int {
+	return billing.Charge(1)
+}
@@ -0,0 +1,13 @@
+package orders
+
+import (
+	"context"
+
+	"example.com/entrypoint-test/internal/billing"
+)
+
+// Handle Lambda handler,在 ripples.yaml 中配置为入口
+func Handle(ctx context.Context) error {
+	_ = billing.Charge(2)
+	return nil
+}
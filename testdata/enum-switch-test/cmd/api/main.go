@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/enum-switch-test/internal/shipping"
+)
+
+func main() {
+	fmt.Println(shipping.Label())
+}
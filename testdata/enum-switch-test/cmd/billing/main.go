@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/enum-switch-test/internal/billing"
+	"example.com/enum-switch-test/pkg/order"
+)
+
+func main() {
+	fmt.Println(billing.Charge(order.Status(1)))
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/enum-switch-test/internal/shipping"
+	"example.com/enum-switch-test/pkg/order"
+)
+
+func main() {
+	fmt.Println(shipping.Ship(order.Status(2)))
+}
@@ -0,0 +1,3 @@
+module example.com/enum-switch-test
+
+go 1.21
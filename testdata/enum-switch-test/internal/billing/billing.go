@@ -0,0 +1,13 @@
+package billing
+
+import "example.com/enum-switch-test/pkg/order"
+
+// Charge reports whether an order loaded from storage should be charged
+func Charge(s order.Status) bool {
+	switch s {
+	case order.StatusPaid:
+		return false
+	default:
+		return true
+	}
+}
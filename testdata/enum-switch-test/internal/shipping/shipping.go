@@ -0,0 +1,13 @@
+package shipping
+
+import "example.com/enum-switch-test/pkg/order"
+
+// Ship reports whether an order loaded from storage is ready to ship
+func Ship(s order.Status) bool {
+	return s != order.StatusShipped
+}
+
+// Label names a status without comparing it
+func Label() []order.Status {
+	return []order.Status{order.StatusShipped}
+}
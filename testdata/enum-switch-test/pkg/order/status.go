@@ -0,0 +1,18 @@
+package order
+
+// Status is the state of an order, persisted as its integer value
+type Status int
+
+const (
+	StatusPending Status = iota
+	StatusPaid
+	StatusShipped
+)
+
+// DefaultRetries is not part of the Status family
+const DefaultRetries = 3
+
+// New returns the status of a new order
+func New() Status {
+	return StatusPending
+}
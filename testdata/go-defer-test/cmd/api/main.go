@@ -0,0 +1,9 @@
+package main
+
+import "example.com/go-defer-test/internal/jobs"
+
+func main() {
+	jobs.Start()
+	w := &jobs.Worker{}
+	w.Run()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/go-defer-test/internal/jobs"
+
+func main() {
+	jobs.Finish()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/go-defer-test/internal/jobs"
+)
+
+func main() {
+	fmt.Println(jobs.Version())
+}
@@ -0,0 +1,3 @@
+module example.com/go-defer-test
+
+go 1.25
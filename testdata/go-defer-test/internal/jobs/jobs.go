@@ -0,0 +1,42 @@
+package jobs
+
+import "fmt"
+
+// Worker 后台任务
+type Worker struct{}
+
+// Run 在新的 goroutine 中运行循环
+func (w *Worker) Run() {
+	go w.Loop()
+}
+
+// Loop 任务循环
+func (w *Worker) Loop() {
+	fmt.Println("loop")
+}
+
+// Start 在新的 goroutine 中轮询
+func Start() {
+	go Poll(1)
+}
+
+// Poll 轮询
+func Poll(n int) {
+	fmt.Println("poll", n)
+}
+
+// Finish 返回前清理
+func Finish() {
+	defer Cleanup("finish")
+	fmt.Println("finish")
+}
+
+// Cleanup 清理
+func Cleanup(name string) {
+	fmt.Println("cleanup", name)
+}
+
+// Version 与 go/defer 无关的函数
+func Version() string {
+	return "v1"
+}
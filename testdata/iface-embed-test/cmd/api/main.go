@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/iface-embed-test/internal/memory"
+)
+
+func main() {
+	s := memory.New()
+	s.Put("name", "api")
+	fmt.Println(s.Get("name"))
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/iface-embed-test/internal/cache"
+	"example.com/iface-embed-test/internal/layered"
+)
+
+func main() {
+	s := cache.NewCache()
+	s.Put("name", "worker")
+	fmt.Println(s.Get("name"))
+
+	l := layered.New()
+	l.Put("name", "layered")
+	fmt.Println(l.Get("name"))
+}
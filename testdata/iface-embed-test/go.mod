@@ -0,0 +1,3 @@
+module example.com/iface-embed-test
+
+go 1.25
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"sort"
+
+	"example.com/iface-embed-test/pkg/store"
+)
+
+// Cache 带键列表的缓存
+type Cache struct {
+	data map[string]string
+}
+
+// NewCache 创建缓存
+func NewCache() store.Store {
+	return &Cache{data: make(map[string]string)}
+}
+
+// Get 读取数据
+func (c *Cache) Get(key string) (string, bool) {
+	v, ok := c.data[key]
+	return v, ok
+}
+
+// Put 写入数据
+func (c *Cache) Put(key, value string) {
+	c.data[key] = value
+}
+
+// Keys 返回所有键
+func (c *Cache) Keys() []string {
+	keys := make([]string, 0, len(c.data))
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,29 @@
+package layered
+
+import "example.com/iface-embed-test/pkg/store"
+
+// Base 基础存储
+type Base struct {
+	data map[string]string
+}
+
+// Get 读取数据
+func (b *Base) Get(key string) (string, bool) {
+	v, ok := b.data[key]
+	return v, ok
+}
+
+// Put 写入数据
+func (b *Base) Put(key, value string) {
+	b.data[key] = value
+}
+
+// Layered 分层存储,方法都由嵌入的 *Base 提升而来
+type Layered struct {
+	*Base
+}
+
+// New 创建分层存储
+func New() store.Store {
+	return Layered{Base: &Base{data: make(map[string]string)}}
+}
@@ -0,0 +1,24 @@
+package memory
+
+import "example.com/iface-embed-test/pkg/store"
+
+// Memory 内存存储
+type Memory struct {
+	data map[string]string
+}
+
+// New 创建内存存储
+func New() store.Store {
+	return &Memory{data: make(map[string]string)}
+}
+
+// Get 读取数据
+func (m *Memory) Get(key string) (string, bool) {
+	v, ok := m.data[key]
+	return v, ok
+}
+
+// Put 写入数据
+func (m *Memory) Put(key, value string) {
+	m.data[key] = value
+}
@@ -0,0 +1,20 @@
+package store
+
+import stdio "io"
+
+// Reader 读取数据
+type Reader interface {
+	Get(key string) (string, bool)
+}
+
+// Store 在 Reader 的基础上支持写入
+type Store interface {
+	Reader
+	Put(key, value string)
+}
+
+// ReadCloser 可关闭的 Reader
+type ReadCloser interface {
+	Reader
+	stdio.Closer
+}
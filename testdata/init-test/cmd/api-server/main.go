@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/init-test/internal/cache"
+	"example.com/init-test/internal/db"
+	"example.com/init-test/internal/logger"
+)
+
+func main() {
+	logger.Info(db.Connect())
+	fmt.Println("cache size:", cache.Size)
+}
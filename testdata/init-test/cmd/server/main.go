@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	_ "example.com/init-test/internal/cache"
+	_ "example.com/init-test/internal/db"
+	"example.com/init-test/pkg/config"
+)
+
+func main() {
+	fmt.Println("server env:", config.Get("env"))
+}
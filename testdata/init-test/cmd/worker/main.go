@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/init-test/internal/db"
+)
+
+func main() {
+	fmt.Println("worker:", db.Connect())
+}
@@ -0,0 +1,19 @@
+package cache
+
+import "example.com/init-test/pkg/config"
+
+// Size 缓存大小
+var Size int
+
+func init() {
+	if config.Get("env") == "dev" {
+		Size = 16
+	}
+}
+
+// defaultTTL 在包初始化时计算,没有其他读取者
+var defaultTTL = computeTTL()
+
+func computeTTL() int {
+	return 60
+}
@@ -0,0 +1,15 @@
+package db
+
+import "example.com/init-test/pkg/config"
+
+// DSN 数据库连接串
+var DSN string
+
+func init() {
+	DSN = config.Get("env") + "-db"
+}
+
+// Connect 连接数据库
+func Connect() string {
+	return DSN
+}
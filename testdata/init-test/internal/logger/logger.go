@@ -0,0 +1,15 @@
+package logger
+
+import "fmt"
+
+// Prefix 日志前缀
+var Prefix string
+
+func init() {
+	Prefix = "[api] "
+}
+
+// Info 打印日志
+func Info(msg string) {
+	fmt.Println(Prefix + msg)
+}
@@ -0,0 +1,13 @@
+package config
+
+// Values 全局配置
+var Values = map[string]string{}
+
+func init() {
+	Values["env"] = "dev"
+}
+
+// Get 获取配置项
+func Get(key string) string {
+	return Values[key]
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/job-test/internal/store"
+)
+
+func main() {
+	fmt.Println(store.Reconcile(1), store.Cleanup(48))
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"time"
+
+	"example.com/job-test/internal/jobs"
+	"example.com/job-test/internal/schedule"
+)
+
+func main() {
+	s := schedule.New()
+	s.Every(24*time.Hour, jobs.RunNightlyReconcile)
+	s.Every(time.Hour, jobs.RunHourlyCleanup)
+	s.Run()
+}
@@ -0,0 +1,3 @@
+module example.com/job-test
+
+go 1.25
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"fmt"
+
+	"example.com/job-test/internal/store"
+)
+
+// RunNightlyReconcile 每晚对账
+func RunNightlyReconcile() {
+	fmt.Println(reconcileWeek())
+}
+
+// RunHourlyCleanup 每小时清理
+func RunHourlyCleanup() {
+	fmt.Println(store.Cleanup(1))
+}
+
+func reconcileWeek() int {
+	total := 0
+	for day := range 7 {
+		total += store.Reconcile(day)
+	}
+	return total
+}
@@ -0,0 +1,30 @@
+package schedule
+
+import "time"
+
+// Job 定时执行的任务
+type Job func()
+
+// Scheduler 按固定间隔执行任务
+type Scheduler struct {
+	jobs map[time.Duration][]Job
+}
+
+// New 创建调度器
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[time.Duration][]Job)}
+}
+
+// Every 登记每隔 d 执行一次的任务
+func (s *Scheduler) Every(d time.Duration, job Job) {
+	s.jobs[d] = append(s.jobs[d], job)
+}
+
+// Run 执行所有任务一次
+func (s *Scheduler) Run() {
+	for _, jobs := range s.jobs {
+		for _, job := range jobs {
+			job()
+		}
+	}
+}
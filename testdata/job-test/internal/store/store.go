@@ -0,0 +1,11 @@
+package store
+
+// Reconcile 对账,返回修正的记录数
+func Reconcile(day int) int {
+	return day % 7
+}
+
+// Cleanup 清理过期记录
+func Cleanup(hours int) int {
+	return hours / 24
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/lazy-init-test/internal/config"
+)
+
+func main() {
+	fmt.Println(config.Region())
+}
@@ -0,0 +1,3 @@
+module example.com/lazy-init-test
+
+go 1.25
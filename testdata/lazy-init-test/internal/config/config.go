@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Config 配置
+type Config struct {
+	Name string
+}
+
+var (
+	once sync.Once
+	cfg  *Config
+)
+
+// Get 第一次调用时加载配置
+func Get() *Config {
+	once.Do(func() {
+		cfg = &Config{Name: parse(os.Getenv("APP_NAME"))}
+	})
+	return cfg
+}
+
+func parse(name string) string {
+	return strings.TrimSpace(name)
+}
+
+// getRegion 第一次调用时计算区域
+var getRegion = sync.OnceValue(func() string {
+	return region(os.Getenv("APP_REGION"))
+})
+
+func region(v string) string {
+	return strings.ToLower(v)
+}
+
+// Region 返回区域
+func Region() string {
+	return getRegion()
+}
+
+// Version 与延迟初始化无关的函数
+func Version() string {
+	return "v1"
+}
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/main-package-test/internal/greet"
+
+func main() {
+	greet.Hello("api")
+}
@@ -0,0 +1,3 @@
+module example.com/main-package-test
+
+go 1.25
@@ -0,0 +1,7 @@
+package db
+
+import "fmt"
+
+func Migrate(version int) {
+	fmt.Println("migrating to", version)
+}
@@ -0,0 +1,11 @@
+package greet
+
+import "fmt"
+
+func Hello(name string) {
+	fmt.Println(message(name))
+}
+
+func message(name string) string {
+	return "hello, " + name
+}
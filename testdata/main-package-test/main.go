@@ -0,0 +1,8 @@
+// 仓库根目录下的 main 包,gopls 追踪器识别不到
+package main
+
+import "example.com/main-package-test/internal/greet"
+
+func main() {
+	greet.Hello("root")
+}
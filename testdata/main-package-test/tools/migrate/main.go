@@ -0,0 +1,8 @@
+// tools 下的 main 包,gopls 追踪器识别不到
+package main
+
+import "example.com/main-package-test/internal/db"
+
+func main() {
+	db.Migrate(1)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/method-value-test/internal/server"
+)
+
+func main() {
+	fmt.Println(server.Upper("cli"))
+}
@@ -0,0 +1,3 @@
+module example.com/method-value-test
+
+go 1.25
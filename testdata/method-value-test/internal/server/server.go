@@ -0,0 +1,48 @@
+package server
+
+import "strings"
+
+// Server 按名称分发请求,处理函数表中保存的是绑定方法
+type Server struct {
+	routes map[string]func() string
+}
+
+// New 创建服务并注册处理函数
+func New() *Server {
+	s := &Server{}
+	s.routes = map[string]func() string{
+		"status": s.Status,
+	}
+	return s
+}
+
+// Handle 调用名称对应的处理函数
+func (s *Server) Handle(name string) string {
+	return s.routes[name]()
+}
+
+// Status 返回服务状态
+func (s *Server) Status() string {
+	return "ok"
+}
+
+// Codec 编码器
+type Codec struct{}
+
+// Encode 编码
+func (Codec) Encode(v string) string {
+	return "<" + v + ">"
+}
+
+// encode 方法表达式,调用时接收者作为第一个参数
+var encode = Codec.Encode
+
+// Render 渲染
+func Render(v string) string {
+	return encode(Codec{}, v)
+}
+
+// Upper 与方法无关的函数
+func Upper(v string) string {
+	return strings.ToUpper(v)
+}
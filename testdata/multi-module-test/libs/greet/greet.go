@@ -0,0 +1,13 @@
+package greet
+
+import "fmt"
+
+// Hello 返回问候语
+func Hello(name string) string {
+	return fmt.Sprintf("hello, %s", name)
+}
+
+// Bye 返回告别语
+func Bye(name string) string {
+	return fmt.Sprintf("bye, %s", name)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+func main() {
+	fmt.Println(greet.Hello("api"))
+}
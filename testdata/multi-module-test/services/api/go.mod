@@ -0,0 +1,7 @@
+module example.com/api
+
+go 1.21
+
+require example.com/greet v0.0.0
+
+replace example.com/greet => ../../libs/greet
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/worker/internal/job"
+
+func main() {
+	job.Run()
+}
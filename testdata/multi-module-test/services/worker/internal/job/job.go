@@ -0,0 +1,17 @@
+package job
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+// Run 执行任务
+func Run() {
+	fmt.Println(greet.Bye("worker"))
+}
+
+// Step 任务中的一步
+func Step() int {
+	return 1
+}
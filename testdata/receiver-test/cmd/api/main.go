@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/receiver-test/internal/store"
+)
+
+func main() {
+	fmt.Println(store.NewLoader("config.yaml").Load())
+}
@@ -0,0 +1,3 @@
+module example.com/receiver-test
+
+go 1.25
@@ -0,0 +1,15 @@
+package store
+
+// Cache 泛型缓存
+type Cache[K comparable, V any] struct {
+	items map[K]V
+}
+
+func (c *Cache[K, V]) Get(key K) V {
+	return c.items[key]
+}
+
+// Path 与 Loader 的声明不在同一个文件中
+func (l *Loader) Path() string {
+	return l.path
+}
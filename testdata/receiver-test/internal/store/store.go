@@ -0,0 +1,15 @@
+package store
+
+// Loader 从路径读取数据
+type Loader struct {
+	path string
+}
+
+func NewLoader(path string) *Loader {
+	return &Loader{path: path}
+}
+
+// Load 的名字是接收者类型名 Loader 的前缀
+func (l *Loader) Load() string {
+	return "loaded " + l.path
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/registry-test/internal/handlers"
+)
+
+func main() {
+	fmt.Println(handlers.Dispatch("upper", "api"))
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/registry-test/internal/handlers"
+	_ "example.com/registry-test/internal/plugins"
+	"example.com/registry-test/internal/registry"
+)
+
+func main() {
+	fmt.Println(handlers.Version(), registry.Run("trim", " cli "))
+}
@@ -0,0 +1,3 @@
+module example.com/registry-test
+
+go 1.25
@@ -0,0 +1,24 @@
+package handlers
+
+import "strings"
+
+// handlers 按名称登记的处理函数
+var handlers = map[string]func(string) string{}
+
+func init() {
+	handlers["upper"] = handleUpper
+}
+
+func handleUpper(v string) string {
+	return strings.ToUpper(v)
+}
+
+// Dispatch 调用名称对应的处理函数
+func Dispatch(name, v string) string {
+	return handlers[name](v)
+}
+
+// Version 与登记无关的函数
+func Version() string {
+	return "v1"
+}
@@ -0,0 +1,16 @@
+package plugins
+
+import (
+	"strings"
+
+	"example.com/registry-test/internal/registry"
+)
+
+func init() {
+	registry.Register("trim", Trim)
+}
+
+// Trim 去掉首尾空白
+func Trim(v string) string {
+	return strings.TrimSpace(v)
+}
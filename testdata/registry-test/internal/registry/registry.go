@@ -0,0 +1,13 @@
+package registry
+
+var funcs = map[string]func(string) string{}
+
+// Register 登记处理函数
+func Register(name string, f func(string) string) {
+	funcs[name] = f
+}
+
+// Run 调用名称对应的处理函数
+func Run(name, v string) string {
+	return funcs[name](v)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"net/http"
+
+	"example.com/route-test/internal/handler"
+	"example.com/route-test/internal/router"
+)
+
+func main() {
+	r := router.New()
+	admin := r.Group("/admin")
+	admin.GET("/users", handler.AdminUsers)
+	_ = http.ListenAndServe(":8081", r)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"net/http"
+
+	"example.com/route-test/internal/handler"
+)
+
+func main() {
+	mux := http.NewServeMux()
+	handler.Register(mux)
+	_ = http.ListenAndServe(":8080", mux)
+}
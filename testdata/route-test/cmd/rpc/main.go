@@ -0,0 +1,12 @@
+package main
+
+import (
+	"example.com/route-test/internal/pb"
+	"example.com/route-test/internal/rpc"
+)
+
+func main() {
+	s := pb.NewServer()
+	pb.RegisterUserServiceServer(s, &rpc.UserServer{})
+	_ = s.Serve()
+}
@@ -0,0 +1,3 @@
+module example.com/route-test
+
+go 1.25
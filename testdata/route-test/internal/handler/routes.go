@@ -0,0 +1,11 @@
+package handler
+
+import "net/http"
+
+// Register 注册 API 路由
+func Register(mux *http.ServeMux) {
+	u := &Users{}
+	mux.HandleFunc("GET /users", u.List)
+	mux.HandleFunc("POST /users", u.Create)
+	mux.Handle("/health", http.HandlerFunc(Health))
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"example.com/route-test/internal/store"
+)
+
+// Users 用户接口
+type Users struct{}
+
+// List 列出用户
+func (u *Users) List(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, store.ListUsers())
+}
+
+// Create 创建用户
+func (u *Users) Create(w http.ResponseWriter, r *http.Request) {
+	_ = store.SaveUser(r.FormValue("name"))
+}
+
+// Health 健康检查
+func Health(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// AdminUsers 管理后台的用户列表
+func AdminUsers(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, len(store.ListUsers()))
+}
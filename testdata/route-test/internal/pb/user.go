@@ -0,0 +1,41 @@
+// Package pb 模仿 protoc-gen-go-grpc 生成的代码
+package pb
+
+import "context"
+
+// UserServiceServer 用户服务
+type UserServiceServer interface {
+	GetUser(ctx context.Context, name string) (string, error)
+}
+
+// Server gRPC 服务器
+type Server struct {
+	services map[string]any
+}
+
+// NewServer 创建服务器
+func NewServer() *Server {
+	return &Server{services: make(map[string]any)}
+}
+
+// Serve 启动服务
+func (s *Server) Serve() error {
+	return nil
+}
+
+// ServiceDesc gRPC 服务描述
+type ServiceDesc struct {
+	ServiceName string
+	HandlerType any
+}
+
+// UserService_ServiceDesc 用户服务的描述,ServiceName 带有 proto 包名
+var UserService_ServiceDesc = ServiceDesc{
+	ServiceName: "user.v1.UserService",
+	HandlerType: (*UserServiceServer)(nil),
+}
+
+// RegisterUserServiceServer 注册用户服务
+func RegisterUserServiceServer(s *Server, srv UserServiceServer) {
+	s.services[UserService_ServiceDesc.ServiceName] = srv
+}
@@ -0,0 +1,30 @@
+// Package router 模仿 gin/echo 风格的路由器
+package router
+
+import "net/http"
+
+// Router 支持分组的路由器
+type Router struct {
+	prefix string
+	mux    *http.ServeMux
+}
+
+// New 创建路由器
+func New() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// Group 创建带前缀的子路由器
+func (r *Router) Group(prefix string) *Router {
+	return &Router{prefix: r.prefix + prefix, mux: r.mux}
+}
+
+// GET 注册 GET 路由
+func (r *Router) GET(path string, h http.HandlerFunc) {
+	r.mux.HandleFunc("GET "+r.prefix+path, h)
+}
+
+// ServeHTTP 实现 http.Handler
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
@@ -0,0 +1,20 @@
+package rpc
+
+import (
+	"context"
+
+	"example.com/route-test/internal/store"
+)
+
+// UserServer 实现 pb.UserServiceServer
+type UserServer struct{}
+
+// GetUser 查询用户
+func (s *UserServer) GetUser(ctx context.Context, name string) (string, error) {
+	for _, u := range store.ListUsers() {
+		if u == name {
+			return u, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,11 @@
+package store
+
+// ListUsers 返回所有用户
+func ListUsers() []string {
+	return []string{"alice", "bob"}
+}
+
+// SaveUser 保存用户
+func SaveUser(name string) error {
+	return nil
+}
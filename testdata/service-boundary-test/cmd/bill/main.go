@@ -0,0 +1,10 @@
+package main
+
+import (
+	"example.com/boundary/internal/bill/api"
+	"example.com/boundary/pkg/grace"
+)
+
+func main() {
+	grace.Run(api.Server{})
+}
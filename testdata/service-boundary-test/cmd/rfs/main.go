@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/boundary/internal/bill/api"
+	"example.com/boundary/internal/rfs/server"
+	"example.com/boundary/pkg/grace"
+)
+
+func main() {
+	fmt.Println(api.Lookup("1"))
+	grace.Run(server.Server{})
+}
@@ -0,0 +1,17 @@
+package api
+
+import "fmt"
+
+type Server struct{}
+
+func (Server) Start() {
+	fmt.Println("bill api started")
+}
+
+// prefix 账单编号前缀
+const prefix = "bill-"
+
+// Lookup 被 rfs 服务直接调用
+func Lookup(id string) string {
+	return prefix + id
+}
@@ -0,0 +1,9 @@
+package server
+
+import "fmt"
+
+type Server struct{}
+
+func (Server) Start() {
+	fmt.Println("rfs server started")
+}
@@ -0,0 +1,10 @@
+package grace
+
+// Runner 由各服务实现,Run 通过接口调用
+type Runner interface {
+	Start()
+}
+
+func Run(r Runner) {
+	r.Start()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/var-literal-test/internal/conf"
+)
+
+func main() {
+	fmt.Println(conf.Max(), conf.Port())
+}
@@ -0,0 +1,3 @@
+module example.com/var-literal-test
+
+go 1.25
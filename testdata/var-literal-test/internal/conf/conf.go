@@ -0,0 +1,21 @@
+package conf
+
+// Limits 匿名结构体字面量
+var Limits = struct {
+	Max int
+	Min int
+}{
+	Max: 10,
+	Min: 1,
+}
+
+// Hosts 和 Ports 在同一条声明中定义
+var Hosts, Ports = []string{
+	"localhost",
+}, map[string]int{
+	"http": 80,
+}
+
+func Max() int { return Limits.Max }
+
+func Port() int { return Ports["http"] }